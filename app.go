@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 )
@@ -20,10 +22,18 @@ const LOG_FILE = "app.log"
 
 // App struct
 type App struct {
-	ctx     context.Context
-	dataDir string
-	mu      sync.Mutex
-	logFile *os.File
+	ctx         context.Context
+	dataDirFlag string       // GUI 起動時の -data フラグ
+	mu          sync.Mutex   // 以下のフィールド (store・dataDir・dataDirInfo・logFile) を保護する
+	moveMu      sync.RWMutex // ストアの操作中は読み取り、データディレクトリの移動とバックエンドの切り替え中は書き込みでロックする
+	dataDir     string
+	dataDirInfo DataDirInfo // データディレクトリの決め方
+	store       Store
+	logFile     *os.File
+	logOutput   io.Writer // ログの表示先。nil は標準出力 (コマンドラインでは標準エラー出力にする)
+
+	validationMu  sync.Mutex
+	validationLog map[string]string // プロジェクトごとに最後に記録した保存時の検証結果
 }
 
 // NewApp creates a new App application struct
@@ -36,35 +46,40 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	// カレントディレクトリの取得
-	cwd, err := os.Getwd()
-	if err != nil {
-		cwd = "."
-	}
-	a.dataDir = filepath.Join(cwd, DATA_DIR_NAME)
+	// 起動方法 (ショートカット・ターミナル) でカレントディレクトリが変わっても同じデータを開く
+	info := resolveDataDir(systemDataDirLocations(a.dataDirFlag))
+	a.setDataDir(info.Path, info)
 
-	// ログファイル初期化
-	var logErr error
-	a.logFile, logErr = os.OpenFile(LOG_FILE, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if logErr != nil {
-		fmt.Printf("ログファイルを開けません: %v\n", logErr)
-	}
+	// ログファイルはデータディレクトリに置く
+	a.openLogFile()
 
 	a.logInfo("=== アプリケーション起動 ===")
+	a.logInfo("データディレクトリ: %s (%s)", info.Path, info.Source)
+
+	a.openDataDir(info.Path)
+}
+
+// openDataDir はデータディレクトリを用意してストアを開く。
+// GUI 起動時とコマンドライン実行時の共通処理。
+func (a *App) openDataDir(dir string) {
+	a.mu.Lock()
+	a.dataDir = dir
+	a.mu.Unlock()
 
-	if _, err := os.Stat(a.dataDir); os.IsNotExist(err) {
-		if err := os.Mkdir(a.dataDir, 0755); err != nil {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
 			a.logError("dataディレクトリ作成失敗: %v", err)
 		} else {
 			a.logInfo("dataディレクトリを作成しました")
 		}
 	}
 
-	// global_assets.json が存在しない場合は自動生成
-	globalAssetsPath := filepath.Join(a.dataDir, "global_assets.json")
-	if _, err := os.Stat(globalAssetsPath); os.IsNotExist(err) {
-		defaultAssets := getDefaultGlobalAssets()
-		if err := a.saveFile(globalAssetsPath, defaultAssets); err != nil {
+	a.initStore()
+
+	// グローバルアセットが存在しない場合は自動生成
+	if _, err := a.storage().LoadGlobalAssets(); errors.Is(err, fs.ErrNotExist) {
+		defaultAssets := assetsDocument{Assets: getDefaultGlobalAssets()}
+		if err := a.saveDocument(a.storage().SaveGlobalAssets, defaultAssets); err != nil {
 			a.logError("global_assets.json 初期化失敗: %v", err)
 		} else {
 			a.logInfo("global_assets.json を初期化しました")
@@ -72,89 +87,180 @@ func (a *App) startup(ctx context.Context) {
 	}
 }
 
+// openExistingDataDir は既にあるデータディレクトリのストアを開く。
+// コマンドライン実行時に使い、ディレクトリや初期データは作らない。
+func (a *App) openExistingDataDir(dir string) error {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("data directory does not exist: %s", dir)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("data directory is not a directory: %s", dir)
+	}
+	a.mu.Lock()
+	a.dataDir = dir
+	a.mu.Unlock()
+	a.initStore()
+	return nil
+}
+
 // shutdown is called at application termination
 func (a *App) shutdown(ctx context.Context) {
-	if a.logFile != nil {
-		a.logFile.Close()
+	if store := a.storage(); store != nil {
+		if err := store.Close(); err != nil {
+			a.logError("ストアのクローズに失敗しました: %v", err)
+		}
 	}
+	a.closeLogFile()
 }
 
-// ログ出力
-func (a *App) logInfo(format string, v ...interface{}) {
-	msg := fmt.Sprintf(format, v...)
-	fmt.Println("[INFO]", msg)
-	if a.logFile != nil {
-		fmt.Fprintf(a.logFile, "[%s] [INFO] %s\n", time.Now().Format("2006-01-02 15:04:05"), msg)
-		a.logFile.Sync()
+// initStore は settings.json の storageBackend に従ってストアを開く。
+// 開けない場合は JSON ストアで起動する。
+func (a *App) initStore() {
+	dir := a.dataDirPath()
+	settingsStore := newJSONStore(dir, a.logError)
+	backend := StorageBackendJSON
+	if data, err := settingsStore.LoadSettings(); err == nil {
+		var settings AppSettings
+		if err := json.Unmarshal(data, &settings); err == nil && settings.StorageBackend != "" {
+			backend = settings.StorageBackend
+		}
+	}
+
+	store, err := openStore(backend, dir, a.logError)
+	if err != nil {
+		a.logError("ストア (%s) を開けません。JSON ストアを使用します: %v", backend, err)
+		store = settingsStore
+		backend = StorageBackendJSON
 	}
+	a.mu.Lock()
+	a.store = store
+	a.mu.Unlock()
+	a.logInfo("ストレージバックエンド: %s", backend)
 }
 
-func (a *App) logError(format string, v ...interface{}) {
-	msg := fmt.Sprintf(format, v...)
-	fmt.Println("[ERROR]", msg)
-	if a.logFile != nil {
-		fmt.Fprintf(a.logFile, "[%s] [ERROR] %s\n", time.Now().Format("2006-01-02 15:04:05"), msg)
-		a.logFile.Sync()
+// storage は現在のストアを返す。
+// 各操作はデータディレクトリの移動・バックエンドの切り替えを待ち、その時点のストアに対して行う。
+func (a *App) storage() Store {
+	if a.currentStore() == nil {
+		return nil
 	}
+	return guardedStore{a}
+}
+
+// currentStore は移動を待たずに現在のストアを返す。moveMu を書き込みでロックしている間に使う。
+func (a *App) currentStore() Store {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.store
+}
+
+// dataDirPath は現在のデータディレクトリを返す
+func (a *App) dataDirPath() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dataDir
+}
+
+// setDataDir はデータディレクトリとその決め方を記録する
+func (a *App) setDataDir(dir string, info DataDirInfo) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dataDir = dir
+	a.dataDirInfo = info
 }
 
-// ヘルパー：ファイル保存
-func (a *App) saveFile(path string, data interface{}) error {
-	bytes, err := json.MarshalIndent(data, "", "  ")
+// switchStore は現在のデータを新しいバックエンドにコピーしてから切り替える。コピー中のストアの操作は待たせる。
+func (a *App) switchStore(backend string) error {
+	a.moveMu.Lock()
+	defer a.moveMu.Unlock()
+	current := a.currentStore()
+	next, err := openStore(backend, a.dataDirPath(), a.logError)
 	if err != nil {
 		return err
 	}
+	if err := copyStore(current, next); err != nil {
+		next.Close()
+		return err
+	}
+
 	a.mu.Lock()
-	defer a.mu.Unlock()
-	return os.WriteFile(path, bytes, 0644)
+	a.store = next
+	a.mu.Unlock()
+	if err := current.Close(); err != nil {
+		a.logError("旧ストアのクローズに失敗しました: %v", err)
+	}
+	a.logInfo("ストレージバックエンドを %s に切り替えました", backend)
+	return nil
 }
 
-// ヘルパー：ファイル読み込み
-func (a *App) loadJSON(path string) ([]byte, error) {
+// ログ出力
+func (a *App) logInfo(format string, v ...interface{}) {
+	a.writeLog("INFO", fmt.Sprintf(format, v...))
+}
+
+func (a *App) logError(format string, v ...interface{}) {
+	a.writeLog("ERROR", fmt.Sprintf(format, v...))
+}
+
+// writeLog は logOutput (既定は標準出力) とログファイルに書く。ログファイルはデータディレクトリの移動で差し替わるため a.mu の中で書く。
+func (a *App) writeLog(level, msg string) {
+	out := a.logOutput
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintf(out, "[%s] %s\n", level, msg)
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	return os.ReadFile(path)
+	if a.logFile != nil {
+		fmt.Fprintf(a.logFile, "[%s] [%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), level, msg)
+		a.logFile.Sync()
+	}
+}
+
+// ヘルパー：ドキュメント保存 (schemaVersion を記録する)
+func (a *App) saveDocument(save func([]byte) error, data interface{}) error {
+	bytes, err := marshalVersioned(data)
+	if err != nil {
+		return err
+	}
+	return save(bytes)
 }
 
 // --- API Methods ---
 
 // GetAssets returns global assets
 func (a *App) GetAssets() (interface{}, error) {
-	filePath := filepath.Join(a.dataDir, "global_assets.json")
-	data, err := a.loadJSON(filePath)
+	data, err := a.storage().LoadGlobalAssets()
 	if err != nil {
 		a.logInfo("global_assets.json が見つかりません。デフォルトデータを返します")
 		return getDefaultGlobalAssets(), nil
 	}
-
-	// 1. まず構造体への直接変換を試みる（新フォーマットならロスレス）
-	var assets []Asset
-	if err := json.Unmarshal(data, &assets); err == nil {
-		// レガシー "shapes" キーの場合、Entities が空になるためチェック
-		needsMigration := false
-		for _, a := range assets {
-			if len(a.Entities) == 0 {
-				needsMigration = true
-				break
-			}
-		}
-		if !needsMigration {
-			return assets, nil
-		}
+	data, err = a.upgradeDocument(kindAssets, "global_assets.json", data, a.storage().SaveGlobalAssets)
+	if err != nil {
+		a.logError("global_assets.json の読み込みに失敗しました: %v", err)
+		return nil, err
 	}
-
-	// 2. レガシーデータ: マップ経由でマイグレーション（shapes → entities 変換）
-	var rawAssets []map[string]interface{}
-	if err := json.Unmarshal(data, &rawAssets); err != nil {
+	var doc assetsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
 		return nil, err
 	}
-	return migrateAssets(rawAssets), nil
+	if doc.Assets == nil {
+		doc.Assets = []Asset{}
+	}
+	return doc.Assets, nil
 }
 
 // SaveAssets saves global assets
 func (a *App) SaveAssets(assets interface{}) error {
-	filePath := filepath.Join(a.dataDir, "global_assets.json")
-	if err := a.saveFile(filePath, assets); err != nil {
+	doc, err := parseAssetsDocument(assets)
+	if err == nil {
+		err = a.saveDocument(a.storage().SaveGlobalAssets, doc)
+	}
+	if err != nil {
 		a.logError("グローバルアセット保存失敗: %v", err)
 		return err
 	}
@@ -162,27 +268,52 @@ func (a *App) SaveAssets(assets interface{}) error {
 	return nil
 }
 
+// parseAssetsDocument はアセットの配列またはグローバルアセットのドキュメントを現在の版で解釈する
+func parseAssetsDocument(v interface{}) (assetsDocument, error) {
+	raw, ok := v.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return assetsDocument{}, err
+		}
+	}
+	data, _, _, err := migrateDocument(kindAssets, raw)
+	if err != nil {
+		return assetsDocument{}, fmt.Errorf("failed to parse assets: %v", err)
+	}
+	var doc assetsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return assetsDocument{}, fmt.Errorf("failed to parse assets: %v", err)
+	}
+	if doc.Assets == nil {
+		doc.Assets = []Asset{}
+	}
+	return doc, nil
+}
+
+// defaultTypeColors はアセット種別ごとの既定色
+var defaultTypeColors = map[string]string{
+	"room":      "#fdfcdc",
+	"furniture": "#8fbc8f",
+	"fixture":   "#cccccc",
+}
+
+// defaultTypeLabels はアセット種別ごとの既定の表示名
+var defaultTypeLabels = map[string]string{
+	"room":      "部屋・床",
+	"furniture": "家具",
+	"fixture":   "設備・建具",
+}
+
 // GetPalette returns color palette
 func (a *App) GetPalette() (interface{}, error) {
-	filePath := filepath.Join(a.dataDir, "palette.json")
 	defaultColors := []string{
 		"#f43f5e", "#fb923c", "#facc15", "#4ade80", "#22d3d8",
 		"#3b82f6", "#8b5cf6", "#ec4899", "#78716c", "#1e293b",
 		"#ffffff", "#fdfcdc", "#fffbf0", "#f0e68c", "#e6e6fa",
 		"#b0e0e6", "#d3d3d3", "#cccccc", "#8b4513", "#87ceeb",
 	}
-	defaultTypeColors := map[string]string{
-		"room":      "#fdfcdc",
-		"furniture": "#8fbc8f",
-		"fixture":   "#cccccc",
-	}
-	defaultTypeLabels := map[string]string{
-		"room":      "部屋・床",
-		"furniture": "家具",
-		"fixture":   "設備・建具",
-	}
-
-	data, err := a.loadJSON(filePath)
+	data, err := a.storage().LoadPalette()
 	if err != nil {
 		// palette.jsonが存在しない場合、作成してデフォルトを返す
 		a.logInfo("palette.json が見つかりません。デフォルトカラーを作成します")
@@ -191,33 +322,28 @@ func (a *App) GetPalette() (interface{}, error) {
 			"defaults": defaultTypeColors,
 			"labels":   defaultTypeLabels,
 		}
-		if err := a.saveFile(filePath, defaultData); err != nil {
+		if err := a.saveDocument(a.storage().SavePalette, defaultData); err != nil {
 			a.logError("palette.json 作成失敗: %v", err)
 		}
 		return defaultData, nil
 	}
 
+	data, err = a.upgradeDocument(kindPalette, "palette.json", data, a.storage().SavePalette)
+	if err != nil {
+		a.logError("palette.json の読み込みに失敗しました: %v", err)
+		return nil, err
+	}
 	var palette map[string]interface{}
 	if err := json.Unmarshal(data, &palette); err != nil {
 		return nil, err
 	}
-
-	// マイグレーション: labels がない場合は追加
-	if _, ok := palette["labels"]; !ok {
-		a.logInfo("palette.json に labels がないため、デフォルト値を追加して保存します")
-		palette["labels"] = defaultTypeLabels
-		if err := a.saveFile(filePath, palette); err != nil {
-			a.logError("palette.json 更新失敗: %v", err)
-		}
-	}
-
+	delete(palette, "schemaVersion")
 	return palette, nil
 }
 
 // SavePalette saves color palette
 func (a *App) SavePalette(palette interface{}) error {
-	filePath := filepath.Join(a.dataDir, "palette.json")
-	if err := a.saveFile(filePath, palette); err != nil {
+	if err := a.saveDocument(a.storage().SavePalette, palette); err != nil {
 		a.logError("パレット保存失敗: %v", err)
 		return err
 	}
@@ -227,14 +353,9 @@ func (a *App) SavePalette(palette interface{}) error {
 
 // GetProjects returns list of projects
 func (a *App) GetProjects() ([]Project, error) {
-	filePath := filepath.Join(a.dataDir, "projects_index.json")
-	data, err := a.loadJSON(filePath)
+	projects, err := a.storage().ListProjects()
 	if err != nil {
-		a.logInfo("プロジェクト一覧が見つかりません")
-		return []Project{}, nil
-	}
-	var projects []Project
-	if err := json.Unmarshal(data, &projects); err != nil {
+		a.logError("プロジェクト一覧の読み込みに失敗しました: %v", err)
 		return []Project{}, nil
 	}
 	return projects, nil
@@ -242,31 +363,23 @@ func (a *App) GetProjects() ([]Project, error) {
 
 // CreateProject creates a new project
 func (a *App) CreateProject(name string) (*Project, error) {
-	filePath := filepath.Join(a.dataDir, "projects_index.json")
-
 	newProj := Project{
-		ID:        fmt.Sprintf("%d", time.Now().UnixNano()),
+		ID:        string(newProjectID(time.Now())),
 		Name:      name,
 		UpdatedAt: time.Now().Format(time.RFC3339),
 	}
 
-	projects := []Project{}
-	data, _ := a.loadJSON(filePath) // 無視して良い（ファイルがない場合は空リスト）
-	json.Unmarshal(data, &projects)
-	projects = append(projects, newProj)
-
-	if err := a.saveFile(filePath, projects); err != nil {
+	if err := a.storage().PutProject(newProj); err != nil {
 		a.logError("プロジェクト一覧保存失敗: %v", err)
 		return nil, err
 	}
 
 	// 新規プロジェクトファイル作成
-	projPath := filepath.Join(a.dataDir, fmt.Sprintf("project_%s.json", newProj.ID))
 	initialData := ProjectData{
 		LocalAssets: []Asset{},
 		Instances:   []Instance{},
 	}
-	if err := a.saveFile(projPath, initialData); err != nil {
+	if err := a.saveDocument(func(b []byte) error { return a.storage().SaveProjectData(newProj.ID, b) }, initialData); err != nil {
 		a.logError("プロジェクトファイル作成失敗: %v", err)
 		return nil, err
 	}
@@ -277,299 +390,62 @@ func (a *App) CreateProject(name string) (*Project, error) {
 
 // GetProjectData returns project details
 func (a *App) GetProjectData(id string) (ProjectData, error) {
-	if id == "" {
-		return ProjectData{}, fmt.Errorf("ID is empty")
+	if _, err := parseProjectID(id); err != nil {
+		a.logError("プロジェクトファイル読み込み失敗: %v", err)
+		return ProjectData{}, err
 	}
-	projPath := filepath.Join(a.dataDir, fmt.Sprintf("project_%s.json", id))
-
-	data, err := a.loadJSON(projPath)
+	data, err := a.storage().LoadProjectData(id)
 	if err != nil {
+		// 破損している場合に空データを返すと、次の自動保存で上書きされてしまう
+		if !errors.Is(err, fs.ErrNotExist) {
+			a.logError("プロジェクトファイル読み込み失敗 (ID: %s): %v", id, err)
+			return ProjectData{}, err
+		}
 		a.logInfo("プロジェクトファイルが見つかりません: %s", id)
 		return ProjectData{LocalAssets: []Asset{}, Instances: []Instance{}}, nil
 	}
-
-	// 1. 新しい構造体への変換を試みる
-	var projData ProjectData
-	if err := json.Unmarshal(data, &projData); err == nil {
-		// アンマーシャルが成功した場合でも、入力JSONが 'shapes' (旧キー) を使用していると
-		// 'Entities' が空になる可能性があるため確認が必要。
-		// json.Unmarshal は不明なフィールドを無視するため、レガシーデータの場合
-		// projData.Entities は空になる。データが存在するか確認する。
-		return normalizeProjectData(projData, data), nil
-	}
-
-	// 2. 互換性対応: 配列形式の場合 (非常に古いレガシーデータ)
-	var raw interface{}
-	json.Unmarshal(data, &raw)
-	if list, ok := raw.([]interface{}); ok {
-		instances := make([]Instance, len(list))
-		for i, v := range list {
-			if m, ok := v.(map[string]interface{}); ok {
-				instances[i] = mapInstance(m)
-			}
-		}
-		return ProjectData{
-			LocalAssets: []Asset{},
-			Instances:   instances,
-		}, nil
-	}
-
-	return ProjectData{}, fmt.Errorf("failed to parse project data")
-}
-
-// normalizeProjectData は読み込まれたJSONが部分的にレガシーであってもデータを完全に構築します
-func normalizeProjectData(p ProjectData, rawData []byte) ProjectData {
-	// Entitiesが存在すれば、新しいフォーマットか既に正規化済みとみなす。
-	// しかし "shapes" キーが存在するが "entities" が存在しない場合を処理する必要がある。
-	// json.Unmarshal(struct) は不明なフィールドをスキップするため、生のJSONを検査するかマップを使用する。
-
-	// レガシーフィールドを確認するためにマップにデコード
-	var rawMap map[string]interface{}
-	json.Unmarshal(rawData, &rawMap)
-
-	// LocalAssets を確認
-	if rawAssets, ok := rawMap["assets"].([]interface{}); ok {
-		// 構造体のUnmarshalがEntitiesを埋めるのに失敗した場合（例："shapes"キーのため）、再処理を行う
-		// 全アセットをチェック（一部だけレガシーの場合にも対応）
-		needsMigration := len(p.LocalAssets) != len(rawAssets)
-		if !needsMigration {
-			for _, a := range p.LocalAssets {
-				if len(a.Entities) == 0 {
-					needsMigration = true
-					break
-				}
-			}
-		}
-		if needsMigration {
-			p.LocalAssets = migrateAssets(convertToMapList(rawAssets))
-		} else {
-			rawList := convertToMapList(rawAssets)
-			for i, a := range p.LocalAssets {
-				if len(a.Entities) == 0 && i < len(rawList) {
-					p.LocalAssets[i] = mapAsset(rawList[i])
-				}
-			}
-		}
-	}
-
-	return p
-}
-
-// []interface{} を []map[string]interface{} に変換するヘルパー
-func convertToMapList(list []interface{}) []map[string]interface{} {
-	res := make([]map[string]interface{}, len(list))
-	for i, v := range list {
-		if m, ok := v.(map[string]interface{}); ok {
-			res[i] = m
-		}
-	}
-	return res
-}
-
-// マイグレーションロジック: レガシーなマップベースのアセットを []Asset に変換
-func migrateAssets(legacy []map[string]interface{}) []Asset {
-	res := make([]Asset, len(legacy))
-	for i, m := range legacy {
-		res[i] = mapAsset(m)
-	}
-	return res
-}
-
-// 単一のレガシーアセットマップを Asset 構造体にマッピング
-func mapAsset(m map[string]interface{}) Asset {
-	a := Asset{
-		ID:             getString(m, "id"),
-		Name:           getString(m, "name"),
-		Type:           getString(m, "type"),
-		W:              getFloat(m, "w"),
-		H:              getFloat(m, "h"),
-		Color:          getString(m, "color"),
-		IsDefaultShape: getBool(m, "isDefaultShape"),
-		Snap:           getBool(m, "snap"),
-	}
-
-	// boundX/boundY を保持
-	if v, ok := m["boundX"]; ok { val := getFloatVal(v); a.BoundX = &val }
-	if v, ok := m["boundY"]; ok { val := getFloatVal(v); a.BoundY = &val }
-
-	// Handle entities/shapes
-	var shapes []interface{}
-	if entities, ok := m["entities"].([]interface{}); ok {
-		shapes = entities
-	} else if s, ok := m["shapes"].([]interface{}); ok {
-		shapes = s
-	}
-
-	if shapes != nil {
-		a.Entities = make([]Entity, len(shapes))
-		for j, s := range shapes {
-			if sm, ok := s.(map[string]interface{}); ok {
-				a.Entities[j] = mapEntity(sm)
-			}
-		}
-	} else {
-		a.Entities = []Entity{}
+	data, err = a.upgradeDocument(kindProject, "プロジェクト "+id, data, func(b []byte) error { return a.storage().SaveProjectData(id, b) })
+	if err != nil {
+		a.logError("プロジェクトファイル読み込み失敗 (ID: %s): %v", id, err)
+		return ProjectData{}, err
 	}
-	return a
+	return parseProjectData(data)
 }
 
-// 単一のレガシーエンティティマップを Entity 構造体にマッピング
-func mapEntity(m map[string]interface{}) Entity {
-	e := Entity{
-		Type:  getString(m, "type"),
-		Layer: getString(m, "layer"),
-		Color: getString(m, "color"),
-	}
-	if e.Layer == "" {
-		e.Layer = "default"
-	}
-
-	// Points
-	if pts, ok := m["points"].([]interface{}); ok {
-		e.Points = make([]Point, len(pts))
-		for k, p := range pts {
-			if pm, ok := p.(map[string]interface{}); ok {
-				e.Points[k] = mapPoint(pm)
-			}
-		}
-	}
-
-	// Circle/Arc props
-	if v, ok := m["cx"]; ok {
-		val := getFloatVal(v)
-		e.CX = &val
-	}
-	if v, ok := m["cy"]; ok {
-		val := getFloatVal(v)
-		e.CY = &val
-	}
-	if v, ok := m["rx"]; ok {
-		val := getFloatVal(v)
-		e.RX = &val
-	}
-	if v, ok := m["ry"]; ok {
-		val := getFloatVal(v)
-		e.RY = &val
-	}
-	if v, ok := m["rotation"]; ok {
-		val := getFloatVal(v)
-		e.Rotation = &val
-	}
-	if v, ok := m["startAngle"]; ok {
-		val := getFloatVal(v)
-		e.StartAngle = &val
-	}
-	if v, ok := m["endAngle"]; ok {
-		val := getFloatVal(v)
-		e.EndAngle = &val
-	}
-	e.ArcMode = getString(m, "arcMode")
-
-	// Rect/Generic props
-	if v, ok := m["x"]; ok {
-		val := getFloatVal(v)
-		e.X = &val
-	}
-	if v, ok := m["y"]; ok {
-		val := getFloatVal(v)
-		e.Y = &val
+// parseProjectData は保存されたプロジェクトJSONを現在の版にして ProjectData に変換します
+func parseProjectData(data []byte) (ProjectData, error) {
+	data, _, _, err := migrateDocument(kindProject, data)
+	if err != nil {
+		return ProjectData{}, fmt.Errorf("failed to parse project data: %v", err)
 	}
-	if v, ok := m["w"]; ok {
-		val := getFloatVal(v)
-		e.W = &val
+	var projData ProjectData
+	if err := json.Unmarshal(data, &projData); err != nil {
+		return ProjectData{}, fmt.Errorf("failed to parse project data: %v", err)
 	}
-	if v, ok := m["h"]; ok {
-		val := getFloatVal(v)
-		e.H = &val
+	if projData.LocalAssets == nil {
+		projData.LocalAssets = []Asset{}
 	}
-
-	// Text
-	e.Text = getString(m, "text")
-	if v, ok := m["fontSize"]; ok {
-		val := getFloatVal(v)
-		e.FontSize = &val
+	if projData.Instances == nil {
+		projData.Instances = []Instance{}
 	}
-
-	return e
+	return projData, nil
 }
 
-func mapPoint(m map[string]interface{}) Point {
-	p := Point{
-		X:       getFloat(m, "x"),
-		Y:       getFloat(m, "y"),
-		IsCurve: getBool(m, "isCurve"),
-	}
-	if h1, ok := m["h1"].(map[string]interface{}); ok {
-		p.H1 = Vec2{X: getFloat(h1, "x"), Y: getFloat(h1, "y")}
-	}
-	if h2, ok := m["h2"].(map[string]interface{}); ok {
-		p.H2 = Vec2{X: getFloat(h2, "x"), Y: getFloat(h2, "y")}
+// SaveProjectData saves project data
+func (a *App) SaveProjectData(id string, data interface{}) error {
+	// 一覧に無いプロジェクトのファイルは作らない (任意の ID でファイルを書けないようにする)
+	if _, err := parseProjectID(id); err != nil {
+		a.logError("プロジェクト保存失敗: %v", err)
+		return err
 	}
-	if handles, ok := m["handles"].([]interface{}); ok {
-		p.Handles = make([]Vec2, len(handles))
-		for i, h := range handles {
-			if hm, ok := h.(map[string]interface{}); ok {
-				p.Handles[i] = Vec2{X: getFloat(hm, "x"), Y: getFloat(hm, "y")}
-			}
+	project, err := a.storage().GetProject(id)
+	if err != nil {
+		a.logError("プロジェクト保存失敗(一覧に無い ID) (ID: %s): %v", id, err)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("project %s does not exist", id)
 		}
+		return err
 	}
-	return p
-}
-
-func mapInstance(m map[string]interface{}) Instance {
-	inst := Instance{
-		ID:       getString(m, "id"),
-		AssetID:  getString(m, "assetId"),
-		Type:     getString(m, "type"),
-		X:        getFloat(m, "x"),
-		Y:        getFloat(m, "y"),
-		Rotation: getFloat(m, "rotation"),
-		Locked:   getBool(m, "locked"),
-		Text:     getString(m, "text"),
-		Color:    getString(m, "color"),
-	}
-	if v, ok := m["fontSize"]; ok {
-		val := getFloatVal(v)
-		inst.FontSize = &val
-	}
-	return inst
-}
-
-// Type conversion helpers
-func getString(m map[string]interface{}, key string) string {
-	if v, ok := m[key].(string); ok {
-		return v
-	}
-	return ""
-}
-
-func getFloat(m map[string]interface{}, key string) float64 {
-	return getFloatVal(m[key])
-}
-
-func getFloatVal(v interface{}) float64 {
-	switch n := v.(type) {
-	case float64:
-		return n
-	case int:
-		return float64(n)
-	case float32:
-		return float64(n)
-	}
-	return 0
-}
-
-func getBool(m map[string]interface{}, key string) bool {
-	if v, ok := m[key].(bool); ok {
-		return v
-	}
-	return false
-}
-
-// SaveProjectData saves project data
-func (a *App) SaveProjectData(id string, data interface{}) error {
-	projPath := filepath.Join(a.dataDir, fmt.Sprintf("project_%s.json", id))
-
 	// データ検証: 受け取ったデータをJSON化してProjectData構造体にマッピングできるか確認
 	bytes, err := json.Marshal(data)
 	if err != nil {
@@ -577,71 +453,78 @@ func (a *App) SaveProjectData(id string, data interface{}) error {
 		return err
 	}
 
-	var projData ProjectData
-	if err := json.Unmarshal(bytes, &projData); err != nil {
+	// 旧形式のデータ (インポートしたファイルなど) は現在の版にしてから構造体にマッピングする
+	projData, err := parseProjectData(bytes)
+	if err != nil {
 		a.logError("プロジェクト保存失敗(構造体不整合) (ID: %s): %v", id, err)
 		return fmt.Errorf("invalid project data structure: %v", err)
 	}
-
-	// レガシーの "shapes" キーを持つデータをマイグレーション
-	projData = normalizeProjectData(projData, bytes)
+	assets := newAssetIndex(projData.LocalAssets, a.globalAssets())
+	settings, _ := a.GetSettings()
+	if err := a.checkProjectData(id, projData, assets, settings.ValidationMode); err != nil {
+		a.logError("プロジェクト保存失敗(検証エラー) (ID: %s): %v", id, err)
+		return err
+	}
+	// 壁に取り付けたドア・窓を壁の位置に合わせる
+	projData = syncHostedOpenings(projData, assets)
 
 	// 検証済みのデータを保存 (元のdataを使うか、構造体を通したデータを使うか)
 	// 構造体を通すことで不正なフィールドを除外できるため、projDataを保存する
-	if err := a.saveFile(projPath, projData); err != nil {
+	doc, err := marshalVersioned(projData)
+	if err != nil {
+		a.logError("プロジェクト保存失敗(JSON化エラー) (ID: %s): %v", id, err)
+		return err
+	}
+	if err := a.storage().SaveProjectData(id, doc); err != nil {
 		a.logError("プロジェクト保存失敗 (ID: %s): %v", id, err)
 		return err
 	}
+	// 一覧で各プロジェクトを読み込まずに済むよう、間取り表記は一覧に記録する
+	if madori := describeMadori(projData, assets); madori != project.Madori {
+		project.Madori = madori
+		if err := a.storage().PutProject(project); err != nil {
+			a.logError("プロジェクト一覧の間取り更新失敗 (ID: %s): %v", id, err)
+		}
+	}
+	a.takeSnapshot(id, doc, snapshotRetention(settings))
+	// サムネイルは自動保存のたびには描かず、一覧で求められたときに作り直す
+	if err := a.storage().DeleteThumbnail(id); err != nil {
+		a.logError("サムネイル削除失敗 (ID: %s): %v", id, err)
+	}
 	a.logInfo("プロジェクト保存: %s", id)
 	return nil
 }
 
 // DeleteProject deletes a project
 func (a *App) DeleteProject(id string) error {
-	indexPath := filepath.Join(a.dataDir, "projects_index.json")
-	projPath := filepath.Join(a.dataDir, fmt.Sprintf("project_%s.json", id))
-
-	projects := []Project{}
-	data, _ := a.loadJSON(indexPath)
-	json.Unmarshal(data, &projects)
-
-	newProjects := []Project{}
-	for _, p := range projects {
-		if p.ID != id {
-			newProjects = append(newProjects, p)
-		}
+	if _, err := parseProjectID(id); err != nil {
+		a.logError("プロジェクト削除失敗: %v", err)
+		return err
 	}
-
-	if err := a.saveFile(indexPath, newProjects); err != nil {
+	if err := a.storage().DeleteProject(id); err != nil {
 		a.logError("プロジェクト削除失敗 (ID: %s): %v", id, err)
 		return err
 	}
-
-	if err := os.Remove(projPath); err != nil && !os.IsNotExist(err) {
-		a.logError("プロジェクトファイル削除失敗 (ID: %s): %v", id, err)
-		// インデックスからは消えたのでエラーはログに残すのみにするか、エラーとして返すか。
-		// ここではエラーログを出して終了とする
-	}
 	a.logInfo("プロジェクト削除: %s", id)
 	return nil
 }
 
 // UpdateProjectName updates project name
 func (a *App) UpdateProjectName(id string, name string) error {
-	indexPath := filepath.Join(a.dataDir, "projects_index.json")
-
-	projects := []Project{}
-	data, _ := a.loadJSON(indexPath)
-	json.Unmarshal(data, &projects)
-
-	for i, p := range projects {
-		if p.ID == id {
-			projects[i].Name = name
-			projects[i].UpdatedAt = time.Now().Format(time.RFC3339)
-		}
+	if _, err := parseProjectID(id); err != nil {
+		a.logError("プロジェクト名更新失敗: %v", err)
+		return err
+	}
+	store := a.storage()
+	project, err := store.GetProject(id)
+	if err != nil {
+		a.logError("プロジェクト名更新失敗 (ID: %s): %v", id, err)
+		return err
 	}
+	project.Name = name
+	project.UpdatedAt = time.Now().Format(time.RFC3339)
 
-	if err := a.saveFile(indexPath, projects); err != nil {
+	if err := store.PutProject(project); err != nil {
 		a.logError("プロジェクト名更新失敗 (ID: %s): %v", id, err)
 		return err
 	}
@@ -655,7 +538,7 @@ func (a *App) ExportProject(id string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	bytes, err := json.Marshal(data)
+	bytes, err := marshalVersioned(data)
 	if err != nil {
 		return "", err
 	}
@@ -669,6 +552,9 @@ func (a *App) ImportProject(name string, jsonData string) (*Project, error) {
 	if err := json.Unmarshal([]byte(jsonData), &raw); err != nil {
 		return nil, fmt.Errorf("invalid project data: %v", err)
 	}
+	if err := checkImport(kindProject, "project", []byte(jsonData)); err != nil {
+		return nil, err
+	}
 
 	// Create new project entry
 	newProj, err := a.CreateProject(name)
@@ -676,8 +562,8 @@ func (a *App) ImportProject(name string, jsonData string) (*Project, error) {
 		return nil, err
 	}
 
-	// Pass raw JSON as json.RawMessage so SaveProjectData preserves the original
-	// bytes (including legacy "shapes" keys) for normalizeProjectData to migrate.
+	// Pass raw JSON as json.RawMessage so SaveProjectData migrates the original
+	// bytes (including legacy "shapes" keys) with the migration pipeline.
 	if err := a.SaveProjectData(newProj.ID, json.RawMessage(jsonData)); err != nil {
 		// Cleanup if save fails
 		a.DeleteProject(newProj.ID)
@@ -700,34 +586,17 @@ func (a *App) ExportGlobalAssets() (string, error) {
 	return string(bytes), nil
 }
 
-// ImportGlobalAssets imports global assets from JSON string
+// ImportGlobalAssets imports global assets from JSON string.
+// Both a plain array of assets and a global assets document of any older schema version are accepted.
 func (a *App) ImportGlobalAssets(jsonData string, mergeMode bool) error {
-	var newAssets []Asset
-	// Try parsing as []Asset first
-	if err := json.Unmarshal([]byte(jsonData), &newAssets); err != nil {
-		// Fallback: try parsing as []map[string]interface{} and migrate
-		var rawMaps []map[string]interface{}
-		if err2 := json.Unmarshal([]byte(jsonData), &rawMaps); err2 != nil {
-			return fmt.Errorf("failed to parse assets: %v", err)
-		}
-		newAssets = migrateAssets(rawMaps)
-	} else {
-		// Check if migration is needed (legacy "shapes" key parsed as empty Entities)
-		needsMigration := false
-		for _, a := range newAssets {
-			if len(a.Entities) == 0 {
-				needsMigration = true
-				break
-			}
-		}
-		if needsMigration {
-			var rawMaps []map[string]interface{}
-			if err := json.Unmarshal([]byte(jsonData), &rawMaps); err == nil {
-				newAssets = migrateAssets(rawMaps)
-			}
-		}
+	if err := checkImport(kindAssets, "assets", []byte(jsonData)); err != nil {
+		return err
 	}
-
+	doc, err := parseAssetsDocument(json.RawMessage(jsonData))
+	if err != nil {
+		return err
+	}
+	newAssets := doc.Assets
 
 	if !mergeMode {
 		return a.SaveAssets(newAssets)
@@ -768,32 +637,58 @@ func (a *App) ImportGlobalAssets(jsonData string, mergeMode bool) error {
 
 // GetSettings returns application settings
 func (a *App) GetSettings() (AppSettings, error) {
-	filePath := filepath.Join(a.dataDir, "settings.json")
 	defaultSettings := AppSettings{
 		GridSize:         20,
 		SnapInterval:     10,
 		InitialZoom:      1.0,
 		AutoSaveInterval: 30000,
+		StorageBackend:   StorageBackendJSON,
+		ValidationMode:   ValidationModeWarn,
 	}
 
-	data, err := a.loadJSON(filePath)
+	data, err := a.storage().LoadSettings()
 	if err != nil {
 		a.logInfo("settings.json が見つかりません。デフォルト設定を返します")
 		return defaultSettings, nil
 	}
+	if upgraded, err := a.upgradeDocument(kindSettings, "settings.json", data, a.storage().SaveSettings); err == nil {
+		data = upgraded
+	}
 
 	var settings AppSettings
 	if err := json.Unmarshal(data, &settings); err != nil {
 		a.logError("settings.json の解析に失敗しました。デフォルト設定を返します: %v", err)
 		return defaultSettings, nil
 	}
+	if settings.StorageBackend == "" {
+		settings.StorageBackend = StorageBackendJSON
+	}
+	if settings.ValidationMode == "" {
+		settings.ValidationMode = ValidationModeWarn
+	}
 	return settings, nil
 }
 
 // SaveSettings saves application settings
 func (a *App) SaveSettings(settings AppSettings) error {
-	filePath := filepath.Join(a.dataDir, "settings.json")
-	if err := a.saveFile(filePath, settings); err != nil {
+	// 未指定の場合は現在のバックエンドを維持する
+	current, _ := a.GetSettings()
+	if settings.StorageBackend == "" {
+		settings.StorageBackend = current.StorageBackend
+	}
+	switch settings.ValidationMode {
+	case "", ValidationModeWarn, ValidationModeBlock:
+	default:
+		return fmt.Errorf("unknown validation mode: %s", settings.ValidationMode)
+	}
+	if settings.StorageBackend != current.StorageBackend {
+		if err := a.switchStore(settings.StorageBackend); err != nil {
+			a.logError("ストレージバックエンド切り替え失敗 (%s): %v", settings.StorageBackend, err)
+			return err
+		}
+	}
+
+	if err := a.saveDocument(a.storage().SaveSettings, settings); err != nil {
 		a.logError("設定保存失敗: %v", err)
 		return err
 	}
@@ -850,6 +745,7 @@ func getDefaultGlobalAssets() []Asset {
 		createPolygonAsset("a_pan", "防水パン", "fixture", 64, 64, "#ffffff", true),
 		createPolygonAsset("a_door", "ドア(片開)", "fixture", 80, 5, "#8b4513", true),
 		createPolygonAsset("a_window", "窓(掃出し)", "fixture", 180, 5, "#87ceeb", true),
+		createPolygonAsset("a_stair", "階段(直)", "fixture", 91, 364, "#e8dcc8", true),
 
 		// --- 家具 ---
 		createPolygonAsset("a_bed_s", "ベッド(S)", "furniture", 100, 200, "#8fbc8f", true),
@@ -861,5 +757,14 @@ func getDefaultGlobalAssets() []Asset {
 		createPolygonAsset("a_chair", "椅子", "furniture", 45, 45, "#cd853f", false),
 	}
 
+	// 3D エクスポートで押し出す高さ (cm)。無いものは種類ごとの既定値
+	heights := map[string]float64{
+		"a_kitchen": 85, "a_pan": 10, "a_bed_s": 45, "a_sofa2": 80, "a_table4": 70,
+		"a_tvboard": 45, "a_fridge": 180, "a_drum": 100, "a_chair": 80,
+	}
+	for i := range assets {
+		assets[i].Height = heights[assets[i].ID]
+	}
+
 	return assets
 }
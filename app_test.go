@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -54,7 +56,11 @@ func TestMigrateAssets(t *testing.T) {
 		},
 	}
 
-	migrated := migrateAssets(legacyData)
+	doc, err := parseAssetsDocument(legacyData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated := doc.Assets
 
 	if len(migrated) != 1 {
 		t.Fatalf("マイグレーション結果の件数が不正です: got %d, want 1", len(migrated))
@@ -78,3 +84,82 @@ func TestMigrateAssets(t *testing.T) {
 		t.Errorf("Points数が不正です: got %d, want 4", len(entity.Points))
 	}
 }
+
+// newTestApp は一時ディレクトリの JSON ストアを使う App を生成します
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	dir := t.TempDir()
+	return &App{dataDir: dir, store: newJSONStore(dir, nil)}
+}
+
+// TestGetProjectDataFallsBackToBackup は本体が破損している場合に .bak から読み込むことを検証します
+func TestGetProjectDataFallsBackToBackup(t *testing.T) {
+	app := newTestApp(t)
+	if err := app.storage().PutProject(Project{ID: "1", Name: "backup"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(app.dataDir, projectFileName("1"))
+
+	good := ProjectData{LocalAssets: []Asset{}, Instances: []Instance{{ID: "i1", Type: "room"}}}
+	for i := 0; i < 2; i++ {
+		if err := app.SaveProjectData("1", good); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 自動保存中のクラッシュを想定して本体を途中で切る
+	if err := os.WriteFile(path, []byte(`{"assets": [], "instan`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := app.GetProjectData("1")
+	if err != nil {
+		t.Fatalf("バックアップから読み込めませんでした: %v", err)
+	}
+	if len(data.Instances) != 1 || data.Instances[0].ID != "i1" {
+		t.Errorf("バックアップの内容が返されていません: %+v", data)
+	}
+}
+
+// TestGetProjectDataCorrupted はバックアップもない破損ファイルを空プロジェクトとして扱わないことを検証します
+func TestGetProjectDataCorrupted(t *testing.T) {
+	app := newTestApp(t)
+	if err := os.WriteFile(filepath.Join(app.dataDir, projectFileName("1")), []byte(`{"assets": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.GetProjectData("1"); err == nil {
+		t.Error("破損したプロジェクトファイルでエラーが返されていません")
+	}
+}
+
+// TestSaveSettingsSwitchesBackend はバックエンド変更時にデータが引き継がれることを検証します
+func TestSaveSettingsSwitchesBackend(t *testing.T) {
+	app := newTestApp(t)
+	proj, err := app.CreateProject("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings, _ := app.GetSettings()
+	settings.StorageBackend = StorageBackendSQLite
+	if err := app.SaveSettings(settings); err != nil {
+		t.Fatalf("バックエンドの切り替えに失敗しました: %v", err)
+	}
+	defer app.storage().Close()
+
+	if _, ok := app.currentStore().(*sqliteStore); !ok {
+		t.Fatalf("SQLite ストアに切り替わっていません: %T", app.storage())
+	}
+	projects, _ := app.GetProjects()
+	if len(projects) != 1 || projects[0].ID != proj.ID {
+		t.Errorf("プロジェクトが引き継がれていません: %+v", projects)
+	}
+
+	// フロントエンドが storageBackend を送らない場合は現在のバックエンドを維持する
+	settings.StorageBackend = ""
+	if err := app.SaveSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := app.GetSettings(); got.StorageBackend != StorageBackendSQLite {
+		t.Errorf("バックエンドが維持されていません: %s", got.StorageBackend)
+	}
+}
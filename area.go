@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// 畳の寸法 (GetProjectAreas の tatamiSize)
+const (
+	TatamiEdoma    = "edoma"    // 江戸間 176×88cm
+	TatamiChukyoma = "chukyoma" // 中京間 182×91cm
+	TatamiKyoma    = "kyoma"    // 京間 191×95.5cm
+)
+
+// tatamiAreasM2 は畳1枚あたりの面積 (m²)
+var tatamiAreasM2 = map[string]float64{
+	TatamiEdoma:    1.76 * 0.88,
+	TatamiChukyoma: 1.82 * 0.91,
+	TatamiKyoma:    1.91 * 0.955,
+}
+
+// tsuboM2 は1坪の面積 (m²)。1坪 = 6尺四方 = 400/121 m²
+const tsuboM2 = 400.0 / 121.0
+
+// cm2ToM2 は cm² を m² に変換する
+func cm2ToM2(v float64) float64 { return v / 10000 }
+
+// round2 は表示用に小数第2位で丸める
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// newFloorArea は m² から畳・坪を含む面積を作る
+func newFloorArea(m2 float64, tatamiM2 float64) FloorArea {
+	return FloorArea{
+		M2:    round2(m2),
+		Jo:    round2(m2 / tatamiM2),
+		Tsubo: round2(m2 / tsuboM2),
+	}
+}
+
+// computeProjectAreas は部屋インスタンスごとの床面積と合計を計算する
+func computeProjectAreas(data ProjectData, assets assetIndex, tatamiSize string) (ProjectAreas, error) {
+	if tatamiSize == "" {
+		tatamiSize = TatamiEdoma
+	}
+	tatamiM2, ok := tatamiAreasM2[tatamiSize]
+	if !ok {
+		return ProjectAreas{}, fmt.Errorf("unknown tatami size: %s", tatamiSize)
+	}
+
+	result := ProjectAreas{TatamiSize: tatamiSize, Rooms: []RoomArea{}}
+	total := 0.0
+	levelTotals := make([]float64, len(data.Levels))
+	for _, inst := range data.Instances {
+		asset := assets.lookup(inst)
+		if asset == nil || asset.Type != "room" {
+			continue
+		}
+		// 回転・平行移動では面積は変わらないため、アセット座標系のまま計算する
+		m2 := cm2ToM2(assetArea(*asset))
+		total += m2
+		room := RoomArea{
+			InstanceID: inst.ID,
+			AssetID:    asset.ID,
+			Name:       asset.Name,
+			Area:       newFloorArea(m2, tatamiM2),
+			PerimeterM: round2(assetPerimeter(*asset) / 100),
+		}
+		if len(data.Levels) > 0 {
+			idx := instanceLevel(inst, data.Levels)
+			room.LevelID = data.Levels[idx].ID
+			levelTotals[idx] += m2
+		}
+		result.Rooms = append(result.Rooms, room)
+	}
+	for i, l := range data.Levels {
+		result.Levels = append(result.Levels, LevelArea{LevelID: l.ID, Name: l.Name, Total: newFloorArea(levelTotals[i], tatamiM2)})
+	}
+	result.Total = newFloorArea(total, tatamiM2)
+	return result, nil
+}
+
+// GetProjectAreas returns the floor area of each room instance and the total floor area.
+// tatamiSize selects the tatami used for 畳 ("edoma", "chukyoma" or "kyoma"; default "edoma").
+func (a *App) GetProjectAreas(id string, tatamiSize string) (ProjectAreas, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return ProjectAreas{}, err
+	}
+	areas, err := computeProjectAreas(data, assets, tatamiSize)
+	if err != nil {
+		a.logError("面積計算失敗 (ID: %s): %v", id, err)
+		return ProjectAreas{}, err
+	}
+	return areas, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- 部材表 ---
+// 配置した設備・家具をアセットごとに数え、置かれた部屋ごとの内訳と金額 (単価 × 数量) をまとめる。
+// 部屋はインスタンスと重なる面積が最も大きい同じ階の部屋とする。どの部屋とも重ならないものは部屋外とする。
+// 複数の階に現れる階段は置いた階で数える。
+
+// bomTypeOrder は部材表に載せるアセットの種類と並び順
+var bomTypeOrder = map[string]int{"fixture": 0, "furniture": 1}
+
+// bomTypeLabels は CSV・XLSX に書く種類の名前
+var bomTypeLabels = map[string]string{"fixture": "設備", "furniture": "家具"}
+
+// buildBillOfMaterials はプロジェクトの部材表を作る
+func buildBillOfMaterials(data ProjectData, assets assetIndex) BillOfMaterials {
+	bom := BillOfMaterials{Items: []BOMItem{}}
+	items := map[string]*BOMItem{}
+	var order []string
+	seen := map[string]bool{}
+	multiLevel := len(data.Levels) > 1
+	for _, level := range splitLevels(data, assets) {
+		shapes := placeShapes(level, assets)
+		var rooms []placedShape
+		for _, s := range shapes {
+			if s.Asset.Type == "room" {
+				rooms = append(rooms, s)
+			}
+		}
+		for _, s := range shapes {
+			if _, ok := bomTypeOrder[s.Asset.Type]; !ok || seen[s.Inst.ID] {
+				continue
+			}
+			seen[s.Inst.ID] = true
+
+			item := items[s.Asset.ID]
+			if item == nil {
+				a := s.Asset
+				item = &BOMItem{
+					AssetID: a.ID, Name: a.Name, Type: a.Type, W: a.W, H: a.H, Color: a.Color,
+					Manufacturer: a.Manufacturer, SKU: a.SKU, UnitPrice: a.UnitPrice,
+					Rooms: []BOMRoomCount{}, InstanceIDs: []string{},
+				}
+				items[a.ID] = item
+				order = append(order, a.ID)
+			}
+			item.Count++
+			item.InstanceIDs = append(item.InstanceIDs, s.Inst.ID)
+
+			room := BOMRoomCount{RoomName: "部屋外"}
+			best := 0.0
+			for _, r := range rooms {
+				if area := overlapArea(s, r); area > best {
+					best = area
+					room = BOMRoomCount{RoomID: r.Inst.ID, RoomName: r.Asset.Name}
+				}
+			}
+			if len(level.Levels) > 0 {
+				room.LevelID = level.Levels[0].ID
+			}
+			if multiLevel {
+				room.RoomName = level.Levels[0].Name + " " + room.RoomName
+			}
+			counted := false
+			for i := range item.Rooms {
+				if item.Rooms[i].RoomID == room.RoomID && item.Rooms[i].LevelID == room.LevelID {
+					item.Rooms[i].Count++
+					counted = true
+				}
+			}
+			if !counted {
+				room.Count = 1
+				item.Rooms = append(item.Rooms, room)
+			}
+		}
+	}
+
+	for _, id := range order {
+		item := items[id]
+		item.Amount = round2(item.UnitPrice * float64(item.Count))
+		bom.Items = append(bom.Items, *item)
+		bom.TotalCount += item.Count
+		bom.TotalAmount += item.Amount
+	}
+	bom.TotalAmount = round2(bom.TotalAmount)
+	sort.SliceStable(bom.Items, func(i, j int) bool {
+		a, b := bom.Items[i], bom.Items[j]
+		if bomTypeOrder[a.Type] != bomTypeOrder[b.Type] {
+			return bomTypeOrder[a.Type] < bomTypeOrder[b.Type]
+		}
+		return a.Name < b.Name
+	})
+	return bom
+}
+
+// bomHeader は CSV・XLSX の見出し
+var bomHeader = []string{"アセットID", "名称", "種類", "幅(cm)", "奥行(cm)", "色", "メーカー", "品番", "単価", "数量", "金額", "部屋"}
+
+// bomRows は部材表を見出し・明細・合計の行にする。数値の列は float64 または int。
+func bomRows(bom BillOfMaterials) [][]interface{} {
+	header := make([]interface{}, len(bomHeader))
+	for i, h := range bomHeader {
+		header[i] = h
+	}
+	rows := [][]interface{}{header}
+	for _, item := range bom.Items {
+		var rooms []string
+		for _, r := range item.Rooms {
+			rooms = append(rooms, fmt.Sprintf("%s×%d", r.RoomName, r.Count))
+		}
+		rows = append(rows, []interface{}{
+			item.AssetID, item.Name, bomTypeLabels[item.Type], item.W, item.H, item.Color,
+			item.Manufacturer, item.SKU, item.UnitPrice, item.Count, item.Amount, strings.Join(rooms, "、"),
+		})
+	}
+	return append(rows, []interface{}{"合計", "", "", "", "", "", "", "", "", bom.TotalCount, bom.TotalAmount, ""})
+}
+
+// encodeBOMCSV は部材表を CSV にする
+func encodeBOMCSV(bom BillOfMaterials) (string, error) {
+	return encodeCSV(bomRows(bom))
+}
+
+// encodeCSV は行の一覧を CSV にする。Excel で文字化けしないように UTF-8 の BOM を付ける。
+func encodeCSV(rows [][]interface{}) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			switch v := v.(type) {
+			case float64:
+				record[i] = fmtNum(v)
+			case int:
+				record[i] = strconv.Itoa(v)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// GetBillOfMaterials returns the schedule of the placed fixtures and furniture: the count of each asset,
+// its dimensions, colour and product details, the rooms the pieces are in and the amount at the unit price.
+func (a *App) GetBillOfMaterials(id string) (BillOfMaterials, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return BillOfMaterials{}, err
+	}
+	return buildBillOfMaterials(data, assets), nil
+}
+
+// ExportBillOfMaterialsCSV returns the bill of materials as CSV (UTF-8 with BOM) with a total row.
+func (a *App) ExportBillOfMaterialsCSV(id string) (string, error) {
+	bom, err := a.GetBillOfMaterials(id)
+	if err != nil {
+		return "", err
+	}
+	out, err := encodeBOMCSV(bom)
+	if err != nil {
+		a.logError("部材表 CSV エクスポート失敗 (ID: %s): %v", id, err)
+		return "", err
+	}
+	a.logInfo("部材表 CSV エクスポート: %s", id)
+	return out, nil
+}
+
+// ExportBillOfMaterialsXLSX returns the bill of materials as an Excel workbook (.xlsx)
+// (base64-encoded on the frontend side).
+func (a *App) ExportBillOfMaterialsXLSX(id string) ([]byte, error) {
+	bom, err := a.GetBillOfMaterials(id)
+	if err != nil {
+		return nil, err
+	}
+	out, err := encodeXLSX("部材表", bomRows(bom))
+	if err != nil {
+		a.logError("部材表 XLSX エクスポート失敗 (ID: %s): %v", id, err)
+		return nil, err
+	}
+	a.logInfo("部材表 XLSX エクスポート: %s", id)
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestBillOfMaterials はアセットごとの数量・部屋ごとの内訳・金額と CSV・XLSX の書き出しを検証します
+func TestBillOfMaterials(t *testing.T) {
+	data := ProjectData{
+		LocalAssets: []Asset{{
+			ID: "l_chair", Name: "ダイニングチェア", Type: "furniture", W: 45, H: 50, Color: "#cd853f",
+			Manufacturer: "Acme", SKU: "DC-01", UnitPrice: 12000,
+		}},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_room6", Type: "room"},
+			{ID: "ldk", AssetID: "a_ldk10", Type: "room", Y: 270},
+			{ID: "c1", AssetID: "l_chair", Type: "furniture", X: 10, Y: 300},
+			{ID: "c2", AssetID: "l_chair", Type: "furniture", X: 100, Y: 300},
+			{ID: "c3", AssetID: "l_chair", Type: "furniture", X: 10, Y: 10},
+			{ID: "c4", AssetID: "l_chair", Type: "furniture", X: 1000, Y: 1000},
+			{ID: "bed", AssetID: "a_bed_s", Type: "furniture", X: 200, Y: 20},
+			// 部屋の境界をまたぐドアは重なりの大きい部屋に数える
+			{ID: "door", AssetID: "a_door", Type: "fixture", X: 100, Y: 268},
+			{ID: "label", Type: "text", Text: "メモ"},
+		},
+	}
+	assets := newAssetIndex(data.LocalAssets, getDefaultGlobalAssets())
+
+	bom := buildBillOfMaterials(data, assets)
+	if len(bom.Items) != 3 || bom.Items[0].AssetID != "a_door" || bom.TotalCount != 6 || bom.TotalAmount != 48000 {
+		t.Fatalf("部材表が不正です: %+v", bom)
+	}
+	var chair BOMItem
+	for _, item := range bom.Items {
+		if item.AssetID == "l_chair" {
+			chair = item
+		}
+	}
+	if chair.Count != 4 || chair.Amount != 48000 || chair.SKU != "DC-01" || len(chair.Rooms) != 3 {
+		t.Fatalf("椅子の行が不正です: %+v", chair)
+	}
+	if r := chair.Rooms[0]; r.RoomID != "ldk" || r.Count != 2 {
+		t.Errorf("LDK の椅子の数が不正です: %+v", r)
+	}
+	if r := chair.Rooms[2]; r.RoomID != "" || r.RoomName != "部屋外" || r.Count != 1 {
+		t.Errorf("部屋外の椅子が不正です: %+v", r)
+	}
+	if door := bom.Items[0]; door.Rooms[0].RoomID != "ldk" {
+		t.Errorf("ドアの部屋が不正です: %+v", door.Rooms)
+	}
+
+	out, err := encodeBOMCSV(bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 || records[0][0] != "アセットID" || records[4][0] != "合計" || records[4][10] != "48000" {
+		t.Errorf("CSV が不正です: %v", records)
+	}
+	if want := "LDK (10畳)×2、洋室 (6畳)×1、部屋外×1"; records[2][11] != want && records[3][11] != want {
+		t.Errorf("CSV の部屋の列が不正です: %v", records)
+	}
+
+	xlsx, err := encodeXLSX("部材表", bomRows(bom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(xlsx), int64(len(xlsx)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(b)
+		}
+	}
+	if len(zr.File) != 6 || !strings.Contains(sheet, `<c r="J5"><v>6</v></c>`) || !strings.Contains(sheet, "ダイニングチェア") {
+		t.Errorf("XLSX のシートが不正です: %s", sheet)
+	}
+	if xlsxColumn(0) != "A" || xlsxColumn(25) != "Z" || xlsxColumn(26) != "AA" {
+		t.Error("列名が不正です")
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// --- 寸法のルールチェック ---
+// 重なりの有無に加えて、使うために空けておくべき寸法を調べる。
+//   fixtureFront  : キッチン・防水パンなどの建具の前 (壁と反対側) に空ける奥行き
+//   corridorWidth : 廊下・ホールの幅 (部屋アセットの短辺)
+//   doorWidth     : ドアの幅
+//   doorSwing     : ドアの部屋側に空ける奥行き (開き戸の軌跡)
+//   passageWidth  : 向かい合う家具の間の通路幅
+// 違反には関係するインスタンスと、可能な場合は動かす先の位置を付ける。
+
+const (
+	ClearancePresetStandard    = "standard"
+	ClearancePresetBarrierFree = "barrierFree" // 車いす使用を想定した寸法
+)
+
+// clearancePresets はプリセットごとの寸法 (cm)
+var clearancePresets = map[string]ClearanceRules{
+	ClearancePresetStandard:    {FixtureFront: 80, CorridorWidth: 78, DoorWidth: 60, PassageWidth: 60},
+	ClearancePresetBarrierFree: {FixtureFront: 150, CorridorWidth: 85, DoorWidth: 80, PassageWidth: 80},
+}
+
+const (
+	clearanceTolerance = 0.5  // 測定誤差として許す不足 (cm)
+	minPassageGap      = 30.0 // これより狭い家具の間は通路とみなさない (cm)
+)
+
+// resolveClearanceRules はプリセットの値で未指定の寸法を埋める
+func resolveClearanceRules(r ClearanceRules) (ClearanceRules, error) {
+	if r.Preset == "" {
+		r.Preset = ClearancePresetStandard
+	}
+	preset, ok := clearancePresets[r.Preset]
+	if !ok {
+		return ClearanceRules{}, fmt.Errorf("unknown clearance preset: %q", r.Preset)
+	}
+	if r.FixtureFront <= 0 {
+		r.FixtureFront = preset.FixtureFront
+	}
+	if r.CorridorWidth <= 0 {
+		r.CorridorWidth = preset.CorridorWidth
+	}
+	if r.DoorWidth <= 0 {
+		r.DoorWidth = preset.DoorWidth
+	}
+	if r.PassageWidth <= 0 {
+		r.PassageWidth = preset.PassageWidth
+	}
+	return r, nil
+}
+
+// isCorridor は部屋アセットが廊下・ホールかどうかを名前から判定する
+func isCorridor(asset Asset) bool {
+	name := strings.ToLower(asset.Name)
+	for _, w := range []string{"廊下", "ホール", "通路", "hall", "corridor"} {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// shapeSide はインスタンスの外接矩形 (アセット座標) の1辺をワールド座標にしたもの
+type shapeSide struct {
+	A, B   Vec2 // 辺の両端
+	Normal Vec2 // 外向きの単位法線
+}
+
+// instanceSides は下・右・上・左の順に4辺を返す。向かい合う辺は添字が2つ違う。
+func instanceSides(inst Instance, lb bbox) [4]shapeSide {
+	tf := instanceTransform(inst)
+	corners := [4]Vec2{lb.Min, {X: lb.Max.X, Y: lb.Min.Y}, lb.Max, {X: lb.Min.X, Y: lb.Max.Y}}
+	normals := [4]Vec2{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+	var sides [4]shapeSide
+	for i := range sides {
+		sides[i] = shapeSide{A: tf(corners[i]), B: tf(corners[(i+1)%4]), Normal: rotateVec(normals[i], inst.Rotation)}
+	}
+	return sides
+}
+
+// strip は辺の外側に奥行き depth の長方形 (反時計回り) を作る
+func (s shapeSide) strip(depth float64) []Vec2 {
+	off := vscale(s.Normal, depth)
+	poly := []Vec2{s.A, s.B, vadd(s.B, off), vadd(s.A, off)}
+	if polygonArea(poly) < 0 {
+		poly[1], poly[3] = poly[3], poly[1]
+	}
+	return poly
+}
+
+// clearanceChecker はプロジェクトの形をまとめて持つ
+type clearanceChecker struct {
+	Rules  ClearanceRules
+	Shapes []placedShape
+	Rooms  []*placedShape
+	Report ClearanceReport
+}
+
+// roomAt は点を含む部屋を返す
+func (c *clearanceChecker) roomAt(p Vec2) *placedShape {
+	for _, r := range c.Rooms {
+		if r.contains(p) {
+			return r
+		}
+	}
+	return nil
+}
+
+// freeDepth は辺の外側に depth だけ空いているかを調べ、空いている奥行きと、それを狭めている物を返す。
+// room を指定した場合は部屋の壁までの距離も考える。
+func (c *clearanceChecker) freeDepth(self *placedShape, side shapeSide, depth float64, room *placedShape) (float64, *placedShape) {
+	free := depth
+	var blocker *placedShape
+	if room != nil {
+		for _, t := range []float64{0.05, 0.5, 0.95} {
+			p := vadd(vlerp(side.A, side.B, t), vscale(side.Normal, 0.01))
+			for _, poly := range room.Polygons {
+				free = math.Min(free, rayDistance(p, side.Normal, poly)+0.01)
+			}
+		}
+	}
+
+	strip := side.strip(depth)
+	var sb bbox
+	for _, v := range strip {
+		sb.add(v)
+	}
+	for i := range c.Shapes {
+		o := &c.Shapes[i]
+		if o == self || o.Asset.Type == "room" || openingKind(*o.Asset) != "" || !boundsOverlap(o.Bounds, sb) {
+			continue
+		}
+		for _, tris := range o.Triangles {
+			for _, tri := range tris {
+				clipped := clipConvex(tri[:], strip)
+				if len(clipped) < 3 || math.Abs(polygonArea(clipped)) < 0.01 {
+					continue
+				}
+				for _, v := range clipped {
+					if d := vdot(vsub(v, side.A), side.Normal); d < free {
+						free, blocker = math.Max(d, 0), o
+					}
+				}
+			}
+		}
+	}
+	return free, blocker
+}
+
+// violation は不足があれば違反として記録する。blocker がある場合はそれを押し出す位置を提案する。
+func (c *clearanceChecker) violation(rule, message string, self *placedShape, required, actual float64, blocker *placedShape, push Vec2) {
+	c.Report.Checked++
+	if actual >= required-clearanceTolerance {
+		return
+	}
+	v := ClearanceViolation{
+		Rule:        rule,
+		Message:     message,
+		InstanceIDs: []string{self.Inst.ID},
+		Required:    required,
+		Actual:      round2(actual),
+	}
+	if blocker != nil {
+		v.InstanceIDs = append(v.InstanceIDs, blocker.Inst.ID)
+		move := vscale(push, required-actual)
+		v.Fix = &ClearanceFix{InstanceID: blocker.Inst.ID, X: roundCoord(blocker.Inst.X + move.X), Y: roundCoord(blocker.Inst.Y + move.Y)}
+	}
+	c.Report.Violations = append(c.Report.Violations, v)
+}
+
+// checkFixture は建具の前 (部屋の壁から遠い側) に空きがあるかを調べる
+func (c *clearanceChecker) checkFixture(s *placedShape) {
+	lb := assetLocalBounds(*s.Asset)
+	sides := instanceSides(s.Inst, lb)
+	room := c.roomAt(instanceTransform(s.Inst)(lb.center()))
+	front := 0
+	if room != nil {
+		// 壁に最も近い辺 (同じなら長い辺) を背面とし、その反対側を正面とする
+		best, bestLen := math.Inf(1), 0.0
+		for i, side := range sides {
+			mid := vlerp(side.A, side.B, 0.5)
+			length := vlen(vsub(side.B, side.A))
+			for _, poly := range room.Polygons {
+				d := distToPolygon(mid, poly)
+				if d < best-clearanceTolerance || (d < best+clearanceTolerance && length > bestLen) {
+					best, bestLen, front = d, length, (i+2)%4
+				}
+			}
+		}
+	}
+	side := sides[front]
+	free, blocker := c.freeDepth(s, side, c.Rules.FixtureFront, room)
+	c.violation("fixtureFront", fmt.Sprintf("%s の前に %.0fcm の空きがありません (%.0fcm)", s.Asset.Name, c.Rules.FixtureFront, free),
+		s, c.Rules.FixtureFront, free, blocker, side.Normal)
+}
+
+// checkDoor はドアの幅と、部屋側の開き戸の軌跡に物が無いかを調べる
+func (c *clearanceChecker) checkDoor(s *placedShape) {
+	lb := assetLocalBounds(*s.Asset)
+	width := math.Max(lb.width(), lb.height())
+	c.violation("doorWidth", fmt.Sprintf("%s の幅が %.0fcm 未満です (%.0fcm)", s.Asset.Name, c.Rules.DoorWidth, width),
+		s, c.Rules.DoorWidth, width, nil, Vec2{})
+
+	depth := c.Rules.DoorSwing
+	if depth <= 0 {
+		depth = width
+	}
+	sides := instanceSides(s.Inst, lb)
+	long := []int{0, 2}
+	if lb.height() > lb.width() {
+		long = []int{1, 3}
+	}
+	for _, i := range long {
+		side := sides[i]
+		probe := vadd(vlerp(side.A, side.B, 0.5), vscale(side.Normal, depth/2))
+		if c.roomAt(probe) == nil {
+			continue
+		}
+		free, blocker := c.freeDepth(s, side, depth, nil)
+		c.violation("doorSwing", fmt.Sprintf("%s の開く範囲 (奥行き %.0fcm) に物があります", s.Asset.Name, depth),
+			s, depth, free, blocker, side.Normal)
+	}
+}
+
+// checkCorridor は廊下・ホールの幅 (アセットの短辺) を調べる
+func (c *clearanceChecker) checkCorridor(s *placedShape) {
+	lb := assetLocalBounds(*s.Asset)
+	width := math.Min(lb.width(), lb.height())
+	c.violation("corridorWidth", fmt.Sprintf("%s の幅が %.0fcm 未満です (%.0fcm)", s.Asset.Name, c.Rules.CorridorWidth, width),
+		s, c.Rules.CorridorWidth, width, nil, Vec2{})
+}
+
+// checkPassages は同じ部屋で向かい合う家具・建具の間の通路幅を調べる
+func (c *clearanceChecker) checkPassages(items []*placedShape) {
+	for i, a := range items {
+		for _, b := range items[i+1:] {
+			if c.roomAt(a.Bounds.center()) != c.roomAt(b.Bounds.center()) {
+				continue
+			}
+			// 外接矩形が縦または横に向かい合っている場合の隙間
+			gap, push := -1.0, Vec2{}
+			overlapX := math.Min(a.Bounds.Max.X, b.Bounds.Max.X) - math.Max(a.Bounds.Min.X, b.Bounds.Min.X)
+			overlapY := math.Min(a.Bounds.Max.Y, b.Bounds.Max.Y) - math.Max(a.Bounds.Min.Y, b.Bounds.Min.Y)
+			switch {
+			case overlapY > 0 && b.Bounds.Min.X >= a.Bounds.Max.X:
+				gap, push = b.Bounds.Min.X-a.Bounds.Max.X, Vec2{X: 1}
+			case overlapY > 0 && a.Bounds.Min.X >= b.Bounds.Max.X:
+				gap, push = a.Bounds.Min.X-b.Bounds.Max.X, Vec2{X: -1}
+			case overlapX > 0 && b.Bounds.Min.Y >= a.Bounds.Max.Y:
+				gap, push = b.Bounds.Min.Y-a.Bounds.Max.Y, Vec2{Y: 1}
+			case overlapX > 0 && a.Bounds.Min.Y >= b.Bounds.Max.Y:
+				gap, push = a.Bounds.Min.Y-b.Bounds.Max.Y, Vec2{Y: -1}
+			}
+			if gap < minPassageGap {
+				continue
+			}
+			c.violation("passageWidth", fmt.Sprintf("%s と %s の間の通路が %.0fcm 未満です (%.0fcm)", a.Asset.Name, b.Asset.Name, c.Rules.PassageWidth, gap),
+				a, c.Rules.PassageWidth, gap, b, push)
+		}
+	}
+}
+
+// check は1つの階の形を調べる
+func (c *clearanceChecker) check() {
+	for i := range c.Shapes {
+		if c.Shapes[i].Asset.Type == "room" {
+			c.Rooms = append(c.Rooms, &c.Shapes[i])
+		}
+	}
+
+	var items []*placedShape
+	for i := range c.Shapes {
+		s := &c.Shapes[i]
+		switch {
+		case s.Asset.Type == "room":
+			if isCorridor(*s.Asset) {
+				c.checkCorridor(s)
+			}
+		case openingKind(*s.Asset) == "door":
+			c.checkDoor(s)
+		case openingKind(*s.Asset) == "window":
+		case isStair(*s.Asset):
+			items = append(items, s)
+		case s.Asset.Type == "fixture":
+			c.checkFixture(s)
+			items = append(items, s)
+		default:
+			items = append(items, s)
+		}
+	}
+	c.checkPassages(items)
+}
+
+// checkClearances はプロジェクトの寸法のルールを調べる
+func checkClearances(data ProjectData, assets assetIndex, rules ClearanceRules) (ClearanceReport, error) {
+	rules, err := resolveClearanceRules(rules)
+	if err != nil {
+		return ClearanceReport{}, err
+	}
+	report := ClearanceReport{Rules: rules, Violations: []ClearanceViolation{}}
+	for _, level := range splitLevels(data, assets) {
+		c := &clearanceChecker{Rules: rules, Shapes: placeShapes(level, assets)}
+		c.Report = ClearanceReport{Violations: []ClearanceViolation{}}
+		c.check()
+		report.Checked += c.Report.Checked
+		report.Violations = append(report.Violations, c.Report.Violations...)
+	}
+
+	order := map[string]int{"fixtureFront": 0, "doorSwing": 1, "doorWidth": 2, "corridorWidth": 3, "passageWidth": 4}
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		return order[report.Violations[i].Rule] < order[report.Violations[j].Rule]
+	})
+	return report, nil
+}
+
+// CheckClearances checks clearances in front of fixtures and doors, corridor and door widths
+// and passages between furniture. Rules left at 0 take the values of rules.Preset.
+func (a *App) CheckClearances(id string, rules ClearanceRules) (ClearanceReport, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return ClearanceReport{}, err
+	}
+	report, err := checkClearances(data, assets, rules)
+	if err != nil {
+		a.logError("寸法チェック失敗 (ID: %s): %v", id, err)
+		return ClearanceReport{}, err
+	}
+	return report, nil
+}
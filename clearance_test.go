@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+// clearanceTestProject は LDK (360×450) と幅 75cm の廊下のプロジェクト
+func clearanceTestProject() ProjectData {
+	corridor := roomRectAsset("l-corridor", "廊下", 75, 300, "#eeeeee")
+	return ProjectData{
+		LocalAssets: []Asset{corridor},
+		Instances: []Instance{
+			{ID: "ldk", AssetID: "a_ldk10", Type: "room"},
+			{ID: "corridor", AssetID: "l-corridor", Type: "room", X: 360},
+			{ID: "kitchen", AssetID: "a_kitchen", Type: "fixture", X: 0, Y: 385},
+			{ID: "table", AssetID: "a_table4", Type: "furniture", X: 20, Y: 300},
+			{ID: "door", AssetID: "a_door", Type: "fixture", X: 80, Y: -2.5},
+			{ID: "chair", AssetID: "a_chair", Type: "furniture", X: 100, Y: 30},
+			{ID: "sofa", AssetID: "a_sofa2", Type: "furniture", X: 20, Y: 100},
+			{ID: "tv", AssetID: "a_tvboard", Type: "furniture", X: 210, Y: 120},
+		},
+	}
+}
+
+// TestCheckClearances は建具の前・ドアの開く範囲・廊下幅・通路幅の違反と修正位置を検証します
+func TestCheckClearances(t *testing.T) {
+	data := clearanceTestProject()
+	assets := newAssetIndex(data.LocalAssets, getDefaultGlobalAssets())
+	report, err := checkClearances(data, assets, ClearanceRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rules.Preset != ClearancePresetStandard || report.Rules.FixtureFront != 80 {
+		t.Errorf("プリセットの値が使われていません: %+v", report.Rules)
+	}
+	if report.Checked == 0 {
+		t.Error("チェックの件数が数えられていません")
+	}
+
+	want := []struct {
+		rule   string
+		ids    []string
+		actual float64
+		fix    *ClearanceFix
+	}{
+		// キッチンは上の壁に付いているので下が正面。食卓との間は 5cm しかない
+		{"fixtureFront", []string{"kitchen", "table"}, 5, &ClearanceFix{InstanceID: "table", X: 20, Y: 225}},
+		// ドアの部屋側 80cm の範囲に椅子がある
+		{"doorSwing", []string{"door", "chair"}, 27.5, &ClearanceFix{InstanceID: "chair", X: 100, Y: 82.5}},
+		{"corridorWidth", []string{"corridor"}, 75, nil},
+		{"passageWidth", []string{"sofa", "tv"}, 30, &ClearanceFix{InstanceID: "tv", X: 240, Y: 120}},
+	}
+	if len(report.Violations) != len(want) {
+		t.Fatalf("違反の件数が不正です: %+v", report.Violations)
+	}
+	for i, w := range want {
+		v := report.Violations[i]
+		if v.Rule != w.rule || !approxEqual(v.Actual, w.actual, 0.01) || len(v.InstanceIDs) != len(w.ids) {
+			t.Errorf("違反 %d が不正です: %+v", i, v)
+			continue
+		}
+		for k, id := range w.ids {
+			if v.InstanceIDs[k] != id {
+				t.Errorf("%s のインスタンスが不正です: %v", v.Rule, v.InstanceIDs)
+			}
+		}
+		if (v.Fix == nil) != (w.fix == nil) || (v.Fix != nil && *v.Fix != *w.fix) {
+			t.Errorf("%s の修正位置が不正です: %+v", v.Rule, v.Fix)
+		}
+	}
+
+	// バリアフリーでは通路 80cm・廊下 85cm が必要になる
+	report, err = checkClearances(data, assets, ClearanceRules{Preset: ClearancePresetBarrierFree, CorridorWidth: 70})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := map[string]int{}
+	for _, v := range report.Violations {
+		rules[v.Rule]++
+	}
+	if rules["corridorWidth"] != 0 || rules["passageWidth"] != 1 || report.Rules.PassageWidth != 80 {
+		t.Errorf("個別の指定がプリセットより優先されていません: %+v", report.Violations)
+	}
+
+	if _, err := checkClearances(data, assets, ClearanceRules{Preset: "unknown"}); err == nil {
+		t.Error("未知のプリセットでエラーになっていません")
+	}
+}
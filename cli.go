@@ -0,0 +1,328 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --- コマンドライン ---
+// GUI を起動せずに図面を書き出すためのサブコマンド。
+//   roomGenerator export -project <ID または名前> -o plan.pdf [-scale 50] [-paper A3] [-level 2F] [-grid] ...
+//   roomGenerator export -project <ID または名前> -o plan.png [-dpi 300 | -width 1200]  (文字は描かない)
+//   roomGenerator export -project <ID または名前> -o model.glb [-level 2F]  (3D モデル。.obj の場合は隣に .mtl も書く)
+//   roomGenerator export -project <ID または名前> -o schedule.xlsx  (部材表。.csv も可)
+//   roomGenerator export -project <ID または名前> -o estimate.pdf -format estimate-pdf  (概算見積。estimate-csv も可)
+//   roomGenerator check -project <ID または名前> [-preset barrierFree]  (データの検証・重なり・寸法のルール・採光と換気を調べる)
+//   roomGenerator schema [-o schema]  (データファイルの JSON Schema を <出力先>/v<版>/ に書き出す)
+
+// exportOptions は export サブコマンドのオプション。各形式は必要なものだけを使う。
+type exportOptions struct {
+	Plan        PlanExportOptions
+	PaperSize   string
+	Orientation string
+	AreaTable   bool
+	DPI         float64
+	Width       int
+	Height      int
+	Output      string // 出力ファイル。OBJ はこの隣にマテリアルライブラリ (.mtl) も書く。
+}
+
+// planExporter はプロジェクトを指定形式のファイル内容に変換する
+type planExporter func(a *App, id string, opts exportOptions) ([]byte, error)
+
+// planExporters は export サブコマンドで使える形式 (拡張子と同じ名前)
+var planExporters = map[string]planExporter{
+	"svg": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		svg, err := a.ExportProjectSVG(id, opts.Plan)
+		return []byte(svg), err
+	},
+	"dxf": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		dxf, err := a.ExportProjectDXF(id)
+		return []byte(dxf), err
+	},
+	"pdf": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		return a.ExportProjectPDF(id, PDFExportOptions{
+			Plan:        opts.Plan,
+			PaperSize:   opts.PaperSize,
+			Orientation: opts.Orientation,
+			AreaTable:   opts.AreaTable,
+		})
+	},
+	"png": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		return a.ExportProjectPNG(id, PNGExportOptions{Plan: opts.Plan, DPI: opts.DPI, Width: opts.Width, Height: opts.Height})
+	},
+	"csv": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		out, err := a.ExportBillOfMaterialsCSV(id)
+		return []byte(out), err
+	},
+	"xlsx": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		return a.ExportBillOfMaterialsXLSX(id)
+	},
+	"estimate-csv": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		out, err := a.ExportCostEstimateCSV(id)
+		return []byte(out), err
+	},
+	"estimate-pdf": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		return a.ExportCostEstimatePDF(id)
+	},
+	"glb": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		return a.ExportProjectGLB(id, ModelExportOptions{Level: opts.Plan.Level})
+	},
+	"obj": func(a *App, id string, opts exportOptions) ([]byte, error) {
+		mtlFile := strings.TrimSuffix(filepath.Base(opts.Output), filepath.Ext(opts.Output)) + ".mtl"
+		model, err := a.ExportProjectOBJ(id, ModelExportOptions{Level: opts.Plan.Level, MaterialFile: mtlFile})
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(filepath.Dir(opts.Output), mtlFile), []byte(model.MTL), 0644); err != nil {
+			return nil, err
+		}
+		return []byte(model.OBJ), nil
+	},
+}
+
+// dataDirUsage は -data フラグの説明
+const dataDirUsage = "data directory (default: $" + dataDirEnv + ", the dataDir of the config file, the data directory of a portable install or the user config directory)"
+
+// isCLICommand は GUI ではなくコマンドラインとして実行すべき引数かどうか
+func isCLICommand(args []string) bool {
+	return len(args) > 0 && (args[0] == "export" || args[0] == "check" || args[0] == "schema")
+}
+
+// runCLI はサブコマンドを実行し、終了コードを返す
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "export":
+		if err := runExportCommand(args[1:], stderr); err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+			return 1
+		}
+		return 0
+	case "check":
+		ok, err := runCheckCommand(args[1:], stdout, stderr)
+		if err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+			return 1
+		}
+		if !ok {
+			return 3
+		}
+		return 0
+	case "schema":
+		if err := runSchemaCommand(args[1:], stdout, stderr); err != nil {
+			fmt.Fprintln(stderr, "error:", err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
+	return 2
+}
+
+func exportFormats() []string {
+	formats := make([]string, 0, len(planExporters))
+	for name := range planExporters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// openCLIApp は -data フラグなどで決まる既存のデータディレクトリを開く。
+// 標準出力はコマンドの結果に使うため、ログは stderr に書く。
+func openCLIApp(dataDir string, stderr io.Writer) (*App, error) {
+	a := NewApp()
+	a.logOutput = stderr
+	if err := a.openExistingDataDir(resolveDataDir(systemDataDirLocations(dataDir)).Path); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func runExportCommand(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dataDir := fs.String("data", "", dataDirUsage)
+	project := fs.String("project", "", "project ID or name")
+	format := fs.String("format", "", "output format ("+strings.Join(exportFormats(), ", ")+"); defaults to the extension of -o")
+	out := fs.String("o", "", "output file")
+	var opts exportOptions
+	fs.Float64Var(&opts.Plan.Scale, "scale", 0, "drawing scale denominator (50 = 1:50); 0 = 1:100 for svg and png, fit to sheet for pdf")
+	fs.BoolVar(&opts.Plan.ShowGrid, "grid", false, "draw a grid")
+	fs.Float64Var(&opts.Plan.GridSpacing, "grid-spacing", defaultGridSpacing, "grid pitch in cm")
+	fs.BoolVar(&opts.Plan.TitleBlock, "title-block", true, "draw the title block (svg)")
+	fs.BoolVar(&opts.Plan.RoomLabels, "labels", true, "draw room names and areas")
+	fs.StringVar(&opts.Plan.TatamiSize, "tatami", TatamiEdoma, "tatami size for room labels (edoma, chukyoma, kyoma)")
+	fs.StringVar(&opts.Plan.Title, "title", "", "title shown in the title block")
+	fs.StringVar(&opts.Plan.Level, "level", "", "level ID or name to draw; svg and pdf default to the first level, glb and obj to every level")
+	fs.StringVar(&opts.PaperSize, "paper", "A4", "paper size for pdf (A4, A3)")
+	fs.StringVar(&opts.Orientation, "orientation", "landscape", "paper orientation for pdf (portrait, landscape)")
+	fs.BoolVar(&opts.AreaTable, "area-table", false, "add a room area table (pdf)")
+	fs.Float64Var(&opts.DPI, "dpi", defaultRasterDPI, "pixels per inch of the plan printed at -scale (png)")
+	fs.IntVar(&opts.Width, "width", 0, "fit the plan into this many pixels wide instead of using -dpi (png)")
+	fs.IntVar(&opts.Height, "height", 0, "fit the plan into this many pixels high instead of using -dpi (png)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" || *out == "" {
+		fs.Usage()
+		return fmt.Errorf("-project and -o are required")
+	}
+	opts.Output = *out
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*out)), ".")
+	}
+	export, ok := planExporters[*format]
+	if !ok {
+		return fmt.Errorf("unsupported format: %q", *format)
+	}
+
+	a, err := openCLIApp(*dataDir, stderr)
+	if err != nil {
+		return err
+	}
+	defer a.storage().Close()
+
+	id, err := a.resolveProjectID(*project)
+	if err != nil {
+		return err
+	}
+	// 書き出し前の確認。重なりやはみ出しがあっても書き出しは行う
+	if report, err := a.CheckCollisions(id); err == nil {
+		for _, w := range collisionWarnings(report) {
+			fmt.Fprintln(stderr, "warning:", w)
+		}
+	}
+	data, err := export(a, id, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*out, data, 0644)
+}
+
+// runCheckCommand はデータの検証・重なり・寸法のルール・採光と換気を調べて結果を表示する。問題が無ければ true を返す。
+func runCheckCommand(args []string, stdout, stderr io.Writer) (bool, error) {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dataDir := fs.String("data", "", dataDirUsage)
+	project := fs.String("project", "", "project ID or name")
+	var rules ClearanceRules
+	fs.StringVar(&rules.Preset, "preset", ClearancePresetStandard, "clearance preset (standard, barrierFree)")
+	fs.Float64Var(&rules.FixtureFront, "fixture-front", 0, "free depth in front of fixtures in cm; 0 uses the preset")
+	fs.Float64Var(&rules.CorridorWidth, "corridor-width", 0, "minimum corridor width in cm; 0 uses the preset")
+	fs.Float64Var(&rules.DoorWidth, "door-width", 0, "minimum door width in cm; 0 uses the preset")
+	fs.Float64Var(&rules.DoorSwing, "door-swing", 0, "free depth on the room side of doors in cm; 0 uses the door width")
+	fs.Float64Var(&rules.PassageWidth, "passage-width", 0, "minimum passage between furniture in cm; 0 uses the preset")
+	var daylight DaylightOptions
+	fs.Float64Var(&daylight.DefaultWindowHeight, "window-height", defaultWindowHeight, "height of windows without openingHeight in cm")
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+	if *project == "" {
+		fs.Usage()
+		return false, fmt.Errorf("-project is required")
+	}
+
+	a, err := openCLIApp(*dataDir, stderr)
+	if err != nil {
+		return false, err
+	}
+	defer a.storage().Close()
+
+	id, err := a.resolveProjectID(*project)
+	if err != nil {
+		return false, err
+	}
+	collisions, err := a.CheckCollisions(id)
+	if err != nil {
+		return false, err
+	}
+	report, err := a.CheckClearances(id, rules)
+	if err != nil {
+		return false, err
+	}
+	light, err := a.CheckDaylight(id, daylight)
+	if err != nil {
+		return false, err
+	}
+	validation, err := a.ValidateProject(id)
+	if err != nil {
+		return false, err
+	}
+
+	r := report.Rules
+	fmt.Fprintf(stdout, "rules: %s (fixture front %.0fcm, corridor %.0fcm, door %.0fcm, passage %.0fcm)\n",
+		r.Preset, r.FixtureFront, r.CorridorWidth, r.DoorWidth, r.PassageWidth)
+	for _, issue := range validation.Issues {
+		fmt.Fprintf(stdout, "%s: %s: %s\n", issue.Severity, issue.Path, issue.Message)
+	}
+	warnings := collisionWarnings(collisions)
+	for _, w := range warnings {
+		fmt.Fprintln(stdout, "collision:", w)
+	}
+	for _, v := range report.Violations {
+		fmt.Fprintf(stdout, "%s: %s [%s]", v.Rule, v.Message, strings.Join(v.InstanceIDs, ", "))
+		if v.Fix != nil {
+			fmt.Fprintf(stdout, " -> move %s to (%s, %s)", v.Fix.InstanceID, fmtNum(v.Fix.X), fmtNum(v.Fix.Y))
+		}
+		fmt.Fprintln(stdout)
+	}
+	for _, r := range light.Rooms {
+		if !r.DaylightOK {
+			fmt.Fprintf(stdout, "daylight: %s has %sm² of windows, 1/7 requires %sm² [%s]\n", r.Name, fmtNum(r.WindowAreaM2), fmtNum(r.DaylightRequiredM2), r.InstanceID)
+		}
+		if !r.VentilationOK {
+			fmt.Fprintf(stdout, "ventilation: %s has %sm² of windows, 1/20 requires %sm² [%s]\n", r.Name, fmtNum(r.WindowAreaM2), fmtNum(r.VentilationRequiredM2), r.InstanceID)
+		}
+	}
+	fmt.Fprintf(stdout, "%d checks, %d invalid values, %d violations, %d collisions, %d rooms short of daylight or ventilation\n",
+		report.Checked, validation.Errors, len(report.Violations), len(warnings), light.Failed)
+	return validation.Errors == 0 && len(report.Violations) == 0 && len(warnings) == 0 && light.Failed == 0, nil
+}
+
+// runSchemaCommand は公開する JSON Schema を書き出す
+func runSchemaCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	out := fs.String("o", "schema", "output directory; the schemas are written to <dir>/v<schema version>/")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	written, err := writeJSONSchemas(*out)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Fprintln(stdout, path)
+	}
+	return nil
+}
+
+// resolveProjectID は ID またはプロジェクト名からプロジェクト ID を求める
+func (a *App) resolveProjectID(ref string) (string, error) {
+	projects, err := a.storage().ListProjects()
+	if err != nil {
+		return "", err
+	}
+	var byName []string
+	for _, p := range projects {
+		if p.ID == ref {
+			return p.ID, nil
+		}
+		if p.Name == ref {
+			byName = append(byName, p.ID)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return "", fmt.Errorf("project not found: %s", ref)
+	case 1:
+		return byName[0], nil
+	}
+	return "", fmt.Errorf("project name is ambiguous: %s (%s)", ref, strings.Join(byName, ", "))
+}
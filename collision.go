@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// --- 重なりの検出 ---
+// インスタンスをアセットのエンティティ (曲線を含む) からワールド座標の多角形にし、
+// 部屋同士・建具や家具同士の重なりと、部屋からはみ出した建具・家具を調べる。
+// ドア・窓は壁の上に置くものなので、はみ出しの対象にしない。
+
+const (
+	collisionCurveSteps = 16  // 曲線1本あたりの分割数
+	minCollisionArea    = 1.0 // 報告する重なり・はみ出しの最小面積 (cm²)。接しているだけのものを除く
+)
+
+// placedShape はワールド座標に置いたインスタンスの形
+type placedShape struct {
+	Inst      Instance
+	Asset     *Asset
+	Polygons  [][]Vec2    // エンティティごとの輪郭
+	Triangles [][][3]Vec2 // エンティティごとの三角形分割
+	Bounds    bbox
+	Area      float64
+}
+
+// placeShapes はテキスト・参照切れ以外のインスタンスをワールド座標の形にする
+func placeShapes(data ProjectData, assets assetIndex) []placedShape {
+	var shapes []placedShape
+	for _, inst := range data.Instances {
+		asset := assets.lookup(inst)
+		if asset == nil {
+			continue
+		}
+		s := placedShape{Inst: inst, Asset: asset}
+		paths, _ := assetOutlines(*asset)
+		for _, p := range paths {
+			poly := p.transform(instanceTransform(inst)).flatten(collisionCurveSteps)
+			tris := triangulate(poly)
+			if len(tris) == 0 {
+				continue
+			}
+			s.Polygons = append(s.Polygons, poly)
+			s.Triangles = append(s.Triangles, tris)
+			for _, v := range poly {
+				s.Bounds.add(v)
+			}
+			for _, t := range tris {
+				s.Area += polygonArea(t[:])
+			}
+		}
+		if s.Bounds.Valid {
+			shapes = append(shapes, s)
+		}
+	}
+	return shapes
+}
+
+// contains は点が形の内部 (境界を含む) にあるかどうか
+func (s placedShape) contains(p Vec2) bool {
+	for _, poly := range s.Polygons {
+		if pointInPolygon(p, poly) {
+			return true
+		}
+	}
+	return false
+}
+
+// boundsOverlap は2つの外接矩形の内部が重なるかどうか
+func boundsOverlap(a, b bbox) bool {
+	return rectOverlaps(bboxRect(a), bboxRect(b))
+}
+
+// overlapArea は2つの形が重なる面積 (cm²)
+func overlapArea(a, b placedShape) float64 {
+	if !boundsOverlap(a.Bounds, b.Bounds) {
+		return 0
+	}
+	sum := 0.0
+	for _, ta := range a.Triangles {
+		for _, tb := range b.Triangles {
+			sum += trianglesOverlapArea(ta, tb)
+		}
+	}
+	return sum
+}
+
+// detectCollisions は重なっているインスタンスの組と、部屋からはみ出したインスタンスを返す。
+// 複数階のプロジェクトは階ごとに調べる。
+func detectCollisions(data ProjectData, assets assetIndex) CollisionReport {
+	report := CollisionReport{Overlaps: []InstanceOverlap{}, Outside: []OutsideInstance{}}
+	seen := map[string]bool{} // 複数の階に現れる階段を重複して報告しない
+	total := 0.0
+	for _, level := range splitLevels(data, assets) {
+		overlaps, outside := detectLevelCollisions(level, assets)
+		for _, o := range overlaps {
+			if key := o.InstanceA + "\x00" + o.InstanceB; !seen[key] {
+				seen[key] = true
+				report.Overlaps = append(report.Overlaps, o)
+				total += o.Area
+			}
+		}
+		for _, o := range outside {
+			if !seen[o.InstanceID] {
+				seen[o.InstanceID] = true
+				report.Outside = append(report.Outside, o)
+			}
+		}
+	}
+	report.TotalOverlapArea = round2(total)
+	sort.SliceStable(report.Overlaps, func(i, j int) bool { return report.Overlaps[i].Area > report.Overlaps[j].Area })
+	return report
+}
+
+// detectLevelCollisions は1つの階の重なりとはみ出しを調べる
+func detectLevelCollisions(data ProjectData, assets assetIndex) ([]InstanceOverlap, []OutsideInstance) {
+	var overlaps []InstanceOverlap
+	var outsides []OutsideInstance
+	shapes := placeShapes(data, assets)
+
+	for i := range shapes {
+		for j := i + 1; j < len(shapes); j++ {
+			a, b := shapes[i], shapes[j]
+			// 部屋と家具が重なるのは当然なので、部屋同士と部屋以外同士だけを調べる
+			if (a.Asset.Type == "room") != (b.Asset.Type == "room") {
+				continue
+			}
+			if area := overlapArea(a, b); area >= minCollisionArea {
+				overlaps = append(overlaps, InstanceOverlap{InstanceA: a.Inst.ID, InstanceB: b.Inst.ID, Area: round2(area)})
+			}
+		}
+	}
+
+	var rooms []placedShape
+	for _, s := range shapes {
+		if s.Asset.Type == "room" {
+			rooms = append(rooms, s)
+		}
+	}
+	if len(rooms) == 0 {
+		return overlaps, outsides
+	}
+	for _, s := range shapes {
+		if s.Asset.Type == "room" || openingKind(*s.Asset) != "" || s.Area <= 0 {
+			continue
+		}
+		inside := 0.0
+		for _, r := range rooms {
+			inside += overlapArea(s, r)
+		}
+		if outside := s.Area - math.Min(inside, s.Area); outside >= minCollisionArea {
+			outsides = append(outsides, OutsideInstance{
+				InstanceID:  s.Inst.ID,
+				OutsideArea: round2(outside),
+				Ratio:       round2(outside / s.Area),
+			})
+		}
+	}
+	return overlaps, outsides
+}
+
+// CheckCollisions reports overlapping instances and fixtures or furniture outside every room.
+func (a *App) CheckCollisions(id string) (CollisionReport, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return CollisionReport{}, err
+	}
+	return detectCollisions(data, assets), nil
+}
+
+// collisionWarnings は書き出し前の確認用に重なりとはみ出しを文章にする
+func collisionWarnings(report CollisionReport) []string {
+	var warnings []string
+	for _, o := range report.Overlaps {
+		warnings = append(warnings, fmt.Sprintf("%s and %s overlap (%.0f cm²)", o.InstanceA, o.InstanceB, o.Area))
+	}
+	for _, o := range report.Outside {
+		warnings = append(warnings, fmt.Sprintf("%s is outside the rooms (%.0f cm², %.0f%%)", o.InstanceID, o.OutsideArea, o.Ratio*100))
+	}
+	return warnings
+}
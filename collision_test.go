@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDetectCollisions は家具同士の重なり・部屋からのはみ出し・曲線の形を検証します
+func TestDetectCollisions(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	round := Asset{ID: "l-round", Name: "丸テーブル", Type: "furniture", Entities: []Entity{
+		{Type: "circle", CX: f64(0), CY: f64(0), RX: f64(50), RY: f64(50)},
+	}}
+	data := ProjectData{
+		LocalAssets: []Asset{round},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_room6", Type: "room"},
+			{ID: "room2", AssetID: "a_room6", Type: "room", X: 360},
+			{ID: "sofa", AssetID: "a_sofa2", Type: "furniture", X: 10, Y: 10},
+			{ID: "table", AssetID: "a_table4", Type: "furniture", X: 150, Y: 50},
+			{ID: "bed", AssetID: "a_bed_s", Type: "furniture", X: 300, Y: 200, Rotation: 90},
+			{ID: "round", AssetID: "l-round", Type: "furniture", X: 720, Y: 135},
+			{ID: "window", AssetID: "a_window", Type: "fixture", X: 0, Y: -2.5},
+			{ID: "memo", Type: "text", Text: "メモ"},
+		},
+	}
+	report := detectCollisions(data, newAssetIndex(data.LocalAssets, globals))
+
+	// ソファ (10..170, 10..100) と食卓 (150..290, 50..130) は 20×50 重なる。
+	// 隣り合う部屋は接しているだけなので含まない。壁の上の窓ははみ出しにもならない。
+	if len(report.Overlaps) != 1 {
+		t.Fatalf("重なりの件数が不正です: %+v", report.Overlaps)
+	}
+	if o := report.Overlaps[0]; o.InstanceA != "sofa" || o.InstanceB != "table" || !approxEqual(o.Area, 20*50, 0.01) {
+		t.Errorf("重なりが不正です: %+v", o)
+	}
+	if report.TotalOverlapArea != 1000 {
+		t.Errorf("重なりの合計が不正です: %v", report.TotalOverlapArea)
+	}
+
+	// 90度回転したベッド (100..300, 200..300) は上に 30cm はみ出す。
+	// 丸テーブル (中心 720, 135, 半径 50) は右の部屋の外に半分出る。
+	outside := map[string]OutsideInstance{}
+	for _, o := range report.Outside {
+		outside[o.InstanceID] = o
+	}
+	if len(outside) != 2 {
+		t.Fatalf("はみ出しの件数が不正です: %+v", report.Outside)
+	}
+	if o := outside["bed"]; !approxEqual(o.OutsideArea, 200*30, 0.01) || !approxEqual(o.Ratio, 0.3, 0.001) {
+		t.Errorf("ベッドのはみ出しが不正です: %+v", o)
+	}
+	if o := outside["round"]; !approxEqual(o.OutsideArea, math.Pi*50*50/2, 50) {
+		t.Errorf("丸テーブルのはみ出しが不正です: %+v", o)
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- データディレクトリの場所 ---
+// 次の順に最初に見つかったものを使う。
+//   1. -data フラグ
+//   2. 環境変数 ROOMGENERATOR_DATA_DIR
+//   3. OS のユーザー設定ディレクトリの roomGenerator/config.json の dataDir (MoveDataDir が書く)
+//   4. 実行ファイルの隣に portable ファイルがあれば、実行ファイルの隣の data/ (ポータブルモード)
+//   5. カレントディレクトリに以前の版が作った data/projects_index.json があればその data/
+//   6. OS のユーザー設定ディレクトリの roomGenerator/data/
+
+// データディレクトリの場所を決める設定
+const (
+	dataDirEnv         = "ROOMGENERATOR_DATA_DIR"
+	configDirName      = "roomGenerator"
+	configFileName     = "config.json"
+	portableMarkerFile = "portable"
+)
+
+// データディレクトリの決め方 (DataDirInfo.Source)
+const (
+	DataDirSourceFlag     = "flag"
+	DataDirSourceEnv      = "env"
+	DataDirSourceConfig   = "config"
+	DataDirSourcePortable = "portable"
+	DataDirSourceLegacy   = "legacy"
+	DataDirSourceDefault  = "default"
+)
+
+// appConfig はユーザー設定ディレクトリの config.json。データディレクトリの外に置く設定。
+type appConfig struct {
+	DataDir string `json:"dataDir,omitempty"`
+}
+
+// dataDirLocations はデータディレクトリを決めるための OS 依存の場所。テストでは差し替える。
+type dataDirLocations struct {
+	Flag      string // -data フラグの値
+	Env       string // 環境変数の値
+	ConfigDir string // ユーザー設定ディレクトリの roomGenerator/。取得できなければ空。
+	ExeDir    string // 実行ファイルのディレクトリ。取得できなければ空。
+	Cwd       string
+}
+
+// systemDataDirLocations は実行環境から場所を集める
+func systemDataDirLocations(flagValue string) dataDirLocations {
+	loc := dataDirLocations{Flag: flagValue, Env: os.Getenv(dataDirEnv), Cwd: "."}
+	if dir, err := os.UserConfigDir(); err == nil {
+		loc.ConfigDir = filepath.Join(dir, configDirName)
+	}
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		loc.ExeDir = filepath.Dir(exe)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		loc.Cwd = cwd
+	}
+	return loc
+}
+
+// parseDataDirFlag は GUI 起動時の引数から -data (--data, -data=..., --data=...) の値を取り出す
+func parseDataDirFlag(args []string) string {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "data" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// absPath は base からの相対パスを絶対パスにする
+func absPath(base, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// resolveDataDir はデータディレクトリの場所と決め方を返す
+func resolveDataDir(loc dataDirLocations) DataDirInfo {
+	info := DataDirInfo{}
+	if loc.ConfigDir != "" {
+		info.ConfigFile = filepath.Join(loc.ConfigDir, configFileName)
+	}
+	switch {
+	case loc.Flag != "":
+		info.Path, info.Source = absPath(loc.Cwd, loc.Flag), DataDirSourceFlag
+	case loc.Env != "":
+		info.Path, info.Source = absPath(loc.Cwd, loc.Env), DataDirSourceEnv
+	}
+	if info.Path != "" {
+		return info
+	}
+	info.Movable = info.ConfigFile != ""
+	if config, err := readAppConfig(info.ConfigFile); err == nil && config.DataDir != "" {
+		info.Path, info.Source = absPath(loc.ConfigDir, config.DataDir), DataDirSourceConfig
+		return info
+	}
+	if loc.ExeDir != "" && fileExists(filepath.Join(loc.ExeDir, portableMarkerFile)) {
+		info.Path, info.Source = filepath.Join(loc.ExeDir, DATA_DIR_NAME), DataDirSourcePortable
+		return info
+	}
+	legacy := filepath.Join(loc.Cwd, DATA_DIR_NAME)
+	if fileExists(filepath.Join(legacy, projectsIndexFile)) || loc.ConfigDir == "" {
+		info.Path, info.Source = legacy, DataDirSourceLegacy
+		return info
+	}
+	info.Path, info.Source = filepath.Join(loc.ConfigDir, DATA_DIR_NAME), DataDirSourceDefault
+	return info
+}
+
+// readAppConfig は config.json を読む。ファイルが無ければ空の設定を返す。
+func readAppConfig(path string) (appConfig, error) {
+	var config appConfig
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// writeAppConfig は config.json を書く
+func writeAppConfig(path string, config appConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := marshalDocument(config)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// isSubPath は path が dir 自身またはその中にあるかどうか
+func isSubPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// dataCopy はデータディレクトリのコピーで移したもの
+type dataCopy struct {
+	files   []string // コピーしたファイル (src からの相対パス)
+	dirs    []string // コピーした src のディレクトリ (浅い順)
+	created []string // dst 側で新しく作ったディレクトリ (浅い順)
+}
+
+// copyDataFiles は src 以下のファイルを dst にコピーし、コピーしたファイルとディレクトリを返す。
+// 書き込み途中の一時ファイルはコピーしない。失敗した場合もそこまでにコピーしたものを返す。
+func copyDataFiles(src, dst string) (dataCopy, error) {
+	var c dataCopy
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			created, err := makeDirs(target)
+			c.created = append(c.created, created...)
+			if err != nil {
+				return err
+			}
+			c.dirs = append(c.dirs, path)
+			return nil
+		}
+		if !d.Type().IsRegular() || strings.Contains(d.Name(), ".tmp-") {
+			return nil
+		}
+		if err := copyFile(path, target); err != nil {
+			return err
+		}
+		c.files = append(c.files, rel)
+		return nil
+	})
+	return c, err
+}
+
+// makeDirs は os.MkdirAll と同様に path を作り、新しく作ったディレクトリを浅い順に返す
+func makeDirs(path string) ([]string, error) {
+	var missing []string
+	for p := path; ; p = filepath.Dir(p) {
+		if _, err := os.Stat(p); err == nil {
+			break
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		missing = append([]string{p}, missing...)
+		if filepath.Dir(p) == p {
+			break
+		}
+	}
+	return missing, os.MkdirAll(path, 0755)
+}
+
+// copyFile は src を dst にコピーする。失敗した場合は書きかけの dst を残さない。
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+	return err
+}
+
+// removeDataFiles はコピーしたファイル (dir からの相対パス) を削除し、dirs のうち空になったディレクトリも削除する。
+// dirs に無いディレクトリと、データ以外のファイルが残っているディレクトリは削除しない。
+func removeDataFiles(dir string, files, dirs []string) []error {
+	var errs []error
+	for _, rel := range files {
+		if err := os.Remove(filepath.Join(dir, rel)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	// 深いディレクトリから削除する
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	return errs
+}
+
+// openLogFile はデータディレクトリの app.log を開く (以前のログは閉じる)
+func (a *App) openLogFile() {
+	a.closeLogFile()
+	dir := a.dataDirPath()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Printf("ログファイルを開けません: %v\n", err)
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(dir, LOG_FILE), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		fmt.Printf("ログファイルを開けません: %v\n", err)
+		return
+	}
+	a.mu.Lock()
+	a.logFile = f
+	a.mu.Unlock()
+}
+
+// closeLogFile は app.log を閉じる。閉じた後のログは標準出力にだけ書く。
+func (a *App) closeLogFile() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.logFile != nil {
+		a.logFile.Close()
+		a.logFile = nil
+	}
+}
+
+// GetDataDirInfo returns the location of the data directory and how it was chosen.
+func (a *App) GetDataDirInfo() DataDirInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	info := a.dataDirInfo
+	info.Path = a.dataDir
+	return info
+}
+
+// MoveDataDir moves the data directory with all projects to dir and remembers the new location in the
+// config file of the OS user config directory. dir must not exist or be empty. The location cannot be
+// changed while the -data flag or the ROOMGENERATOR_DATA_DIR environment variable sets it.
+func (a *App) MoveDataDir(dir string) (DataDirInfo, error) {
+	// 移動が終わるまでストアの操作 (自動保存など) を待たせる。
+	// コピー後に元の場所へ書かれた変更が、元の場所の削除で失われないようにする。
+	a.moveMu.Lock()
+	defer a.moveMu.Unlock()
+
+	info := a.GetDataDirInfo()
+	switch {
+	case info.Source == DataDirSourceFlag:
+		return info, fmt.Errorf("the data directory is set by the -data flag and cannot be moved from the app")
+	case info.Source == DataDirSourceEnv:
+		return info, fmt.Errorf("the data directory is set by %s and cannot be moved from the app", dataDirEnv)
+	case !info.Movable:
+		return info, fmt.Errorf("the user config directory is not available to remember a new data directory")
+	}
+	if strings.TrimSpace(dir) == "" {
+		return info, fmt.Errorf("destination is empty")
+	}
+	dest, err := filepath.Abs(dir)
+	if err != nil {
+		return info, err
+	}
+	src := info.Path
+	if isSubPath(src, dest) || isSubPath(dest, src) {
+		return info, fmt.Errorf("destination %s overlaps the current data directory %s", dest, src)
+	}
+	if entries, err := os.ReadDir(dest); err == nil && len(entries) > 0 {
+		return info, fmt.Errorf("destination %s is not empty", dest)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return info, err
+	}
+
+	// ストアとログを閉じてからコピーする (SQLite のファイルも含めて一貫した状態で移す)
+	a.logInfo("データディレクトリを移動します: %s -> %s", src, dest)
+	if err := a.currentStore().Close(); err != nil {
+		a.logError("ストアのクローズに失敗しました: %v", err)
+	}
+	a.closeLogFile()
+	copied, err := copyDataFiles(src, dest)
+	if err == nil {
+		err = writeAppConfig(info.ConfigFile, appConfig{DataDir: dest})
+	}
+	if err != nil {
+		// 移動先の途中までのコピーと、移動のために作ったディレクトリを消し、元の場所で開き直す
+		removeDataFiles(dest, copied.files, copied.created)
+		a.openLogFile()
+		a.initStore()
+		a.logError("データディレクトリの移動に失敗しました: %v", err)
+		return a.GetDataDirInfo(), err
+	}
+
+	info.Path, info.Source = dest, DataDirSourceConfig
+	a.setDataDir(dest, info)
+	a.openLogFile()
+	a.initStore()
+	for _, err := range removeDataFiles(src, copied.files, copied.dirs) {
+		a.logError("移動元のファイルを削除できません: %v", err)
+	}
+	a.logInfo("データディレクトリを移動しました: %s (%d ファイル)", dest, len(copied.files))
+	return a.GetDataDirInfo(), nil
+}
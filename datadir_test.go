@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestResolveDataDir はフラグ・環境変数・設定ファイル・ポータブルモード・既定の順にデータディレクトリを決めることを検証します
+func TestResolveDataDir(t *testing.T) {
+	root := t.TempDir()
+	loc := dataDirLocations{
+		ConfigDir: filepath.Join(root, "config", configDirName),
+		ExeDir:    filepath.Join(root, "app"),
+		Cwd:       filepath.Join(root, "cwd"),
+	}
+	for _, dir := range []string{loc.ExeDir, loc.Cwd} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(name string, loc dataDirLocations, path, source string) {
+		t.Helper()
+		if info := resolveDataDir(loc); info.Path != path || info.Source != source {
+			t.Errorf("%s: %+v, want %s (%s)", name, info, path, source)
+		}
+	}
+
+	check("既定", loc, filepath.Join(loc.ConfigDir, DATA_DIR_NAME), DataDirSourceDefault)
+
+	// 以前の版がカレントディレクトリに作ったデータはそのまま使う
+	legacy := filepath.Join(loc.Cwd, DATA_DIR_NAME)
+	os.MkdirAll(legacy, 0755)
+	os.WriteFile(filepath.Join(legacy, projectsIndexFile), []byte(`[]`), 0644)
+	check("カレントディレクトリ", loc, legacy, DataDirSourceLegacy)
+
+	os.WriteFile(filepath.Join(loc.ExeDir, portableMarkerFile), nil, 0644)
+	check("ポータブル", loc, filepath.Join(loc.ExeDir, DATA_DIR_NAME), DataDirSourcePortable)
+
+	if err := writeAppConfig(filepath.Join(loc.ConfigDir, configFileName), appConfig{DataDir: "moved"}); err != nil {
+		t.Fatal(err)
+	}
+	check("設定ファイル", loc, filepath.Join(loc.ConfigDir, "moved"), DataDirSourceConfig)
+
+	loc.Env = "env-data"
+	check("環境変数", loc, filepath.Join(loc.Cwd, "env-data"), DataDirSourceEnv)
+
+	loc.Flag = filepath.Join(root, "flag")
+	check("フラグ", loc, loc.Flag, DataDirSourceFlag)
+	if resolveDataDir(loc).Movable {
+		t.Error("フラグで指定したデータディレクトリが移動できる扱いになっています")
+	}
+
+	for want, args := range map[string][]string{
+		"/x": {"-data", "/x"},
+		"/y": {"--data=/y"},
+		"/z": {"-psn_0_1", "-data=/z"},
+		"":   {"-database", "/w"},
+	} {
+		if got := parseDataDirFlag(args); got != want {
+			t.Errorf("parseDataDirFlag(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
+
+// TestMoveDataDir はプロジェクトを含むデータディレクトリを移動し、新しい場所を設定ファイルに記録することを検証します
+func TestMoveDataDir(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "old")
+	app := NewApp()
+	app.dataDirInfo = DataDirInfo{Source: DataDirSourceDefault, ConfigFile: filepath.Join(root, "config", configFileName), Movable: true}
+	app.openDataDir(src)
+	proj, err := app.CreateProject("move")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := ProjectData{Instances: []Instance{{ID: "i1", AssetID: "a_room6", Type: "room"}}}
+	if err := app.SaveProjectData(proj.ID, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// 空でない場所・現在の場所の中へは移動しない
+	occupied := filepath.Join(root, "occupied")
+	os.MkdirAll(occupied, 0755)
+	os.WriteFile(filepath.Join(occupied, "other.txt"), nil, 0644)
+	for _, dest := range []string{occupied, filepath.Join(src, "nested"), ""} {
+		if _, err := app.MoveDataDir(dest); err == nil {
+			t.Errorf("%q へ移動できました", dest)
+		}
+	}
+
+	dest := filepath.Join(root, "new", "data")
+	info, err := app.MoveDataDir(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Path != dest || info.Source != DataDirSourceConfig || app.dataDir != dest {
+		t.Errorf("移動後の情報が不正です: %+v", info)
+	}
+	if config, err := readAppConfig(info.ConfigFile); err != nil || config.DataDir != dest {
+		t.Errorf("設定ファイルに新しい場所が記録されていません: %+v, %v", config, err)
+	}
+	if projects, _ := app.GetProjects(); len(projects) != 1 || projects[0].ID != proj.ID {
+		t.Errorf("移動後のプロジェクト一覧が不正です: %+v", projects)
+	}
+	if got, err := app.GetProjectData(proj.ID); err != nil || len(got.Instances) != 1 {
+		t.Errorf("移動後にプロジェクトを読み込めません: %+v, %v", got, err)
+	}
+	if snaps, _ := app.ListSnapshots(proj.ID); len(snaps) == 0 {
+		t.Error("スナップショットが移動されていません")
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("移動元のデータディレクトリが残っています: %v", err)
+	}
+
+	app.dataDirInfo.Source = DataDirSourceFlag
+	if _, err := app.MoveDataDir(filepath.Join(root, "again")); err == nil {
+		t.Error("フラグで指定したデータディレクトリを移動できました")
+	}
+}
+
+// TestMoveDataDirFailure は移動に失敗したとき、移動のために作ったものだけを消して元の場所で開き直すことを検証します
+func TestMoveDataDirFailure(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "old")
+	blocker := filepath.Join(root, "blocker")
+	os.WriteFile(blocker, nil, 0644)
+	app := NewApp()
+	// 設定ファイルの親がファイルなので、コピーの後の設定の書き込みで失敗する
+	app.dataDirInfo = DataDirInfo{Source: DataDirSourceDefault, ConfigFile: filepath.Join(blocker, configFileName), Movable: true}
+	app.openDataDir(src)
+	proj, err := app.CreateProject("move")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 利用者が用意した空のディレクトリは残し、移動のために作ったディレクトリは消す
+	existing := filepath.Join(root, "existing")
+	os.Mkdir(existing, 0755)
+	created := filepath.Join(root, "new", "data")
+	for _, dest := range []string{existing, created} {
+		if _, err := app.MoveDataDir(dest); err == nil {
+			t.Fatalf("%q への移動が成功しました", dest)
+		}
+	}
+	if entries, err := os.ReadDir(existing); err != nil || len(entries) != 0 {
+		t.Errorf("移動先のディレクトリが空の状態で残っていません: %v, %v", entries, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "new")); !os.IsNotExist(err) {
+		t.Errorf("移動のために作ったディレクトリが残っています: %v", err)
+	}
+	if app.dataDir != src {
+		t.Errorf("元の場所で開き直していません: %s", app.dataDir)
+	}
+	if _, err := app.GetProjectData(proj.ID); err != nil {
+		t.Errorf("元の場所のプロジェクトを読み込めません: %v", err)
+	}
+
+	// コピーに失敗したファイルは書きかけのまま残さない
+	dst := filepath.Join(root, "partial")
+	if err := copyFile(src, dst); err == nil {
+		t.Error("ディレクトリのコピーが成功しました")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("書きかけのファイルが残っています: %v", err)
+	}
+}
+
+// TestMoveDataDirWhileSaving は移動中の自動保存が失われず、移動後の場所に保存されることを検証します
+func TestMoveDataDirWhileSaving(t *testing.T) {
+	root := t.TempDir()
+	app := NewApp()
+	app.dataDirInfo = DataDirInfo{Source: DataDirSourceDefault, ConfigFile: filepath.Join(root, "config", configFileName), Movable: true}
+	app.openDataDir(filepath.Join(root, "old"))
+	defer app.shutdown(nil)
+	proj, err := app.CreateProject("autosave")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const saves = 50
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= saves; i++ {
+			data := ProjectData{Instances: []Instance{{ID: fmt.Sprintf("i%d", i), AssetID: "a_room6", Type: "room"}}}
+			if err := app.SaveProjectData(proj.ID, data); err != nil {
+				t.Errorf("保存 %d に失敗しました: %v", i, err)
+			}
+			app.logInfo("保存 %d", i)
+		}
+	}()
+	dest := filepath.Join(root, "new")
+	if _, err := app.MoveDataDir(dest); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if app.GetDataDirInfo().Path != dest {
+		t.Fatalf("移動後のデータディレクトリが不正です: %+v", app.GetDataDirInfo())
+	}
+	got, err := app.GetProjectData(proj.ID)
+	if err != nil || len(got.Instances) != 1 || got.Instances[0].ID != fmt.Sprintf("i%d", saves) {
+		t.Errorf("最後の保存が移動後の場所にありません: %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// --- 採光・換気のチェック ---
+// 居室の床面積に対して、部屋の境界に置いた窓の面積が 1/7 (採光) と 1/20 (換気) 以上あるかを調べる。
+// 窓の面積は 境界に沿った幅 × 高さ とし、採光補正係数や開けられる部分の割合は考えない (設計前の目安)。
+// 窓の外側が別の部屋の場合は室内の窓として数えない。ただしベランダ等の屋外は外とみなす。
+
+const (
+	daylightRatio       = 1.0 / 7  // 採光に必要な窓の面積 / 床面積
+	ventilationRatio    = 1.0 / 20 // 換気に必要な窓の面積 / 床面積
+	defaultWindowHeight = 180.0    // 高さの指定が無い窓の高さ (cm)。掃出し窓を想定
+	windowWallTolerance = 1.0      // 窓の中心線と部屋の辺のずれの許容 (cm)。窓の厚みの半分に加える
+	windowProbeDistance = 10.0     // 窓の外側を調べる点の窓の面からの距離 (cm)
+)
+
+// isHabitable は部屋アセットが居室 (LDK・DK・リビング・ダイニング・洋室・和室など) かどうかを名前から判定する
+func isHabitable(asset Asset) bool {
+	switch madoriKindOf(asset.Name) {
+	case "ldk", "dk", "l", "d", "bedroom":
+		return true
+	}
+	return false
+}
+
+// isOutdoor は部屋アセットがベランダ・バルコニー等の屋外かどうかを名前から判定する
+func isOutdoor(asset Asset) bool {
+	name := strings.ToLower(asset.Name)
+	for _, w := range []string{"ベランダ", "バルコニー", "テラス", "庭", "balcony", "terrace"} {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowHeight は窓の高さ (cm) を 指定・アセットの openingHeight・既定値 の順に決める
+func windowHeight(asset Asset, opts DaylightOptions) float64 {
+	if h := opts.WindowHeights[asset.ID]; h > 0 {
+		return h
+	}
+	if asset.OpeningHeight > 0 {
+		return asset.OpeningHeight
+	}
+	if opts.DefaultWindowHeight > 0 {
+		return opts.DefaultWindowHeight
+	}
+	return defaultWindowHeight
+}
+
+// windowSpan は窓の中心線 (長辺方向) の両端と厚みをワールド座標で返す
+func windowSpan(inst Instance, asset Asset) (a, b Vec2, thickness float64) {
+	lb := assetLocalBounds(asset)
+	c := lb.center()
+	tf := instanceTransform(inst)
+	if lb.width() >= lb.height() {
+		return tf(Vec2{X: lb.Min.X, Y: c.Y}), tf(Vec2{X: lb.Max.X, Y: c.Y}), lb.height()
+	}
+	return tf(Vec2{X: c.X, Y: lb.Min.Y}), tf(Vec2{X: c.X, Y: lb.Max.Y}), lb.width()
+}
+
+// boundaryOverlap は線分 a-b が多角形の辺に沿っている長さを返す。tol は辺の直線からのずれの許容。
+func boundaryOverlap(a, b Vec2, poly []Vec2, tol float64) float64 {
+	sum := 0.0
+	for i := range poly {
+		if lo, hi, ok := collinearOverlap(a, b, poly[i], poly[(i+1)%len(poly)], tol); ok {
+			sum += hi - lo
+		}
+	}
+	return math.Min(sum, vlen(vsub(b, a)))
+}
+
+// windowWidthOn は窓が部屋の境界に沿っている幅 (cm) を返す。窓の外側が屋外でない別の部屋なら 0。
+func windowWidthOn(win placedShape, room *placedShape, rooms []*placedShape) float64 {
+	a, b, thickness := windowSpan(win.Inst, *win.Asset)
+	width := 0.0
+	for _, poly := range room.Polygons {
+		width += boundaryOverlap(a, b, poly, thickness/2+windowWallTolerance)
+	}
+	if width <= 0 {
+		return 0
+	}
+
+	// 窓の両側のうち部屋の外になる方が、別の (屋外でない) 部屋の中かどうか
+	mid := vlerp(a, b, 0.5)
+	dir := vsub(b, a)
+	n := vscale(Vec2{X: -dir.Y, Y: dir.X}, (thickness/2+windowProbeDistance)/vlen(dir))
+	for _, p := range []Vec2{vadd(mid, n), vsub(mid, n)} {
+		if room.contains(p) {
+			continue
+		}
+		for _, other := range rooms {
+			if other != room && !isOutdoor(*other.Asset) && other.contains(p) {
+				return 0
+			}
+		}
+	}
+	return math.Min(width, vlen(dir))
+}
+
+// checkDaylight は部屋ごとの床面積と窓の面積から採光・換気を調べる
+func checkDaylight(data ProjectData, assets assetIndex, opts DaylightOptions) (DaylightReport, error) {
+	for id, h := range opts.WindowHeights {
+		if h < 0 {
+			return DaylightReport{}, fmt.Errorf("invalid window height for %s: %v", id, h)
+		}
+	}
+	if opts.DefaultWindowHeight < 0 {
+		return DaylightReport{}, fmt.Errorf("invalid window height: %v", opts.DefaultWindowHeight)
+	}
+
+	report := DaylightReport{Rooms: []RoomDaylight{}}
+	for _, level := range splitLevels(data, assets) {
+		for _, rd := range levelDaylight(level, assets, opts) {
+			if !rd.DaylightOK || !rd.VentilationOK {
+				report.Failed++
+			}
+			report.Rooms = append(report.Rooms, rd)
+		}
+	}
+	return report, nil
+}
+
+// levelDaylight は1つの階の部屋ごとに採光・換気を調べる
+func levelDaylight(data ProjectData, assets assetIndex, opts DaylightOptions) []RoomDaylight {
+	shapes := placeShapes(data, assets)
+	var rooms, windows []*placedShape
+	for i := range shapes {
+		s := &shapes[i]
+		switch {
+		case s.Asset.Type == "room":
+			rooms = append(rooms, s)
+		case openingKind(*s.Asset) == "window":
+			windows = append(windows, s)
+		}
+	}
+
+	var result []RoomDaylight
+	for _, r := range rooms {
+		if isOutdoor(*r.Asset) {
+			continue
+		}
+		floor := cm2ToM2(r.Area)
+		rd := RoomDaylight{
+			InstanceID:            r.Inst.ID,
+			Name:                  r.Asset.Name,
+			Habitable:             isHabitable(*r.Asset),
+			FloorAreaM2:           round2(floor),
+			Windows:               []RoomWindow{},
+			DaylightRequiredM2:    round2(floor * daylightRatio),
+			VentilationRequiredM2: round2(floor * ventilationRatio),
+		}
+		windowArea := 0.0
+		for _, w := range windows {
+			width := windowWidthOn(*w, r, rooms)
+			if width <= 0 {
+				continue
+			}
+			height := windowHeight(*w.Asset, opts)
+			area := cm2ToM2(width * height)
+			windowArea += area
+			rd.Windows = append(rd.Windows, RoomWindow{
+				InstanceID: w.Inst.ID,
+				AssetID:    w.Asset.ID,
+				Width:      round2(width),
+				Height:     height,
+				AreaM2:     round2(area),
+			})
+		}
+		rd.WindowAreaM2 = round2(windowArea)
+		if floor > 0 {
+			rd.Ratio = math.Round(windowArea/floor*1000) / 1000
+		}
+		// 居室以外は採光・換気の対象外
+		rd.DaylightOK = !rd.Habitable || windowArea >= floor*daylightRatio
+		rd.VentilationOK = !rd.Habitable || windowArea >= floor*ventilationRatio
+		result = append(result, rd)
+	}
+	return result
+}
+
+// CheckDaylight reports the window area of each room and whether habitable rooms meet
+// the 1/7 daylight and 1/20 ventilation ratios of the building code.
+func (a *App) CheckDaylight(id string, opts DaylightOptions) (DaylightReport, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return DaylightReport{}, err
+	}
+	report, err := checkDaylight(data, assets, opts)
+	if err != nil {
+		a.logError("採光チェック失敗 (ID: %s): %v", id, err)
+		return DaylightReport{}, err
+	}
+	return report, nil
+}
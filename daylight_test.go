@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+// TestCheckDaylight は窓の面積から採光 1/7・換気 1/20 を判定することを検証します
+func TestCheckDaylight(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	koshi := Asset{ID: "w_koshi", Name: "腰窓", Type: "fixture", W: 120, H: 5, OpeningHeight: 100}
+	// 洋室 (360×270) の下にベランダ、上に LDK (360×450)、LDK の右上にトイレ
+	data := ProjectData{
+		LocalAssets: []Asset{koshi},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_room6", Type: "room"},
+			{ID: "ldk", AssetID: "a_ldk10", Type: "room", Y: 270},
+			{ID: "balcony", AssetID: "a_balcony", Type: "room", Y: -90},
+			{ID: "toilet", AssetID: "a_toilet", Type: "room", X: 360, Y: 585},
+			{ID: "w1", AssetID: "a_window", Type: "fixture", X: 90, Y: -2.5},                 // 洋室→ベランダ
+			{ID: "w2", AssetID: "w_koshi", Type: "fixture", X: 2.5, Y: 60, Rotation: 90},     // 洋室の左の壁
+			{ID: "w3", AssetID: "a_window", Type: "fixture", X: 362.5, Y: 360, Rotation: 90}, // LDK の右の壁
+			{ID: "inner", AssetID: "a_window", Type: "fixture", X: 90, Y: 267.5},             // 洋室と LDK の間
+			{ID: "sofa", AssetID: "a_sofa2", Type: "furniture", X: 100, Y: 400},
+		},
+	}
+	assets := newAssetIndex(data.LocalAssets, globals)
+
+	report, err := checkDaylight(data, assets, DaylightOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Rooms) != 3 || report.Failed != 0 {
+		t.Fatalf("結果が不正です: %+v", report)
+	}
+	room, ldk, toilet := report.Rooms[0], report.Rooms[1], report.Rooms[2]
+	if room.FloorAreaM2 != 9.72 || room.WindowAreaM2 != 4.44 || len(room.Windows) != 2 || !room.Habitable {
+		t.Errorf("洋室の結果が不正です: %+v", room)
+	}
+	if room.DaylightRequiredM2 != 1.39 || room.VentilationRequiredM2 != 0.49 {
+		t.Errorf("洋室の必要面積が不正です: %+v", room)
+	}
+	if ldk.WindowAreaM2 != 3.24 || len(ldk.Windows) != 1 || ldk.Windows[0].InstanceID != "w3" || ldk.Windows[0].Width != 180 {
+		t.Errorf("LDK の窓が不正です: %+v", ldk.Windows)
+	}
+	if toilet.Habitable || !toilet.DaylightOK || len(toilet.Windows) != 0 {
+		t.Errorf("トイレは対象外です: %+v", toilet)
+	}
+
+	// 窓の高さを 110cm にすると LDK は採光だけ足りなくなる
+	report, err = checkDaylight(data, assets, DaylightOptions{WindowHeights: map[string]float64{"a_window": 110}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	room, ldk = report.Rooms[0], report.Rooms[1]
+	if report.Failed != 1 || ldk.DaylightOK || !ldk.VentilationOK || ldk.WindowAreaM2 != 1.98 {
+		t.Errorf("LDK の判定が不正です: %+v", ldk)
+	}
+	if !room.DaylightOK || room.WindowAreaM2 != 3.18 {
+		t.Errorf("洋室の判定が不正です: %+v", room)
+	}
+
+	if _, err := checkDaylight(data, assets, DaylightOptions{DefaultWindowHeight: -1}); err == nil {
+		t.Error("負の高さでエラーになっていません")
+	}
+}
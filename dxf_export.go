@@ -0,0 +1,610 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- DXF エクスポート ---
+// AutoCAD 2000 (AC1015) 形式の ASCII DXF を出力する。単位はセンチメートル ($INSUNITS = 5)。
+// アセットは BLOCK、インスタンスは INSERT とし、Entity.Layer を DXF の画層に対応させる。
+
+const (
+	dxfUnitsCentimeters = 5
+	dxfDefaultLayer     = "0"
+	dxfTextLayer        = "text"
+)
+
+// dxfWriter はグループコードと値の組を書き出し、ハンドルを採番する
+type dxfWriter struct {
+	b          strings.Builder
+	nextHandle int
+}
+
+func newDXFWriter() *dxfWriter {
+	return &dxfWriter{nextHandle: 1}
+}
+
+// handle は新しいハンドル (16進文字列) を払い出す
+func (w *dxfWriter) handle() string {
+	h := strconv.FormatInt(int64(w.nextHandle), 16)
+	w.nextHandle++
+	return strings.ToUpper(h)
+}
+
+func (w *dxfWriter) str(code int, v string) {
+	fmt.Fprintf(&w.b, "%3d\n%s\n", code, v)
+}
+
+func (w *dxfWriter) num(code int, v float64) {
+	w.str(code, dxfNum(v))
+}
+
+func (w *dxfWriter) int(code int, v int) {
+	w.str(code, strconv.Itoa(v))
+}
+
+// point は 10/20/30 のような座標の組を書く
+func (w *dxfWriter) point(code int, v Vec2) {
+	w.num(code, v.X)
+	w.num(code+10, v.Y)
+	w.num(code+20, 0)
+}
+
+// entityHead は全エンティティ共通のグループを書く
+func (w *dxfWriter) entityHead(kind, owner, layer string) {
+	w.str(0, kind)
+	w.str(5, w.handle())
+	w.str(330, owner)
+	w.str(100, "AcDbEntity")
+	w.str(8, layer)
+}
+
+// dxfNum は数値を DXF 用に整形する (小数点以下6桁まで)
+func dxfNum(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "0.0"
+	}
+	s := strconv.FormatFloat(v, 'f', 6, 64)
+	s = strings.TrimRight(s, "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	if s == "-0.0" {
+		return "0.0"
+	}
+	return s
+}
+
+// dxfText は文字列を DXF のテキスト値にする。ASCII 以外は \U+XXXX 形式でエスケープする。
+func dxfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(" ")
+		case r == '\r':
+		case r < 0x80:
+			b.WriteRune(r)
+		case r <= 0xFFFF:
+			fmt.Fprintf(&b, `\U+%04X`, r)
+		default:
+			b.WriteString("?")
+		}
+	}
+	return b.String()
+}
+
+// dxfName はブロック名・画層名に使えない文字を置き換える
+func dxfName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>/\":;?*|,=`+"`", r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	return dxfText(s)
+}
+
+// dxfLayerName はエンティティの画層名を DXF の画層名にする
+func dxfLayerName(layer string) string {
+	if strings.TrimSpace(layer) == "" {
+		return dxfDefaultLayer
+	}
+	return dxfName(layer)
+}
+
+// dxfEllipseAxes は楕円の長軸ベクトル・短軸比・長軸基準の媒介変数角 (ラジアン) のずれを返す。
+// DXF の ELLIPSE は長軸を基準とするため、ry > rx の場合は軸を 90 度回す。
+func dxfEllipseAxes(es ellipseShape) (major Vec2, ratio float64, offset float64) {
+	if es.RX >= es.RY {
+		return rotateVec(Vec2{X: es.RX, Y: 0}, es.Rotation), es.RY / es.RX, 0
+	}
+	return rotateVec(Vec2{X: 0, Y: es.RY}, es.Rotation), es.RX / es.RY, -math.Pi / 2
+}
+
+// writeEntity はエンティティを DXF のプリミティブとして書き出す
+func (w *dxfWriter) writeEntity(e Entity, owner string) {
+	layer := dxfLayerName(e.Layer)
+
+	if e.Type == "text" {
+		if e.Text == "" {
+			return
+		}
+		w.writeText(owner, layer, Vec2{X: deref(e.X), Y: deref(e.Y)}, derefOr(e.FontSize, 24)/baseScale, deref(e.Rotation), e.Text)
+		return
+	}
+
+	if es, ok := ellipseFromEntity(e); ok {
+		if es.RX <= 0 || es.RY <= 0 {
+			return
+		}
+		w.writeEllipse(owner, layer, es)
+		return
+	}
+
+	path, ok := entityOutline(e)
+	if !ok || len(path) == 0 {
+		return
+	}
+	if pathIsPolyline(path) {
+		w.writePolyline(owner, layer, path)
+	} else {
+		w.writeSpline(owner, layer, path)
+	}
+}
+
+// pathIsPolyline は輪郭が直線のみかどうか
+func pathIsPolyline(path shapePath) bool {
+	for _, s := range path {
+		if !s.Line {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *dxfWriter) writePolyline(owner, layer string, path shapePath) {
+	w.entityHead("LWPOLYLINE", owner, layer)
+	w.str(100, "AcDbPolyline")
+	w.int(90, len(path))
+	w.int(70, 1) // 閉じたポリライン
+	w.num(43, 0)
+	for _, s := range path {
+		w.num(10, s.P0.X)
+		w.num(20, s.P0.Y)
+	}
+}
+
+// writeSpline はベジェ曲線の連なりを3次 B スプラインとして書き出す。
+// 各区間の境界でノットを3重にすると、制御点はベジェの制御点そのものになる。
+func (w *dxfWriter) writeSpline(owner, layer string, path shapePath) {
+	ctrl := []Vec2{path[0].P0}
+	for _, s := range path {
+		p1, p2 := s.P1, s.P2
+		if s.Line {
+			p1, p2 = vlerp(s.P0, s.P3, 1.0/3.0), vlerp(s.P0, s.P3, 2.0/3.0)
+		}
+		ctrl = append(ctrl, p1, p2, s.P3)
+	}
+	knots := []float64{0, 0, 0, 0}
+	for i := 1; i < len(path); i++ {
+		knots = append(knots, float64(i), float64(i), float64(i))
+	}
+	n := float64(len(path))
+	knots = append(knots, n, n, n, n)
+
+	w.entityHead("SPLINE", owner, layer)
+	w.str(100, "AcDbSpline")
+	w.num(210, 0)
+	w.num(220, 0)
+	w.num(230, 1)
+	w.int(70, 8) // 平面
+	w.int(71, 3)
+	w.int(72, len(knots))
+	w.int(73, len(ctrl))
+	w.int(74, 0)
+	w.num(42, 1e-10)
+	w.num(43, 1e-10)
+	for _, k := range knots {
+		w.num(40, k)
+	}
+	for _, p := range ctrl {
+		w.point(10, p)
+	}
+}
+
+// writeEllipse は円・楕円・扇形・弓形を CIRCLE / ARC / ELLIPSE と閉じる線分で書き出す
+func (w *dxfWriter) writeEllipse(owner, layer string, es ellipseShape) {
+	circular := math.Abs(es.RX-es.RY) < 1e-9
+	if circular {
+		if es.full() {
+			w.entityHead("CIRCLE", owner, layer)
+			w.str(100, "AcDbCircle")
+			w.point(10, es.Center)
+			w.num(40, es.RX)
+			return
+		}
+		w.entityHead("ARC", owner, layer)
+		w.str(100, "AcDbCircle")
+		w.point(10, es.Center)
+		w.num(40, es.RX)
+		w.str(100, "AcDbArc")
+		w.num(50, normalizeDeg(es.Start+es.Rotation))
+		w.num(51, normalizeDeg(es.Start+es.Sweep+es.Rotation))
+	} else {
+		major, ratio, offset := dxfEllipseAxes(es)
+		start, end := 0.0, 2*math.Pi
+		if !es.full() {
+			start = es.Start*math.Pi/180 + offset
+			end = start + es.Sweep*math.Pi/180
+		}
+		w.entityHead("ELLIPSE", owner, layer)
+		w.str(100, "AcDbEllipse")
+		w.point(10, es.Center)
+		w.point(11, major)
+		w.num(210, 0)
+		w.num(220, 0)
+		w.num(230, 1)
+		w.num(40, ratio)
+		w.num(41, start)
+		w.num(42, end)
+	}
+	if es.full() {
+		return
+	}
+
+	// 扇形は中心を通る2本、弓形は弦1本で閉じる
+	start, end := es.point(es.Start), es.point(es.Start+es.Sweep)
+	if es.Sector {
+		w.writeLine(owner, layer, end, es.Center)
+		w.writeLine(owner, layer, es.Center, start)
+	} else {
+		w.writeLine(owner, layer, end, start)
+	}
+}
+
+func (w *dxfWriter) writeLine(owner, layer string, a, b Vec2) {
+	w.entityHead("LINE", owner, layer)
+	w.str(100, "AcDbLine")
+	w.point(10, a)
+	w.point(11, b)
+}
+
+func (w *dxfWriter) writeText(owner, layer string, pos Vec2, height, rotation float64, text string) {
+	w.entityHead("TEXT", owner, layer)
+	w.str(100, "AcDbText")
+	w.point(10, pos)
+	w.num(40, height)
+	w.str(1, dxfText(text))
+	if rotation != 0 {
+		w.num(50, normalizeDeg(rotation))
+	}
+	w.str(7, "Standard")
+	w.str(100, "AcDbText")
+}
+
+func (w *dxfWriter) writeInsert(owner, layer, block string, pos Vec2, rotation float64) {
+	w.entityHead("INSERT", owner, layer)
+	w.str(100, "AcDbBlockReference")
+	w.str(2, block)
+	w.point(10, pos)
+	if rotation != 0 {
+		w.num(50, normalizeDeg(rotation))
+	}
+}
+
+// normalizeDeg は角度を [0, 360) に正規化する
+func normalizeDeg(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// dxfBlock は書き出すブロック (アセット) と、その BLOCK_RECORD のハンドル
+type dxfBlock struct {
+	Name   string
+	Asset  *Asset
+	Record string
+}
+
+// collectDXFBlocks は書き出すアセットを集める。
+// ローカルアセットは未使用でも含め、グローバルアセットは参照されているものだけを含める。
+func collectDXFBlocks(data ProjectData, assets assetIndex) []*Asset {
+	seen := map[string]bool{}
+	var list []*Asset
+	addAsset := func(a *Asset) {
+		if a != nil && !seen[a.ID] {
+			seen[a.ID] = true
+			list = append(list, a)
+		}
+	}
+	for i := range data.LocalAssets {
+		addAsset(assets[data.LocalAssets[i].ID])
+	}
+	for _, inst := range data.Instances {
+		addAsset(assets.lookup(inst))
+	}
+	return list
+}
+
+// renderProjectDXF はプロジェクトを DXF 文書に変換する
+func renderProjectDXF(data ProjectData, assets assetIndex) string {
+	bounds := planBounds(buildPlan(data, assets))
+	body := newDXFWriter()
+
+	// ハンドルはテーブル → ブロック → エンティティの順に採番する
+	tableHandles := map[string]string{}
+	for _, t := range []string{"VPORT", "LTYPE", "LAYER", "STYLE", "VIEW", "UCS", "APPID", "DIMSTYLE", "BLOCK_RECORD"} {
+		tableHandles[t] = body.handle()
+	}
+	modelSpace, paperSpace := body.handle(), body.handle()
+
+	// ブロック名はアセット ID から作り、重複しないようにする
+	blockNames := map[string]bool{}
+	var blocks []dxfBlock
+	blockOf := map[string]string{}
+	for _, a := range collectDXFBlocks(data, assets) {
+		name := dxfName(a.ID)
+		if name == "" {
+			name = "ASSET"
+		}
+		for base, i := name, 2; blockNames[strings.ToUpper(name)]; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+		}
+		blockNames[strings.ToUpper(name)] = true
+		blocks = append(blocks, dxfBlock{Name: name, Asset: a, Record: body.handle()})
+		blockOf[a.ID] = name
+	}
+
+	// 複数階のプロジェクトはインスタンスの画層名の前に階の名前を付ける
+	instanceLayer := func(inst Instance, layer string) string {
+		if len(data.Levels) < 2 {
+			return layer
+		}
+		return dxfName(data.Levels[instanceLevel(inst, data.Levels)].Name + "_" + layer)
+	}
+
+	// 使われている画層を集める
+	layerSet := map[string]bool{dxfDefaultLayer: true}
+	for _, b := range blocks {
+		for _, e := range b.Asset.Entities {
+			layerSet[dxfLayerName(e.Layer)] = true
+		}
+	}
+	for _, inst := range data.Instances {
+		if inst.Type == "text" {
+			layerSet[instanceLayer(inst, dxfTextLayer)] = true
+		} else if inst.Type == wallType {
+			layerSet[instanceLayer(inst, dxfLayerName(wallType))] = true
+		} else if a := assets.lookup(inst); a != nil {
+			layerSet[instanceLayer(inst, dxfLayerName(a.Type))] = true
+		}
+	}
+	layers := make([]string, 0, len(layerSet))
+	for l := range layerSet {
+		layers = append(layers, l)
+	}
+	sort.Strings(layers)
+
+	w := body
+	w.str(0, "SECTION")
+	w.str(2, "TABLES")
+	writeTable := func(name string, count int, entries func(owner string)) {
+		w.str(0, "TABLE")
+		w.str(2, name)
+		w.str(5, tableHandles[name])
+		w.str(330, "0")
+		w.str(100, "AcDbSymbolTable")
+		w.int(70, count)
+		if name == "DIMSTYLE" {
+			w.str(100, "AcDbDimStyleTable")
+		}
+		entries(tableHandles[name])
+		w.str(0, "ENDTAB")
+	}
+	record := func(kind, owner, subclass, name string) {
+		w.str(0, kind)
+		if kind == "DIMSTYLE" {
+			w.str(105, w.handle())
+		} else {
+			w.str(5, w.handle())
+		}
+		w.str(330, owner)
+		w.str(100, "AcDbSymbolTableRecord")
+		w.str(100, subclass)
+		w.str(2, name)
+		w.int(70, 0)
+	}
+
+	writeTable("VPORT", 1, func(owner string) {
+		record("VPORT", owner, "AcDbViewportTableRecord", "*ACTIVE")
+		w.num(10, 0)
+		w.num(20, 0)
+		w.num(11, 1)
+		w.num(21, 1)
+		w.point(12, bounds.center())
+		w.num(40, math.Max(bounds.height(), bounds.width())*1.1)
+		w.num(41, 1.5)
+	})
+	writeTable("LTYPE", 3, func(owner string) {
+		for _, lt := range []string{"ByBlock", "ByLayer", "Continuous"} {
+			record("LTYPE", owner, "AcDbLinetypeTableRecord", lt)
+			desc := ""
+			if lt == "Continuous" {
+				desc = "Solid line"
+			}
+			w.str(3, desc)
+			w.int(72, 65)
+			w.int(73, 0)
+			w.num(40, 0)
+		}
+	})
+	writeTable("LAYER", len(layers), func(owner string) {
+		for _, l := range layers {
+			record("LAYER", owner, "AcDbLayerTableRecord", l)
+			w.int(62, 7)
+			w.str(6, "Continuous")
+		}
+	})
+	writeTable("STYLE", 1, func(owner string) {
+		record("STYLE", owner, "AcDbTextStyleTableRecord", "Standard")
+		w.num(40, 0)
+		w.num(41, 1)
+		w.num(50, 0)
+		w.int(71, 0)
+		w.num(42, 2.5)
+		w.str(3, "txt")
+		w.str(4, "")
+	})
+	writeTable("VIEW", 0, func(string) {})
+	writeTable("UCS", 0, func(string) {})
+	writeTable("APPID", 1, func(owner string) {
+		record("APPID", owner, "AcDbRegAppTableRecord", "ACAD")
+	})
+	writeTable("DIMSTYLE", 1, func(owner string) {
+		record("DIMSTYLE", owner, "AcDbDimStyleTableRecord", "Standard")
+	})
+	writeTable("BLOCK_RECORD", 2+len(blocks), func(owner string) {
+		writeRecord := func(handle, name string) {
+			w.str(0, "BLOCK_RECORD")
+			w.str(5, handle)
+			w.str(330, owner)
+			w.str(100, "AcDbSymbolTableRecord")
+			w.str(100, "AcDbBlockTableRecord")
+			w.str(2, name)
+		}
+		writeRecord(modelSpace, "*Model_Space")
+		writeRecord(paperSpace, "*Paper_Space")
+		for _, b := range blocks {
+			writeRecord(b.Record, b.Name)
+		}
+	})
+	w.str(0, "ENDSEC")
+
+	// BLOCKS
+	w.str(0, "SECTION")
+	w.str(2, "BLOCKS")
+	writeBlock := func(name, record, description string, entities func()) {
+		w.entityHead("BLOCK", record, dxfDefaultLayer)
+		w.str(100, "AcDbBlockBegin")
+		w.str(2, name)
+		w.int(70, 0)
+		w.point(10, Vec2{})
+		w.str(3, name)
+		w.str(1, "")
+		if description != "" {
+			w.str(4, dxfText(description))
+		}
+		entities()
+		w.entityHead("ENDBLK", record, dxfDefaultLayer)
+		w.str(100, "AcDbBlockEnd")
+	}
+	writeBlock("*Model_Space", modelSpace, "", func() {})
+	writeBlock("*Paper_Space", paperSpace, "", func() {})
+	for _, b := range blocks {
+		writeBlock(b.Name, b.Record, b.Asset.Name, func() {
+			entities := b.Asset.Entities
+			if len(entities) == 0 {
+				// エンティティの無いアセットは w×h の矩形として描く
+				path, _ := rectPath(Entity{X: f64ptr(0), Y: f64ptr(0), W: f64ptr(b.Asset.W), H: f64ptr(b.Asset.H)})
+				w.writePolyline(b.Record, dxfDefaultLayer, path)
+			}
+			for _, e := range entities {
+				w.writeEntity(e, b.Record)
+			}
+		})
+	}
+	w.str(0, "ENDSEC")
+
+	// ENTITIES (モデル空間)
+	w.str(0, "SECTION")
+	w.str(2, "ENTITIES")
+	walls := map[string]*wallSegment{}
+	for _, wall := range placeWalls(data, assets) {
+		walls[wall.Inst.ID] = wall
+	}
+	for _, inst := range sortedInstances(data.Instances, assets) {
+		if wall := walls[inst.ID]; wall != nil && inst.Type == wallType {
+			// 壁はブロックにせず、接合と開口を反映した輪郭をそのまま書き出す
+			for _, poly := range wall.polygons() {
+				w.writePolyline(modelSpace, instanceLayer(inst, dxfLayerName(wallType)), polylinePath(poly))
+			}
+			continue
+		}
+		if inst.Type == "text" {
+			if strings.TrimSpace(inst.Text) != "" {
+				w.writeText(modelSpace, instanceLayer(inst, dxfTextLayer), Vec2{X: inst.X, Y: inst.Y}, derefOr(inst.FontSize, 24)/baseScale, inst.Rotation, inst.Text)
+			}
+			continue
+		}
+		a := assets.lookup(inst)
+		if a == nil {
+			continue
+		}
+		w.writeInsert(modelSpace, instanceLayer(inst, dxfLayerName(a.Type)), blockOf[a.ID], Vec2{X: inst.X, Y: inst.Y}, inst.Rotation)
+	}
+	w.str(0, "ENDSEC")
+
+	// OBJECTS (ルート辞書のみ)
+	root, group := w.handle(), w.handle()
+	w.str(0, "SECTION")
+	w.str(2, "OBJECTS")
+	w.str(0, "DICTIONARY")
+	w.str(5, root)
+	w.str(330, "0")
+	w.str(100, "AcDbDictionary")
+	w.int(281, 1)
+	w.str(3, "ACAD_GROUP")
+	w.str(350, group)
+	w.str(0, "DICTIONARY")
+	w.str(5, group)
+	w.str(330, root)
+	w.str(100, "AcDbDictionary")
+	w.int(281, 1)
+	w.str(0, "ENDSEC")
+	w.str(0, "EOF")
+
+	// HEADER は採番が終わってから $HANDSEED を決めて先頭に付ける
+	h := newDXFWriter()
+	h.str(0, "SECTION")
+	h.str(2, "HEADER")
+	h.str(9, "$ACADVER")
+	h.str(1, "AC1015")
+	h.str(9, "$DWGCODEPAGE")
+	h.str(3, "ANSI_1252")
+	h.str(9, "$INSBASE")
+	h.point(10, Vec2{})
+	h.str(9, "$EXTMIN")
+	h.point(10, bounds.Min)
+	h.str(9, "$EXTMAX")
+	h.point(10, bounds.Max)
+	h.str(9, "$INSUNITS")
+	h.int(70, dxfUnitsCentimeters)
+	h.str(9, "$MEASUREMENT")
+	h.int(70, 1)
+	h.str(9, "$HANDSEED")
+	h.str(5, strings.ToUpper(strconv.FormatInt(int64(w.nextHandle), 16)))
+	h.str(0, "ENDSEC")
+	return h.b.String() + w.b.String()
+}
+
+func f64ptr(v float64) *float64 { return &v }
+
+// ExportProjectDXF returns the project layout as an AutoCAD 2000 ASCII DXF document (units: cm).
+// Each asset becomes a BLOCK, each instance an INSERT, and Entity.Layer selects the DXF layer.
+// In multi-level projects the instance layers are prefixed with the level name (e.g. "2F_room").
+func (a *App) ExportProjectDXF(id string) (string, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return "", err
+	}
+	dxf := renderProjectDXF(data, assets)
+	a.logInfo("DXF エクスポート: %s", id)
+	return dxf, nil
+}
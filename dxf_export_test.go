@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// dxfPairs はテスト用に DXF をグループコードと値の組に分解します
+func dxfPairs(t *testing.T, dxf string) [][2]string {
+	t.Helper()
+	lines := strings.Split(strings.TrimRight(dxf, "\n"), "\n")
+	if len(lines)%2 != 0 {
+		t.Fatalf("行数が奇数です: %d", len(lines))
+	}
+	pairs := make([][2]string, 0, len(lines)/2)
+	for i := 0; i < len(lines); i += 2 {
+		pairs = append(pairs, [2]string{strings.TrimSpace(lines[i]), lines[i+1]})
+	}
+	return pairs
+}
+
+// TestRenderProjectDXF はブロック・INSERT・画層・各プリミティブの出力を検証します
+func TestRenderProjectDXF(t *testing.T) {
+	bezier := Asset{ID: "a-fork-1", Name: "曲線テーブル", Type: "furniture", W: 100, H: 100, Entities: []Entity{
+		{Type: "polygon", Layer: "家具", Points: []Point{
+			{X: 0, Y: 0}, {X: 100, Y: 0, IsCurve: true, H1: Vec2{X: 0, Y: -20}, H2: Vec2{X: 0, Y: 20}}, {X: 100, Y: 100},
+		}},
+		{Type: "ellipse", Layer: "家具", CX: f64(50), CY: f64(50), RX: f64(30), RY: f64(20)},
+		{Type: "arc", CX: f64(0), CY: f64(0), RX: f64(10), RY: f64(10), StartAngle: f64(0), EndAngle: f64(90), ArcMode: "sector"},
+		{Type: "text", X: f64(10), Y: f64(10), Text: "机"},
+	}}
+	data := ProjectData{
+		LocalAssets: []Asset{bezier},
+		Instances: []Instance{
+			{ID: "r1", AssetID: "a_room6", Type: "room"},
+			{ID: "f1", AssetID: "a-fork-1", Type: "furniture", X: 50, Y: 60, Rotation: 90},
+			{ID: "t1", Type: "text", Text: "北"},
+		},
+	}
+	dxf := renderProjectDXF(data, newAssetIndex(data.LocalAssets, getDefaultGlobalAssets()))
+	pairs := dxfPairs(t, dxf)
+
+	count := map[string]int{}
+	handles := map[string]bool{}
+	maxHandle := int64(0)
+	var seed int64
+	for i, p := range pairs {
+		switch p[0] {
+		case "0":
+			count[p[1]]++
+		case "5", "105":
+			if i > 0 && pairs[i-1][1] == "$HANDSEED" {
+				seed, _ = strconv.ParseInt(p[1], 16, 64)
+				continue
+			}
+			if handles[p[1]] {
+				t.Errorf("ハンドルが重複しています: %s", p[1])
+			}
+			handles[p[1]] = true
+			if h, _ := strconv.ParseInt(p[1], 16, 64); h > maxHandle {
+				maxHandle = h
+			}
+		}
+	}
+	if seed <= maxHandle {
+		t.Errorf("$HANDSEED が最大ハンドル以下です: %X <= %X", seed, maxHandle)
+	}
+
+	// *Model_Space, *Paper_Space と使用中の2アセット
+	want := map[string]int{"BLOCK": 4, "INSERT": 2, "SPLINE": 1, "ELLIPSE": 1, "ARC": 1, "LINE": 2, "TEXT": 2, "EOF": 1}
+	for kind, n := range want {
+		if count[kind] != n {
+			t.Errorf("%s の数が不正です: got %d, want %d", kind, count[kind], n)
+		}
+	}
+	for _, s := range []string{"AC1015", "$INSUNITS\n 70\n5", `\U+5BB6\U+5177`, `\U+66F2\U+7DDA`, "a_room6", "a-fork-1"} {
+		if !strings.Contains(dxf, s) {
+			t.Errorf("%q が含まれていません", s)
+		}
+	}
+	// 回転は INSERT の 50 に出力される
+	if !strings.Contains(dxf, "AcDbBlockReference\n  2\na-fork-1\n 10\n50.0\n 20\n60.0\n 30\n0.0\n 50\n90.0\n") {
+		t.Error("INSERT の位置・回転が不正です")
+	}
+}
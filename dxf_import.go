@@ -0,0 +1,993 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- DXF 読み込み ---
+// BLOCK をアセット、モデル空間の INSERT をインスタンスに変換する。
+// 座標は $INSUNITS (または指定の単位) に従ってセンチメートルに換算する。
+
+// dxfUnit は単位名と cm への換算係数
+type dxfUnit struct {
+	Name string
+	CM   float64
+}
+
+// dxfInsUnits は $INSUNITS の値ごとの単位
+var dxfInsUnits = map[int]dxfUnit{
+	1:  {"in", 2.54},
+	2:  {"ft", 30.48},
+	4:  {"mm", 0.1},
+	5:  {"cm", 1},
+	6:  {"m", 100},
+	10: {"yd", 91.44},
+	14: {"dm", 10},
+}
+
+// dxfUnitNames は DXFImportOptions.Units で指定できる単位
+var dxfUnitNames = map[string]dxfUnit{
+	"mm": {"mm", 0.1},
+	"cm": {"cm", 1},
+	"m":  {"m", 100},
+	"in": {"in", 2.54},
+	"ft": {"ft", 30.48},
+}
+
+const (
+	dxfMaxInsertDepth = 16
+	dxfChainTolerance = 0.01 // 端点を同一とみなす距離 (cm)
+	dxfSplineSamples  = 16   // 折れ線近似するスプラインの1区間あたりの分割数
+	dxfDefaultText    = "#333333"
+)
+
+// dxfPiece は変換途中の図形。Ellipse は完全な円・楕円 (ellipse エンティティとして出力する)。
+type dxfPiece struct {
+	Path    shapePath
+	Closed  bool
+	Ellipse *ellipseShape
+	Layer   string
+}
+
+// dxfLabel は TEXT / MTEXT
+type dxfLabel struct {
+	Pos      Vec2
+	Height   float64
+	Rotation float64
+	Text     string
+	Layer    string
+}
+
+// dxfGeometry はブロックやモデル空間の図形
+type dxfGeometry struct {
+	Pieces []dxfPiece
+	Labels []dxfLabel
+}
+
+// dxfTransform は拡大縮小 → 回転 → 平行移動の変換 (INSERT と同じ順序)
+type dxfTransform struct {
+	Origin   Vec2
+	Rotation float64
+	SX, SY   float64
+}
+
+func (t dxfTransform) apply(v Vec2) Vec2 {
+	return vadd(t.Origin, rotateVec(Vec2{X: v.X * t.SX, Y: v.Y * t.SY}, t.Rotation))
+}
+
+// transform は図形を変換する。一様でない拡大縮小や鏡像では楕円を輪郭線として扱う。
+func (g dxfGeometry) transform(t dxfTransform) dxfGeometry {
+	var res dxfGeometry
+	uniform := t.SX > 0 && math.Abs(t.SX-t.SY) < 1e-9
+	for _, p := range g.Pieces {
+		q := dxfPiece{Path: p.Path.transform(t.apply), Closed: p.Closed, Layer: p.Layer}
+		if p.Ellipse != nil && uniform {
+			e := *p.Ellipse
+			e.Center = t.apply(e.Center)
+			e.RX *= t.SX
+			e.RY *= t.SX
+			e.Rotation += t.Rotation
+			q.Ellipse = &e
+		}
+		res.Pieces = append(res.Pieces, q)
+	}
+	scale := math.Sqrt(math.Abs(t.SX * t.SY))
+	for _, l := range g.Labels {
+		l.Pos = t.apply(l.Pos)
+		l.Height *= scale
+		l.Rotation += t.Rotation
+		res.Labels = append(res.Labels, l)
+	}
+	return res
+}
+
+func (g *dxfGeometry) append(o dxfGeometry) {
+	g.Pieces = append(g.Pieces, o.Pieces...)
+	g.Labels = append(g.Labels, o.Labels...)
+}
+
+// bounds は図形全体の外接矩形
+func (g dxfGeometry) bounds() bbox {
+	var b bbox
+	for _, p := range g.Pieces {
+		pb := p.Path.bounds()
+		if pb.Valid {
+			b.add(pb.Min)
+			b.add(pb.Max)
+		}
+	}
+	for _, l := range g.Labels {
+		b.add(l.Pos)
+	}
+	return b
+}
+
+// dxfConverter はブロックの展開結果をキャッシュしながら図形を変換する
+type dxfConverter struct {
+	file     *dxfFile
+	blocks   map[string]dxfGeometry
+	visiting map[string]bool
+	skipped  map[string]int
+	notes    []string
+}
+
+func newDXFConverter(f *dxfFile) *dxfConverter {
+	return &dxfConverter{
+		file:     f,
+		blocks:   map[string]dxfGeometry{},
+		visiting: map[string]bool{},
+		skipped:  map[string]int{},
+	}
+}
+
+func (c *dxfConverter) note(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for _, n := range c.notes {
+		if n == msg {
+			return
+		}
+	}
+	c.notes = append(c.notes, msg)
+}
+
+// blockGeometry はブロックの図形を基点からの相対座標で返す
+func (c *dxfConverter) blockGeometry(name string, depth int) (dxfGeometry, bool) {
+	if g, ok := c.blocks[name]; ok {
+		return g, true
+	}
+	block, ok := c.file.Blocks[name]
+	if !ok {
+		c.note("ブロック %s が定義されていません", name)
+		return dxfGeometry{}, false
+	}
+	if c.visiting[name] || depth > dxfMaxInsertDepth {
+		c.note("ブロック %s の参照が循環しているか深すぎるため展開しませんでした", name)
+		return dxfGeometry{}, false
+	}
+	c.visiting[name] = true
+	g := c.entitiesGeometry(block.Entities, depth+1)
+	delete(c.visiting, name)
+
+	g = g.transform(dxfTransform{Origin: vscale(block.Base, -1), SX: 1, SY: 1})
+	c.blocks[name] = g
+	return g, true
+}
+
+func (c *dxfConverter) entitiesGeometry(entities []*dxfEntity, depth int) dxfGeometry {
+	var g dxfGeometry
+	for _, e := range entities {
+		g.append(c.entityGeometry(e, depth))
+	}
+	return g
+}
+
+// insertTransform は INSERT の変換。押し出し方向が -Z の場合 (OCS) は X を反転する。
+func insertTransform(e *dxfEntity) dxfTransform {
+	t := dxfTransform{
+		Origin:   e.point(10),
+		Rotation: e.float(50, 0),
+		SX:       e.float(41, 1),
+		SY:       e.float(42, 1),
+	}
+	if e.float(230, 1) < 0 {
+		t.Origin.X = -t.Origin.X
+		t.Rotation = -t.Rotation
+		t.SX = -t.SX
+	}
+	return t
+}
+
+// entityGeometry は1つのエンティティを図形に変換する (図面の単位のまま)
+func (c *dxfConverter) entityGeometry(e *dxfEntity, depth int) dxfGeometry {
+	var g dxfGeometry
+	layer := strings.TrimSpace(e.str(8))
+	if layer == "0" {
+		layer = ""
+	}
+	add := func(path shapePath, closed bool) {
+		if len(path) > 0 && path.length() > 1e-9 {
+			g.Pieces = append(g.Pieces, dxfPiece{Path: path, Closed: closed, Layer: layer})
+		}
+	}
+	// CIRCLE / ARC / LWPOLYLINE / TEXT などは OCS 座標。押し出し方向が -Z の場合は X を反転する。
+	ocs := func() {
+		if e.float(230, 1) < 0 {
+			g = g.transform(dxfTransform{SX: -1, SY: 1})
+		}
+	}
+
+	switch e.Type {
+	case "LINE":
+		add(shapePath{lineSegment(e.point(10), e.point(11))}, false)
+
+	case "LWPOLYLINE":
+		var vs []Vec2
+		var bulges []float64
+		for _, p := range e.Pairs {
+			switch p.Code {
+			case 10:
+				vs = append(vs, Vec2{X: parseDXFFloat(p.Value)})
+				bulges = append(bulges, 0)
+			case 20:
+				if len(vs) > 0 {
+					vs[len(vs)-1].Y = parseDXFFloat(p.Value)
+				}
+			case 42:
+				if len(bulges) > 0 {
+					bulges[len(bulges)-1] = parseDXFFloat(p.Value)
+				}
+			}
+		}
+		closed := e.int(70, 0)&1 != 0
+		add(bulgePolyline(vs, bulges, closed), closed)
+		ocs()
+
+	case "POLYLINE":
+		flags := e.int(70, 0)
+		if flags&(16|64) != 0 {
+			c.skipped["POLYLINE (メッシュ)"]++
+			break
+		}
+		var vs []Vec2
+		var bulges []float64
+		for _, v := range e.Vertices {
+			if v.int(70, 0)&16 != 0 {
+				continue // スプラインのフレーム制御点
+			}
+			vs = append(vs, v.point(10))
+			bulges = append(bulges, v.float(42, 0))
+		}
+		closed := flags&1 != 0
+		add(bulgePolyline(vs, bulges, closed), closed)
+		if flags&8 == 0 {
+			ocs()
+		}
+
+	case "ARC":
+		sweep := math.Mod(e.float(51, 360)-e.float(50, 0), 360)
+		if sweep <= 0 {
+			sweep += 360
+		}
+		r := e.float(40, 0)
+		if r > 0 {
+			arc := ellipseShape{Center: e.point(10), RX: r, RY: r, Start: e.float(50, 0), Sweep: sweep}
+			add(arc.arcPath(), false)
+		}
+		ocs()
+
+	case "CIRCLE":
+		if r := e.float(40, 0); r > 0 {
+			circle := ellipseShape{Center: e.point(10), RX: r, RY: r, Sweep: 360, Sector: true}
+			g.Pieces = append(g.Pieces, dxfPiece{Path: circle.arcPath(), Closed: true, Ellipse: &circle, Layer: layer})
+		}
+		ocs()
+
+	case "ELLIPSE":
+		major := e.point(11)
+		rx := vlen(major)
+		ry := rx * e.float(40, 1)
+		if rx <= 0 || ry <= 0 {
+			break
+		}
+		start, end := e.float(41, 0), e.float(42, 2*math.Pi)
+		es := ellipseShape{
+			Center:   e.point(10),
+			RX:       rx,
+			RY:       ry,
+			Rotation: math.Atan2(major.Y, major.X) * 180 / math.Pi,
+			Start:    start * 180 / math.Pi,
+			Sweep:    360,
+			Sector:   true,
+		}
+		if math.Abs(end-start) < 2*math.Pi-1e-6 {
+			sweep := math.Mod(end-start, 2*math.Pi)
+			if sweep <= 0 {
+				sweep += 2 * math.Pi
+			}
+			es.Sweep = sweep * 180 / math.Pi
+			add(es.arcPath(), false)
+			break
+		}
+		g.Pieces = append(g.Pieces, dxfPiece{Path: es.arcPath(), Closed: true, Ellipse: &es, Layer: layer})
+
+	case "SPLINE":
+		path, closed := splinePath(e)
+		add(path, closed)
+
+	case "TEXT", "MTEXT":
+		text := e.str(1)
+		if e.Type == "MTEXT" {
+			var b strings.Builder
+			for _, p := range e.Pairs {
+				if p.Code == 3 {
+					b.WriteString(p.Value)
+				}
+			}
+			text = stripMText(b.String() + text)
+		} else {
+			text = decodeDXFText(text)
+		}
+		if strings.TrimSpace(text) == "" {
+			break
+		}
+		rotation := e.float(50, 0)
+		if e.Type == "MTEXT" && rotation == 0 {
+			if dir := e.point(11); dir.X != 0 || dir.Y != 0 {
+				rotation = math.Atan2(dir.Y, dir.X) * 180 / math.Pi
+			}
+		}
+		g.Labels = append(g.Labels, dxfLabel{
+			Pos:      e.point(10),
+			Height:   e.float(40, 2.5),
+			Rotation: rotation,
+			Text:     text,
+			Layer:    layer,
+		})
+		if e.Type == "TEXT" {
+			ocs()
+		}
+
+	case "INSERT":
+		name := strings.TrimSpace(e.str(2))
+		if block, ok := c.blockGeometry(name, depth); ok {
+			g = block.transform(insertTransform(e))
+		}
+
+	case "ATTDEF", "ATTRIB", "SEQEND", "VIEWPORT":
+		// 属性とビューポートは形状を持たないため無視する
+
+	default:
+		c.skipped[e.Type]++
+	}
+	return g
+}
+
+func parseDXFFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return v
+}
+
+// bulgePolyline はふくらみ (bulge) 付きの頂点列を輪郭にする
+func bulgePolyline(vs []Vec2, bulges []float64, closed bool) shapePath {
+	var path shapePath
+	n := len(vs)
+	last := n - 1
+	if closed {
+		last = n
+	}
+	for i := 0; i < last; i++ {
+		a, b := vs[i], vs[(i+1)%n]
+		if vlen(vsub(b, a)) < 1e-12 {
+			continue
+		}
+		path = append(path, bulgeSegments(a, b, bulges[i])...)
+	}
+	return path
+}
+
+// bulgeSegments は a から b への円弧 (bulge = tan(中心角/4)、正で反時計回り) を返す
+func bulgeSegments(a, b Vec2, bulge float64) shapePath {
+	if math.Abs(bulge) < 1e-9 {
+		return shapePath{lineSegment(a, b)}
+	}
+	theta := 4 * math.Atan(bulge)
+	chord := vsub(b, a)
+	c := vlen(chord)
+	r := c / 2 / math.Abs(math.Sin(theta/2))
+	u := vscale(chord, 1/c)
+	h := c / 2 / math.Tan(theta/2)
+	center := vadd(vlerp(a, b, 0.5), vscale(Vec2{X: -u.Y, Y: u.X}, h))
+
+	from, to := a, b
+	if theta < 0 {
+		from, to = b, a
+	}
+	d := vsub(from, center)
+	arc := ellipseShape{
+		Center: center,
+		RX:     r,
+		RY:     r,
+		Start:  math.Atan2(d.Y, d.X) * 180 / math.Pi,
+		Sweep:  math.Abs(theta) * 180 / math.Pi,
+	}
+	path := arc.arcPath()
+	path[0].P0 = from
+	path[len(path)-1].P3 = to
+	if theta < 0 {
+		path = reversePath(path)
+	}
+	return path
+}
+
+// reversePath は輪郭の向きを逆にする
+func reversePath(p shapePath) shapePath {
+	res := make(shapePath, len(p))
+	for i, s := range p {
+		res[len(p)-1-i] = pathSegment{P0: s.P3, P1: s.P2, P2: s.P1, P3: s.P0, Line: s.Line}
+	}
+	return res
+}
+
+// splinePath は SPLINE を輪郭にする。端点で固定された非有理 B スプラインはノット挿入で
+// ベジェ曲線に分解し、それ以外は折れ線で近似する。制御点が無い場合はフィット点を結ぶ。
+func splinePath(e *dxfEntity) (shapePath, bool) {
+	degree := e.int(71, 3)
+	knots := e.floats(40)
+	ctrl := e.points(10)
+	weights := e.floats(41)
+	closed := e.int(70, 0)&1 != 0
+
+	if len(ctrl) < 2 || degree < 1 || len(knots) != len(ctrl)+degree+1 {
+		fit := e.points(11)
+		var path shapePath
+		for i := 0; i+1 < len(fit); i++ {
+			path = append(path, lineSegment(fit[i], fit[i+1]))
+		}
+		if closed && len(fit) > 2 {
+			path = append(path, lineSegment(fit[len(fit)-1], fit[0]))
+		}
+		return path, closed
+	}
+
+	rational := false
+	for _, w := range weights {
+		if math.Abs(w-1) > 1e-9 {
+			rational = true
+		}
+	}
+	var path shapePath
+	if !rational && degree <= 3 && splineClamped(knots, degree) {
+		path = bezierSegments(degree, knots, ctrl)
+	} else {
+		path = sampleSpline(degree, knots, ctrl, weights)
+	}
+	if len(path) > 0 && vlen(vsub(path[0].P0, path[len(path)-1].P3)) < 1e-9 {
+		closed = true
+	}
+	return path, closed
+}
+
+func splineClamped(knots []float64, p int) bool {
+	n := len(knots)
+	for i := 1; i <= p; i++ {
+		if knots[i] != knots[0] || knots[n-1-i] != knots[n-1] {
+			return false
+		}
+	}
+	return true
+}
+
+// insertKnot は B スプラインにノット u を1つ挿入する (Boehm のアルゴリズム)
+func insertKnot(p int, knots []float64, ctrl []Vec2, u float64) ([]float64, []Vec2) {
+	k := 0
+	for i := 0; i < len(knots)-1; i++ {
+		if knots[i] <= u && u < knots[i+1] {
+			k = i
+		}
+	}
+	res := make([]Vec2, len(ctrl)+1)
+	for i := range res {
+		switch {
+		case i <= k-p:
+			res[i] = ctrl[i]
+		case i >= k+1:
+			res[i] = ctrl[i-1]
+		default:
+			a := (u - knots[i]) / (knots[i+p] - knots[i])
+			res[i] = vlerp(ctrl[i-1], ctrl[i], a)
+		}
+	}
+	nk := make([]float64, 0, len(knots)+1)
+	nk = append(nk, knots[:k+1]...)
+	nk = append(nk, u)
+	nk = append(nk, knots[k+1:]...)
+	return nk, res
+}
+
+// bezierSegments は内部ノットの重複度を次数まで上げ、区間ごとのベジェ曲線に分解する
+func bezierSegments(p int, knots []float64, ctrl []Vec2) shapePath {
+	first, last := knots[0], knots[len(knots)-1]
+	mult := map[float64]int{}
+	var inner []float64
+	for _, k := range knots {
+		if k > first && k < last {
+			if mult[k] == 0 {
+				inner = append(inner, k)
+			}
+			mult[k]++
+		}
+	}
+	for _, u := range inner {
+		for m := mult[u]; m < p; m++ {
+			knots, ctrl = insertKnot(p, knots, ctrl, u)
+		}
+	}
+
+	var path shapePath
+	for i := 0; i+p < len(ctrl); i += p {
+		q := ctrl[i : i+p+1]
+		switch p {
+		case 1:
+			path = append(path, lineSegment(q[0], q[1]))
+		case 2:
+			path = append(path, quadSegment(q[0], q[1], q[2]))
+		case 3:
+			path = append(path, pathSegment{P0: q[0], P1: q[1], P2: q[2], P3: q[3]})
+		}
+	}
+	return path
+}
+
+// sampleSpline は (有理) B スプラインを de Boor のアルゴリズムで評価し、折れ線で近似する
+func sampleSpline(p int, knots []float64, ctrl []Vec2, weights []float64) shapePath {
+	w := func(i int) float64 {
+		if i < len(weights) && weights[i] > 0 {
+			return weights[i]
+		}
+		return 1
+	}
+	eval := func(u float64) Vec2 {
+		k := p
+		for k < len(ctrl)-1 && u >= knots[k+1] {
+			k++
+		}
+		type hp struct{ x, y, w float64 }
+		d := make([]hp, p+1)
+		for j := 0; j <= p; j++ {
+			i := j + k - p
+			d[j] = hp{ctrl[i].X * w(i), ctrl[i].Y * w(i), w(i)}
+		}
+		for r := 1; r <= p; r++ {
+			for j := p; j >= r; j-- {
+				i := j + k - p
+				den := knots[i+p-r+1] - knots[i]
+				a := 0.0
+				if den != 0 {
+					a = (u - knots[i]) / den
+				}
+				d[j] = hp{
+					(1-a)*d[j-1].x + a*d[j].x,
+					(1-a)*d[j-1].y + a*d[j].y,
+					(1-a)*d[j-1].w + a*d[j].w,
+				}
+			}
+		}
+		return Vec2{X: d[p].x / d[p].w, Y: d[p].y / d[p].w}
+	}
+
+	lo, hi := knots[p], knots[len(ctrl)]
+	if hi <= lo {
+		return nil
+	}
+	steps := dxfSplineSamples * (len(ctrl) - p)
+	var path shapePath
+	prev := eval(lo)
+	for i := 1; i <= steps; i++ {
+		u := lo + (hi-lo)*float64(i)/float64(steps)
+		if i == steps {
+			u = math.Nextafter(hi, lo)
+		}
+		pt := eval(u)
+		path = append(path, lineSegment(prev, pt))
+		prev = pt
+	}
+	return path
+}
+
+var (
+	mtextParamCode  = regexp.MustCompile(`\\[ACFHQTWacfhqtwp][^;\\]*;`)
+	mtextToggleCode = regexp.MustCompile(`\\[LlOoKk]`)
+	mtextStack      = regexp.MustCompile(`\\S([^;]*);`)
+)
+
+// stripMText は MTEXT の書式コードを取り除いて文字列にする
+func stripMText(s string) string {
+	s = strings.NewReplacer(`\P`, "\n", `\~`, " ", `\\`, "\x00", `\{`, "\x01", `\}`, "\x02").Replace(s)
+	s = mtextStack.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.NewReplacer("^", "/", "#", "/").Replace(mtextStack.FindStringSubmatch(m)[1])
+	})
+	s = mtextParamCode.ReplaceAllString(s, "")
+	s = mtextToggleCode.ReplaceAllString(s, "")
+	s = strings.NewReplacer("{", "", "}", "", "\x00", `\`, "\x01", "{", "\x02", "}").Replace(s)
+	return decodeDXFText(s)
+}
+
+// dxfEntities は図形 (cm) をアセットのエンティティにする。
+// 同じ画層で端点が一致する開いた線はつないで1つの輪郭にし、閉じない線は往復する多角形で表す。
+func dxfEntities(g dxfGeometry) []Entity {
+	var entities []Entity
+	polygon := func(path shapePath, layer string) {
+		if pts := pathPoints(path); len(pts) >= 2 {
+			entities = append(entities, Entity{Type: "polygon", Layer: layer, Points: pts})
+		}
+	}
+
+	var open []dxfPiece
+	for _, p := range g.Pieces {
+		switch {
+		case p.Ellipse != nil:
+			e := p.Ellipse
+			ent := Entity{
+				Type:       "ellipse",
+				Layer:      p.Layer,
+				CX:         f64ptr(roundCoord(e.Center.X)),
+				CY:         f64ptr(roundCoord(e.Center.Y)),
+				RX:         f64ptr(roundCoord(e.RX)),
+				RY:         f64ptr(roundCoord(e.RY)),
+				StartAngle: f64ptr(0),
+				EndAngle:   f64ptr(360),
+				ArcMode:    "sector",
+			}
+			if rot := roundCoord(normalizeDeg(e.Rotation)); rot != 0 && rot != 360 {
+				ent.Rotation = f64ptr(rot)
+			}
+			entities = append(entities, ent)
+		case p.Closed:
+			polygon(p.Path, p.Layer)
+		default:
+			open = append(open, p)
+		}
+	}
+
+	for _, chain := range chainPieces(open) {
+		path := chain.Path
+		if vlen(vsub(path[0].P0, path[len(path)-1].P3)) > dxfChainTolerance {
+			path = append(path, reversePath(path)...)
+		}
+		polygon(path, chain.Layer)
+	}
+
+	for _, l := range g.Labels {
+		ent := Entity{
+			Type:     "text",
+			Layer:    l.Layer,
+			X:        f64ptr(roundCoord(l.Pos.X)),
+			Y:        f64ptr(roundCoord(l.Pos.Y)),
+			Text:     l.Text,
+			FontSize: f64ptr(roundCoord(l.Height * baseScale)),
+		}
+		if rot := roundCoord(normalizeDeg(l.Rotation)); rot != 0 && rot != 360 {
+			ent.Rotation = f64ptr(rot)
+		}
+		entities = append(entities, ent)
+	}
+	return entities
+}
+
+// chainPieces は同じ画層で端点が一致する開いた線を順につなぐ
+func chainPieces(pieces []dxfPiece) []dxfPiece {
+	near := func(a, b Vec2) bool { return vlen(vsub(a, b)) <= dxfChainTolerance }
+	used := make([]bool, len(pieces))
+	var chains []dxfPiece
+	for i, p := range pieces {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		path := append(shapePath{}, p.Path...)
+		for extended := true; extended; {
+			extended = false
+			for j, q := range pieces {
+				if used[j] || q.Layer != p.Layer {
+					continue
+				}
+				start, end := path[0].P0, path[len(path)-1].P3
+				switch {
+				case near(end, q.Path[0].P0):
+					path = append(path, q.Path...)
+				case near(end, q.Path[len(q.Path)-1].P3):
+					path = append(path, reversePath(q.Path)...)
+				case near(start, q.Path[len(q.Path)-1].P3):
+					path = append(append(shapePath{}, q.Path...), path...)
+				case near(start, q.Path[0].P0):
+					path = append(reversePath(q.Path), path...)
+				default:
+					continue
+				}
+				used[j] = true
+				extended = true
+			}
+		}
+		chains = append(chains, dxfPiece{Path: path, Layer: p.Layer})
+	}
+	return chains
+}
+
+// pathPoints は閉じた輪郭を頂点列にする。曲線の制御点は isCurve の h2 (始点側) / h1 (終点側) で表す。
+func pathPoints(path shapePath) []Point {
+	if len(path) > 1 && vlen(vsub(path[0].P0, path[len(path)-1].P3)) <= dxfChainTolerance && path[len(path)-1].Line &&
+		vlen(vsub(path[len(path)-1].P0, path[len(path)-1].P3)) <= dxfChainTolerance {
+		path = path[:len(path)-1] // 始点に戻る長さ 0 の辺
+	}
+	n := len(path)
+	pts := make([]Point, n)
+	for i, s := range path {
+		pts[i].X = roundCoord(s.P0.X)
+		pts[i].Y = roundCoord(s.P0.Y)
+	}
+	for i, s := range path {
+		if s.Line {
+			continue
+		}
+		next := (i + 1) % n
+		pts[i].IsCurve = true
+		pts[i].H2 = Vec2{X: roundCoord(s.P1.X - s.P0.X), Y: roundCoord(s.P1.Y - s.P0.Y)}
+		pts[next].IsCurve = true
+		pts[next].H1 = Vec2{X: roundCoord(s.P2.X - s.P3.X), Y: roundCoord(s.P2.Y - s.P3.Y)}
+	}
+	return pts
+}
+
+// roundCoord は座標を 0.001cm 単位に丸める
+func roundCoord(v float64) float64 {
+	r := math.Round(v*1000) / 1000
+	if r == 0 {
+		return 0 // -0 を避ける
+	}
+	return r
+}
+
+// dxfAsset は図形 (cm) からアセットを作る。形状が無い場合は false。
+func dxfAsset(id, name, typ string, g dxfGeometry) (Asset, bool) {
+	entities := dxfEntities(g)
+	if len(entities) == 0 {
+		return Asset{}, false
+	}
+	b := g.bounds()
+	return Asset{
+		ID:       id,
+		Name:     name,
+		Type:     typ,
+		W:        math.Round(b.width()),
+		H:        math.Round(b.height()),
+		Color:    defaultTypeColors[typ],
+		Entities: entities,
+		BoundX:   f64ptr(math.Round(b.Min.X)),
+		BoundY:   f64ptr(math.Round(b.Min.Y)),
+	}, true
+}
+
+// resolveDXFUnit は換算に使う単位を決める。指定が無ければ $INSUNITS (未設定なら mm)。
+func resolveDXFUnit(f *dxfFile, override string) (dxfUnit, string, error) {
+	if override != "" {
+		u, ok := dxfUnitNames[strings.ToLower(override)]
+		if !ok {
+			return dxfUnit{}, "", fmt.Errorf("unsupported units: %q", override)
+		}
+		return u, "", nil
+	}
+	code := f.headerInt("$INSUNITS", 0)
+	if u, ok := dxfInsUnits[code]; ok {
+		return u, "", nil
+	}
+	if code == 0 {
+		return dxfInsUnits[4], "", nil
+	}
+	return dxfInsUnits[4], fmt.Sprintf("$INSUNITS=%d には対応していないため mm とみなしました", code), nil
+}
+
+// convertDXF は DXF をアセットとインスタンス (cm) に変換する。
+// ブロック外の図形は name のアセットにまとめ、原点に配置する。
+func convertDXF(f *dxfFile, options DXFImportOptions, name string, now time.Time) (DXFImportResult, error) {
+	unit, unitNote, err := resolveDXFUnit(f, options.Units)
+	if err != nil {
+		return DXFImportResult{}, err
+	}
+	assetType := options.AssetType
+	if assetType == "" {
+		assetType = "furniture"
+	}
+	if _, ok := defaultTypeColors[assetType]; !ok {
+		return DXFImportResult{}, fmt.Errorf("unsupported asset type: %q", assetType)
+	}
+
+	res := DXFImportResult{Assets: []Asset{}, Instances: []Instance{}, Units: unit.Name, Warnings: []string{}}
+	if unitNote != "" {
+		res.Warnings = append(res.Warnings, unitNote)
+	}
+	c := newDXFConverter(f)
+	stamp := now.UnixMilli()
+	newAssetID := func() string { return fmt.Sprintf("a-dxf-%d-%d", stamp, len(res.Assets)+1) }
+	toCM := dxfTransform{SX: unit.CM, SY: unit.CM}
+
+	// 参照されているブロック (参照されていないブロックはインスタンス無しのアセットにする)
+	referenced := map[string]bool{}
+	for _, e := range f.Entities {
+		if e.Type == "INSERT" {
+			referenced[strings.TrimSpace(e.str(2))] = true
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, e := range block.Entities {
+			if e.Type == "INSERT" {
+				referenced[strings.TrimSpace(e.str(2))] = true
+			}
+		}
+	}
+
+	type variantKey struct {
+		Block  string
+		SX, SY float64
+	}
+	variants := map[variantKey]string{}
+	var loose dxfGeometry
+
+	for _, e := range f.Entities {
+		if e.int(67, 0) == 1 {
+			continue // ペーパー空間
+		}
+		if e.Type != "INSERT" {
+			if layer := strings.TrimSpace(e.str(8)); (e.Type == "TEXT" || e.Type == "MTEXT") && layer == dxfTextLayer {
+				// 画層 "text" の文字列はテキストインスタンスにする (ExportProjectDXF の逆変換)
+				for _, l := range c.entityGeometry(e, 0).transform(toCM).Labels {
+					res.Instances = append(res.Instances, Instance{
+						ID:       fmt.Sprintf("t-%d-%d", stamp, len(res.Instances)+1),
+						Type:     "text",
+						X:        roundCoord(l.Pos.X),
+						Y:        roundCoord(l.Pos.Y),
+						Rotation: roundCoord(l.Rotation),
+						Text:     l.Text,
+						FontSize: f64ptr(roundCoord(l.Height * baseScale)),
+						Color:    dxfDefaultText,
+					})
+				}
+				continue
+			}
+			loose.append(c.entityGeometry(e, 0))
+			continue
+		}
+
+		blockName := strings.TrimSpace(e.str(2))
+		t := insertTransform(e)
+		key := variantKey{Block: blockName, SX: t.SX, SY: t.SY}
+		assetID, ok := variants[key]
+		if !ok {
+			geom, found := c.blockGeometry(blockName, 0)
+			if !found {
+				continue
+			}
+			typ := assetType
+			if layer := strings.TrimSpace(e.str(8)); defaultTypeColors[layer] != "" {
+				typ = layer
+			}
+			label := blockName
+			if d := c.file.Blocks[blockName].Description; d != "" {
+				label = d
+			}
+			if t.SX != 1 || t.SY != 1 {
+				label = fmt.Sprintf("%s (%g×%g)", label, t.SX, t.SY)
+			}
+			geom = geom.transform(dxfTransform{SX: t.SX * unit.CM, SY: t.SY * unit.CM})
+			asset, ok := dxfAsset(newAssetID(), label, typ, geom)
+			if !ok {
+				c.note("ブロック %s に変換できる図形がありません", blockName)
+				variants[key] = ""
+				continue
+			}
+			res.Assets = append(res.Assets, asset)
+			assetID = asset.ID
+			variants[key] = assetID
+		}
+		if assetID == "" {
+			continue
+		}
+		var typ string
+		for _, a := range res.Assets {
+			if a.ID == assetID {
+				typ = a.Type
+			}
+		}
+		res.Instances = append(res.Instances, Instance{
+			ID:       fmt.Sprintf("i-%d-%d", stamp, len(res.Instances)+1),
+			AssetID:  assetID,
+			Type:     typ,
+			X:        roundCoord(t.Origin.X * unit.CM),
+			Y:        roundCoord(t.Origin.Y * unit.CM),
+			Rotation: roundCoord(normalizeDeg(t.Rotation)),
+		})
+	}
+
+	// 参照されていないブロック (部品ライブラリなど)
+	for _, blockName := range f.Order {
+		if referenced[blockName] || strings.HasPrefix(blockName, "*") {
+			continue
+		}
+		geom, _ := c.blockGeometry(blockName, 0)
+		label := blockName
+		if d := f.Blocks[blockName].Description; d != "" {
+			label = d
+		}
+		if asset, ok := dxfAsset(newAssetID(), label, assetType, geom.transform(toCM)); ok {
+			res.Assets = append(res.Assets, asset)
+		}
+	}
+
+	// ブロック外の図形
+	if asset, ok := dxfAsset(newAssetID(), name, assetType, loose.transform(toCM)); ok {
+		res.Assets = append(res.Assets, asset)
+		res.Instances = append(res.Instances, Instance{
+			ID:      fmt.Sprintf("i-%d-%d", stamp, len(res.Instances)+1),
+			AssetID: asset.ID,
+			Type:    asset.Type,
+		})
+	}
+
+	res.Warnings = append(res.Warnings, c.notes...)
+	kinds := make([]string, 0, len(c.skipped))
+	for kind := range c.skipped {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("未対応の %s を %d 個スキップしました", kind, c.skipped[kind]))
+	}
+	if len(res.Assets) == 0 && len(res.Instances) == 0 {
+		return res, fmt.Errorf("no importable entities in DXF")
+	}
+	return res, nil
+}
+
+// ParseDXF converts a DXF drawing into assets and instances (in cm) without saving them.
+// BLOCKs become assets and model-space INSERTs become instances.
+func (a *App) ParseDXF(content []byte, options DXFImportOptions) (DXFImportResult, error) {
+	f, err := readDXF(content)
+	if err != nil {
+		return DXFImportResult{}, err
+	}
+	return convertDXF(f, options, "DXF 図面", time.Now())
+}
+
+// ImportDXFProject creates a new project from a DXF drawing.
+func (a *App) ImportDXFProject(name string, content []byte, options DXFImportOptions) (*Project, error) {
+	f, err := readDXF(content)
+	if err != nil {
+		a.logError("DXF 読み込み失敗: %v", err)
+		return nil, err
+	}
+	res, err := convertDXF(f, options, name, time.Now())
+	if err != nil {
+		a.logError("DXF 変換失敗: %v", err)
+		return nil, err
+	}
+
+	newProj, err := a.CreateProject(name)
+	if err != nil {
+		return nil, err
+	}
+	data := ProjectData{LocalAssets: res.Assets, Instances: res.Instances}
+	if err := a.SaveProjectData(newProj.ID, data); err != nil {
+		a.DeleteProject(newProj.ID)
+		return nil, err
+	}
+	for _, w := range res.Warnings {
+		a.logInfo("DXF インポート: %s", w)
+	}
+	a.logInfo("DXF インポート: %s (アセット %d 件, インスタンス %d 件, 単位 %s)", name, len(res.Assets), len(res.Instances), res.Units)
+	return newProj, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// dxfSource はグループコードと値を交互に並べた DXF 文字列を作ります
+func dxfSource(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func parseDXFForTest(t *testing.T, src []byte, options DXFImportOptions) DXFImportResult {
+	t.Helper()
+	f, err := readDXF(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := convertDXF(f, options, "図面", time.Unix(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// TestDXFRoundTrip は ExportProjectDXF の出力を読み込んで形状と配置が戻ることを検証します
+func TestDXFRoundTrip(t *testing.T) {
+	table := Asset{ID: "a-fork-1", Name: "曲線テーブル", Type: "furniture", W: 100, H: 100, Entities: []Entity{
+		{Type: "polygon", Layer: "家具", Points: []Point{
+			{X: 0, Y: 0}, {X: 100, Y: 0, IsCurve: true, H1: Vec2{X: 0, Y: -20}, H2: Vec2{X: 20, Y: 20}}, {X: 100, Y: 100},
+		}},
+		{Type: "ellipse", CX: f64(50), CY: f64(50), RX: f64(30), RY: f64(20), Rotation: f64(30)},
+		{Type: "arc", CX: f64(0), CY: f64(0), RX: f64(10), RY: f64(10), StartAngle: f64(0), EndAngle: f64(90), ArcMode: "sector"},
+		{Type: "text", X: f64(10), Y: f64(10), Text: "机", FontSize: f64(20)},
+	}}
+	data := ProjectData{
+		LocalAssets: []Asset{table},
+		Instances: []Instance{
+			{ID: "r1", AssetID: "a_room6", Type: "room"},
+			{ID: "f1", AssetID: "a-fork-1", Type: "furniture", X: 50, Y: 60, Rotation: 90},
+			{ID: "t1", Type: "text", Text: "北", X: 10, Y: 20, FontSize: f64(30)},
+		},
+	}
+	dxf := renderProjectDXF(data, newAssetIndex(data.LocalAssets, getDefaultGlobalAssets()))
+	res := parseDXFForTest(t, []byte(dxf), DXFImportOptions{})
+
+	if res.Units != "cm" {
+		t.Errorf("単位が不正です: %s", res.Units)
+	}
+	if len(res.Assets) != 2 || len(res.Instances) != 3 {
+		t.Fatalf("アセット・インスタンスの数が不正です: %d, %d", len(res.Assets), len(res.Instances))
+	}
+
+	var imported Asset
+	for _, a := range res.Assets {
+		if a.Name == "曲線テーブル" {
+			imported = a
+		}
+	}
+	if imported.ID == "" || imported.Type != "furniture" {
+		t.Fatalf("アセットが復元されていません: %+v", res.Assets)
+	}
+	if got, want := assetArea(imported), assetArea(table); math.Abs(got-want) > want*0.001 {
+		t.Errorf("面積が一致しません: got %.2f, want %.2f", got, want)
+	}
+	kinds := map[string]int{}
+	for _, e := range imported.Entities {
+		kinds[e.Type]++
+		if e.Type == "ellipse" && (deref(e.RX) != 30 || deref(e.RY) != 20 || deref(e.Rotation) != 30) {
+			t.Errorf("楕円が不正です: rx=%v ry=%v rotation=%v", deref(e.RX), deref(e.RY), deref(e.Rotation))
+		}
+		if e.Type == "text" && (e.Text != "机" || deref(e.FontSize) != 20) {
+			t.Errorf("文字が不正です: %q %v", e.Text, deref(e.FontSize))
+		}
+	}
+	if kinds["polygon"] != 2 || kinds["ellipse"] != 1 || kinds["text"] != 1 {
+		t.Errorf("エンティティの種類が不正です: %v", kinds)
+	}
+
+	var furniture, room, text *Instance
+	for i := range res.Instances {
+		switch inst := &res.Instances[i]; inst.Type {
+		case "furniture":
+			furniture = inst
+		case "room":
+			room = inst
+		case "text":
+			text = inst
+		}
+	}
+	if furniture == nil || furniture.X != 50 || furniture.Y != 60 || furniture.Rotation != 90 || furniture.AssetID != imported.ID {
+		t.Errorf("家具の配置が不正です: %+v", furniture)
+	}
+	if room == nil {
+		t.Error("画層 room の INSERT が部屋になっていません")
+	}
+	if text == nil || text.Text != "北" || deref(text.FontSize) != 30 || text.Y != 20 {
+		t.Errorf("テキストインスタンスが不正です: %+v", text)
+	}
+}
+
+// TestDXFImportUnitsAndBulge は $INSUNITS による換算とふくらみ付きポリラインを検証します
+func TestDXFImportUnitsAndBulge(t *testing.T) {
+	src := dxfSource(
+		"0", "SECTION", "2", "HEADER", "9", "$INSUNITS", "70", "4", "0", "ENDSEC",
+		"0", "SECTION", "2", "BLOCKS",
+		"0", "BLOCK", "2", "DESK", "10", "500", "20", "0",
+		// 1000mm 角の正方形。上辺は半円 (bulge = 1) で外側に膨らむ。
+		"0", "LWPOLYLINE", "8", "0", "90", "4", "70", "1",
+		"10", "0", "20", "0", "10", "1000", "20", "0", "10", "1000", "20", "1000", "42", "1", "10", "0", "20", "1000",
+		"0", "ENDBLK",
+		"0", "BLOCK", "2", "LIB", "4", "部品", "10", "0", "20", "0",
+		"0", "CIRCLE", "10", "0", "20", "0", "40", "100",
+		"0", "ENDBLK",
+		"0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		"0", "INSERT", "8", "fixture", "2", "DESK", "10", "2000", "20", "0", "50", "-90",
+		"0", "HATCH", "8", "0",
+		"0", "ENDSEC",
+		"0", "EOF",
+	)
+	res := parseDXFForTest(t, []byte(src), DXFImportOptions{})
+	if res.Units != "mm" || len(res.Assets) != 2 || len(res.Instances) != 1 {
+		t.Fatalf("変換結果が不正です: units=%s assets=%d instances=%d", res.Units, len(res.Assets), len(res.Instances))
+	}
+
+	desk := res.Assets[0]
+	want := 100.0*100 + math.Pi*50*50/2
+	if got := assetArea(desk); math.Abs(got-want) > want*0.001 {
+		t.Errorf("面積が不正です: got %.1f, want %.1f", got, want)
+	}
+	if deref(desk.BoundX) != -50 || desk.W != 100 || desk.H != 150 || desk.Type != "fixture" || desk.Color != "#cccccc" {
+		t.Errorf("アセットの外接矩形・種別が不正です: boundX=%v w=%v h=%v type=%s", deref(desk.BoundX), desk.W, desk.H, desk.Type)
+	}
+	if inst := res.Instances[0]; inst.X != 200 || inst.Rotation != 270 || inst.Type != "fixture" {
+		t.Errorf("インスタンスが不正です: %+v", inst)
+	}
+
+	// 参照されていないブロックはインスタンス無しのアセットになる
+	if lib := res.Assets[1]; lib.Name != "部品" || len(lib.Entities) != 1 || deref(lib.Entities[0].RX) != 10 {
+		t.Errorf("部品ブロックが不正です: %+v", lib)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "HATCH") {
+		t.Errorf("警告が不正です: %v", res.Warnings)
+	}
+
+	// 単位の指定は $INSUNITS より優先される
+	res = parseDXFForTest(t, []byte(src), DXFImportOptions{Units: "cm"})
+	if inst := res.Instances[0]; inst.X != 2000 {
+		t.Errorf("単位の指定が反映されていません: %+v", inst)
+	}
+	f, _ := readDXF([]byte(src))
+	if _, err := convertDXF(f, DXFImportOptions{Units: "pt"}, "", time.Now()); err == nil {
+		t.Error("未対応の単位でエラーになっていません")
+	}
+	if _, err := convertDXF(f, DXFImportOptions{AssetType: "door"}, "", time.Now()); err == nil {
+		t.Error("未対応の種別でエラーになっていません")
+	}
+}
+
+// TestDXFImportLooseEntities はブロック外の線の連結と Shift_JIS の文字列を検証します
+func TestDXFImportLooseEntities(t *testing.T) {
+	src := dxfSource(
+		"0", "SECTION", "2", "HEADER", "9", "$INSUNITS", "70", "5", "0", "ENDSEC",
+		"0", "SECTION", "2", "ENTITIES",
+		// 順不同・向きも揃っていない4本の線で長方形を描く
+		"0", "LINE", "8", "壁", "10", "0", "20", "0", "11", "300", "21", "0",
+		"0", "LINE", "8", "壁", "10", "300", "20", "200", "11", "0", "21", "200",
+		"0", "LINE", "8", "壁", "10", "300", "20", "200", "11", "300", "21", "0",
+		"0", "LINE", "8", "壁", "10", "0", "20", "0", "11", "0", "21", "200",
+		// 閉じない線は往復する多角形になる
+		"0", "LINE", "8", "補助", "10", "0", "20", "300", "11", "100", "21", "300",
+		"0", "MTEXT", "8", "0", "10", "150", "20", "100", "40", "10", "1", `{\fMS Gothic|b0;居間}\P(12畳)`,
+		"0", "ENDSEC",
+		"0", "EOF",
+	)
+	sjis, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := parseDXFForTest(t, sjis, DXFImportOptions{AssetType: "room"})
+	if len(res.Assets) != 1 || len(res.Instances) != 1 {
+		t.Fatalf("変換結果が不正です: assets=%d instances=%d", len(res.Assets), len(res.Instances))
+	}
+	a := res.Assets[0]
+	if a.Name != "図面" || a.Type != "room" {
+		t.Errorf("アセットの名前・種別が不正です: %s %s", a.Name, a.Type)
+	}
+	if len(a.Entities) != 3 {
+		t.Fatalf("エンティティ数が不正です: %d", len(a.Entities))
+	}
+	if wall := a.Entities[0]; wall.Layer != "壁" || len(wall.Points) != 4 || entityArea(wall) != 300*200 {
+		t.Errorf("線が長方形に連結されていません: %+v", wall)
+	}
+	if line := a.Entities[1]; len(line.Points) != 2 || entityArea(line) != 0 {
+		t.Errorf("開いた線が不正です: %+v", line)
+	}
+	if text := a.Entities[2]; text.Text != "居間\n(12畳)" || deref(text.FontSize) != 20 {
+		t.Errorf("文字が不正です: %q", text.Text)
+	}
+}
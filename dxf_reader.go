@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// --- DXF リーダー ---
+// ASCII DXF をグループコードの組に分解し、HEADER の変数・BLOCKS・ENTITIES を取り出す。
+
+// dxfPair はグループコードと値の組
+type dxfPair struct {
+	Code  int
+	Value string
+}
+
+// dxfEntity は "0" で始まる1つのエンティティ。POLYLINE の場合は Vertices に VERTEX を持つ。
+type dxfEntity struct {
+	Type     string
+	Pairs    []dxfPair
+	Vertices []*dxfEntity
+}
+
+// dxfBlockDef は BLOCK 定義
+type dxfBlockDef struct {
+	Name        string
+	Description string
+	Base        Vec2
+	Entities    []*dxfEntity
+}
+
+// dxfFile は読み込んだ DXF の内容
+type dxfFile struct {
+	Header   map[string][]dxfPair
+	Blocks   map[string]*dxfBlockDef
+	Order    []string // ブロックの定義順
+	Entities []*dxfEntity
+}
+
+// str は最初に現れたグループコードの値を返す
+func (e *dxfEntity) str(code int) string {
+	for _, p := range e.Pairs {
+		if p.Code == code {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// float は最初に現れたグループコードの数値を返す (無い場合は def)
+func (e *dxfEntity) float(code int, def float64) float64 {
+	for _, p := range e.Pairs {
+		if p.Code == code {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(p.Value), 64); err == nil {
+				return v
+			}
+		}
+	}
+	return def
+}
+
+func (e *dxfEntity) int(code int, def int) int {
+	return int(e.float(code, float64(def)))
+}
+
+// floats は同じグループコードの値をすべて返す (ノット列など)
+func (e *dxfEntity) floats(code int) []float64 {
+	var vs []float64
+	for _, p := range e.Pairs {
+		if p.Code == code {
+			v, _ := strconv.ParseFloat(strings.TrimSpace(p.Value), 64)
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// point は code (X) と code+10 (Y) の座標を返す
+func (e *dxfEntity) point(code int) Vec2 {
+	return Vec2{X: e.float(code, 0), Y: e.float(code+10, 0)}
+}
+
+// points は code/code+10 の組を出現順にすべて返す
+func (e *dxfEntity) points(code int) []Vec2 {
+	var pts []Vec2
+	for _, p := range e.Pairs {
+		v, _ := strconv.ParseFloat(strings.TrimSpace(p.Value), 64)
+		switch p.Code {
+		case code:
+			pts = append(pts, Vec2{X: v})
+		case code + 10:
+			if len(pts) > 0 {
+				pts[len(pts)-1].Y = v
+			}
+		}
+	}
+	return pts
+}
+
+// decodeDXFBytes はファイル内容を文字列にする。UTF-8 でない場合は Shift_JIS (ANSI_932) とみなす。
+func decodeDXFBytes(data []byte) (string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if bytes.HasPrefix(data, []byte("AutoCAD Binary DXF")) {
+		return "", fmt.Errorf("binary DXF is not supported")
+	}
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("unsupported DXF text encoding: %v", err)
+	}
+	return string(decoded), nil
+}
+
+// parseDXFPairs は DXF 文字列をグループコードの組に分解する
+func parseDXFPairs(s string) ([]dxfPair, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	var pairs []dxfPair
+	for i := 0; i+1 < len(lines); i += 2 {
+		codeStr := strings.TrimSpace(lines[i])
+		if codeStr == "" && i+2 >= len(lines) {
+			break
+		}
+		code, err := strconv.Atoi(codeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DXF group code at line %d: %q", i+1, codeStr)
+		}
+		value := strings.TrimRight(lines[i+1], "\r")
+		if code == 0 && strings.TrimSpace(value) == "EOF" {
+			break
+		}
+		pairs = append(pairs, dxfPair{Code: code, Value: value})
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("empty DXF")
+	}
+	return pairs, nil
+}
+
+// splitDXFEntities は "0" のグループで区切ってエンティティの列にする。
+// POLYLINE に続く VERTEX は SEQEND まで POLYLINE にまとめる。
+func splitDXFEntities(pairs []dxfPair) []*dxfEntity {
+	var list []*dxfEntity
+	var current, polyline *dxfEntity
+	for _, p := range pairs {
+		if p.Code != 0 {
+			if current != nil {
+				current.Pairs = append(current.Pairs, p)
+			}
+			continue
+		}
+		current = &dxfEntity{Type: strings.TrimSpace(p.Value)}
+		switch {
+		case current.Type == "VERTEX" && polyline != nil:
+			polyline.Vertices = append(polyline.Vertices, current)
+		case current.Type == "SEQEND":
+			polyline = nil
+		default:
+			if current.Type == "POLYLINE" {
+				polyline = current
+			} else {
+				polyline = nil
+			}
+			list = append(list, current)
+		}
+	}
+	return list
+}
+
+// readDXF は DXF を読み込む
+func readDXF(data []byte) (*dxfFile, error) {
+	text, err := decodeDXFBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	pairs, err := parseDXFPairs(text)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &dxfFile{Header: map[string][]dxfPair{}, Blocks: map[string]*dxfBlockDef{}}
+	for i := 0; i < len(pairs); i++ {
+		if pairs[i].Code != 0 || strings.TrimSpace(pairs[i].Value) != "SECTION" || i+1 >= len(pairs) {
+			continue
+		}
+		name := strings.TrimSpace(pairs[i+1].Value)
+		end := i + 2
+		for end < len(pairs) && !(pairs[end].Code == 0 && strings.TrimSpace(pairs[end].Value) == "ENDSEC") {
+			end++
+		}
+		body := pairs[i+2 : end]
+		switch name {
+		case "HEADER":
+			var variable string
+			for _, p := range body {
+				if p.Code == 9 {
+					variable = strings.TrimSpace(p.Value)
+					continue
+				}
+				f.Header[variable] = append(f.Header[variable], p)
+			}
+		case "BLOCKS":
+			var block *dxfBlockDef
+			for _, e := range splitDXFEntities(body) {
+				switch e.Type {
+				case "BLOCK":
+					block = &dxfBlockDef{
+						Name:        strings.TrimSpace(e.str(2)),
+						Description: decodeDXFText(e.str(4)),
+						Base:        e.point(10),
+					}
+				case "ENDBLK":
+					if block != nil && block.Name != "" {
+						if _, dup := f.Blocks[block.Name]; !dup {
+							f.Order = append(f.Order, block.Name)
+						}
+						f.Blocks[block.Name] = block
+					}
+					block = nil
+				default:
+					if block != nil {
+						block.Entities = append(block.Entities, e)
+					}
+				}
+			}
+		case "ENTITIES":
+			f.Entities = splitDXFEntities(body)
+		}
+		i = end
+	}
+	return f, nil
+}
+
+// headerInt は HEADER 変数の整数値を返す
+func (f *dxfFile) headerInt(name string, def int) int {
+	for _, p := range f.Header[name] {
+		if v, err := strconv.Atoi(strings.TrimSpace(p.Value)); err == nil {
+			return v
+		}
+	}
+	return def
+}
+
+// decodeDXFText は \U+XXXX エスケープと %%d などの制御コードを文字に戻す
+func decodeDXFText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], `\U+`) && i+7 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+3:i+7], 16, 32); err == nil {
+				b.WriteRune(rune(v))
+				i += 6
+				continue
+			}
+		}
+		if strings.HasPrefix(s[i:], "%%") && i+2 < len(s) {
+			switch s[i+2] {
+			case 'd', 'D':
+				b.WriteString("°")
+			case 'c', 'C':
+				b.WriteString("⌀")
+			case 'p', 'P':
+				b.WriteString("±")
+			case '%':
+				b.WriteString("%")
+			}
+			// %%u / %%o (下線・上線の切り替え) は読み捨てる
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// --- 概算見積 ---
+// 部屋の床面積に床材の単価 (m² または畳あたり) を掛けた床材費と、配置した設備・家具の単価・施工費を
+// 明細にまとめ、税抜の小計に消費税を加える。単価はプロジェクトの costRates とアセットの unitPrice・laborPrice。
+// 単価の無い部屋・アセットは明細に載せない。
+
+// defaultTaxRate は costRates が無いプロジェクトの消費税率
+const defaultTaxRate = 0.1
+
+// 床材の単価の単位 (FlooringRate.Unit)
+const (
+	FlooringUnitM2 = "m2"
+	FlooringUnitJo = "jo"
+)
+
+// estimateCategoryLabels は CSV・PDF に書く明細の区分名
+var estimateCategoryLabels = map[string]string{
+	"flooring":  "床材",
+	"fixture":   "設備",
+	"furniture": "家具",
+	"labor":     "施工費",
+}
+
+// estimateHeader は CSV・PDF の見出し
+var estimateHeader = []string{"区分", "品名", "数量", "単位", "単価", "金額"}
+
+// normalizeCostRates は既定値を補い、不正な単価を検出する
+func normalizeCostRates(r *CostRates) (CostRates, error) {
+	if r == nil {
+		return CostRates{Flooring: []FlooringRate{}, TaxRate: defaultTaxRate, TatamiSize: TatamiEdoma}, nil
+	}
+	rates := *r
+	if rates.TaxRate < 0 || rates.TaxRate > 1 || math.IsNaN(rates.TaxRate) {
+		return CostRates{}, fmt.Errorf("invalid tax rate: %v", rates.TaxRate)
+	}
+	if rates.TatamiSize == "" {
+		rates.TatamiSize = TatamiEdoma
+	}
+	rates.Flooring = append([]FlooringRate{}, rates.Flooring...)
+	for i := range rates.Flooring {
+		f := &rates.Flooring[i]
+		if f.Unit == "" {
+			f.Unit = FlooringUnitM2
+		}
+		if f.Unit != FlooringUnitM2 && f.Unit != FlooringUnitJo {
+			return CostRates{}, fmt.Errorf("unknown flooring unit: %s", f.Unit)
+		}
+		if f.UnitPrice < 0 || f.LaborPrice < 0 || math.IsNaN(f.UnitPrice) || math.IsNaN(f.LaborPrice) {
+			return CostRates{}, fmt.Errorf("invalid flooring price for %q", f.AssetID)
+		}
+	}
+	return rates, nil
+}
+
+// estimateLine は数量と単価から金額を計算した明細を作る
+func estimateLine(category, name string, qty float64, unit string, price float64) EstimateLine {
+	return EstimateLine{Category: category, Name: name, Quantity: qty, Unit: unit, UnitPrice: price, Amount: round2(qty * price)}
+}
+
+// buildCostEstimate はプロジェクトの概算見積を作る。
+// 明細は床材・設備・家具・施工費の順。施工費は床材の張り手間、設備・家具の取付費の順に並べる。
+func buildCostEstimate(data ProjectData, assets assetIndex) (CostEstimate, error) {
+	rates, err := normalizeCostRates(data.CostRates)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	areas, err := computeProjectAreas(data, assets, rates.TatamiSize)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	flooring := map[string]FlooringRate{}
+	for _, f := range rates.Flooring {
+		flooring[f.AssetID] = f
+	}
+	levelNames := map[string]string{}
+	if len(data.Levels) > 1 {
+		for _, l := range data.Levels {
+			levelNames[l.ID] = l.Name + " "
+		}
+	}
+
+	est := CostEstimate{Lines: []EstimateLine{}, TaxRate: rates.TaxRate}
+	var labor []EstimateLine
+	for _, room := range areas.Rooms {
+		rate, ok := flooring[room.AssetID]
+		if !ok {
+			if rate, ok = flooring[""]; !ok {
+				continue
+			}
+		}
+		qty, unit := room.Area.M2, "m²"
+		if rate.Unit == FlooringUnitJo {
+			qty, unit = room.Area.Jo, "畳"
+		}
+		material := rate.Material
+		if material == "" {
+			material = "床材"
+		}
+		name := levelNames[room.LevelID] + room.Name + " " + material
+		if rate.UnitPrice > 0 {
+			line := estimateLine("flooring", name, qty, unit, rate.UnitPrice)
+			line.RoomID, line.AssetID = room.InstanceID, room.AssetID
+			est.Lines = append(est.Lines, line)
+		}
+		if rate.LaborPrice > 0 {
+			line := estimateLine("labor", name+" 張り手間", qty, unit, rate.LaborPrice)
+			line.RoomID, line.AssetID = room.InstanceID, room.AssetID
+			labor = append(labor, line)
+		}
+	}
+
+	// 設備・家具は部材表の並び (種類・名称順) で数える
+	for _, item := range buildBillOfMaterials(data, assets).Items {
+		if item.UnitPrice > 0 {
+			line := estimateLine(item.Type, item.Name, float64(item.Count), "個", item.UnitPrice)
+			line.AssetID = item.AssetID
+			est.Lines = append(est.Lines, line)
+		}
+		if a := assets[item.AssetID]; a != nil && a.LaborPrice > 0 {
+			line := estimateLine("labor", item.Name+" 取付", float64(item.Count), "個", a.LaborPrice)
+			line.AssetID = item.AssetID
+			labor = append(labor, line)
+		}
+	}
+	est.Lines = append(est.Lines, labor...)
+
+	for _, line := range est.Lines {
+		est.Subtotal += line.Amount
+	}
+	est.Subtotal = round2(est.Subtotal)
+	// 消費税は1円未満を切り捨てる。浮動小数点の誤差で切り捨てすぎないよう先に丸める。
+	est.Tax = math.Floor(round2(est.Subtotal * est.TaxRate))
+	est.Total = round2(est.Subtotal + est.Tax)
+	return est, nil
+}
+
+// taxLabel は消費税の行の名前
+func taxLabel(rate float64) string {
+	return "消費税 (" + fmtNum(round2(rate*100)) + "%)"
+}
+
+// estimateRows は見積を見出し・明細・小計・消費税・合計の行にする。数値の列は float64。
+func estimateRows(est CostEstimate) [][]interface{} {
+	header := make([]interface{}, len(estimateHeader))
+	for i, h := range estimateHeader {
+		header[i] = h
+	}
+	rows := [][]interface{}{header}
+	for _, line := range est.Lines {
+		rows = append(rows, []interface{}{
+			estimateCategoryLabels[line.Category], line.Name, line.Quantity, line.Unit, line.UnitPrice, line.Amount,
+		})
+	}
+	return append(rows,
+		[]interface{}{"小計", "", "", "", "", est.Subtotal},
+		[]interface{}{taxLabel(est.TaxRate), "", "", "", "", est.Tax},
+		[]interface{}{"合計", "", "", "", "", est.Total},
+	)
+}
+
+// fmtAmount は金額を3桁区切りで整形する (小数は2桁まで)
+func fmtAmount(v float64) string {
+	s := fmtNum(round2(v))
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + frac
+}
+
+// fitText は幅 maxEm に収まるように文字列を切り詰める
+func fitText(s string, maxEm float64) string {
+	if textWidthEm(s) <= maxEm {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && textWidthEm(string(r))+1 > maxEm {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
+// 見積書 PDF のレイアウト (A4 縦, mm)
+const (
+	estimatePageW   = 210.0
+	estimatePageH   = 297.0
+	estimateMarginX = 20.0
+	estimateTopMM   = 30.0 // 1ページ目の表の上端から用紙上端まで (表題・宛名の欄)
+	estimateRowHMM  = 6.5
+	estimateTextMM  = 3.0
+	estimateFootMM  = 15.0
+)
+
+// renderEstimatePDF は見積書の PDF を生成する。明細が1ページに収まらない場合は複数ページにする。
+// estimateTableTop は明細表の上端。1ページ目は表題と見積金額の下から始める。
+func estimateTableTop(first bool) float64 {
+	top := estimatePageH - 20
+	if first {
+		top -= estimateTopMM + 8
+	}
+	return top
+}
+
+// estimatePageCapacity は1ページに入る明細・小計の行数 (見出しの行を除く)
+func estimatePageCapacity(first bool) int {
+	return int((estimateTableTop(first) - estimateRowHMM - estimateFootMM) / estimateRowHMM)
+}
+
+func renderEstimatePDF(est CostEstimate, title planTitle, now time.Time) []byte {
+	left, right := estimateMarginX, estimatePageW-estimateMarginX
+	// 区分・品名は左揃え、数量・単価・金額は右揃え、単位は左揃え
+	colX := []float64{left + 2, left + 20, left + 108, left + 110, left + 140, right - 2}
+	colAlign := []float64{0, 0, 1, 0, 1, 1}
+	nameEm := (colX[2] - colX[1] - 18) / estimateTextMM
+
+	row := func(c *pdfCanvas, y float64, cells []string) {
+		for i, s := range cells {
+			c.text(Vec2{X: colX[i], Y: y + 2}, estimateTextMM, s, colAlign[i], 0)
+		}
+	}
+	summary := [][]string{
+		{"小計", "", "", "", "", fmtAmount(est.Subtotal)},
+		{taxLabel(est.TaxRate), "", "", "", "", fmtAmount(est.Tax)},
+		{"合計", "", "", "", "", fmtAmount(est.Total)},
+	}
+
+	var pages []*pdfCanvas
+	lines := est.Lines
+	summarized := false
+	for !summarized {
+		c := newPDFCanvas()
+		c.strokeColor(pdfBlack)
+		c.fillColor(pdfBlack)
+		c.lineWidth(sheetRuleMM)
+		top := estimateTableTop(len(pages) == 0)
+		if len(pages) == 0 {
+			c.text(Vec2{X: estimatePageW / 2, Y: top - 8}, 7, "概算見積書", 0.5, 0)
+			c.text(Vec2{X: left, Y: top - 20}, 4, title.Name, 0, 0)
+			c.text(Vec2{X: right, Y: top - 20}, estimateTextMM, now.Local().Format("2006/01/02"), 1, 0)
+			c.text(Vec2{X: left, Y: estimatePageH - 20 - estimateTopMM + 2}, 4.5, "御見積金額 "+fmtAmount(est.Total)+" (税込)", 0, 0)
+		}
+		y := top - estimateRowHMM
+		row(c, y, estimateHeader)
+		c.line(Vec2{X: left, Y: y}, Vec2{X: right, Y: y})
+
+		// 明細を詰め、残りの行に小計以下が収まらなければ次のページに送る
+		capacity := estimatePageCapacity(len(pages) == 0)
+		n := min(len(lines), capacity)
+		for _, line := range lines[:n] {
+			y -= estimateRowHMM
+			row(c, y, []string{
+				estimateCategoryLabels[line.Category], fitText(line.Name, nameEm), fmtNum(line.Quantity),
+				line.Unit, fmtAmount(line.UnitPrice), fmtAmount(line.Amount),
+			})
+		}
+		lines = lines[n:]
+		if len(lines) == 0 && capacity-n >= len(summary) {
+			summarized = true
+			c.line(Vec2{X: left, Y: y}, Vec2{X: right, Y: y})
+			for _, cells := range summary {
+				y -= estimateRowHMM
+				row(c, y, cells)
+			}
+		}
+		c.rect(left, y, right-left, top-y, "S")
+		pages = append(pages, c)
+	}
+	for i, c := range pages {
+		c.text(Vec2{X: estimatePageW / 2, Y: estimateFootMM / 2}, 2.5, fmt.Sprintf("%d / %d", i+1, len(pages)), 0.5, 0)
+	}
+	return buildPDF(estimatePageW, estimatePageH, pages, title.Name+" 概算見積書", now)
+}
+
+// GetCostEstimate returns the line-item cost estimate of the project: flooring by room floor area
+// and the flooring rates of the project (costRates), fixtures and furniture by the unitPrice of their assets,
+// installation labor, the subtotal, consumption tax and total.
+func (a *App) GetCostEstimate(id string) (CostEstimate, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	est, err := buildCostEstimate(data, assets)
+	if err != nil {
+		a.logError("見積作成失敗 (ID: %s): %v", id, err)
+		return CostEstimate{}, err
+	}
+	return est, nil
+}
+
+// ExportCostEstimateCSV returns the cost estimate as CSV (UTF-8 with BOM) with subtotal, tax and total rows.
+func (a *App) ExportCostEstimateCSV(id string) (string, error) {
+	est, err := a.GetCostEstimate(id)
+	if err != nil {
+		return "", err
+	}
+	out, err := encodeCSV(estimateRows(est))
+	if err != nil {
+		a.logError("見積 CSV エクスポート失敗 (ID: %s): %v", id, err)
+		return "", err
+	}
+	a.logInfo("見積 CSV エクスポート: %s", id)
+	return out, nil
+}
+
+// ExportCostEstimatePDF returns the cost estimate as an A4 PDF document (base64-encoded on the frontend side).
+func (a *App) ExportCostEstimatePDF(id string) ([]byte, error) {
+	est, err := a.GetCostEstimate(id)
+	if err != nil {
+		return nil, err
+	}
+	a.logInfo("見積 PDF エクスポート: %s", id)
+	return renderEstimatePDF(est, a.planTitleFor(id), time.Now()), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCostEstimate は床材・設備・家具・施工費の明細と消費税、CSV・PDF の書き出しを検証します
+func TestCostEstimate(t *testing.T) {
+	data := ProjectData{
+		LocalAssets: []Asset{{
+			ID: "l_chair", Name: "ダイニングチェア", Type: "furniture", W: 45, H: 50, UnitPrice: 12000,
+		}, {
+			ID: "l_sink", Name: "洗面台", Type: "fixture", W: 75, H: 50, UnitPrice: 80000, LaborPrice: 15000,
+		}},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_room6", Type: "room"},
+			{ID: "ldk", AssetID: "a_ldk10", Type: "room", Y: 270},
+			{ID: "c1", AssetID: "l_chair", Type: "furniture", X: 10, Y: 300},
+			{ID: "c2", AssetID: "l_chair", Type: "furniture", X: 100, Y: 300},
+			{ID: "sink", AssetID: "l_sink", Type: "fixture", X: 10, Y: 10},
+			{ID: "bed", AssetID: "a_bed_s", Type: "furniture", X: 200, Y: 20},
+		},
+		CostRates: &CostRates{
+			Flooring: []FlooringRate{
+				{AssetID: "a_room6", Material: "畳", Unit: FlooringUnitJo, UnitPrice: 10000},
+				{Material: "フローリング", UnitPrice: 5000, LaborPrice: 2000},
+			},
+			TaxRate: 0.1,
+		},
+	}
+	assets := newAssetIndex(data.LocalAssets, getDefaultGlobalAssets())
+	areas, err := computeProjectAreas(data, assets, TatamiEdoma)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	est, err := buildCostEstimate(data, assets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var categories []string
+	for _, line := range est.Lines {
+		categories = append(categories, line.Category)
+	}
+	if got := strings.Join(categories, ","); got != "flooring,flooring,fixture,furniture,labor,labor" {
+		t.Fatalf("明細の並びが不正です: %s", got)
+	}
+	room, ldk := est.Lines[0], est.Lines[1]
+	if room.Unit != "畳" || room.Quantity != areas.Rooms[0].Area.Jo || room.Amount != round2(areas.Rooms[0].Area.Jo*10000) {
+		t.Errorf("畳単価の床材が不正です: %+v", room)
+	}
+	if ldk.Unit != "m²" || ldk.Quantity != areas.Rooms[1].Area.M2 || ldk.RoomID != "ldk" || ldk.Name != "LDK (10畳) フローリング" {
+		t.Errorf("既定の床材が不正です: %+v", ldk)
+	}
+	if chair := est.Lines[3]; chair.Quantity != 2 || chair.Amount != 24000 {
+		t.Errorf("家具の明細が不正です: %+v", chair)
+	}
+	if l := est.Lines[4]; l.RoomID != "ldk" || l.UnitPrice != 2000 {
+		t.Errorf("床材の施工費が不正です: %+v", l)
+	}
+	if l := est.Lines[5]; l.Name != "洗面台 取付" || l.Amount != 15000 {
+		t.Errorf("取付費が不正です: %+v", l)
+	}
+	sum := 0.0
+	for _, line := range est.Lines {
+		sum += line.Amount
+	}
+	if est.Subtotal != round2(sum) || est.Tax != float64(int(est.Subtotal*0.1)) || est.Total != est.Subtotal+est.Tax {
+		t.Errorf("合計が不正です: %+v", est)
+	}
+
+	// 単価が無ければ明細は空で、税率は既定値
+	if est, err := buildCostEstimate(ProjectData{Instances: data.Instances[:2]}, assets); err != nil || len(est.Lines) != 0 || est.TaxRate != defaultTaxRate {
+		t.Errorf("単価の無い見積が不正です: %+v, %v", est, err)
+	}
+	bad := data
+	bad.CostRates = &CostRates{Flooring: []FlooringRate{{Unit: "tsubo", UnitPrice: 1}}}
+	if _, err := buildCostEstimate(bad, assets); err == nil {
+		t.Error("不明な単位でエラーになっていません")
+	}
+
+	out, err := encodeCSV(estimateRows(est))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 10 || records[0][0] != "区分" || records[8][0] != "消費税 (10%)" || records[9][5] != fmtNum(est.Total) {
+		t.Errorf("CSV が不正です: %v", records)
+	}
+
+	if got := fmtAmount(1234567.5); got != "1,234,567.5" {
+		t.Errorf("金額の整形が不正です: %s", got)
+	}
+	if got := fmtAmount(-999); got != "-999" {
+		t.Errorf("金額の整形が不正です: %s", got)
+	}
+
+	// 明細が多いと複数ページになり、合計は最後のページに載る
+	long := est
+	for i := 0; i < 60; i++ {
+		long.Lines = append(long.Lines, est.Lines[3])
+	}
+	pdf := renderEstimatePDF(long, planTitle{Name: "見積"}, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) || !bytes.Contains(pdf, []byte("/Count 2 >>")) {
+		t.Errorf("複数ページの PDF になっていません")
+	}
+
+	// 明細は1ページ目に収まるが小計以下が収まらない場合は、小計以下だけを次のページに送る
+	capacity := estimatePageCapacity(true)
+	for lines, pages := range map[int]int{capacity - 3: 1, capacity - 2: 2, capacity - 1: 2, capacity: 2, capacity + 1: 2} {
+		edge := est
+		edge.Lines = nil
+		for i := 0; i < lines; i++ {
+			edge.Lines = append(edge.Lines, est.Lines[3])
+		}
+		pdf := renderEstimatePDF(edge, planTitle{Name: "見積"}, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+		if count := fmt.Sprintf("/Count %d >>", pages); !bytes.Contains(pdf, []byte(count)) {
+			t.Errorf("明細 %d 行の PDF が %d ページになっていません", lines, pages)
+		}
+	}
+}
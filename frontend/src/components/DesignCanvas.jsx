@@ -100,7 +100,9 @@ const DesignCanvasRender = ({ viewState, asset, entities, selectedShapeIndices,
                                                 ? <ellipse cx={(s.x + s.w / 2) * BASE_SCALE} cy={toSvgY(s.y + s.h / 2) * BASE_SCALE} rx={s.w * BASE_SCALE / 2} ry={s.h * BASE_SCALE / 2} {...style} />
                                                 : s.type === 'ellipse'
                                                     ? <path d={generateEllipsePath(s)} transform={rotateTransform} {...style} />
-                                                    : <path d={generateSvgPath(s.points)} {...style} />
+                                                    : s.type === 'text'
+                                                        ? <text x={(s.x || 0) * BASE_SCALE} y={toSvgY(s.y || 0) * BASE_SCALE} fill={s.color || '#333'} fontSize={s.fontSize || 24} transform={rot ? `rotate(${rot} ${(s.x || 0) * BASE_SCALE} ${toSvgY(s.y || 0) * BASE_SCALE})` : ''} style={{ whiteSpace: 'pre', userSelect: 'none' }}>{s.text}</text>
+                                                        : <path d={generateSvgPath(s.points)} {...style} />
                                             }
                                             {/* Ellipse Handles */}
                                             {isSelected && s.type === 'ellipse' && (() => {
@@ -269,6 +269,26 @@ export const DesignProperties = () => {
                             ))}
                         </select>
                     </div>
+                    {(asset.type === 'furniture' || asset.type === 'fixture') && (
+                        <>
+                            <div className="prop-row">
+                                <label className="prop-label">メーカー</label>
+                                <input value={asset.manufacturer || ''} onChange={e => updateRoot('manufacturer', e.target.value)} className="prop-input text-left" />
+                            </div>
+                            <div className="prop-row">
+                                <label className="prop-label">品番</label>
+                                <input value={asset.sku || ''} onChange={e => updateRoot('sku', e.target.value)} className="prop-input text-left" />
+                            </div>
+                            <div className="prop-row">
+                                <label className="prop-label">単価</label>
+                                <NumberInput value={asset.unitPrice || 0} onChange={e => updateRoot('unitPrice', Math.max(0, Number(e.target.value)))} className="prop-input" />
+                            </div>
+                            <div className="prop-row">
+                                <label className="prop-label">取付費</label>
+                                <NumberInput value={asset.laborPrice || 0} onChange={e => updateRoot('laborPrice', Math.max(0, Number(e.target.value)))} className="prop-input" />
+                            </div>
+                        </>
+                    )}
                     <div className="pt-2">
                         <label className="prop-label block mb-1">全体色</label>
                         <ColorPicker value={asset.color} onChange={c => updateRoot('color', c)} palette={palette} onAddToPalette={onAddToPalette} />
@@ -355,6 +375,7 @@ export const DesignProperties = () => {
                             <label className="prop-label block mb-1">色</label>
                             <ColorPicker value={selectedEntity.color || asset.color} onChange={c => updateEntity('color', c)} palette={palette} onAddToPalette={onAddToPalette} />
                         </div>
+                        <div className="prop-row"><label className="prop-label">画層</label><input type="text" value={selectedEntity.layer || ''} placeholder="0" onChange={e => updateEntity('layer', e.target.value)} className="prop-input" title="DXF 出力時の画層名" /></div>
 
                         {/* 楕円プロパティ */}
                         {selectedEntity.type === 'ellipse' && (
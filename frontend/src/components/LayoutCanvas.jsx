@@ -1,8 +1,21 @@
 import React, { useRef, useState, useMemo, useEffect } from 'react';
-import { BASE_SCALE, SNAP_UNIT, LAYERS } from '../lib/constants';
+import { BASE_SCALE, SNAP_UNIT, LAYERS, DEFAULT_WALL_THICKNESS } from '../lib/constants';
 import { toMM, toSvgY, toCartesianY, toSvgRotation } from '../lib/utils';
 import { RenderAssetShapes } from './SharedRender';
 import { useStore } from '../store';
+import { isOnLevel } from '../domain/levelService';
+
+// Walls have no asset: draw the band along the centre line from the origin.
+// Joins and openings are applied by the backend (GetWallOutlines) when exporting.
+const RenderWall = ({ item, isSelected }) => {
+    const length = (item.length || 0) * BASE_SCALE;
+    const thickness = (item.thickness || DEFAULT_WALL_THICKNESS) * BASE_SCALE;
+    return (
+        <rect x={0} y={-thickness / 2} width={length} height={thickness}
+              fill="#444444" stroke={isSelected ? '#3b82f6' : '#222'} strokeWidth={isSelected ? 2 : 1}
+              strokeDasharray={isSelected ? '6 3' : undefined} />
+    );
+};
 
 // RenderItem (Pure Component if possible, but we pass props)
 const RenderItem = ({ item, isSelected, onDown }) => {
@@ -23,6 +36,8 @@ const RenderItem = ({ item, isSelected, onDown }) => {
                     {isSelected && <rect x="-5" y="-25" width="100" height="35" fill="rgba(59,130,246,0.1)" stroke="#3b82f6" strokeWidth="2" strokeDasharray="4" />}
                     <text fill={item.color} fontSize={item.fontSize} fontWeight="bold" style={{ whiteSpace: 'pre', userSelect: 'none' }}>{item.text}</text>
                 </g>
+            ) : item.type === 'wall' ? (
+                <RenderWall item={item} isSelected={isSelected} />
             ) : (
                 <g>
                     <RenderAssetShapes item={item} isSelected={isSelected} />
@@ -79,8 +94,12 @@ export const LayoutCanvas = () => {
     const setInstances = useStore(state => state.setInstances);
     const selectedIds = useStore(state => state.selectedIds);
     const setSelectedIds = useStore(state => state.setSelectedIds);
+    const levels = useStore(state => state.levels);
+    const currentLevelId = useStore(state => state.currentLevelId);
 
     const assets = [...localAssets, ...globalAssets];
+    // Only the instances of the level being edited are shown and selectable
+    const onLevel = (inst) => isOnLevel(inst, assets.find(a => a.id === inst.assetId), levels, currentLevelId);
 
     const [localInstances, setLocalInstances] = useState(instances);
     const dragRef = useRef({ isDragging: false, mode: null });
@@ -176,7 +195,14 @@ export const LayoutCanvas = () => {
                 const minY = Math.min(p1.y, p2.y);
                 const maxY = Math.max(p1.y, p2.y);
 
-                const inBox = localInstances.filter(inst => {
+                const inBox = localInstances.filter(onLevel).filter(inst => {
+                    if (inst.type === 'wall') {
+                        // Centre of the wall's centre line
+                        const r = (inst.rotation || 0) * Math.PI / 180;
+                        const cx = inst.x + Math.cos(r) * (inst.length || 0) / 2;
+                        const cy = inst.y + Math.sin(r) * (inst.length || 0) / 2;
+                        return cx >= minX && cx <= maxX && cy >= minY && cy <= maxY;
+                    }
                     // Check intersection in Cartesian space
                     const asset = assets.find(a => a.id === inst.assetId);
                     const w = inst.type === 'text' ? 100 : (asset?.w || 0);
@@ -224,17 +250,18 @@ export const LayoutCanvas = () => {
     };
 
     const sortedItems = useMemo(() => {
-        return localInstances.map(inst => {
+        return localInstances.filter(onLevel).map(inst => {
             if (inst.type === 'text') return { ...inst, z: 99 };
+            if (inst.type === 'wall') return { ...inst, z: LAYERS.wall };
             const asset = assets.find(a => a.id === inst.assetId);
-            const z = (asset && LAYERS[asset.type] !== undefined) ? LAYERS[asset.type] : 2;
+            const z = (asset && LAYERS[asset.type] !== undefined) ? LAYERS[asset.type] : LAYERS.furniture;
             return asset ? { ...inst, ...asset, id: inst.id, z } : null;
         }).filter(Boolean).sort((a, b) => {
             const aSelected = selectedIds.includes(a.id) ? 1000 : 0;
             const bSelected = selectedIds.includes(b.id) ? 1000 : 0;
             return (a.z + aSelected) - (b.z + bSelected);
         });
-    }, [localInstances, assets, selectedIds]);
+    }, [localInstances, assets, selectedIds, levels, currentLevelId]);
 
     return (
         <div className="w-full h-full absolute top-0 left-0 z-20 overflow-auto canvas-scroll pt-5 pl-5" onPointerDown={e => handleDown(e, null)} onPointerMove={handleMove} onPointerUp={handleUp} ref={svgRef}>
@@ -2,6 +2,8 @@ import React from 'react';
 import { Icon, Icons } from './Icon';
 import { NumberInput } from './NumberInput';
 import { fromMM, toMM } from '../lib/utils';
+import { DEFAULT_WALL_THICKNESS, DEFAULT_WALL_HEIGHT } from '../lib/constants';
+import { instanceLevelId, isStairAsset } from '../domain/levelService';
 import { useStore } from '../store';
 
 export const LayoutProperties = () => {
@@ -12,6 +14,7 @@ export const LayoutProperties = () => {
     const setSelectedIds = useStore(state => state.setSelectedIds);
     const setMode = useStore(state => state.setMode);
     const setDesignTargetId = useStore(state => state.setDesignTargetId);
+    const levels = useStore(state => state.levels);
 
     const localAssets = useStore(state => state.localAssets);
     const globalAssets = useStore(state => state.globalAssets);
@@ -32,7 +35,7 @@ export const LayoutProperties = () => {
                             const a = assets.find(x => x.id === inst.assetId);
                             return (
                                 <div key={inst.id} onClick={() => setSelectedIds([inst.id])} className="p-2 border rounded hover:bg-gray-50 cursor-pointer flex items-center justify-between">
-                                    <span className="truncate">{a ? a.name : inst.text}</span>
+                                    <span className="truncate">{a ? a.name : inst.type === 'wall' ? '壁' : inst.text}</span>
                                     <span className="text-[10px] text-gray-300">{inst.id.slice(-4)}</span>
                                 </div>
                             )
@@ -130,7 +133,7 @@ export const LayoutProperties = () => {
                                     const a = inst ? assets.find(x => x.id === inst.assetId) : null;
                                     return (
                                         <div key={id} className="text-xs p-2 bg-gray-50 rounded flex items-center justify-between">
-                                            <span className="truncate">{a?.name || inst?.text || 'テキスト'}</span>
+                                            <span className="truncate">{a?.name || (inst?.type === 'wall' ? '壁' : inst?.text) || 'テキスト'}</span>
                                             <button onClick={() => setSelectedIds(prev => prev.filter(x => x !== id))} className="text-gray-300 hover:text-red-500">×</button>
                                         </div>
                                     );
@@ -142,12 +145,12 @@ export const LayoutProperties = () => {
                     <>
                         {/* Selected Item Info */}
                         <div className="bg-blue-50 border border-blue-100 rounded p-3 mb-4">
-                            <div className="font-bold text-sm text-blue-800 mb-1">{item.type === 'text' ? 'テキスト' : asset?.name}</div>
+                            <div className="font-bold text-sm text-blue-800 mb-1">{item.type === 'text' ? 'テキスト' : item.type === 'wall' ? '壁' : asset?.name}</div>
                             <div className="text-[10px] text-blue-400 font-mono">{item.id}</div>
                         </div>
 
                         {/* Actions */}
-                        {item.type !== 'text' && (
+                        {item.type !== 'text' && item.type !== 'wall' && (
                             <button
                                 onClick={() => {
                                     setDesignTargetId(item.assetId);
@@ -179,6 +182,47 @@ export const LayoutProperties = () => {
                             </div>
                         </div>
 
+                        {/* Level */}
+                        {levels.length > 0 && (
+                            <div className="mb-4">
+                                <div className="text-xs font-bold text-gray-400 mb-2 border-b pb-1">階</div>
+                                <div className="prop-row">
+                                    <label className="prop-label">配置する階</label>
+                                    <select value={instanceLevelId(item, levels)} onChange={e => update('levelId', e.target.value)} className="prop-input">
+                                        {levels.map(l => <option key={l.id} value={l.id}>{l.name}</option>)}
+                                    </select>
+                                </div>
+                                {isStairAsset(asset) && (
+                                    <div className="prop-row">
+                                        <label className="prop-label">上の階</label>
+                                        <select value={item.topLevelId || ''} onChange={e => update('topLevelId', e.target.value || undefined)} className="prop-input">
+                                            <option value="">1つ上の階</option>
+                                            {levels.map(l => <option key={l.id} value={l.id}>{l.name}</option>)}
+                                        </select>
+                                    </div>
+                                )}
+                            </div>
+                        )}
+
+                        {/* Wall only */}
+                        {item.type === 'wall' && (
+                            <div className="mb-4">
+                                <div className="text-xs font-bold text-gray-400 mb-2 border-b pb-1">壁の設定</div>
+                                <div className="prop-row">
+                                    <label className="prop-label">長さ (mm)</label>
+                                    <NumberInput value={toMM(item.length || 0)} onChange={e => update('length', fromMM(Number(e.target.value)))} className="prop-input" />
+                                </div>
+                                <div className="prop-row">
+                                    <label className="prop-label">厚さ (mm)</label>
+                                    <NumberInput value={toMM(item.thickness || DEFAULT_WALL_THICKNESS)} onChange={e => update('thickness', fromMM(Number(e.target.value)))} className="prop-input" />
+                                </div>
+                                <div className="prop-row">
+                                    <label className="prop-label">高さ (mm)</label>
+                                    <NumberInput value={toMM(item.height || DEFAULT_WALL_HEIGHT)} onChange={e => update('height', fromMM(Number(e.target.value)))} className="prop-input" />
+                                </div>
+                            </div>
+                        )}
+
                         {/* Content (Text only) */}
                         {item.type === 'text' && (
                             <div className="mb-4">
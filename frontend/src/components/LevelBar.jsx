@@ -0,0 +1,65 @@
+import React, { useState } from 'react';
+import { Icon, Icons } from './Icon';
+import { NumberInput } from './NumberInput';
+import { fromMM, toMM } from '../lib/utils';
+import { DEFAULT_LEVEL_HEIGHT } from '../domain/levelService';
+import { useStore } from '../store';
+
+// Level picker and editor shown over the layout canvas
+export const LevelBar = () => {
+    const levels = useStore(state => state.levels);
+    const currentLevelId = useStore(state => state.currentLevelId);
+    const setCurrentLevelId = useStore(state => state.setCurrentLevelId);
+    const addLevel = useStore(state => state.addLevel);
+    const updateLevel = useStore(state => state.updateLevel);
+    const removeLevel = useStore(state => state.removeLevel);
+    const [editing, setEditing] = useState(false);
+
+    const level = levels.find(l => l.id === currentLevelId);
+
+    const remove = () => {
+        if (!confirm(`${level.name} とこの階に配置したアイテムを削除しますか？`)) return;
+        removeLevel(level.id);
+        setEditing(false);
+    };
+
+    return (
+        <div className="absolute top-6 right-6 z-30 flex flex-col items-end gap-1">
+            <div className="bg-white p-1 rounded shadow-md border flex gap-1">
+                {levels.map(l => (
+                    <button key={l.id} onClick={() => setCurrentLevelId(l.id)} className={`px-3 py-1.5 rounded text-xs font-bold ${l.id === currentLevelId ? 'bg-blue-100 text-blue-700' : 'text-gray-500 hover:bg-gray-50'}`}>
+                        {l.name || '(無題)'}
+                    </button>
+                ))}
+                {level && (
+                    <button onClick={() => setEditing(v => !v)} title="階の設定" className={`p-1.5 rounded ${editing ? 'bg-gray-100 text-gray-700' : 'text-gray-400 hover:bg-gray-50'}`}>
+                        <Icon p={Icons.Settings} size={14} />
+                    </button>
+                )}
+                <button onClick={addLevel} title="階を追加" className="px-2 py-1.5 rounded text-xs font-bold text-gray-500 hover:bg-gray-50 flex items-center gap-1">
+                    <Icon p={Icons.Plus} size={14} /> {levels.length === 0 && '階を追加'}
+                </button>
+            </div>
+
+            {editing && level && (
+                <div className="bg-white p-3 rounded shadow-md border w-56">
+                    <div className="prop-row">
+                        <label className="prop-label">名前</label>
+                        <input type="text" value={level.name} onChange={e => updateLevel(level.id, { name: e.target.value })} className="prop-input" />
+                    </div>
+                    <div className="prop-row">
+                        <label className="prop-label">床高 (mm)</label>
+                        <NumberInput value={toMM(level.elevation || 0)} onChange={e => updateLevel(level.id, { elevation: fromMM(Number(e.target.value)) })} className="prop-input" />
+                    </div>
+                    <div className="prop-row">
+                        <label className="prop-label">階高 (mm)</label>
+                        <NumberInput value={toMM(level.height || DEFAULT_LEVEL_HEIGHT)} onChange={e => updateLevel(level.id, { height: fromMM(Number(e.target.value)) })} className="prop-input" />
+                    </div>
+                    <button onClick={remove} className="btn-action bg-white border border-red-200 text-red-500 hover:bg-red-50 mt-2">
+                        <Icon p={Icons.Trash} size={14} /> この階を削除
+                    </button>
+                </div>
+            )}
+        </div>
+    );
+};
@@ -31,6 +31,10 @@ export const RenderAssetShapes = ({ item, isSelected }) => {
                 if (s.type === 'circle') return <ellipse key={i} cx={(s.x + s.w / 2) * BASE_SCALE} cy={toSvgY(s.y + s.h / 2) * BASE_SCALE} rx={s.w * BASE_SCALE / 2} ry={s.h * BASE_SCALE / 2} {...style} />;
                 if (s.type === 'ellipse') return <path key={i} d={generateEllipsePath(s)} transform={rotateTransform} {...style} />;
                 if (s.type === 'polygon' && s.points) return <path key={i} d={generateSvgPath(s.points)} {...style} />;
+                if (s.type === 'text') {
+                    const tx = (s.x || 0) * BASE_SCALE, ty = toSvgY(s.y || 0) * BASE_SCALE;
+                    return <text key={i} x={tx} y={ty} fill={s.color || '#333'} fontSize={s.fontSize || 24} transform={rot ? `rotate(${rot} ${tx} ${ty})` : ''} style={{ whiteSpace: 'pre', userSelect: 'none' }}>{s.text}</text>;
+                }
 
                 // Rect: Cartesian (Bottom-Left) -> SVG (Top-Left)
                 // x=x, y=-(y+h)
@@ -0,0 +1,41 @@
+// Levels (ProjectData.levels) are ordered from the bottom. Instances belong to a level by levelId;
+// an empty or unknown levelId means the first level. Same rules as level.go.
+
+export const DEFAULT_LEVEL_HEIGHT = 290; // Floor-to-floor height (cm)
+
+// Create the level above the last one
+export const createLevel = (levels) => {
+    const last = levels[levels.length - 1];
+    return {
+        id: `lv-${Date.now()}-${levels.length + 1}`,
+        name: `${levels.length + 1}F`,
+        elevation: last ? last.elevation + (last.height || DEFAULT_LEVEL_HEIGHT) : 0,
+        height: DEFAULT_LEVEL_HEIGHT
+    };
+};
+
+// ID of the level an instance is placed on (null for a project without levels)
+export const instanceLevelId = (inst, levels) => {
+    if (!levels || levels.length === 0) return null;
+    return levels.some(l => l.id === inst.levelId) ? inst.levelId : levels[0].id;
+};
+
+// Stairs are detected from the asset ID and name like isStair in level.go
+export const isStairAsset = (asset) => {
+    const name = (asset?.name || '').toLowerCase();
+    return asset?.id === 'a_stair' || name.includes('階段') || name.includes('stair');
+};
+
+// Whether an instance appears on a level. Stairs also appear on every level up to topLevelId
+// (default: the level above), like stairSpan in level.go.
+export const isOnLevel = (inst, asset, levels, levelId) => {
+    if (!levels || levels.length === 0 || !levelId) return true;
+    const index = levels.findIndex(l => l.id === levelId);
+    const lo = levels.findIndex(l => l.id === instanceLevelId(inst, levels));
+    if (!isStairAsset(asset)) return index === lo;
+    let hi = lo + 1;
+    const top = levels.findIndex(l => l.id === inst.topLevelId);
+    if (top > lo) hi = top;
+    if (hi >= levels.length) hi = lo;
+    return index >= lo && index <= hi;
+};
@@ -57,6 +57,10 @@ export const loadProjectData = async (projectId, api) => {
         categoryLabels,
         localAssets: loadedAssets,
         instances,
+        // Kept as loaded and written back on save
+        levels: projectData?.levels || [],
+        currentLevelId: projectData?.levels?.[0]?.id || null,
+        costRates: projectData?.costRates || null,
         // Reset selection state
         selectedIds: [],
         designTargetId: null,
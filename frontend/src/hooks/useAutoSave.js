@@ -6,6 +6,8 @@ export const useAutoSave = () => {
     const localAssets = useStore(state => state.localAssets);
     const instances = useStore(state => state.instances);
     const projectDefaultColors = useStore(state => state.projectDefaultColors);
+    const levels = useStore(state => state.levels);
+    const costRates = useStore(state => state.costRates);
     const saveProjectData = useStore(state => state.saveProjectData);
     const autoSaveInterval = useStore(state => state.autoSaveInterval);
 
@@ -16,5 +18,5 @@ export const useAutoSave = () => {
             saveProjectData();
         }, delay);
         return () => clearTimeout(timer);
-    }, [localAssets, instances, projectDefaultColors, currentProjectId, saveProjectData, autoSaveInterval]);
+    }, [localAssets, instances, projectDefaultColors, levels, costRates, currentProjectId, saveProjectData, autoSaveInterval]);
 };
@@ -15,4 +15,37 @@ export const API = {
     importGlobalAssets: (jsonData, mergeMode) => window.go?.main?.App?.ImportGlobalAssets(jsonData, mergeMode),
     getSettings: () => window.go?.main?.App?.GetSettings() ?? Promise.resolve({ gridSize: 20, snapInterval: 10, initialZoom: 1.0, autoSaveInterval: 30000 }),
     saveSettings: (s) => window.go?.main?.App?.SaveSettings(s),
+    getDataDirInfo: () => window.go?.main?.App?.GetDataDirInfo(),
+    moveDataDir: (dir) => window.go?.main?.App?.MoveDataDir(dir),
+    listSnapshots: (id) => window.go?.main?.App?.ListSnapshots(id) ?? Promise.resolve([]),
+    getSnapshotData: (id, snapshotId) => window.go?.main?.App?.GetSnapshotData(id, snapshotId),
+    restoreSnapshot: (id, snapshotId) => window.go?.main?.App?.RestoreSnapshot(id, snapshotId),
+    restoreSnapshotAsProject: (id, snapshotId, name) => window.go?.main?.App?.RestoreSnapshotAsProject(id, snapshotId, name),
+    getProjectAreas: (id, tatamiSize) => window.go?.main?.App?.GetProjectAreas(id, tatamiSize),
+    exportProjectSVG: (id, options) => window.go?.main?.App?.ExportProjectSVG(id, options),
+    exportProjectPDF: (id, options) => window.go?.main?.App?.ExportProjectPDF(id, options),
+    exportProjectDXF: (id) => window.go?.main?.App?.ExportProjectDXF(id),
+    exportProjectPNG: (id, options) => window.go?.main?.App?.ExportProjectPNG(id, options),
+    getProjectThumbnail: (id) => window.go?.main?.App?.GetProjectThumbnail(id),
+    getBillOfMaterials: (id) => window.go?.main?.App?.GetBillOfMaterials(id),
+    exportBillOfMaterialsCSV: (id) => window.go?.main?.App?.ExportBillOfMaterialsCSV(id),
+    exportBillOfMaterialsXLSX: (id) => window.go?.main?.App?.ExportBillOfMaterialsXLSX(id),
+    getCostEstimate: (id) => window.go?.main?.App?.GetCostEstimate(id),
+    exportCostEstimateCSV: (id) => window.go?.main?.App?.ExportCostEstimateCSV(id),
+    exportCostEstimatePDF: (id) => window.go?.main?.App?.ExportCostEstimatePDF(id),
+    validateProject: (id) => window.go?.main?.App?.ValidateProject(id),
+    getJSONSchema: (name) => window.go?.main?.App?.GetJSONSchema(name),
+    exportProjectGLB: (id, options) => window.go?.main?.App?.ExportProjectGLB(id, options),
+    exportProjectOBJ: (id, options) => window.go?.main?.App?.ExportProjectOBJ(id, options),
+    parseDXF: (content, options) => window.go?.main?.App?.ParseDXF(content, options),
+    importDXFProject: (name, content, options) => window.go?.main?.App?.ImportDXFProject(name, content, options),
+    parseMadori: (notation) => window.go?.main?.App?.ParseMadori(notation),
+    getProjectMadori: (id) => window.go?.main?.App?.GetProjectMadori(id),
+    generateFloorPlans: (program) => window.go?.main?.App?.GenerateFloorPlans(program),
+    checkCollisions: (id) => window.go?.main?.App?.CheckCollisions(id),
+    checkClearances: (id, rules) => window.go?.main?.App?.CheckClearances(id, rules),
+    checkDaylight: (id, opts) => window.go?.main?.App?.CheckDaylight(id, opts),
+    deriveWalls: (id, opts) => window.go?.main?.App?.DeriveWalls(id, opts),
+    getWallOutlines: (id) => window.go?.main?.App?.GetWallOutlines(id),
+    proposeFurnitureLayouts: (id, request) => window.go?.main?.App?.ProposeFurnitureLayouts(id, request),
 };
@@ -3,4 +3,7 @@
 // LayoutCanvas, DesignCanvas, Ruler, assetService, utils 等で直接参照されている。
 export const BASE_SCALE = 2.0;
 export const SNAP_UNIT = 5;
-export const LAYERS = { room: 0, fixture: 1, furniture: 2, text: 3 };
+export const LAYERS = { room: 0, wall: 1, fixture: 2, furniture: 3, text: 4 };
+// Walls without thickness / height use these (cm, same as defaultWallThickness / defaultWallHeight in wall.go)
+export const DEFAULT_WALL_THICKNESS = 12;
+export const DEFAULT_WALL_HEIGHT = 240;
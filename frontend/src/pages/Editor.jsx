@@ -14,6 +14,7 @@ import { useAutoSave } from '../hooks/useAutoSave';
 import { useKeyboardControls } from '../hooks/useKeyboardControls';
 import { Header } from '../components/Header';
 import { ResizeHandle } from '../components/ResizeHandle';
+import { LevelBar } from '../components/LevelBar';
 
 const Editor = () => {
     const { id } = useParams();
@@ -178,6 +179,8 @@ const Editor = () => {
                         <button onClick={() => setViewState(p => ({ ...p, scale: p.scale / 1.2 }))} className="p-1.5 rounded hover:bg-gray-100 text-gray-600"><Icon p={Icons.ZoomOut} /></button>
                     </div>
 
+                    {mode === 'layout' && <LevelBar />}
+
                     <Ruler viewState={viewState} />
 
                     {mode === 'layout' ? (
@@ -70,6 +70,23 @@ const Home = () => {
         }
     };
 
+    // 間取り (例: 3LDK, 2LDK+S LDK15畳) から部屋を自動で割り付けたプロジェクトを作る
+    const handleGenerate = async () => {
+        const preset = await showInput("間取りを入力してください (例: 3LDK, 2LDK+S LDK15畳)", "3LDK");
+        if (!preset) return;
+        try {
+            const seed = Date.now();
+            const [layout] = await API.generateFloorPlans({ preset, rooms: [], adjacency: [], siteWidth: 0, siteHeight: 0, grid: 0, count: 1, seed });
+            const newProj = await API.createProject(`${preset} 自動生成`);
+            await API.saveProjectData(newProj.id, layout.data);
+            setProjects(prev => [...prev, newProj]);
+            navigate(`/project/${newProj.id}`);
+        } catch (err) {
+            console.error(err);
+            alert("間取りの生成に失敗しました: " + err);
+        }
+    };
+
     const handleDelete = async (e, id) => {
         e.stopPropagation();
         const ok = await showConfirm("プロジェクトを削除しますか？");
@@ -90,6 +107,12 @@ const Home = () => {
         const file = e.target.files[0];
         if (!file) return;
 
+        if (/\.dxf$/i.test(file.name)) {
+            handleImportDXF(file);
+            e.target.value = '';
+            return;
+        }
+
         const reader = new FileReader();
         reader.onload = async (evt) => {
             try {
@@ -109,6 +132,30 @@ const Home = () => {
         e.target.value = '';
     };
 
+    // DXF のブロックをアセット、INSERT を配置として新しいプロジェクトを作る
+    const handleImportDXF = (file) => {
+        const reader = new FileReader();
+        reader.onload = async (evt) => {
+            try {
+                // []byte の引数には base64 文字列を渡す
+                const bytes = new Uint8Array(evt.target.result);
+                let binary = '';
+                for (let i = 0; i < bytes.length; i += 0x8000) {
+                    binary += String.fromCharCode(...bytes.subarray(i, i + 0x8000));
+                }
+                const name = file.name.replace(/\.dxf$/i, "");
+                await API.importDXFProject(name, btoa(binary), { units: '', assetType: 'furniture' });
+                const newProjects = await API.getProjects();
+                setProjects(newProjects);
+                alert("DXF をインポートしました");
+            } catch (err) {
+                console.error(err);
+                alert("DXF のインポートに失敗しました: " + err);
+            }
+        };
+        reader.readAsArrayBuffer(file);
+    };
+
     const handleExport = async (e, id) => {
         e.stopPropagation();
         try {
@@ -126,6 +173,62 @@ const Home = () => {
         }
     };
 
+    const handleExportSVG = async (e, id, name) => {
+        e.stopPropagation();
+        try {
+            const svg = await API.exportProjectSVG(id, { scale: 100, showGrid: true, gridSpacing: 91, titleBlock: true, roomLabels: true, tatamiSize: 'edoma' });
+            const blob = new Blob([svg], { type: 'image/svg+xml' });
+            const url = URL.createObjectURL(blob);
+            const a = document.createElement('a');
+            a.href = url;
+            a.download = `${name || id}.svg`;
+            a.click();
+            URL.revokeObjectURL(url);
+        } catch (err) {
+            console.error(err);
+            alert("図面のエクスポートに失敗しました");
+        }
+    };
+
+    const handleExportDXF = async (e, id, name) => {
+        e.stopPropagation();
+        try {
+            const dxf = await API.exportProjectDXF(id);
+            const blob = new Blob([dxf], { type: 'application/dxf' });
+            const url = URL.createObjectURL(blob);
+            const a = document.createElement('a');
+            a.href = url;
+            a.download = `${name || id}.dxf`;
+            a.click();
+            URL.revokeObjectURL(url);
+        } catch (err) {
+            console.error(err);
+            alert("DXF のエクスポートに失敗しました");
+        }
+    };
+
+    const handleExportPDF = async (e, id, name) => {
+        e.stopPropagation();
+        try {
+            // []byte は base64 文字列として返される
+            const b64 = await API.exportProjectPDF(id, {
+                plan: { scale: 0, showGrid: false, gridSpacing: 91, titleBlock: true, roomLabels: true, tatamiSize: 'edoma' },
+                paperSize: 'A3', orientation: 'landscape', areaTable: true,
+            });
+            const bytes = Uint8Array.from(atob(b64), c => c.charCodeAt(0));
+            const blob = new Blob([bytes], { type: 'application/pdf' });
+            const url = URL.createObjectURL(blob);
+            const a = document.createElement('a');
+            a.href = url;
+            a.download = `${name || id}.pdf`;
+            a.click();
+            URL.revokeObjectURL(url);
+        } catch (err) {
+            console.error(err);
+            alert("図面のエクスポートに失敗しました: " + err);
+        }
+    };
+
     return (
         <div className="min-h-screen bg-gray-50 flex flex-col">
             {modal?.type === 'input' && <InputModal title={modal.title} defaultValue={modal.defaultValue} onConfirm={handleModalConfirm} onCancel={handleModalCancel} />}
@@ -138,13 +241,16 @@ const Home = () => {
                     <div className="flex items-center justify-between mb-8">
                         <h2 className="text-2xl font-bold text-gray-800">プロジェクト一覧</h2>
                         <div className="flex gap-4">
-                            <input type="file" ref={fileInputRef} onChange={handleImport} className="hidden" accept=".json" />
+                            <input type="file" ref={fileInputRef} onChange={handleImport} className="hidden" accept=".json,.dxf" />
                             <button onClick={() => fileInputRef.current.click()} className="flex items-center gap-2 px-4 py-2 bg-white border rounded shadow-sm text-gray-600 hover:bg-gray-50 font-bold">
                                 <Icon p={Icons.Upload} size={18} /> インポート
                             </button>
                             <button onClick={() => navigate('/library')} className="flex items-center gap-2 px-4 py-2 bg-white border rounded shadow-sm text-blue-600 hover:bg-blue-50 font-bold">
                                 <Icon p={Icons.Globe} size={18} /> 共通ライブラリ
                             </button>
+                            <button onClick={handleGenerate} className="flex items-center gap-2 px-4 py-2 bg-white border rounded shadow-sm text-blue-600 hover:bg-blue-50 font-bold">
+                                <Icon p={Icons.Square} size={18} /> 間取り生成
+                            </button>
                             <button onClick={handleCreate} className="flex items-center gap-2 px-4 py-2 bg-blue-600 text-white rounded shadow hover:bg-blue-700 font-bold">
                                 <Icon p={Icons.Plus} size={18} /> 新規作成
                             </button>
@@ -161,7 +267,7 @@ const Home = () => {
                                 <div className="p-4 border-t flex items-center justify-between bg-white">
                                     <div className="flex-1 min-w-0">
                                         <h3 className="font-bold text-gray-800 truncate">{p.name}</h3>
-                                        <p className="text-xs text-gray-400 truncate">ID: {p.id}</p>
+                                        <p className="text-xs text-gray-400 truncate">{p.madori && <span className="mr-2 font-bold text-blue-500">{p.madori}</span>}ID: {p.id}</p>
                                     </div>
                                 </div>
 
@@ -173,6 +279,15 @@ const Home = () => {
                                      <button onClick={(e) => handleExport(e, p.id)} className="p-1.5 bg-white rounded-full shadow border text-gray-400 hover:text-blue-600" title="エクスポート">
                                         <Icon p={Icons.Download} size={14} />
                                     </button>
+                                    <button onClick={(e) => handleExportSVG(e, p.id, p.name)} className="p-1.5 bg-white rounded-full shadow border text-gray-400 hover:text-blue-600" title="図面 (SVG) を書き出し">
+                                        <Icon p={Icons.Poly} size={14} />
+                                    </button>
+                                    <button onClick={(e) => handleExportPDF(e, p.id, p.name)} className="p-1.5 bg-white rounded-full shadow border text-gray-400 hover:text-blue-600" title="図面 (PDF) を書き出し">
+                                        <Icon p={Icons.File} size={14} />
+                                    </button>
+                                    <button onClick={(e) => handleExportDXF(e, p.id, p.name)} className="p-1.5 bg-white rounded-full shadow border text-gray-400 hover:text-blue-600" title="CAD (DXF) に書き出し">
+                                        <Icon p={Icons.Box} size={14} />
+                                    </button>
                                     <button onClick={(e) => handleDelete(e, p.id)} className="p-1.5 bg-white rounded-full shadow border text-gray-400 hover:text-red-500" title="削除">
                                         <Icon p={Icons.Trash} size={14} />
                                     </button>
@@ -6,6 +6,16 @@ import { Icon, Icons } from '../components/Icon';
 import { Header } from '../components/Header';
 import { ColorPicker } from '../components/ColorPicker';
 
+// データフォルダの決め方 (DataDirInfo.source) の説明
+const DATA_DIR_SOURCES = {
+    flag: '起動時の -data オプションで指定されています',
+    env: '環境変数 ROOMGENERATOR_DATA_DIR で指定されています',
+    config: 'ユーザー設定フォルダの config.json で指定されています',
+    portable: 'ポータブルモード (実行ファイルの隣の portable ファイル) のため、実行ファイルの隣のフォルダを使っています',
+    legacy: '以前の版で作成したカレントディレクトリのフォルダを使っています',
+    default: 'ユーザー設定フォルダを使っています',
+};
+
 const Settings = () => {
     const navigate = useNavigate();
 
@@ -36,6 +46,10 @@ const Settings = () => {
     const [newCatKey, setNewCatKey] = useState('');
     const [newCatLabel, setNewCatLabel] = useState('');
     const [newCatColor, setNewCatColor] = useState('#cccccc');
+    const [storageBackend, setStorageBackend] = useState('json');
+    const [validationMode, setValidationMode] = useState('warn');
+    const [dataDir, setDataDir] = useState(null);
+    const [newDataDir, setNewDataDir] = useState('');
 
     // Load settings on mount
     useEffect(() => {
@@ -45,16 +59,35 @@ const Settings = () => {
                 setSnapInterval(s.snapInterval);
                 setInitialZoom(s.initialZoom);
                 setAutoSaveInterval(s.autoSaveInterval);
+                setStorageBackend(s.storageBackend || 'json');
+                setValidationMode(s.validationMode || 'warn');
             }
         });
+        API.getDataDirInfo()?.then(setDataDir);
     }, []);
 
+    const handleMoveDataDir = async () => {
+        if (!newDataDir) return alert('移動先のフォルダを入力してください');
+        if (!confirm(`データフォルダを「${newDataDir}」へ移動しますか？\nすべてのプロジェクトとアセットを移動し、次回以降もこの場所を使います。`)) return;
+        try {
+            const info = await API.moveDataDir(newDataDir);
+            setDataDir(info);
+            setNewDataDir('');
+            alert("データフォルダを移動しました");
+            window.location.reload();
+        } catch (e) {
+            alert("移動に失敗しました: " + e);
+        }
+    };
+
     const handleSave = async () => {
         const settings = {
             gridSize,
             snapInterval,
             initialZoom,
-            autoSaveInterval
+            autoSaveInterval,
+            storageBackend,
+            validationMode
         };
         try {
             await API.saveSettings(settings);
@@ -190,6 +223,50 @@ const Settings = () => {
                             />
                             <p className="text-xs text-gray-400 mt-1">※ 最小値は5000ミリ秒（5秒）です</p>
                         </div>
+                        <div className="mt-6">
+                            <label className="block text-sm font-bold text-gray-600 mb-2">保存形式</label>
+                            <select
+                                value={storageBackend}
+                                onChange={(e) => setStorageBackend(e.target.value)}
+                                className="border rounded px-3 py-2 w-full"
+                            >
+                                <option value="json">JSONファイル</option>
+                                <option value="sqlite">SQLiteデータベース</option>
+                            </select>
+                            <p className="text-xs text-gray-400 mt-1">※ 変更すると現在のデータを新しい保存形式へコピーします。プロジェクト数が多い場合は SQLite を推奨します</p>
+                        </div>
+                        <div className="mt-6">
+                            <label className="block text-sm font-bold text-gray-600 mb-2">保存時のデータ検証</label>
+                            <select
+                                value={validationMode}
+                                onChange={(e) => setValidationMode(e.target.value)}
+                                className="border rounded px-3 py-2 w-full"
+                            >
+                                <option value="warn">ログに記録して保存する</option>
+                                <option value="block">エラーがあれば保存しない</option>
+                            </select>
+                            <p className="text-xs text-gray-400 mt-1">※ 存在しないアセットへの参照、3点未満の多角形、重複した ID などを検出します。インポート時にも適用されます</p>
+                        </div>
+                        {dataDir && (
+                            <div className="mt-6">
+                                <label className="block text-sm font-bold text-gray-600 mb-2">データフォルダ</label>
+                                <div className="font-mono text-sm bg-gray-50 border rounded px-3 py-2 break-all">{dataDir.path}</div>
+                                <p className="text-xs text-gray-400 mt-1">{DATA_DIR_SOURCES[dataDir.source] || dataDir.source}</p>
+                                {dataDir.movable && (
+                                    <div className="flex gap-2 mt-2">
+                                        <input
+                                            value={newDataDir}
+                                            onChange={(e) => setNewDataDir(e.target.value)}
+                                            className="border rounded px-3 py-2 flex-1 font-mono text-sm"
+                                            placeholder="移動先のフォルダ (空のフォルダ)"
+                                        />
+                                        <button onClick={handleMoveDataDir} className="bg-blue-500 text-white px-4 py-2 rounded text-sm hover:bg-blue-600 font-bold">
+                                            移動
+                                        </button>
+                                    </div>
+                                )}
+                            </div>
+                        )}
                     </div>
 
                     {/* Footer Actions */}
@@ -1,5 +1,8 @@
 import { forkAsset, createInstance, createTextInstance } from '../domain/assetService';
 
+// New instances are placed on the level shown in the editor
+const currentLevel = (state) => (state.levels?.length > 0 && state.currentLevelId ? { levelId: state.currentLevelId } : {});
+
 export const createInstanceSlice = (set, get) => ({
     instances: [],
 
@@ -19,7 +22,7 @@ export const createInstanceSlice = (set, get) => ({
             asset = newLocalAsset;
         }
 
-        const newInst = createInstance(asset, state.viewState);
+        const newInst = { ...createInstance(asset, state.viewState), ...currentLevel(state) };
 
         set({
             localAssets: newLocalAssets,
@@ -30,7 +33,7 @@ export const createInstanceSlice = (set, get) => ({
 
     addText: () => {
         const state = get();
-        const newInst = createTextInstance(state.viewState);
+        const newInst = { ...createTextInstance(state.viewState), ...currentLevel(state) };
         set({
             instances: [...state.instances, newInst],
             selectedIds: [newInst.id]
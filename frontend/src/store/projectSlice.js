@@ -1,10 +1,14 @@
 import { API } from '../lib/api';
 import { syncAssetColors } from '../domain/assetService';
 import { loadProjectData, DEFAULT_COLORS } from '../domain/projectService';
+import { createLevel, instanceLevelId } from '../domain/levelService';
 
 export const createProjectSlice = (set, get) => ({
     projects: [],
     currentProjectId: null,
+    levels: [],
+    currentLevelId: null,
+    costRates: null,
     viewState: { x: 50, y: 600, scale: 1 },
 
     setProjects: (updater) => set((state) => ({ projects: typeof updater === 'function' ? updater(state.projects) : updater })),
@@ -32,10 +36,37 @@ export const createProjectSlice = (set, get) => ({
         await API.saveProjectData(state.currentProjectId, {
             assets: state.localAssets,
             instances: state.instances,
-            defaultColors: state.projectDefaultColors
+            defaultColors: state.projectDefaultColors,
+            levels: state.levels || [],
+            costRates: state.costRates || undefined
         });
     },
 
+    setCostRates: (costRates) => set({ costRates }),
+
+    setCurrentLevelId: (id) => set({ currentLevelId: id, selectedIds: [] }),
+
+    addLevel: () => {
+        const state = get();
+        let levels = state.levels || [];
+        // A project without levels is single-storey: its instances stay on the new first level
+        if (levels.length === 0) levels = [createLevel([])];
+        const level = createLevel(levels);
+        set({ levels: [...levels, level], currentLevelId: level.id, selectedIds: [] });
+    },
+
+    updateLevel: (id, patch) => set((state) => ({
+        levels: state.levels.map(l => l.id === id ? { ...l, ...patch } : l)
+    })),
+
+    // Remove a level together with the instances placed on it
+    removeLevel: (id) => {
+        const state = get();
+        const levels = state.levels.filter(l => l.id !== id);
+        const instances = state.instances.filter(inst => instanceLevelId(inst, state.levels) !== id);
+        set({ levels, instances, currentLevelId: levels[0]?.id || null, selectedIds: [] });
+    },
+
     updateProjectDefaultColor: (categoryKey, newColor) => {
         const state = get();
         const projectDefaultColors = { ...(state.projectDefaultColors || {}), [categoryKey]: newColor };
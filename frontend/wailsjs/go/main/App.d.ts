@@ -2,28 +2,92 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function CheckClearances(arg1:string,arg2:main.ClearanceRules):Promise<main.ClearanceReport>;
+
+export function CheckCollisions(arg1:string):Promise<main.CollisionReport>;
+
+export function CheckDaylight(arg1:string,arg2:main.DaylightOptions):Promise<main.DaylightReport>;
+
 export function CreateProject(arg1:string):Promise<main.Project>;
 
 export function DeleteProject(arg1:string):Promise<void>;
 
+export function DeriveWalls(arg1:string,arg2:main.WallOptions):Promise<main.ProjectData>;
+
+export function ExportBillOfMaterialsCSV(arg1:string):Promise<string>;
+
+export function ExportBillOfMaterialsXLSX(arg1:string):Promise<Array<number>>;
+
+export function ExportCostEstimateCSV(arg1:string):Promise<string>;
+
+export function ExportCostEstimatePDF(arg1:string):Promise<Array<number>>;
+
 export function ExportGlobalAssets():Promise<string>;
 
 export function ExportProject(arg1:string):Promise<string>;
 
+export function ExportProjectDXF(arg1:string):Promise<string>;
+
+export function ExportProjectGLB(arg1:string,arg2:main.ModelExportOptions):Promise<Array<number>>;
+
+export function ExportProjectOBJ(arg1:string,arg2:main.ModelExportOptions):Promise<main.OBJExport>;
+
+export function ExportProjectPDF(arg1:string,arg2:main.PDFExportOptions):Promise<Array<number>>;
+
+export function ExportProjectPNG(arg1:string,arg2:main.PNGExportOptions):Promise<Array<number>>;
+
+export function ExportProjectSVG(arg1:string,arg2:main.PlanExportOptions):Promise<string>;
+
+export function GenerateFloorPlans(arg1:main.FloorPlanProgram):Promise<Array<main.GeneratedLayout>>;
+
 export function GetAssets():Promise<any>;
 
+export function GetBillOfMaterials(arg1:string):Promise<main.BillOfMaterials>;
+
+export function GetCostEstimate(arg1:string):Promise<main.CostEstimate>;
+
+export function GetDataDirInfo():Promise<main.DataDirInfo>;
+
+export function GetJSONSchema(arg1:string):Promise<string>;
+
 export function GetPalette():Promise<any>;
 
+export function GetProjectAreas(arg1:string,arg2:string):Promise<main.ProjectAreas>;
+
 export function GetProjectData(arg1:string):Promise<main.ProjectData>;
 
+export function GetProjectMadori(arg1:string):Promise<string>;
+
+export function GetProjectThumbnail(arg1:string):Promise<Array<number>>;
+
 export function GetProjects():Promise<Array<main.Project>>;
 
 export function GetSettings():Promise<main.AppSettings>;
 
+export function GetSnapshotData(arg1:string,arg2:string):Promise<main.ProjectData>;
+
+export function GetWallOutlines(arg1:string):Promise<Array<main.WallOutline>>;
+
+export function ImportDXFProject(arg1:string,arg2:Array<number>,arg3:main.DXFImportOptions):Promise<main.Project>;
+
 export function ImportGlobalAssets(arg1:string,arg2:boolean):Promise<void>;
 
 export function ImportProject(arg1:string,arg2:string):Promise<main.Project>;
 
+export function ListSnapshots(arg1:string):Promise<Array<main.SnapshotInfo>>;
+
+export function MoveDataDir(arg1:string):Promise<main.DataDirInfo>;
+
+export function ParseDXF(arg1:Array<number>,arg2:main.DXFImportOptions):Promise<main.DXFImportResult>;
+
+export function ParseMadori(arg1:string):Promise<main.MadoriProgram>;
+
+export function ProposeFurnitureLayouts(arg1:string,arg2:main.FurniturePlacementRequest):Promise<Array<main.FurnitureLayout>>;
+
+export function RestoreSnapshot(arg1:string,arg2:string):Promise<void>;
+
+export function RestoreSnapshotAsProject(arg1:string,arg2:string,arg3:string):Promise<main.Project>;
+
 export function SaveAssets(arg1:any):Promise<void>;
 
 export function SavePalette(arg1:any):Promise<void>;
@@ -33,3 +97,5 @@ export function SaveProjectData(arg1:string,arg2:any):Promise<void>;
 export function SaveSettings(arg1:main.AppSettings):Promise<void>;
 
 export function UpdateProjectName(arg1:string,arg2:string):Promise<void>;
+
+export function ValidateProject(arg1:string):Promise<main.ValidationReport>;
@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CheckClearances(arg1, arg2) {
+  return window['go']['main']['App']['CheckClearances'](arg1, arg2);
+}
+
+export function CheckCollisions(arg1) {
+  return window['go']['main']['App']['CheckCollisions'](arg1);
+}
+
+export function CheckDaylight(arg1, arg2) {
+  return window['go']['main']['App']['CheckDaylight'](arg1, arg2);
+}
+
 export function CreateProject(arg1) {
   return window['go']['main']['App']['CreateProject'](arg1);
 }
@@ -10,6 +22,26 @@ export function DeleteProject(arg1) {
   return window['go']['main']['App']['DeleteProject'](arg1);
 }
 
+export function DeriveWalls(arg1, arg2) {
+  return window['go']['main']['App']['DeriveWalls'](arg1, arg2);
+}
+
+export function ExportBillOfMaterialsCSV(arg1) {
+  return window['go']['main']['App']['ExportBillOfMaterialsCSV'](arg1);
+}
+
+export function ExportBillOfMaterialsXLSX(arg1) {
+  return window['go']['main']['App']['ExportBillOfMaterialsXLSX'](arg1);
+}
+
+export function ExportCostEstimateCSV(arg1) {
+  return window['go']['main']['App']['ExportCostEstimateCSV'](arg1);
+}
+
+export function ExportCostEstimatePDF(arg1) {
+  return window['go']['main']['App']['ExportCostEstimatePDF'](arg1);
+}
+
 export function ExportGlobalAssets() {
   return window['go']['main']['App']['ExportGlobalAssets']();
 }
@@ -18,18 +50,74 @@ export function ExportProject(arg1) {
   return window['go']['main']['App']['ExportProject'](arg1);
 }
 
+export function ExportProjectDXF(arg1) {
+  return window['go']['main']['App']['ExportProjectDXF'](arg1);
+}
+
+export function ExportProjectGLB(arg1, arg2) {
+  return window['go']['main']['App']['ExportProjectGLB'](arg1, arg2);
+}
+
+export function ExportProjectOBJ(arg1, arg2) {
+  return window['go']['main']['App']['ExportProjectOBJ'](arg1, arg2);
+}
+
+export function ExportProjectPDF(arg1, arg2) {
+  return window['go']['main']['App']['ExportProjectPDF'](arg1, arg2);
+}
+
+export function ExportProjectPNG(arg1, arg2) {
+  return window['go']['main']['App']['ExportProjectPNG'](arg1, arg2);
+}
+
+export function ExportProjectSVG(arg1, arg2) {
+  return window['go']['main']['App']['ExportProjectSVG'](arg1, arg2);
+}
+
+export function GenerateFloorPlans(arg1) {
+  return window['go']['main']['App']['GenerateFloorPlans'](arg1);
+}
+
 export function GetAssets() {
   return window['go']['main']['App']['GetAssets']();
 }
 
+export function GetBillOfMaterials(arg1) {
+  return window['go']['main']['App']['GetBillOfMaterials'](arg1);
+}
+
+export function GetCostEstimate(arg1) {
+  return window['go']['main']['App']['GetCostEstimate'](arg1);
+}
+
+export function GetDataDirInfo() {
+  return window['go']['main']['App']['GetDataDirInfo']();
+}
+
+export function GetJSONSchema(arg1) {
+  return window['go']['main']['App']['GetJSONSchema'](arg1);
+}
+
 export function GetPalette() {
   return window['go']['main']['App']['GetPalette']();
 }
 
+export function GetProjectAreas(arg1, arg2) {
+  return window['go']['main']['App']['GetProjectAreas'](arg1, arg2);
+}
+
 export function GetProjectData(arg1) {
   return window['go']['main']['App']['GetProjectData'](arg1);
 }
 
+export function GetProjectMadori(arg1) {
+  return window['go']['main']['App']['GetProjectMadori'](arg1);
+}
+
+export function GetProjectThumbnail(arg1) {
+  return window['go']['main']['App']['GetProjectThumbnail'](arg1);
+}
+
 export function GetProjects() {
   return window['go']['main']['App']['GetProjects']();
 }
@@ -38,6 +126,18 @@ export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetSnapshotData(arg1, arg2) {
+  return window['go']['main']['App']['GetSnapshotData'](arg1, arg2);
+}
+
+export function GetWallOutlines(arg1) {
+  return window['go']['main']['App']['GetWallOutlines'](arg1);
+}
+
+export function ImportDXFProject(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportDXFProject'](arg1, arg2, arg3);
+}
+
 export function ImportGlobalAssets(arg1, arg2) {
   return window['go']['main']['App']['ImportGlobalAssets'](arg1, arg2);
 }
@@ -46,6 +146,34 @@ export function ImportProject(arg1, arg2) {
   return window['go']['main']['App']['ImportProject'](arg1, arg2);
 }
 
+export function ListSnapshots(arg1) {
+  return window['go']['main']['App']['ListSnapshots'](arg1);
+}
+
+export function MoveDataDir(arg1) {
+  return window['go']['main']['App']['MoveDataDir'](arg1);
+}
+
+export function ParseDXF(arg1, arg2) {
+  return window['go']['main']['App']['ParseDXF'](arg1, arg2);
+}
+
+export function ParseMadori(arg1) {
+  return window['go']['main']['App']['ParseMadori'](arg1);
+}
+
+export function ProposeFurnitureLayouts(arg1, arg2) {
+  return window['go']['main']['App']['ProposeFurnitureLayouts'](arg1, arg2);
+}
+
+export function RestoreSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1, arg2);
+}
+
+export function RestoreSnapshotAsProject(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RestoreSnapshotAsProject'](arg1, arg2, arg3);
+}
+
 export function SaveAssets(arg1) {
   return window['go']['main']['App']['SaveAssets'](arg1);
 }
@@ -65,3 +193,7 @@ export function SaveSettings(arg1) {
 export function UpdateProjectName(arg1, arg2) {
   return window['go']['main']['App']['UpdateProjectName'](arg1, arg2);
 }
+
+export function ValidateProject(arg1) {
+  return window['go']['main']['App']['ValidateProject'](arg1);
+}
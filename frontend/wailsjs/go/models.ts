@@ -5,6 +5,9 @@ export namespace main {
 	    snapInterval: number;
 	    initialZoom: number;
 	    autoSaveInterval: number;
+	    storageBackend?: string;
+	    snapshotRetention?: SnapshotRetention;
+	    validationMode?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppSettings(source);
@@ -16,7 +19,28 @@ export namespace main {
 	        this.snapInterval = source["snapInterval"];
 	        this.initialZoom = source["initialZoom"];
 	        this.autoSaveInterval = source["autoSaveInterval"];
+	        this.storageBackend = source["storageBackend"];
+	        this.snapshotRetention = this.convertValues(source["snapshotRetention"], SnapshotRetention);
+	        this.validationMode = source["validationMode"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class Vec2 {
 	    x: number;
@@ -148,6 +172,12 @@ export namespace main {
 	    snap?: boolean;
 	    boundX?: number;
 	    boundY?: number;
+	    openingHeight?: number;
+	    height?: number;
+	    manufacturer?: string;
+	    sku?: string;
+	    unitPrice?: number;
+	    laborPrice?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Asset(source);
@@ -166,6 +196,12 @@ export namespace main {
 	        this.snap = source["snap"];
 	        this.boundX = source["boundX"];
 	        this.boundY = source["boundY"];
+	        this.openingHeight = source["openingHeight"];
+	        this.height = source["height"];
+	        this.manufacturer = source["manufacturer"];
+	        this.sku = source["sku"];
+	        this.unitPrice = source["unitPrice"];
+	        this.laborPrice = source["laborPrice"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -198,6 +234,14 @@ export namespace main {
 	    text?: string;
 	    fontSize?: number;
 	    color?: string;
+	    length?: number;
+	    thickness?: number;
+	    height?: number;
+	    hostId?: string;
+	    hostOffset?: number;
+	    hostFlip?: boolean;
+	    levelId?: string;
+	    topLevelId?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Instance(source);
@@ -215,6 +259,14 @@ export namespace main {
 	        this.text = source["text"];
 	        this.fontSize = source["fontSize"];
 	        this.color = source["color"];
+	        this.length = source["length"];
+	        this.thickness = source["thickness"];
+	        this.height = source["height"];
+	        this.hostId = source["hostId"];
+	        this.hostOffset = source["hostOffset"];
+	        this.hostFlip = source["hostFlip"];
+	        this.levelId = source["levelId"];
+	        this.topLevelId = source["topLevelId"];
 	    }
 	}
 	
@@ -222,6 +274,7 @@ export namespace main {
 	    id: string;
 	    name: string;
 	    updatedAt: string;
+	    madori?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Project(source);
@@ -232,12 +285,15 @@ export namespace main {
 	        this.id = source["id"];
 	        this.name = source["name"];
 	        this.updatedAt = source["updatedAt"];
+	        this.madori = source["madori"];
 	    }
 	}
 	export class ProjectData {
 	    assets: Asset[];
 	    instances: Instance[];
 	    defaultColors?: Record<string, string>;
+	    levels?: Level[];
+	    costRates?: CostRates;
 	
 	    static createFrom(source: any = {}) {
 	        return new ProjectData(source);
@@ -248,6 +304,348 @@ export namespace main {
 	        this.assets = this.convertValues(source["assets"], Asset);
 	        this.instances = this.convertValues(source["instances"], Instance);
 	        this.defaultColors = source["defaultColors"];
+	        this.levels = this.convertValues(source["levels"], Level);
+	        this.costRates = this.convertValues(source["costRates"], CostRates);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SnapshotInfo {
+	    id: string;
+	    createdAt: string;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.createdAt = source["createdAt"];
+	        this.size = source["size"];
+	    }
+	}
+	export class SnapshotRetention {
+	    keepAllMinutes: number;
+	    hourlyHours: number;
+	    dailyDays: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotRetention(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.keepAllMinutes = source["keepAllMinutes"];
+	        this.hourlyHours = source["hourlyHours"];
+	        this.dailyDays = source["dailyDays"];
+	    }
+	}
+	export class FloorArea {
+	    m2: number;
+	    jo: number;
+	    tsubo: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FloorArea(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.m2 = source["m2"];
+	        this.jo = source["jo"];
+	        this.tsubo = source["tsubo"];
+	    }
+	}
+	export class RoomArea {
+	    instanceId: string;
+	    levelId?: string;
+	    assetId: string;
+	    name: string;
+	    area: FloorArea;
+	    perimeterM: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoomArea(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.levelId = source["levelId"];
+	        this.assetId = source["assetId"];
+	        this.name = source["name"];
+	        this.area = this.convertValues(source["area"], FloorArea);
+	        this.perimeterM = source["perimeterM"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProjectAreas {
+	    tatamiSize: string;
+	    rooms: RoomArea[];
+	    levels?: LevelArea[];
+	    total: FloorArea;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectAreas(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tatamiSize = source["tatamiSize"];
+	        this.rooms = this.convertValues(source["rooms"], RoomArea);
+	        this.levels = this.convertValues(source["levels"], LevelArea);
+	        this.total = this.convertValues(source["total"], FloorArea);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PlanExportOptions {
+	    scale: number;
+	    showGrid: boolean;
+	    gridSpacing: number;
+	    titleBlock: boolean;
+	    roomLabels: boolean;
+	    tatamiSize: string;
+	    title?: string;
+	    level?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlanExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scale = source["scale"];
+	        this.showGrid = source["showGrid"];
+	        this.gridSpacing = source["gridSpacing"];
+	        this.titleBlock = source["titleBlock"];
+	        this.roomLabels = source["roomLabels"];
+	        this.tatamiSize = source["tatamiSize"];
+	        this.title = source["title"];
+	        this.level = source["level"];
+	    }
+	}
+	export class PDFExportOptions {
+	    plan: PlanExportOptions;
+	    paperSize: string;
+	    orientation: string;
+	    areaTable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PDFExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plan = this.convertValues(source["plan"], PlanExportOptions);
+	        this.paperSize = source["paperSize"];
+	        this.orientation = source["orientation"];
+	        this.areaTable = source["areaTable"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DXFImportOptions {
+	    units: string;
+	    assetType: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DXFImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.units = source["units"];
+	        this.assetType = source["assetType"];
+	    }
+	}
+	export class DXFImportResult {
+	    assets: Asset[];
+	    instances: Instance[];
+	    units: string;
+	    warnings: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DXFImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.assets = this.convertValues(source["assets"], Asset);
+	        this.instances = this.convertValues(source["instances"], Instance);
+	        this.units = source["units"];
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RoomRequirement {
+	    id: string;
+	    kind: string;
+	    name: string;
+	    areaM2: number;
+	    minWidth: number;
+	    assetId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoomRequirement(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.areaM2 = source["areaM2"];
+	        this.minWidth = source["minWidth"];
+	        this.assetId = source["assetId"];
+	    }
+	}
+	export class MadoriProgram {
+	    notation: string;
+	    bedrooms: number;
+	    main: string;
+	    extras: string[];
+	    rooms: RoomRequirement[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MadoriProgram(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.notation = source["notation"];
+	        this.bedrooms = source["bedrooms"];
+	        this.main = source["main"];
+	        this.extras = source["extras"];
+	        this.rooms = this.convertValues(source["rooms"], RoomRequirement);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FloorPlanProgram {
+	    preset: string;
+	    rooms: RoomRequirement[];
+	    adjacency: string[][];
+	    siteWidth: number;
+	    siteHeight: number;
+	    grid: number;
+	    count: number;
+	    seed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FloorPlanProgram(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preset = source["preset"];
+	        this.rooms = this.convertValues(source["rooms"], RoomRequirement);
+	        this.adjacency = source["adjacency"];
+	        this.siteWidth = source["siteWidth"];
+	        this.siteHeight = source["siteHeight"];
+	        this.grid = source["grid"];
+	        this.count = source["count"];
+	        this.seed = source["seed"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -268,6 +666,892 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class GeneratedRoom {
+	    id: string;
+	    kind: string;
+	    name: string;
+	    instanceId: string;
+	    x: number;
+	    y: number;
+	    w: number;
+	    h: number;
+	    area: FloorArea;
+	
+	    static createFrom(source: any = {}) {
+	        return new GeneratedRoom(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.name = source["name"];
+	        this.instanceId = source["instanceId"];
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.w = source["w"];
+	        this.h = source["h"];
+	        this.area = this.convertValues(source["area"], FloorArea);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GeneratedLayout {
+	    data: ProjectData;
+	    rooms: GeneratedRoom[];
+	    score: number;
+	    unsatisfied: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GeneratedLayout(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = this.convertValues(source["data"], ProjectData);
+	        this.rooms = this.convertValues(source["rooms"], GeneratedRoom);
+	        this.score = source["score"];
+	        this.unsatisfied = source["unsatisfied"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FurniturePlacementRequest {
+	    roomInstanceId: string;
+	    assetIds: string[];
+	    clearance: number;
+	    count: number;
+	    seed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FurniturePlacementRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roomInstanceId = source["roomInstanceId"];
+	        this.assetIds = source["assetIds"];
+	        this.clearance = source["clearance"];
+	        this.count = source["count"];
+	        this.seed = source["seed"];
+	    }
+	}
+	export class FurnitureLayout {
+	    instances: Instance[];
+	    score: number;
+	    unplaced: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FurnitureLayout(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instances = this.convertValues(source["instances"], Instance);
+	        this.score = source["score"];
+	        this.unplaced = source["unplaced"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InstanceOverlap {
+	    instanceA: string;
+	    instanceB: string;
+	    area: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new InstanceOverlap(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceA = source["instanceA"];
+	        this.instanceB = source["instanceB"];
+	        this.area = source["area"];
+	    }
+	}
+	export class OutsideInstance {
+	    instanceId: string;
+	    outsideArea: number;
+	    ratio: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new OutsideInstance(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.outsideArea = source["outsideArea"];
+	        this.ratio = source["ratio"];
+	    }
+	}
+	export class CollisionReport {
+	    overlaps: InstanceOverlap[];
+	    outside: OutsideInstance[];
+	    totalOverlapArea: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CollisionReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.overlaps = this.convertValues(source["overlaps"], InstanceOverlap);
+	        this.outside = this.convertValues(source["outside"], OutsideInstance);
+	        this.totalOverlapArea = source["totalOverlapArea"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ClearanceRules {
+	    preset: string;
+	    fixtureFront: number;
+	    corridorWidth: number;
+	    doorWidth: number;
+	    doorSwing: number;
+	    passageWidth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClearanceRules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preset = source["preset"];
+	        this.fixtureFront = source["fixtureFront"];
+	        this.corridorWidth = source["corridorWidth"];
+	        this.doorWidth = source["doorWidth"];
+	        this.doorSwing = source["doorSwing"];
+	        this.passageWidth = source["passageWidth"];
+	    }
+	}
+	export class ClearanceFix {
+	    instanceId: string;
+	    x: number;
+	    y: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClearanceFix(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.x = source["x"];
+	        this.y = source["y"];
+	    }
+	}
+	export class ClearanceViolation {
+	    rule: string;
+	    message: string;
+	    instanceIds: string[];
+	    required: number;
+	    actual: number;
+	    fix?: ClearanceFix;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClearanceViolation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rule = source["rule"];
+	        this.message = source["message"];
+	        this.instanceIds = source["instanceIds"];
+	        this.required = source["required"];
+	        this.actual = source["actual"];
+	        this.fix = this.convertValues(source["fix"], ClearanceFix);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ClearanceReport {
+	    rules: ClearanceRules;
+	    checked: number;
+	    violations: ClearanceViolation[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ClearanceReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rules = this.convertValues(source["rules"], ClearanceRules);
+	        this.checked = source["checked"];
+	        this.violations = this.convertValues(source["violations"], ClearanceViolation);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DaylightOptions {
+	    windowHeights?: Record<string, number>;
+	    defaultWindowHeight: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DaylightOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.windowHeights = source["windowHeights"];
+	        this.defaultWindowHeight = source["defaultWindowHeight"];
+	    }
+	}
+	export class RoomWindow {
+	    instanceId: string;
+	    assetId: string;
+	    width: number;
+	    height: number;
+	    areaM2: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoomWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.assetId = source["assetId"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.areaM2 = source["areaM2"];
+	    }
+	}
+	export class RoomDaylight {
+	    instanceId: string;
+	    name: string;
+	    habitable: boolean;
+	    floorAreaM2: number;
+	    windows: RoomWindow[];
+	    windowAreaM2: number;
+	    ratio: number;
+	    daylightRequiredM2: number;
+	    ventilationRequiredM2: number;
+	    daylightOk: boolean;
+	    ventilationOk: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoomDaylight(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.name = source["name"];
+	        this.habitable = source["habitable"];
+	        this.floorAreaM2 = source["floorAreaM2"];
+	        this.windows = this.convertValues(source["windows"], RoomWindow);
+	        this.windowAreaM2 = source["windowAreaM2"];
+	        this.ratio = source["ratio"];
+	        this.daylightRequiredM2 = source["daylightRequiredM2"];
+	        this.ventilationRequiredM2 = source["ventilationRequiredM2"];
+	        this.daylightOk = source["daylightOk"];
+	        this.ventilationOk = source["ventilationOk"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DaylightReport {
+	    rooms: RoomDaylight[];
+	    failed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DaylightReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rooms = this.convertValues(source["rooms"], RoomDaylight);
+	        this.failed = source["failed"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WallOptions {
+	    thickness: number;
+	    exteriorThickness: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WallOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.thickness = source["thickness"];
+	        this.exteriorThickness = source["exteriorThickness"];
+	        this.height = source["height"];
+	    }
+	}
+	export class WallOutline {
+	    instanceId: string;
+	    polygons: Vec2[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new WallOutline(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.instanceId = source["instanceId"];
+	        this.polygons = this.convertValues(source["polygons"], Vec2);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Level {
+	    id: string;
+	    name: string;
+	    elevation: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Level(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.elevation = source["elevation"];
+	        this.height = source["height"];
+	    }
+	}
+	export class LevelArea {
+	    levelId: string;
+	    name: string;
+	    total: FloorArea;
+	
+	    static createFrom(source: any = {}) {
+	        return new LevelArea(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.levelId = source["levelId"];
+	        this.name = source["name"];
+	        this.total = this.convertValues(source["total"], FloorArea);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ModelExportOptions {
+	    level?: string;
+	    materialFile?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.level = source["level"];
+	        this.materialFile = source["materialFile"];
+	    }
+	}
+	export class OBJExport {
+	    obj: string;
+	    mtl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OBJExport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.obj = source["obj"];
+	        this.mtl = source["mtl"];
+	    }
+	}
+	export class PNGExportOptions {
+	    plan: PlanExportOptions;
+	    dpi: number;
+	    width: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PNGExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.plan = this.convertValues(source["plan"], PlanExportOptions);
+	        this.dpi = source["dpi"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BOMRoomCount {
+	    roomId: string;
+	    roomName: string;
+	    levelId?: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BOMRoomCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roomId = source["roomId"];
+	        this.roomName = source["roomName"];
+	        this.levelId = source["levelId"];
+	        this.count = source["count"];
+	    }
+	}
+	export class BOMItem {
+	    assetId: string;
+	    name: string;
+	    type: string;
+	    w: number;
+	    h: number;
+	    color: string;
+	    manufacturer?: string;
+	    sku?: string;
+	    unitPrice: number;
+	    count: number;
+	    amount: number;
+	    rooms: BOMRoomCount[];
+	    instanceIds: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BOMItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.assetId = source["assetId"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.w = source["w"];
+	        this.h = source["h"];
+	        this.color = source["color"];
+	        this.manufacturer = source["manufacturer"];
+	        this.sku = source["sku"];
+	        this.unitPrice = source["unitPrice"];
+	        this.count = source["count"];
+	        this.amount = source["amount"];
+	        this.rooms = this.convertValues(source["rooms"], BOMRoomCount);
+	        this.instanceIds = source["instanceIds"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BillOfMaterials {
+	    items: BOMItem[];
+	    totalCount: number;
+	    totalAmount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BillOfMaterials(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], BOMItem);
+	        this.totalCount = source["totalCount"];
+	        this.totalAmount = source["totalAmount"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FlooringRate {
+	    assetId: string;
+	    material: string;
+	    unit: string;
+	    unitPrice: number;
+	    laborPrice: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FlooringRate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.assetId = source["assetId"];
+	        this.material = source["material"];
+	        this.unit = source["unit"];
+	        this.unitPrice = source["unitPrice"];
+	        this.laborPrice = source["laborPrice"];
+	    }
+	}
+	export class CostRates {
+	    flooring: FlooringRate[];
+	    taxRate: number;
+	    tatamiSize: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CostRates(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.flooring = this.convertValues(source["flooring"], FlooringRate);
+	        this.taxRate = source["taxRate"];
+	        this.tatamiSize = source["tatamiSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EstimateLine {
+	    category: string;
+	    name: string;
+	    roomId?: string;
+	    assetId?: string;
+	    quantity: number;
+	    unit: string;
+	    unitPrice: number;
+	    amount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EstimateLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.category = source["category"];
+	        this.name = source["name"];
+	        this.roomId = source["roomId"];
+	        this.assetId = source["assetId"];
+	        this.quantity = source["quantity"];
+	        this.unit = source["unit"];
+	        this.unitPrice = source["unitPrice"];
+	        this.amount = source["amount"];
+	    }
+	}
+	export class CostEstimate {
+	    lines: EstimateLine[];
+	    subtotal: number;
+	    taxRate: number;
+	    tax: number;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CostEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lines = this.convertValues(source["lines"], EstimateLine);
+	        this.subtotal = source["subtotal"];
+	        this.taxRate = source["taxRate"];
+	        this.tax = source["tax"];
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ValidationIssue {
+	    path: string;
+	    severity: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ValidationReport {
+	    issues: ValidationIssue[];
+	    errors: number;
+	    warnings: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.issues = this.convertValues(source["issues"], ValidationIssue);
+	        this.errors = source["errors"];
+	        this.warnings = source["warnings"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DataDirInfo {
+	    path: string;
+	    source: string;
+	    configFile: string;
+	    movable: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DataDirInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.source = source["source"];
+	        this.configFile = source["configFile"];
+	        this.movable = source["movable"];
+	    }
+	}
 
 }
 
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// --- 家具の自動配置 ---
+// 部屋インスタンスの中に家具を重ならないように置く。回転は 0/90/180/270 度で、
+// Snap のアセットはフロントエンドと同じ 5cm 刻み、それ以外は壁に沿う位置も候補にする。
+// ドア・窓の前は空けておき、壁際に置けるほど良い配置とする。
+// 乱数は Seed で初期化するため、同じ条件と Seed からは常に同じ結果になる。
+
+const (
+	furnitureSnapUnit  = 5.0    // フロントエンドの SNAP_UNIT
+	defaultClearance   = 60.0   // 窓の前に空ける奥行き (cm)
+	furnitureAttempts  = 60     // 無作為に作る候補の数
+	furnitureChoices   = 4      // 無作為な候補で各家具の位置を選ぶ上位の数
+	furnitureUnplaced  = 1000.0 // 置けなかった家具1つあたりの減点
+	furnitureWallScale = 0.1    // 壁からの距離 (cm) あたりの減点
+)
+
+// furnitureRotations は試す回転角
+var furnitureRotations = []float64{0, 90, 180, 270}
+
+// rectOverlaps は2つの長方形の内部が重なるかどうか (辺が接するだけなら重ならない)
+func rectOverlaps(a, b genRect) bool {
+	const eps = 0.01
+	return a.X < b.X+b.W-eps && b.X < a.X+a.W-eps && a.Y < b.Y+b.H-eps && b.Y < a.Y+a.H-eps
+}
+
+// bboxRect は外接矩形を長方形にする
+func bboxRect(b bbox) genRect {
+	return genRect{X: b.Min.X, Y: b.Min.Y, W: b.width(), H: b.height()}
+}
+
+// assetLocalBounds はアセット座標系での外接矩形
+func assetLocalBounds(asset Asset) bbox {
+	var b bbox
+	paths, _ := assetOutlines(asset)
+	for _, p := range paths {
+		pb := p.bounds()
+		b.add(pb.Min)
+		b.add(pb.Max)
+	}
+	return b
+}
+
+// instanceBounds はインスタンスのワールド座標での外接矩形
+func instanceBounds(inst Instance, asset Asset) bbox {
+	var b bbox
+	tf := instanceTransform(inst)
+	paths, _ := assetOutlines(asset)
+	for _, p := range paths {
+		wb := p.transform(tf).bounds()
+		b.add(wb.Min)
+		b.add(wb.Max)
+	}
+	return b
+}
+
+// openingKind はアセットがドア・窓かどうかを ID と名前から判定する ("door", "window" または "")
+func openingKind(asset Asset) string {
+	name := strings.ToLower(asset.Name)
+	switch {
+	case asset.ID == "a_door" || strings.Contains(name, "ドア") || strings.Contains(name, "扉") || strings.Contains(name, "door"):
+		return "door"
+	case asset.ID == "a_window" || strings.Contains(name, "窓") || strings.Contains(name, "window"):
+		return "window"
+	}
+	return ""
+}
+
+// openingClearance はドア・窓の前に空ける範囲 (ワールド座標)。
+// 開口の長辺に直交する方向へ両側に depth だけ広げた矩形とする。
+func openingClearance(inst Instance, asset Asset, depth float64) bbox {
+	lb := assetLocalBounds(asset)
+	if lb.width() >= lb.height() {
+		lb.Min.Y -= depth
+		lb.Max.Y += depth
+	} else {
+		lb.Min.X -= depth
+		lb.Max.X += depth
+	}
+	var b bbox
+	tf := instanceTransform(inst)
+	for _, v := range []Vec2{lb.Min, {X: lb.Max.X, Y: lb.Min.Y}, lb.Max, {X: lb.Min.X, Y: lb.Max.Y}} {
+		b.add(tf(v))
+	}
+	return b
+}
+
+// furnitureRoom は家具を置く部屋の形と、避けるべき範囲
+type furnitureRoom struct {
+	Polygons  [][]Vec2
+	Bounds    bbox
+	Obstacles []genRect // 既存の建具・家具と、ドア・窓の前の範囲
+}
+
+// contains は長方形が部屋の中に収まるかどうか
+func (room *furnitureRoom) contains(r genRect) bool {
+	corners := []Vec2{{X: r.X, Y: r.Y}, {X: r.X + r.W, Y: r.Y}, {X: r.X + r.W, Y: r.Y + r.H}, {X: r.X, Y: r.Y + r.H}}
+	for _, poly := range room.Polygons {
+		inside := true
+		for _, c := range corners {
+			if !pointInPolygon(c, poly) {
+				inside = false
+				break
+			}
+		}
+		// L 字などで頂点が長方形に食い込んでいないか
+		for _, v := range poly {
+			if !inside {
+				break
+			}
+			if v.X > r.X+0.01 && v.X < r.X+r.W-0.01 && v.Y > r.Y+0.01 && v.Y < r.Y+r.H-0.01 {
+				inside = false
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
+
+// wallGap は長方形の各辺の中点から壁までの距離の最小値 (壁際に置くほど小さい)
+func (room *furnitureRoom) wallGap(r genRect) float64 {
+	mids := []Vec2{{X: r.X, Y: r.Y + r.H/2}, {X: r.X + r.W, Y: r.Y + r.H/2}, {X: r.X + r.W/2, Y: r.Y}, {X: r.X + r.W/2, Y: r.Y + r.H}}
+	gap := math.Inf(1)
+	for _, poly := range room.Polygons {
+		for _, m := range mids {
+			gap = math.Min(gap, distToPolygon(m, poly))
+		}
+	}
+	return gap
+}
+
+// furniturePlacement は家具1つの置き方
+type furniturePlacement struct {
+	X, Y, Rotation float64
+	Rect           genRect
+	Gap            float64
+}
+
+// furnitureItem は配置する家具と、部屋の中で置ける位置の一覧 (良い順)
+type furnitureItem struct {
+	Asset      *Asset
+	Placements []furniturePlacement
+}
+
+// furnitureAxis は片方の軸の候補座標 (インスタンス座標)。offset は回転後の外接矩形の最小値、size はその幅。
+func furnitureAxis(lo, hi, offset, size float64, snap bool, walls []float64) []float64 {
+	min, max := lo-offset, hi-size-offset
+	if max < min-1e-9 {
+		return nil
+	}
+	seen := map[float64]bool{}
+	var values []float64
+	add := func(v float64) {
+		v = roundCoord(v)
+		if v < min-1e-9 || v > max+1e-9 || seen[v] {
+			return
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	for v := math.Ceil(min/furnitureSnapUnit-1e-9) * furnitureSnapUnit; v <= max+1e-9; v += furnitureSnapUnit {
+		add(v)
+	}
+	if !snap {
+		// 壁にぴったり付ける位置
+		add(min)
+		add(max)
+		for _, w := range walls {
+			add(w - offset)
+			add(w - size - offset)
+		}
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// placements は部屋の中で既存の物と重ならない置き方を良い順に返す
+func (room *furnitureRoom) placements(asset *Asset) []furniturePlacement {
+	local := assetLocalBounds(*asset)
+	if !local.Valid {
+		return nil
+	}
+	var wallX, wallY []float64
+	for _, poly := range room.Polygons {
+		for _, v := range poly {
+			wallX = append(wallX, v.X)
+			wallY = append(wallY, v.Y)
+		}
+	}
+
+	var list []furniturePlacement
+	for _, rot := range furnitureRotations {
+		var rb bbox
+		for _, v := range []Vec2{local.Min, {X: local.Max.X, Y: local.Min.Y}, local.Max, {X: local.Min.X, Y: local.Max.Y}} {
+			rb.add(rotateVec(v, rot))
+		}
+		xs := furnitureAxis(room.Bounds.Min.X, room.Bounds.Max.X, rb.Min.X, rb.width(), asset.Snap, wallX)
+		ys := furnitureAxis(room.Bounds.Min.Y, room.Bounds.Max.Y, rb.Min.Y, rb.height(), asset.Snap, wallY)
+		for _, y := range ys {
+			for _, x := range xs {
+				r := genRect{X: x + rb.Min.X, Y: y + rb.Min.Y, W: rb.width(), H: rb.height()}
+				if !room.contains(r) {
+					continue
+				}
+				free := true
+				for _, o := range room.Obstacles {
+					if rectOverlaps(r, o) {
+						free = false
+						break
+					}
+				}
+				if free {
+					list = append(list, furniturePlacement{X: x, Y: y, Rotation: rot, Rect: r, Gap: room.wallGap(r)})
+				}
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Gap < list[j].Gap })
+	return list
+}
+
+// newFurnitureRoom は部屋インスタンスの形と、部屋の中の障害物を集める
+func newFurnitureRoom(data ProjectData, assets assetIndex, roomID string, clearance float64) (*furnitureRoom, error) {
+	var roomInst *Instance
+	for i := range data.Instances {
+		if data.Instances[i].ID == roomID {
+			roomInst = &data.Instances[i]
+		}
+	}
+	if roomInst == nil {
+		return nil, fmt.Errorf("room instance not found: %s", roomID)
+	}
+	roomAsset := assets.lookup(*roomInst)
+	if roomAsset == nil || roomAsset.Type != "room" {
+		return nil, fmt.Errorf("instance is not a room: %s", roomID)
+	}
+
+	room := &furnitureRoom{}
+	paths, _ := assetOutlines(*roomAsset)
+	for _, p := range paths {
+		poly := p.transform(instanceTransform(*roomInst)).flatten(8)
+		room.Polygons = append(room.Polygons, poly)
+		for _, v := range poly {
+			room.Bounds.add(v)
+		}
+	}
+	if !room.Bounds.Valid {
+		return nil, fmt.Errorf("room has no outline: %s", roomID)
+	}
+
+	roomRect := bboxRect(room.Bounds)
+	for _, inst := range data.Instances {
+		asset := assets.lookup(inst)
+		if asset == nil || asset.Type == "room" {
+			continue
+		}
+		b := instanceBounds(inst, *asset)
+		if !b.Valid || !rectOverlaps(bboxRect(b), roomRect) {
+			continue
+		}
+		room.Obstacles = append(room.Obstacles, bboxRect(b))
+		switch openingKind(*asset) {
+		case "door":
+			lb := assetLocalBounds(*asset)
+			depth := math.Max(math.Max(lb.width(), lb.height()), clearance)
+			room.Obstacles = append(room.Obstacles, bboxRect(openingClearance(inst, *asset, depth)))
+		case "window":
+			room.Obstacles = append(room.Obstacles, bboxRect(openingClearance(inst, *asset, clearance)))
+		}
+	}
+	return room, nil
+}
+
+// furnitureCandidate は家具の置き方の組み合わせ
+type furnitureCandidate struct {
+	Chosen []*furniturePlacement // items と同じ順。置けなかった家具は nil
+	Score  float64
+	Key    string
+}
+
+// arrange は order の順に家具を置く。pick は置ける位置の数を受け取り、選ぶ位置の添字を返す。
+func arrange(items []furnitureItem, order []int, pick func(int) int) furnitureCandidate {
+	c := furnitureCandidate{Chosen: make([]*furniturePlacement, len(items))}
+	var placed []genRect
+	for _, i := range order {
+		var free []*furniturePlacement
+		for k := range items[i].Placements {
+			p := &items[i].Placements[k]
+			ok := true
+			for _, r := range placed {
+				if rectOverlaps(p.Rect, r) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				free = append(free, p)
+				if len(free) >= furnitureChoices {
+					break
+				}
+			}
+		}
+		if len(free) == 0 {
+			c.Score += furnitureUnplaced
+			continue
+		}
+		p := free[pick(len(free))]
+		c.Chosen[i] = p
+		placed = append(placed, p.Rect)
+		c.Score += p.Gap * furnitureWallScale
+	}
+
+	var b strings.Builder
+	for _, p := range c.Chosen {
+		if p == nil {
+			b.WriteString("-;")
+			continue
+		}
+		fmt.Fprintf(&b, "%s,%s,%s;", fmtNum(p.X), fmtNum(p.Y), fmtNum(p.Rotation))
+	}
+	c.Key = b.String()
+	return c
+}
+
+// proposeFurnitureLayouts は家具の置き方の候補を良い順に返す
+func proposeFurnitureLayouts(data ProjectData, assets assetIndex, req FurniturePlacementRequest) ([]FurnitureLayout, error) {
+	if len(req.AssetIDs) == 0 {
+		return nil, fmt.Errorf("no furniture to place")
+	}
+	clearance := req.Clearance
+	if clearance <= 0 {
+		clearance = defaultClearance
+	}
+	count := req.Count
+	if count <= 0 {
+		count = defaultLayoutCount
+	}
+	// 複数階のプロジェクトでは部屋と同じ階のものだけを避ける
+	levelID := ""
+	for _, inst := range data.Instances {
+		if inst.ID == req.RoomInstanceID && len(data.Levels) > 0 {
+			idx := instanceLevel(inst, data.Levels)
+			levelID = data.Levels[idx].ID
+			data = levelData(data, assets, idx)
+			break
+		}
+	}
+	room, err := newFurnitureRoom(data, assets, req.RoomInstanceID, clearance)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]furnitureItem, len(req.AssetIDs))
+	cache := map[string][]furniturePlacement{}
+	for i, id := range req.AssetIDs {
+		asset := assets[id]
+		if asset == nil {
+			return nil, fmt.Errorf("unknown asset: %q", id)
+		}
+		if asset.Type == "room" {
+			return nil, fmt.Errorf("asset is a room: %q", id)
+		}
+		if _, ok := cache[id]; !ok {
+			cache[id] = room.placements(asset)
+		}
+		items[i] = furnitureItem{Asset: asset, Placements: cache[id]}
+	}
+
+	// 1つ目は大きい家具から最良の位置に置く。残りは順番と位置を無作為に選ぶ。
+	largest := make([]int, len(items))
+	for i := range largest {
+		largest[i] = i
+	}
+	area := func(i int) float64 { b := assetLocalBounds(*items[i].Asset); return b.width() * b.height() }
+	sort.SliceStable(largest, func(i, j int) bool { return area(largest[i]) > area(largest[j]) })
+
+	rng := rand.New(rand.NewSource(req.Seed))
+	found := map[string]furnitureCandidate{}
+	add := func(c furnitureCandidate) {
+		if _, ok := found[c.Key]; !ok {
+			found[c.Key] = c
+		}
+	}
+	add(arrange(items, largest, func(int) int { return 0 }))
+	for i := 0; i < furnitureAttempts; i++ {
+		add(arrange(items, rng.Perm(len(items)), rng.Intn))
+	}
+	list := make([]furnitureCandidate, 0, len(found))
+	for _, c := range found {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score < list[j].Score
+		}
+		return list[i].Key < list[j].Key
+	})
+	if len(list) > count {
+		list = list[:count]
+	}
+
+	layouts := make([]FurnitureLayout, 0, len(list))
+	for n, c := range list {
+		layout := FurnitureLayout{Instances: []Instance{}, Unplaced: []string{}, Score: math.Round(c.Score*10) / 10}
+		for i, p := range c.Chosen {
+			if p == nil {
+				layout.Unplaced = append(layout.Unplaced, items[i].Asset.ID)
+				continue
+			}
+			layout.Instances = append(layout.Instances, Instance{
+				ID:       fmt.Sprintf("i-furn-%d-%d", n+1, i+1),
+				AssetID:  items[i].Asset.ID,
+				Type:     items[i].Asset.Type,
+				X:        p.X,
+				Y:        p.Y,
+				Rotation: p.Rotation,
+				LevelID:  levelID,
+			})
+		}
+		layouts = append(layouts, layout)
+	}
+	return layouts, nil
+}
+
+// ProposeFurnitureLayouts proposes placements of furniture assets inside a room instance, best first.
+// Furniture does not overlap existing fixtures or furniture and keeps clear of doors and windows.
+func (a *App) ProposeFurnitureLayouts(id string, req FurniturePlacementRequest) ([]FurnitureLayout, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return nil, err
+	}
+	layouts, err := proposeFurnitureLayouts(data, assets, req)
+	if err != nil {
+		a.logError("家具配置失敗 (ID: %s): %v", id, err)
+		return nil, err
+	}
+	a.logInfo("家具の配置案を作成しました: %d 案 (seed %d)", len(layouts), req.Seed)
+	return layouts, nil
+}
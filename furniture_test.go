@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// furnitureTestProject は LDK (360×450) の下の壁にドア、右の壁に窓があるプロジェクト
+func furnitureTestProject() ProjectData {
+	return ProjectData{
+		LocalAssets: []Asset{},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_ldk10", Type: "room"},
+			{ID: "door", AssetID: "a_door", Type: "fixture", X: 40, Y: 0},
+			{ID: "window", AssetID: "a_window", Type: "fixture", X: 360, Y: 100, Rotation: 90},
+			{ID: "kitchen", AssetID: "a_kitchen", Type: "fixture", X: 0, Y: 385},
+		},
+	}
+}
+
+// TestProposeFurnitureLayouts は家具が部屋の中に重ならず、ドア・窓の前を空けて置かれることを検証します
+func TestProposeFurnitureLayouts(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	data := furnitureTestProject()
+	assets := newAssetIndex(data.LocalAssets, globals)
+	req := FurniturePlacementRequest{RoomInstanceID: "room", AssetIDs: []string{"a_sofa2", "a_tvboard", "a_table4", "a_chair"}, Seed: 7}
+
+	layouts, err := proposeFurnitureLayouts(data, assets, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) != defaultLayoutCount {
+		t.Fatalf("候補の数が不正です: %d", len(layouts))
+	}
+	again, _ := proposeFurnitureLayouts(data, assets, req)
+	if !reflect.DeepEqual(layouts, again) {
+		t.Error("同じ seed で結果が変わりました")
+	}
+
+	door := bboxRect(openingClearance(data.Instances[1], *assets["a_door"], 80))
+	window := bboxRect(openingClearance(data.Instances[2], *assets["a_window"], defaultClearance))
+	for n, l := range layouts {
+		if n > 0 && l.Score < layouts[n-1].Score {
+			t.Errorf("候補が点数順になっていません: %v < %v", l.Score, layouts[n-1].Score)
+		}
+		if len(l.Unplaced) != 0 || len(l.Instances) != len(req.AssetIDs) {
+			t.Errorf("置けなかった家具があります: %v", l.Unplaced)
+		}
+		var rects []genRect
+		for _, inst := range l.Instances {
+			asset := assets[inst.AssetID]
+			r := bboxRect(instanceBounds(inst, *asset))
+			if r.X < -1e-6 || r.Y < -1e-6 || r.X+r.W > 360+1e-6 || r.Y+r.H > 450+1e-6 {
+				t.Errorf("%s が部屋からはみ出しています: %+v", inst.AssetID, r)
+			}
+			if rectOverlaps(r, door) || rectOverlaps(r, window) {
+				t.Errorf("%s がドア・窓の前に置かれています: %+v", inst.AssetID, r)
+			}
+			if rectOverlaps(r, bboxRect(instanceBounds(data.Instances[3], *assets["a_kitchen"]))) {
+				t.Errorf("%s がキッチンと重なっています", inst.AssetID)
+			}
+			for _, o := range rects {
+				if rectOverlaps(r, o) {
+					t.Errorf("%s が他の家具と重なっています", inst.AssetID)
+				}
+			}
+			rects = append(rects, r)
+			if math.Mod(inst.Rotation, 90) != 0 {
+				t.Errorf("%s の回転が不正です: %v", inst.AssetID, inst.Rotation)
+			}
+			if asset.Snap && (math.Mod(inst.X, furnitureSnapUnit) != 0 || math.Mod(inst.Y, furnitureSnapUnit) != 0) {
+				t.Errorf("%s が 5cm 刻みになっていません: %v, %v", inst.AssetID, inst.X, inst.Y)
+			}
+		}
+	}
+
+	// 入りきらない家具は Unplaced に入る
+	req.AssetIDs = []string{"a_bed_s", "a_bed_s", "a_bed_s", "a_bed_s", "a_bed_s", "a_bed_s", "a_bed_s"}
+	layouts, err = proposeFurnitureLayouts(data, assets, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts[0].Unplaced) == 0 {
+		t.Error("入りきらない家具が Unplaced に入っていません")
+	}
+
+	for name, r := range map[string]FurniturePlacementRequest{
+		"家具なし":    {RoomInstanceID: "room"},
+		"部屋でない":   {RoomInstanceID: "door", AssetIDs: []string{"a_chair"}},
+		"未知のアセット": {RoomInstanceID: "room", AssetIDs: []string{"a_missing"}},
+	} {
+		if _, err := proposeFurnitureLayouts(data, assets, r); err == nil {
+			t.Errorf("%s: エラーになっていません", name)
+		}
+	}
+}
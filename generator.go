@@ -0,0 +1,643 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// --- 間取りの自動生成 ---
+// 部屋の一覧 (プログラム) を敷地の長方形に割り付ける。長方形を縦横に二分割していく
+// スライス構造で、部屋の並び順と分割方向を変えた候補を評価し、違反の少ないものから返す。
+// 乱数は Seed で初期化するため、同じプログラムと Seed からは常に同じ結果になる。
+
+const (
+	defaultPlanGrid    = 45.5 // 半間
+	defaultLayoutCount = 3
+	generatorAttempts  = 300  // 無作為に作る候補の数
+	generatorRefine    = 10   // 局所探索で改良する上位候補の数
+	generatorSwaps     = 200  // 局所探索で試す入れ替えの回数
+	minSharedWall      = 80.0 // 出入りできるとみなす共有壁の長さ (cm, ドア幅)
+)
+
+// roomKind は部屋の種類ごとの既定値
+type roomKind struct {
+	Template string   // 名前・色・既定面積の元にする既定アセット
+	Name     string   // 表示名
+	AreaM2   float64  // 既定の面積。0 の場合はテンプレートの面積
+	MinWidth float64  // 短辺の最小値 (cm)
+	Exterior bool     // 外周に面するべき部屋 (採光・出入口)
+	Access   []string // 出入りに使う部屋の種類。空の場合は動線となる部屋 (circulationKinds)
+}
+
+// jo は江戸間の畳数を m² にする
+func jo(n float64) float64 { return n * tatamiAreasM2[TatamiEdoma] }
+
+var roomKinds = map[string]roomKind{
+	"ldk":      {Template: "a_ldk10", Name: "LDK", MinWidth: 270, Exterior: true},
+	"dk":       {Template: "a_ldk10", Name: "DK", AreaM2: jo(8), MinWidth: 220, Exterior: true},
+	"k":        {Template: "a_ldk10", Name: "K", AreaM2: jo(3), MinWidth: 130, Exterior: true},
+	"bedroom":  {Template: "a_room6", Name: "洋室", MinWidth: 220, Exterior: true},
+	"entrance": {Template: "a_ent", Name: "玄関", MinWidth: 120, Exterior: true},
+	"hall":     {Template: "a_ent", Name: "ホール", AreaM2: jo(2), MinWidth: 90},
+	"toilet":   {Template: "a_toilet", Name: "トイレ", MinWidth: 80},
+	"bath":     {Template: "a_bath", Name: "浴室", MinWidth: 130, Access: []string{"washroom"}},
+	"washroom": {Template: "a_bath", Name: "洗面所", MinWidth: 130},
+	"storage":  {Template: "a_room6", Name: "納戸", AreaM2: jo(4.5), MinWidth: 130},
+	"wic":      {Template: "a_room6", Name: "WIC", AreaM2: jo(2), MinWidth: 90, Access: []string{"bedroom", "hall", "ldk", "dk"}},
+	"sic":      {Template: "a_ent", Name: "SIC", AreaM2: jo(1), MinWidth: 90, Access: []string{"entrance"}},
+	"balcony":  {Template: "a_balcony", Name: "バルコニー", MinWidth: 90, Exterior: true, Access: []string{"ldk", "dk", "bedroom"}},
+}
+
+// circulationKinds は他の部屋への出入口になる部屋
+var circulationKinds = map[string]bool{"ldk": true, "dk": true, "k": true, "entrance": true, "hall": true}
+
+// expandPreset は "3LDK" や "2LDK+S LDK15畳" などの間取りを部屋の一覧と隣接条件にする。
+// 表記の部屋に玄関・廊下・水回りを加え、面積表記の無い LDK は居室数から広さを決める。
+func expandPreset(preset string) ([]RoomRequirement, [][2]string, error) {
+	m, err := parseMadori(preset)
+	if err != nil || m.Bedrooms < 1 || m.Bedrooms > 9 {
+		return nil, nil, fmt.Errorf("unsupported preset: %q", preset)
+	}
+	n := m.Bedrooms
+	main := strings.ToLower(m.Main)
+
+	rooms := []RoomRequirement{{ID: "entrance", Kind: "entrance"}}
+	var adjacency [][2]string
+	if n >= 2 {
+		rooms = append(rooms, RoomRequirement{ID: "hall", Kind: "hall"})
+		adjacency = append(adjacency, [2]string{"entrance", "hall"})
+	}
+	for _, r := range m.Rooms {
+		switch {
+		case r.Kind == "ldk" && r.AreaM2 == 0:
+			r.AreaM2 = jo(math.Min(10+2*float64(n-1), 16))
+		case r.Kind == "bedroom" && main == "r" && r.AreaM2 == 0:
+			r.AreaM2 = jo(8)
+		}
+		rooms = append(rooms, r)
+	}
+	if main != "r" && n == 1 {
+		adjacency = append(adjacency, [2]string{"entrance", main})
+	}
+	rooms = append(rooms,
+		RoomRequirement{ID: "toilet", Kind: "toilet"},
+		RoomRequirement{ID: "washroom", Kind: "washroom"},
+		RoomRequirement{ID: "bath", Kind: "bath"},
+	)
+	adjacency = append(adjacency, [2]string{"bath", "washroom"})
+	return rooms, adjacency, nil
+}
+
+// genRoom は割り付ける部屋
+type genRoom struct {
+	ID     string
+	Kind   string
+	Name   string
+	Target float64 // 目標面積 (cm²)
+	Spec   roomKind
+}
+
+// genRect は敷地内の長方形 (左下原点, cm)
+type genRect struct {
+	X, Y, W, H float64
+}
+
+// sharedWall は2つの長方形が共有する壁の長さ
+func sharedWall(a, b genRect) float64 {
+	const eps = 0.01
+	overlap := func(a0, a1, b0, b1 float64) float64 { return math.Min(a1, b1) - math.Max(a0, b0) }
+	if math.Abs(a.X+a.W-b.X) < eps || math.Abs(b.X+b.W-a.X) < eps {
+		return math.Max(overlap(a.Y, a.Y+a.H, b.Y, b.Y+b.H), 0)
+	}
+	if math.Abs(a.Y+a.H-b.Y) < eps || math.Abs(b.Y+b.H-a.Y) < eps {
+		return math.Max(overlap(a.X, a.X+a.W, b.X, b.X+b.W), 0)
+	}
+	return 0
+}
+
+// exteriorWall は敷地の外周に面する辺の長さ
+func exteriorWall(r, site genRect) float64 {
+	const eps = 0.01
+	length := 0.0
+	if r.X < eps || math.Abs(r.X+r.W-site.W) < eps {
+		length += r.H
+	}
+	if r.Y < eps || math.Abs(r.Y+r.H-site.H) < eps {
+		length += r.W
+	}
+	return length
+}
+
+// floorPlanJob は正規化済みの生成条件
+type floorPlanJob struct {
+	Rooms     []genRoom
+	Adjacency [][2][]int // 隣接条件 (それぞれ該当する部屋の添字)
+	Labels    [][2]string
+	Site      genRect
+	Grid      float64
+}
+
+// resolveRoomRef は隣接条件の参照 (部屋 ID または種類) に該当する部屋を返す
+func resolveRoomRef(rooms []genRoom, ref string) []int {
+	var byKind []int
+	for i, r := range rooms {
+		if r.ID == ref {
+			return []int{i}
+		}
+		if r.Kind == ref {
+			byKind = append(byKind, i)
+		}
+	}
+	return byKind
+}
+
+// newFloorPlanJob はプログラムを検証し、既定値と敷地の大きさを決める
+func newFloorPlanJob(p FloorPlanProgram, templates map[string]Asset) (*floorPlanJob, error) {
+	reqs, adjacency := p.Rooms, p.Adjacency
+	if len(reqs) == 0 {
+		if p.Preset == "" {
+			return nil, fmt.Errorf("program has no rooms")
+		}
+		var err error
+		var presetAdj [][2]string
+		if reqs, presetAdj, err = expandPreset(p.Preset); err != nil {
+			return nil, err
+		}
+		adjacency = append(presetAdj, adjacency...)
+	}
+
+	job := &floorPlanJob{Grid: p.Grid}
+	if job.Grid <= 0 {
+		job.Grid = defaultPlanGrid
+	}
+	counts := map[string]int{}
+	for _, r := range reqs {
+		counts[r.Kind]++
+	}
+	seen := map[string]int{}
+	ids := map[string]bool{}
+	total := 0.0
+	for _, r := range reqs {
+		spec, ok := roomKinds[r.Kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown room kind: %q", r.Kind)
+		}
+		if r.AssetID != "" && r.AssetID != spec.Template {
+			t, ok := templates[r.AssetID]
+			if !ok || t.Type != "room" {
+				return nil, fmt.Errorf("unknown room asset: %q", r.AssetID)
+			}
+			spec.Template, spec.Name, spec.AreaM2 = t.ID, t.Name, 0
+		}
+		seen[r.Kind]++
+		if r.ID == "" {
+			r.ID = r.Kind
+			if counts[r.Kind] > 1 {
+				r.ID = fmt.Sprintf("%s%d", r.Kind, seen[r.Kind])
+			}
+		}
+		if ids[r.ID] {
+			return nil, fmt.Errorf("duplicate room id: %q", r.ID)
+		}
+		ids[r.ID] = true
+		if r.Name == "" {
+			r.Name = spec.Name
+			if counts[r.Kind] > 1 {
+				r.Name = fmt.Sprintf("%s%d", spec.Name, seen[r.Kind])
+			}
+		}
+		area := r.AreaM2
+		if area <= 0 {
+			area = spec.AreaM2
+		}
+		if area <= 0 {
+			t := templates[spec.Template]
+			area = cm2ToM2(t.W * t.H)
+		}
+		if r.MinWidth > 0 {
+			spec.MinWidth = r.MinWidth
+		}
+		job.Rooms = append(job.Rooms, genRoom{ID: r.ID, Kind: r.Kind, Name: r.Name, Target: area * 10000, Spec: spec})
+		total += area * 10000
+	}
+
+	for _, pair := range adjacency {
+		a, b := resolveRoomRef(job.Rooms, pair[0]), resolveRoomRef(job.Rooms, pair[1])
+		if len(a) == 0 || len(b) == 0 {
+			return nil, fmt.Errorf("adjacency refers to unknown room: %q - %q", pair[0], pair[1])
+		}
+		job.Adjacency = append(job.Adjacency, [2][]int{a, b})
+		job.Labels = append(job.Labels, [2]string{job.Rooms[a[0]].Name, job.Rooms[b[0]].Name})
+	}
+
+	// 敷地。指定が無い辺は合計面積から決める (格子に乗るよう切り上げ)
+	g := job.Grid
+	ceilGrid := func(v float64) float64 { return math.Ceil(v/g-1e-9) * g }
+	floorGrid := func(v float64) float64 { return math.Floor(v/g+1e-9) * g }
+	w, h := floorGrid(p.SiteWidth), floorGrid(p.SiteHeight)
+	switch {
+	case w <= 0 && h <= 0:
+		w = ceilGrid(math.Sqrt(total * 1.25))
+		h = ceilGrid(total / w)
+	case w <= 0:
+		w = ceilGrid(total / h)
+	case h <= 0:
+		h = ceilGrid(total / w)
+	}
+	job.Site = genRect{W: roundCoord(w), H: roundCoord(h)}
+	site := w * h
+	if site < total*0.9 {
+		return nil, fmt.Errorf("site %.1f m² is too small for the program (%.1f m²)", cm2ToM2(site), cm2ToM2(total))
+	}
+
+	// 余る面積は廊下に回し、それでも余る分は各部屋に比例配分する
+	if left := site - total; left > total*0.05 {
+		hall := -1
+		for i, r := range job.Rooms {
+			if r.Kind == "hall" {
+				hall = i
+				break
+			}
+		}
+		toHall := math.Min(left, total*0.15)
+		if hall < 0 && !ids["hall"] {
+			job.Rooms = append(job.Rooms, genRoom{ID: "hall", Kind: "hall", Name: roomKinds["hall"].Name, Spec: roomKinds["hall"]})
+			hall = len(job.Rooms) - 1
+		}
+		if hall >= 0 {
+			job.Rooms[hall].Target += toHall
+			left -= toHall
+		}
+		scale := (total + left) / total
+		for i := range job.Rooms {
+			if i != hall {
+				job.Rooms[i].Target *= scale
+			}
+		}
+	}
+	return job, nil
+}
+
+// sliceLayout は order の部屋を目標面積の比で r に割り付ける
+func (job *floorPlanJob) sliceLayout(order []int, r genRect, rng *rand.Rand, out []genRect) {
+	if len(order) == 1 {
+		out[order[0]] = r
+		return
+	}
+	total := 0.0
+	for _, i := range order {
+		total += job.Rooms[i].Target
+	}
+	// 面積がなるべく半分になる位置で二分する (乱数で前後にずらす)
+	split, acc, bestDiff := 1, 0.0, math.Inf(1)
+	for k := 1; k < len(order); k++ {
+		acc += job.Rooms[order[k-1]].Target
+		if d := math.Abs(acc - total/2); d < bestDiff {
+			split, bestDiff = k, d
+		}
+	}
+	if len(order) > 2 && rng.Float64() < 0.3 {
+		split = int(math.Max(1, math.Min(float64(len(order)-1), float64(split+rng.Intn(3)-1))))
+	}
+	first := 0.0
+	for _, i := range order[:split] {
+		first += job.Rooms[i].Target
+	}
+	ratio := first / total
+
+	// 長辺を切るのを基本とし、一定の確率で向きを変える
+	vertical := r.W >= r.H
+	if rng.Float64() < 0.25 {
+		vertical = !vertical
+	}
+	g := job.Grid
+	cut := func(start, length float64) (float64, bool) {
+		if length < 2*g-1e-9 {
+			return 0, false
+		}
+		c := math.Round((start+length*ratio)/g) * g
+		c = math.Max(start+g, math.Min(start+length-g, c))
+		return roundCoord(c), true
+	}
+	var a, b genRect
+	c, ok := 0.0, false
+	for try := 0; try < 2 && !ok; try++ {
+		if vertical {
+			if c, ok = cut(r.X, r.W); ok {
+				a = genRect{X: r.X, Y: r.Y, W: c - r.X, H: r.H}
+				b = genRect{X: c, Y: r.Y, W: r.X + r.W - c, H: r.H}
+			}
+		} else {
+			if c, ok = cut(r.Y, r.H); ok {
+				a = genRect{X: r.X, Y: r.Y, W: r.W, H: c - r.Y}
+				b = genRect{X: r.X, Y: c, W: r.W, H: r.Y + r.H - c}
+			}
+		}
+		if !ok {
+			vertical = !vertical
+		}
+	}
+	if !ok {
+		// 格子1つ分より狭い場合は格子を無視して面積比で切る
+		if vertical {
+			w := roundCoord(r.W * ratio)
+			a = genRect{X: r.X, Y: r.Y, W: w, H: r.H}
+			b = genRect{X: r.X + w, Y: r.Y, W: r.W - w, H: r.H}
+		} else {
+			h := roundCoord(r.H * ratio)
+			a = genRect{X: r.X, Y: r.Y, W: r.W, H: h}
+			b = genRect{X: r.X, Y: r.Y + h, W: r.W, H: r.H - h}
+		}
+	}
+	job.sliceLayout(order[:split], a, rng, out)
+	job.sliceLayout(order[split:], b, rng, out)
+}
+
+// layout は並び順と分割用の seed から割り付けを作る (同じ引数なら同じ結果)
+func (job *floorPlanJob) layout(order []int, seed int64) []genRect {
+	rects := make([]genRect, len(job.Rooms))
+	job.sliceLayout(order, job.Site, rand.New(rand.NewSource(seed)), rects)
+	return rects
+}
+
+// evaluate は割り付けの違反を点数 (小さいほど良い) と説明にする
+func (job *floorPlanJob) evaluate(rects []genRect) (float64, []string) {
+	score := 0.0
+	var unsatisfied []string
+	touches := func(i, j int) bool { return sharedWall(rects[i], rects[j]) >= minSharedWall }
+	touchesAny := func(i int, set []int) bool {
+		for _, j := range set {
+			if j != i && touches(i, j) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for k, pair := range job.Adjacency {
+		ok := false
+		for _, i := range pair[0] {
+			if touchesAny(i, pair[1]) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			score += 100
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s と %s が接していません", job.Labels[k][0], job.Labels[k][1]))
+		}
+	}
+
+	// 動線: 動線となる部屋は玄関からつながり、その他の部屋は出入りできる部屋に接する
+	reach := make([]bool, len(rects))
+	var queue []int
+	hasEntrance := false
+	for i, r := range job.Rooms {
+		if r.Kind == "entrance" {
+			reach[i], hasEntrance = true, true
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for j, r := range job.Rooms {
+			if !reach[j] && circulationKinds[r.Kind] && touches(i, j) {
+				reach[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+	for i, r := range job.Rooms {
+		ok := false
+		switch {
+		case circulationKinds[r.Kind]:
+			ok = reach[i] || !hasEntrance
+		case len(r.Spec.Access) == 0:
+			for j, o := range job.Rooms {
+				if circulationKinds[o.Kind] && (reach[j] || !hasEntrance) && touches(i, j) {
+					ok = true
+				}
+			}
+		default:
+			for _, kind := range r.Spec.Access {
+				if touchesAny(i, resolveRoomRef(job.Rooms, kind)) {
+					ok = true
+				}
+			}
+		}
+		if !ok {
+			score += 50
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s に出入りできません", r.Name))
+		}
+	}
+
+	for i, r := range job.Rooms {
+		rect := rects[i]
+		short, long := math.Min(rect.W, rect.H), math.Max(rect.W, rect.H)
+		if r.Spec.Exterior && exteriorWall(rect, job.Site) < minSharedWall {
+			score += 30
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s が外周に面していません", r.Name))
+		}
+		if short < r.Spec.MinWidth-1e-6 {
+			score += 20 + (r.Spec.MinWidth-short)/job.Grid*10
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s の幅が足りません (%scm)", r.Name, fmtNum(short)))
+		}
+		if r.Kind != "hall" && short > 0 && long/short > 2 {
+			score += (long/short - 2) * 15
+		}
+		score += math.Abs(rect.W*rect.H-r.Target) / r.Target * 20
+	}
+	return score, unsatisfied
+}
+
+// layoutKey は同じ割り付けを判定するためのキー
+func layoutKey(rects []genRect) string {
+	var b strings.Builder
+	for _, r := range rects {
+		fmt.Fprintf(&b, "%s,%s,%s,%s;", fmtNum(r.X), fmtNum(r.Y), fmtNum(r.W), fmtNum(r.H))
+	}
+	return b.String()
+}
+
+type genCandidate struct {
+	Order []int
+	Seed  int64
+	Rects []genRect
+	Score float64
+	Key   string
+}
+
+// search は無作為な候補を作り、上位の候補を部屋の入れ替えで改良する
+func (job *floorPlanJob) search(seed int64) []genCandidate {
+	rng := rand.New(rand.NewSource(seed))
+	found := map[string]genCandidate{}
+	try := func(order []int, s int64) genCandidate {
+		rects := job.layout(order, s)
+		score, _ := job.evaluate(rects)
+		c := genCandidate{Order: append([]int(nil), order...), Seed: s, Rects: rects, Score: score, Key: layoutKey(rects)}
+		if _, ok := found[c.Key]; !ok {
+			found[c.Key] = c
+		}
+		return c
+	}
+	sorted := func() []genCandidate {
+		list := make([]genCandidate, 0, len(found))
+		for _, c := range found {
+			list = append(list, c)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score < list[j].Score
+			}
+			return list[i].Key < list[j].Key
+		})
+		return list
+	}
+
+	n := len(job.Rooms)
+	for i := 0; i < generatorAttempts; i++ {
+		try(rng.Perm(n), rng.Int63())
+	}
+	if n > 1 {
+		top := sorted()
+		if len(top) > generatorRefine {
+			top = top[:generatorRefine]
+		}
+		for _, c := range top {
+			best := c
+			for i := 0; i < generatorSwaps; i++ {
+				order := append([]int(nil), best.Order...)
+				x, y := rng.Intn(n), rng.Intn(n)
+				order[x], order[y] = order[y], order[x]
+				if next := try(order, best.Seed); next.Score < best.Score {
+					best = next
+				}
+			}
+		}
+	}
+	return sorted()
+}
+
+// generateFloorPlans はプログラムから間取りの候補を点数の良い順に作る。
+// templates は部屋の名前・色の元にするアセット (getDefaultGlobalAssets)、
+// globals は参照できるグローバルアセットで、寸法が一致する部屋はローカルアセットを作らずに参照する。
+func generateFloorPlans(p FloorPlanProgram, templates []Asset, globals []Asset) ([]GeneratedLayout, error) {
+	tmpl := map[string]Asset{}
+	for _, a := range templates {
+		tmpl[a.ID] = a
+	}
+	global := map[string]Asset{}
+	for _, a := range globals {
+		global[a.ID] = a
+		if _, ok := tmpl[a.ID]; !ok {
+			tmpl[a.ID] = a // RoomRequirement.AssetID で指定できる利用者のアセット
+		}
+	}
+	job, err := newFloorPlanJob(p, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	count := p.Count
+	if count <= 0 {
+		count = defaultLayoutCount
+	}
+
+	candidates := job.search(p.Seed)
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+	layouts := make([]GeneratedLayout, 0, len(candidates))
+	for n, c := range candidates {
+		layouts = append(layouts, job.buildLayout(c, n, p.Seed, tmpl, global))
+	}
+	return layouts, nil
+}
+
+// buildLayout は割り付けをプロジェクトデータにする
+func (job *floorPlanJob) buildLayout(c genCandidate, n int, seed int64, tmpl, global map[string]Asset) GeneratedLayout {
+	score, unsatisfied := job.evaluate(c.Rects)
+	layout := GeneratedLayout{
+		Data:        ProjectData{LocalAssets: []Asset{}, Instances: []Instance{}},
+		Rooms:       []GeneratedRoom{},
+		Score:       math.Round(score*10) / 10,
+		Unsatisfied: unsatisfied,
+	}
+	if layout.Unsatisfied == nil {
+		layout.Unsatisfied = []string{}
+	}
+	for i, r := range job.Rooms {
+		rect := c.Rects[i]
+		t := tmpl[r.Spec.Template]
+		color := t.Color
+		if color == "" {
+			color = defaultTypeColors["room"]
+		}
+
+		assetID := ""
+		if g, ok := global[t.ID]; ok && g.W == rect.W && g.H == rect.H && g.Type == "room" {
+			assetID = g.ID
+		} else {
+			assetID = fmt.Sprintf("a-gen-%d-%d-%d", seed, n+1, i+1)
+			layout.Data.LocalAssets = append(layout.Data.LocalAssets, roomRectAsset(assetID, r.Name, rect.W, rect.H, color))
+		}
+		inst := Instance{
+			ID:      fmt.Sprintf("i-gen-%d-%d", n+1, i+1),
+			AssetID: assetID,
+			Type:    "room",
+			X:       rect.X,
+			Y:       rect.Y,
+		}
+		layout.Data.Instances = append(layout.Data.Instances, inst)
+		layout.Rooms = append(layout.Rooms, GeneratedRoom{
+			ID:         r.ID,
+			Kind:       r.Kind,
+			Name:       r.Name,
+			InstanceID: inst.ID,
+			X:          rect.X,
+			Y:          rect.Y,
+			W:          rect.W,
+			H:          rect.H,
+			Area:       newFloorArea(cm2ToM2(rect.W*rect.H), tatamiAreasM2[TatamiEdoma]),
+		})
+	}
+	return layout
+}
+
+// roomRectAsset は原点を左下とする長方形の部屋アセット
+func roomRectAsset(id, name string, w, h float64, color string) Asset {
+	return Asset{
+		ID:    id,
+		Name:  name,
+		Type:  "room",
+		W:     w,
+		H:     h,
+		Color: color,
+		Snap:  true,
+		Entities: []Entity{{
+			Type:  "polygon",
+			Layer: "default",
+			Color: color,
+			Points: []Point{
+				{X: 0, Y: 0}, {X: w, Y: 0}, {X: w, Y: h}, {X: 0, Y: h},
+			},
+		}},
+		BoundX: f64ptr(0),
+		BoundY: f64ptr(0),
+	}
+}
+
+// GenerateFloorPlans generates candidate layouts for a room program, best first.
+// The same program and seed always produce the same layouts.
+func (a *App) GenerateFloorPlans(program FloorPlanProgram) ([]GeneratedLayout, error) {
+	layouts, err := generateFloorPlans(program, getDefaultGlobalAssets(), a.globalAssets())
+	if err != nil {
+		a.logError("間取り生成失敗: %v", err)
+		return nil, err
+	}
+	a.logInfo("間取りを生成しました: %d 案 (seed %d)", len(layouts), program.Seed)
+	return layouts, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGenerateFloorPlans は 3LDK の生成結果が敷地を重なりなく埋め、seed で再現できることを検証します
+func TestGenerateFloorPlans(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	program := FloorPlanProgram{Preset: "3LDK", Seed: 42}
+	layouts, err := generateFloorPlans(program, globals, globals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) != defaultLayoutCount {
+		t.Fatalf("候補の数が不正です: %d", len(layouts))
+	}
+	again, _ := generateFloorPlans(program, globals, globals)
+	if !reflect.DeepEqual(layouts, again) {
+		t.Error("同じ seed で結果が変わりました")
+	}
+
+	for n, l := range layouts {
+		if n > 0 && l.Score < layouts[n-1].Score {
+			t.Errorf("候補が点数順になっていません: %v < %v", l.Score, layouts[n-1].Score)
+		}
+		names := map[string]bool{}
+		site := genRect{}
+		total := 0.0
+		for i, r := range l.Rooms {
+			names[r.Name] = true
+			site.W = math.Max(site.W, r.X+r.W)
+			site.H = math.Max(site.H, r.Y+r.H)
+			total += r.W * r.H
+			for _, v := range []float64{r.X, r.Y, r.W, r.H} {
+				if k := v / defaultPlanGrid; math.Abs(k-math.Round(k)) > 1e-6 {
+					t.Errorf("%s が格子に乗っていません: %v", r.Name, v)
+				}
+			}
+			for _, o := range l.Rooms[i+1:] {
+				if r.X < o.X+o.W && o.X < r.X+r.W && r.Y < o.Y+o.H && o.Y < r.Y+r.H {
+					t.Errorf("%s と %s が重なっています", r.Name, o.Name)
+				}
+			}
+		}
+		if math.Abs(total-site.W*site.H) > 1e-6 {
+			t.Errorf("部屋が敷地を埋めていません: %v / %v", total, site.W*site.H)
+		}
+		for _, want := range []string{"玄関", "LDK", "洋室1", "洋室2", "洋室3", "トイレ", "洗面所", "浴室"} {
+			if !names[want] {
+				t.Errorf("%s がありません", want)
+			}
+		}
+
+		// インスタンスはローカルアセットか既定アセットを参照する
+		assets := newAssetIndex(l.Data.LocalAssets, globals)
+		if len(l.Data.Instances) != len(l.Rooms) {
+			t.Fatalf("インスタンスの数が不正です: %d", len(l.Data.Instances))
+		}
+		for i, inst := range l.Data.Instances {
+			a := assets.lookup(inst)
+			if a == nil || a.Type != "room" || a.W != l.Rooms[i].W || a.H != l.Rooms[i].H {
+				t.Errorf("インスタンス %s のアセットが不正です", inst.ID)
+			}
+		}
+	}
+
+	program.Seed = 7
+	other, _ := generateFloorPlans(program, globals, globals)
+	if reflect.DeepEqual(layouts[0].Rooms, other[0].Rooms) && reflect.DeepEqual(layouts[2].Rooms, other[2].Rooms) {
+		t.Error("seed を変えても結果が変わりません")
+	}
+}
+
+// TestGenerateFloorPlansProgram は部屋の明示指定・隣接条件・敷地の検証を確認します
+func TestGenerateFloorPlansProgram(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	program := FloorPlanProgram{
+		Rooms: []RoomRequirement{
+			{Kind: "entrance"},
+			{ID: "ldk", Kind: "ldk", AreaM2: 20},
+			{ID: "kitchen", Kind: "k", Name: "キッチン"},
+			{Kind: "bedroom"},
+			{Kind: "toilet"},
+		},
+		Adjacency:  [][2]string{{"kitchen", "ldk"}, {"toilet", "entrance"}},
+		SiteWidth:  910,
+		SiteHeight: 546,
+		Count:      1,
+		Seed:       1,
+	}
+	layouts, err := generateFloorPlans(program, globals, globals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts) != 1 {
+		t.Fatalf("候補の数が不正です: %d", len(layouts))
+	}
+	for _, u := range layouts[0].Unsatisfied {
+		if strings.Contains(u, "接していません") {
+			t.Errorf("隣接条件を満たしていません: %s", u)
+		}
+	}
+	// 余った面積はホールになる
+	var hall *GeneratedRoom
+	for i, r := range layouts[0].Rooms {
+		if r.Kind == "hall" {
+			hall = &layouts[0].Rooms[i]
+		}
+		if r.X+r.W > 910+1e-6 || r.Y+r.H > 546+1e-6 {
+			t.Errorf("%s が敷地からはみ出しています", r.Name)
+		}
+	}
+	if hall == nil {
+		t.Error("余った面積がホールになっていません")
+	}
+
+	for name, p := range map[string]FloorPlanProgram{
+		"敷地が狭い":   {Preset: "3LDK", SiteWidth: 500, SiteHeight: 500},
+		"未知の種類":   {Rooms: []RoomRequirement{{Kind: "garage"}}},
+		"未知の間取り":  {Preset: "LDK3"},
+		"未知の隣接条件": {Preset: "1LDK", Adjacency: [][2]string{{"ldk", "garage"}}},
+	} {
+		if _, err := generateFloorPlans(p, globals, globals); err == nil {
+			t.Errorf("%s: エラーになっていません", name)
+		}
+	}
+}
@@ -0,0 +1,684 @@
+package main
+
+import "math"
+
+// --- 幾何計算 ---
+// 座標はすべてセンチメートル、Y 軸上向きのデカルト座標系 (フロントエンドと同じ)。
+// 角度は度単位で反時計回りを正とする。
+
+// pathSegment は P0 から P3 への3次ベジェ曲線。Line が true の場合は直線で P1/P2 は使わない。
+type pathSegment struct {
+	P0, P1, P2, P3 Vec2
+	Line           bool
+}
+
+// shapePath は閉じた輪郭を構成するセグメントの列
+type shapePath []pathSegment
+
+func vadd(a, b Vec2) Vec2             { return Vec2{X: a.X + b.X, Y: a.Y + b.Y} }
+func vsub(a, b Vec2) Vec2             { return Vec2{X: a.X - b.X, Y: a.Y - b.Y} }
+func vscale(a Vec2, k float64) Vec2   { return Vec2{X: a.X * k, Y: a.Y * k} }
+func vlerp(a, b Vec2, t float64) Vec2 { return Vec2{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t} }
+func vlen(a Vec2) float64             { return math.Hypot(a.X, a.Y) }
+func vdot(a, b Vec2) float64          { return a.X*b.X + a.Y*b.Y }
+func vcross(a, b Vec2) float64        { return a.X*b.Y - a.Y*b.X }
+
+// rotateVec は原点を中心に deg 度回転させる
+func rotateVec(v Vec2, deg float64) Vec2 {
+	if deg == 0 {
+		return v
+	}
+	rad := deg * math.Pi / 180
+	c, s := math.Cos(rad), math.Sin(rad)
+	return Vec2{X: v.X*c - v.Y*s, Y: v.X*s + v.Y*c}
+}
+
+func lineSegment(a, b Vec2) pathSegment {
+	return pathSegment{P0: a, P1: a, P2: b, P3: b, Line: true}
+}
+
+// quadSegment は2次ベジェ曲線を等価な3次ベジェ曲線に次数上げする
+func quadSegment(a, q, b Vec2) pathSegment {
+	return pathSegment{
+		P0: a,
+		P1: vlerp(a, q, 2.0/3.0),
+		P2: vlerp(b, q, 2.0/3.0),
+		P3: b,
+	}
+}
+
+// at はパラメータ t (0..1) における位置を返す
+func (s pathSegment) at(t float64) Vec2 {
+	if s.Line {
+		return vlerp(s.P0, s.P3, t)
+	}
+	mt := 1 - t
+	a := mt * mt * mt
+	b := 3 * mt * mt * t
+	c := 3 * mt * t * t
+	d := t * t * t
+	return Vec2{
+		X: a*s.P0.X + b*s.P1.X + c*s.P2.X + d*s.P3.X,
+		Y: a*s.P0.Y + b*s.P1.Y + c*s.P2.Y + d*s.P3.Y,
+	}
+}
+
+// deriv はパラメータ t における接ベクトルを返す
+func (s pathSegment) deriv(t float64) Vec2 {
+	if s.Line {
+		return vsub(s.P3, s.P0)
+	}
+	mt := 1 - t
+	a := vscale(vsub(s.P1, s.P0), 3*mt*mt)
+	b := vscale(vsub(s.P2, s.P1), 6*mt*t)
+	c := vscale(vsub(s.P3, s.P2), 3*t*t)
+	return vadd(vadd(a, b), c)
+}
+
+// 5点 Gauss-Legendre 求積の節点と重み (区間 [-1, 1])
+var gaussNodes = [5]float64{0, -0.5384693101056831, 0.5384693101056831, -0.9061798459386640, 0.9061798459386640}
+var gaussWeights = [5]float64{0.5688888888888889, 0.4786286704993665, 0.4786286704993665, 0.2369268850561891, 0.2369268850561891}
+
+// integrate は f を区間 [a, b] で積分する。5点 Gauss-Legendre は5次以下の多項式に対して厳密。
+func integrate(f func(float64) float64, a, b float64) float64 {
+	half := (b - a) / 2
+	mid := (a + b) / 2
+	sum := 0.0
+	for i, x := range gaussNodes {
+		sum += gaussWeights[i] * f(mid+half*x)
+	}
+	return sum * half
+}
+
+// integrateAdaptive は区間を分割しながら tol 以下の誤差になるまで積分する
+func integrateAdaptive(f func(float64) float64, a, b, tol float64, depth int) float64 {
+	whole := integrate(f, a, b)
+	mid := (a + b) / 2
+	left := integrate(f, a, mid)
+	right := integrate(f, mid, b)
+	if depth <= 0 || math.Abs(left+right-whole) <= tol {
+		return left + right
+	}
+	return integrateAdaptive(f, a, mid, tol/2, depth-1) + integrateAdaptive(f, mid, b, tol/2, depth-1)
+}
+
+// signedArea はグリーンの定理によるセグメントの符号付き面積への寄与 (反時計回りで正)。
+// 被積分関数は3次ベジェでも5次多項式なので求積は厳密。
+func (s pathSegment) signedArea() float64 {
+	if s.Line {
+		return vcross(s.P0, s.P3) / 2
+	}
+	return integrate(func(t float64) float64 {
+		return vcross(s.at(t), s.deriv(t)) / 2
+	}, 0, 1)
+}
+
+// length はセグメントの長さを返す
+func (s pathSegment) length() float64 {
+	if s.Line {
+		return vlen(vsub(s.P3, s.P0))
+	}
+	return integrateAdaptive(func(t float64) float64 {
+		return vlen(s.deriv(t))
+	}, 0, 1, 1e-9, 12)
+}
+
+// signedArea は閉じた輪郭の符号付き面積 (cm²)
+func (p shapePath) signedArea() float64 {
+	sum := 0.0
+	for _, s := range p {
+		sum += s.signedArea()
+	}
+	return sum
+}
+
+// length は輪郭の周長 (cm)
+func (p shapePath) length() float64 {
+	sum := 0.0
+	for _, s := range p {
+		sum += s.length()
+	}
+	return sum
+}
+
+// transform は各制御点に f を適用した輪郭を返す (アフィン変換はベジェ曲線を保つ)
+func (p shapePath) transform(f func(Vec2) Vec2) shapePath {
+	res := make(shapePath, len(p))
+	for i, s := range p {
+		res[i] = pathSegment{P0: f(s.P0), P1: f(s.P1), P2: f(s.P2), P3: f(s.P3), Line: s.Line}
+	}
+	return res
+}
+
+// flatten は輪郭を折れ線に変換する。曲線は1本あたり steps 分割する。
+// 戻り値は閉じた多角形の頂点列 (始点は繰り返さない)。
+func (p shapePath) flatten(steps int) []Vec2 {
+	var pts []Vec2
+	for _, s := range p {
+		if s.Line {
+			pts = append(pts, s.P0)
+			continue
+		}
+		for i := 0; i < steps; i++ {
+			pts = append(pts, s.at(float64(i)/float64(steps)))
+		}
+	}
+	return pts
+}
+
+// bbox は軸平行な外接矩形
+type bbox struct {
+	Min, Max Vec2
+	Valid    bool
+}
+
+// add は点を含むように外接矩形を広げる
+func (b *bbox) add(v Vec2) {
+	if !b.Valid {
+		b.Min, b.Max, b.Valid = v, v, true
+		return
+	}
+	b.Min.X = math.Min(b.Min.X, v.X)
+	b.Min.Y = math.Min(b.Min.Y, v.Y)
+	b.Max.X = math.Max(b.Max.X, v.X)
+	b.Max.Y = math.Max(b.Max.Y, v.Y)
+}
+
+func (b bbox) width() float64  { return b.Max.X - b.Min.X }
+func (b bbox) height() float64 { return b.Max.Y - b.Min.Y }
+func (b bbox) center() Vec2    { return vlerp(b.Min, b.Max, 0.5) }
+
+// bounds は輪郭の外接矩形 (曲線は分割点から求める)
+func (p shapePath) bounds() bbox {
+	var b bbox
+	for _, v := range p.flatten(16) {
+		b.add(v)
+	}
+	return b
+}
+
+// distToSegment は点 p と線分 ab の距離
+func distToSegment(p, a, b Vec2) float64 {
+	ab := vsub(b, a)
+	t := 0.0
+	if l := vdot(ab, ab); l > 0 {
+		t = math.Max(0, math.Min(1, vdot(vsub(p, a), ab)/l))
+	}
+	return vlen(vsub(p, vadd(a, vscale(ab, t))))
+}
+
+// distToPolygon は点と多角形の境界の距離
+func distToPolygon(p Vec2, poly []Vec2) float64 {
+	d := math.Inf(1)
+	for i := range poly {
+		d = math.Min(d, distToSegment(p, poly[i], poly[(i+1)%len(poly)]))
+	}
+	return d
+}
+
+// pointInPolygon は点が多角形の内部にあるかどうか (境界上は内部とみなす)
+func pointInPolygon(p Vec2, poly []Vec2) bool {
+	const eps = 0.01
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if distToSegment(p, a, b) < eps {
+			return true
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// rayDistance は点 p から向き dir (単位ベクトル) に進んで多角形の境界に当たるまでの距離。当たらない場合は +Inf。
+func rayDistance(p, dir Vec2, poly []Vec2) float64 {
+	best := math.Inf(1)
+	for i := range poly {
+		a, b := poly[i], poly[(i+1)%len(poly)]
+		e := vsub(b, a)
+		denom := vcross(dir, e)
+		if math.Abs(denom) < 1e-12 {
+			continue
+		}
+		ap := vsub(a, p)
+		t := vcross(ap, e) / denom
+		u := vcross(ap, dir) / denom
+		if t > 1e-6 && u >= -1e-9 && u <= 1+1e-9 {
+			best = math.Min(best, t)
+		}
+	}
+	return best
+}
+
+// polygonArea は頂点列の符号付き面積 (反時計回りで正)
+func polygonArea(poly []Vec2) float64 {
+	sum := 0.0
+	for i := range poly {
+		sum += vcross(poly[i], poly[(i+1)%len(poly)])
+	}
+	return sum / 2
+}
+
+// pointInTriangle は点が三角形 abc (反時計回り) の内部または辺上にあるかどうか
+func pointInTriangle(p, a, b, c Vec2) bool {
+	const eps = 1e-9
+	return vcross(vsub(b, a), vsub(p, a)) >= -eps &&
+		vcross(vsub(c, b), vsub(p, b)) >= -eps &&
+		vcross(vsub(a, c), vsub(p, c)) >= -eps
+}
+
+// triangulate は自己交差のない多角形を耳切り法で反時計回りの三角形に分割する
+func triangulate(poly []Vec2) [][3]Vec2 {
+	var pts []Vec2
+	for i, v := range poly {
+		if i == 0 || vlen(vsub(v, pts[len(pts)-1])) > 1e-9 {
+			pts = append(pts, v)
+		}
+	}
+	if len(pts) > 1 && vlen(vsub(pts[0], pts[len(pts)-1])) <= 1e-9 {
+		pts = pts[:len(pts)-1]
+	}
+	if len(pts) < 3 {
+		return nil
+	}
+	if polygonArea(pts) < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+
+	idx := make([]int, len(pts))
+	for i := range idx {
+		idx[i] = i
+	}
+	var tris [][3]Vec2
+	for len(idx) > 3 {
+		m := len(idx)
+		found := false
+		for i := 0; i < m; i++ {
+			a, b, c := pts[idx[(i+m-1)%m]], pts[idx[i]], pts[idx[(i+1)%m]]
+			turn := vcross(vsub(b, a), vsub(c, b))
+			if math.Abs(turn) < 1e-9 {
+				// 一直線上の頂点は面積に寄与しないので取り除く
+				idx = append(idx[:i], idx[i+1:]...)
+				found = true
+				break
+			}
+			if turn < 0 {
+				continue
+			}
+			ear := true
+			for _, j := range idx {
+				p := pts[j]
+				if p == a || p == b || p == c {
+					continue
+				}
+				if pointInTriangle(p, a, b, c) {
+					ear = false
+					break
+				}
+			}
+			if ear {
+				tris = append(tris, [3]Vec2{a, b, c})
+				idx = append(idx[:i], idx[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			break // 自己交差している
+		}
+	}
+	if len(idx) == 3 {
+		a, b, c := pts[idx[0]], pts[idx[1]], pts[idx[2]]
+		if vcross(vsub(b, a), vsub(c, b)) > 1e-9 {
+			tris = append(tris, [3]Vec2{a, b, c})
+		}
+	}
+	return tris
+}
+
+// clipConvex は subject を反時計回りの凸多角形 clip で切り取る (Sutherland–Hodgman)
+func clipConvex(subject, clip []Vec2) []Vec2 {
+	out := subject
+	for i := range clip {
+		if len(out) == 0 {
+			break
+		}
+		a, b := clip[i], clip[(i+1)%len(clip)]
+		edge := vsub(b, a)
+		inside := func(p Vec2) bool { return vcross(edge, vsub(p, a)) >= 0 }
+		cut := func(p, q Vec2) Vec2 {
+			t := vcross(edge, vsub(a, p)) / vcross(edge, vsub(q, p))
+			return vlerp(p, q, t)
+		}
+		in := out
+		out = nil
+		for j, cur := range in {
+			prev := in[(j+len(in)-1)%len(in)]
+			switch {
+			case inside(cur):
+				if !inside(prev) {
+					out = append(out, cut(prev, cur))
+				}
+				out = append(out, cur)
+			case inside(prev):
+				out = append(out, cut(prev, cur))
+			}
+		}
+	}
+	return out
+}
+
+// trianglesOverlapArea は2組の三角形分割が重なる面積
+func trianglesOverlapArea(a, b [][3]Vec2) float64 {
+	sum := 0.0
+	for _, ta := range a {
+		for _, tb := range b {
+			if clipped := clipConvex(ta[:], tb[:]); len(clipped) >= 3 {
+				sum += math.Abs(polygonArea(clipped))
+			}
+		}
+	}
+	return sum
+}
+
+// polygonsOverlapArea は自己交差のない2つの多角形が重なる面積
+func polygonsOverlapArea(a, b []Vec2) float64 {
+	return trianglesOverlapArea(triangulate(a), triangulate(b))
+}
+
+// lineIntersection は点 p を通る向き d の直線と、点 q を通る向き e の直線の交点を返す。平行なら false。
+func lineIntersection(p, d, q, e Vec2) (Vec2, bool) {
+	den := vcross(d, e)
+	if math.Abs(den) < 1e-9*vlen(d)*vlen(e) {
+		return Vec2{}, false
+	}
+	t := vcross(vsub(q, p), e) / den
+	return vadd(p, vscale(d, t)), true
+}
+
+// collinearOverlap は線分 a-b を、ほぼ同じ直線上にある線分 p-q に投影して重なる区間 (p からの距離) を返す。
+// 平行でない・直線から tol より離れている・重ならない場合は false。
+func collinearOverlap(a, b, p, q Vec2, tol float64) (lo, hi float64, ok bool) {
+	d, e := vsub(b, a), vsub(q, p)
+	l, el := vlen(d), vlen(e)
+	if l == 0 || el == 0 || math.Abs(vcross(d, e))/(l*el) > 0.02 {
+		return 0, 0, false
+	}
+	u := vscale(e, 1/el)
+	if math.Abs(vcross(u, vsub(a, p))) > tol || math.Abs(vcross(u, vsub(b, p))) > tol {
+		return 0, 0, false
+	}
+	sa, sb := vdot(vsub(a, p), u), vdot(vsub(b, p), u)
+	lo, hi = math.Max(math.Min(sa, sb), 0), math.Min(math.Max(sa, sb), el)
+	return lo, hi, hi > lo
+}
+
+// polylinePath は多角形の頂点列から直線だけの閉じた輪郭を作る
+func polylinePath(poly []Vec2) shapePath {
+	path := make(shapePath, len(poly))
+	for i, v := range poly {
+		path[i] = lineSegment(v, poly[(i+1)%len(poly)])
+	}
+	return path
+}
+
+// polygonPath はフロントエンドの generateSvgPath と同じ規則で頂点列から閉じた輪郭を作る。
+//   - handles なし: isCurve の場合は h2 (始点側) / h1 (終点側) の相対オフセットを制御点とする3次ベジェ
+//   - handles 1個: 2次ベジェ、2個: 3次ベジェ (handles は絶対座標)
+//   - handles 3個以上: 辺上の等分点を通る2次ベジェの連結
+func polygonPath(points []Point) shapePath {
+	var path shapePath
+	n := len(points)
+	for i := 0; i < n; i++ {
+		curr := points[i]
+		next := points[(i+1)%n]
+		p0 := Vec2{X: curr.X, Y: curr.Y}
+		p3 := Vec2{X: next.X, Y: next.Y}
+
+		switch len(curr.Handles) {
+		case 0:
+			if curr.IsCurve || next.IsCurve {
+				path = append(path, pathSegment{P0: p0, P1: vadd(p0, curr.H2), P2: vadd(p3, next.H1), P3: p3})
+			} else {
+				path = append(path, lineSegment(p0, p3))
+			}
+		case 1:
+			path = append(path, quadSegment(p0, curr.Handles[0], p3))
+		case 2:
+			path = append(path, pathSegment{P0: p0, P1: curr.Handles[0], P2: curr.Handles[1], P3: p3})
+		default:
+			last := p0
+			step := 1 / float64(len(curr.Handles))
+			for j, h := range curr.Handles {
+				end := vlerp(p0, p3, float64(j+1)*step)
+				path = append(path, quadSegment(last, h, end))
+				last = end
+			}
+		}
+	}
+	return path
+}
+
+// ellipseShape は楕円・円・扇形・弓形エンティティのパラメータ
+type ellipseShape struct {
+	Center   Vec2
+	RX, RY   float64
+	Rotation float64 // 度
+	Start    float64 // 開始角 (度, 媒介変数角)
+	Sweep    float64 // 反時計回りの掃引角 (度, 0 < Sweep <= 360)
+	Sector   bool    // true: 扇形 (中心を通る), false: 弓形 (弦で閉じる)
+}
+
+// full は掃引角が一周分かどうか
+func (e ellipseShape) full() bool { return e.Sweep >= 360 }
+
+// point は媒介変数角 deg における楕円上の点
+func (e ellipseShape) point(deg float64) Vec2 {
+	rad := deg * math.Pi / 180
+	local := Vec2{X: e.RX * math.Cos(rad), Y: e.RY * math.Sin(rad)}
+	return vadd(e.Center, rotateVec(local, e.Rotation))
+}
+
+// ellipseFromEntity はエンティティから楕円パラメータを取り出す。
+// cx/cy/rx/ry が無い場合は getRotatedAABB と同様に x/y/w/h の外接矩形から求める。
+func ellipseFromEntity(e Entity) (ellipseShape, bool) {
+	if e.Type != "circle" && e.Type != "ellipse" && e.Type != "arc" {
+		return ellipseShape{}, false
+	}
+	x, y, w, h := deref(e.X), deref(e.Y), deref(e.W), deref(e.H)
+	es := ellipseShape{
+		Center: Vec2{X: x + w/2, Y: y + h/2},
+		RX:     w / 2,
+		RY:     h / 2,
+		Start:  derefOr(e.StartAngle, 0),
+		Sector: e.ArcMode != "chord",
+	}
+	if e.CX != nil {
+		es.Center.X = *e.CX
+	}
+	if e.CY != nil {
+		es.Center.Y = *e.CY
+	}
+	if e.RX != nil {
+		es.RX = *e.RX
+	}
+	if e.RY != nil {
+		es.RY = *e.RY
+	}
+	if e.Type != "circle" {
+		es.Rotation = deref(e.Rotation)
+	}
+
+	end := derefOr(e.EndAngle, 360)
+	if e.Type == "circle" || math.Abs(end-es.Start) >= 360 {
+		es.Sweep = 360
+	} else {
+		es.Sweep = math.Mod(end-es.Start, 360)
+		if es.Sweep <= 0 {
+			es.Sweep += 360
+		}
+	}
+	return es, true
+}
+
+// arcPath は楕円弧を90度以下に分割した3次ベジェで近似する (相対誤差 0.03% 未満)
+func (e ellipseShape) arcPath() shapePath {
+	n := int(math.Ceil(e.Sweep / 90))
+	step := e.Sweep / float64(n)
+	k := 4.0 / 3.0 * math.Tan(step*math.Pi/180/4)
+
+	tangent := func(deg float64) Vec2 {
+		rad := deg * math.Pi / 180
+		return rotateVec(Vec2{X: -e.RX * math.Sin(rad), Y: e.RY * math.Cos(rad)}, e.Rotation)
+	}
+
+	var path shapePath
+	for i := 0; i < n; i++ {
+		a := e.Start + float64(i)*step
+		b := a + step
+		p0, p3 := e.point(a), e.point(b)
+		path = append(path, pathSegment{
+			P0: p0,
+			P1: vadd(p0, vscale(tangent(a), k)),
+			P2: vsub(p3, vscale(tangent(b), k)),
+			P3: p3,
+		})
+	}
+	return path
+}
+
+// outline は楕円・扇形・弓形の閉じた輪郭を返す
+func (e ellipseShape) outline() shapePath {
+	path := e.arcPath()
+	if e.full() {
+		return path
+	}
+	start, end := e.point(e.Start), e.point(e.Start+e.Sweep)
+	if e.Sector {
+		return append(path, lineSegment(end, e.Center), lineSegment(e.Center, start))
+	}
+	return append(path, lineSegment(end, start))
+}
+
+// area は厳密な面積。媒介変数角 Δt の扇形の面積は rx·ry·Δt/2、弓形はそこから三角形を引く。
+func (e ellipseShape) area() float64 {
+	t := e.Sweep * math.Pi / 180
+	if e.full() {
+		return math.Pi * e.RX * e.RY
+	}
+	if e.Sector {
+		return e.RX * e.RY * t / 2
+	}
+	return e.RX * e.RY * (t - math.Sin(t)) / 2
+}
+
+// perimeter は輪郭の長さ。弧長は数値積分で求める。
+func (e ellipseShape) perimeter() float64 {
+	arc := integrateAdaptive(func(deg float64) float64 {
+		rad := deg * math.Pi / 180
+		return math.Hypot(e.RX*math.Sin(rad), e.RY*math.Cos(rad)) * math.Pi / 180
+	}, e.Start, e.Start+e.Sweep, 1e-9, 16)
+	if e.full() {
+		return arc
+	}
+	start, end := e.point(e.Start), e.point(e.Start+e.Sweep)
+	if e.Sector {
+		return arc + vlen(vsub(start, e.Center)) + vlen(vsub(end, e.Center))
+	}
+	return arc + vlen(vsub(end, start))
+}
+
+// rectPath は x/y/w/h で指定された矩形 (rect 省略形) の輪郭。rotation は中心回りに適用する。
+func rectPath(e Entity) (shapePath, bool) {
+	if e.X == nil || e.Y == nil || e.W == nil || e.H == nil {
+		return nil, false
+	}
+	x, y, w, h := *e.X, *e.Y, *e.W, *e.H
+	center := Vec2{X: x + w/2, Y: y + h/2}
+	corners := []Vec2{{X: x, Y: y}, {X: x + w, Y: y}, {X: x + w, Y: y + h}, {X: x, Y: y + h}}
+	var path shapePath
+	for i := range corners {
+		a := vadd(center, rotateVec(vsub(corners[i], center), deref(e.Rotation)))
+		b := vadd(center, rotateVec(vsub(corners[(i+1)%4], center), deref(e.Rotation)))
+		path = append(path, lineSegment(a, b))
+	}
+	return path, true
+}
+
+// entityOutline はエンティティの閉じた輪郭を返す。テキストなど輪郭を持たない場合は false。
+func entityOutline(e Entity) (shapePath, bool) {
+	switch e.Type {
+	case "text":
+		return nil, false
+	case "polygon":
+		if len(e.Points) < 2 {
+			return nil, false
+		}
+		return polygonPath(e.Points), true
+	}
+	if es, ok := ellipseFromEntity(e); ok {
+		return es.outline(), true
+	}
+	return rectPath(e)
+}
+
+// entityArea はエンティティの面積 (cm²)。自己交差のない輪郭を前提とする。
+func entityArea(e Entity) float64 {
+	if es, ok := ellipseFromEntity(e); ok {
+		return es.area()
+	}
+	path, ok := entityOutline(e)
+	if !ok {
+		return 0
+	}
+	return math.Abs(path.signedArea())
+}
+
+// entityPerimeter はエンティティの周長 (cm)
+func entityPerimeter(e Entity) float64 {
+	if es, ok := ellipseFromEntity(e); ok {
+		return es.perimeter()
+	}
+	path, ok := entityOutline(e)
+	if !ok {
+		return 0
+	}
+	return path.length()
+}
+
+// assetArea はアセットを構成するエンティティの面積の合計 (cm²)。
+// エンティティ同士の重なりは考慮しない。
+func assetArea(a Asset) float64 {
+	sum := 0.0
+	for _, e := range a.Entities {
+		sum += entityArea(e)
+	}
+	return sum
+}
+
+// assetPerimeter はアセットを構成するエンティティの周長の合計 (cm)
+func assetPerimeter(a Asset) float64 {
+	sum := 0.0
+	for _, e := range a.Entities {
+		sum += entityPerimeter(e)
+	}
+	return sum
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func derefOr(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
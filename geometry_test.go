@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b, tol float64) bool { return math.Abs(a-b) <= tol }
+
+func f64(v float64) *float64 { return &v }
+
+// TestPolygonAreaAndPerimeter は矩形ポリゴンの面積と周長を検証します
+func TestPolygonAreaAndPerimeter(t *testing.T) {
+	var room Asset
+	for _, a := range getDefaultGlobalAssets() {
+		if a.ID == "a_room6" {
+			room = a
+		}
+	}
+	if got := assetArea(room); !approxEqual(got, 360*270, 1e-9) {
+		t.Errorf("面積が不正です: got %f, want %f", got, 360.0*270)
+	}
+	if got := assetPerimeter(room); !approxEqual(got, 2*(360+270), 1e-9) {
+		t.Errorf("周長が不正です: got %f", got)
+	}
+}
+
+// TestBezierPolygonArea はベジェハンドル付きポリゴンの面積を検証します
+func TestBezierPolygonArea(t *testing.T) {
+	// 半径 100 の円を 4 本の3次ベジェで近似したもの (k = 0.5523)
+	k := 4.0 / 3.0 * (math.Sqrt2 - 1) * 100
+	pts := []Point{
+		{X: 100, Y: 0, IsCurve: true, H1: Vec2{0, -k}, H2: Vec2{0, k}},
+		{X: 0, Y: 100, IsCurve: true, H1: Vec2{k, 0}, H2: Vec2{-k, 0}},
+		{X: -100, Y: 0, IsCurve: true, H1: Vec2{0, k}, H2: Vec2{0, -k}},
+		{X: 0, Y: -100, IsCurve: true, H1: Vec2{-k, 0}, H2: Vec2{k, 0}},
+	}
+	e := Entity{Type: "polygon", Points: pts}
+	if got := entityArea(e); !approxEqual(got, math.Pi*100*100, 0.001*math.Pi*100*100) {
+		t.Errorf("ベジェ円の面積が不正です: got %f", got)
+	}
+	if got := entityPerimeter(e); !approxEqual(got, 2*math.Pi*100, 0.001*2*math.Pi*100) {
+		t.Errorf("ベジェ円の周長が不正です: got %f", got)
+	}
+
+	// 2次ベジェ (handles 1個) で膨らませた辺: 放物線の膨らみ部分は 2/3·底辺·高さ/2
+	quad := Entity{Type: "polygon", Points: []Point{
+		{X: 0, Y: 0, Handles: []Vec2{{X: 50, Y: -50}}},
+		{X: 100, Y: 0},
+		{X: 100, Y: 100},
+		{X: 0, Y: 100},
+	}}
+	if got := entityArea(quad); !approxEqual(got, 100*100+2.0/3.0*100*25, 1e-6) {
+		t.Errorf("2次ベジェ辺の面積が不正です: got %f", got)
+	}
+}
+
+// TestEllipseArea は楕円・扇形・弓形の面積を検証します
+func TestEllipseArea(t *testing.T) {
+	full := Entity{Type: "ellipse", CX: f64(0), CY: f64(0), RX: f64(60), RY: f64(30), StartAngle: f64(0), EndAngle: f64(360)}
+	if got := entityArea(full); !approxEqual(got, math.Pi*60*30, 1e-9) {
+		t.Errorf("楕円の面積が不正です: got %f", got)
+	}
+
+	sector := Entity{Type: "ellipse", CX: f64(0), CY: f64(0), RX: f64(50), RY: f64(50), StartAngle: f64(0), EndAngle: f64(90), ArcMode: "sector"}
+	if got := entityArea(sector); !approxEqual(got, math.Pi*50*50/4, 1e-9) {
+		t.Errorf("扇形の面積が不正です: got %f", got)
+	}
+	if got := entityPerimeter(sector); !approxEqual(got, math.Pi*50/2+100, 1e-6) {
+		t.Errorf("扇形の周長が不正です: got %f", got)
+	}
+
+	chord := sector
+	chord.ArcMode = "chord"
+	if got := entityArea(chord); !approxEqual(got, math.Pi*50*50/4-50*50/2, 1e-9) {
+		t.Errorf("弓形の面積が不正です: got %f", got)
+	}
+
+	// 開始角 > 終了角は 0 度をまたぐ弧として扱う
+	wrap := Entity{Type: "ellipse", RX: f64(10), RY: f64(10), StartAngle: f64(270), EndAngle: f64(90)}
+	if got := entityArea(wrap); !approxEqual(got, math.Pi*100/2, 1e-9) {
+		t.Errorf("0度をまたぐ扇形の面積が不正です: got %f", got)
+	}
+
+	// 輪郭のベジェ近似は解析解とほぼ一致する
+	path, _ := entityOutline(full)
+	if got := math.Abs(path.signedArea()); !approxEqual(got, math.Pi*60*30, 0.001*math.Pi*60*30) {
+		t.Errorf("楕円輪郭の面積が不正です: got %f", got)
+	}
+}
+
+// TestComputeProjectAreas は畳・坪換算と合計を検証します
+func TestComputeProjectAreas(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	data := ProjectData{Instances: []Instance{
+		{ID: "r1", AssetID: "a_room6", Type: "room", Rotation: 90},
+		{ID: "r2", AssetID: "a_ldk10", Type: "room"},
+		{ID: "f1", AssetID: "a_bed_s", Type: "furniture"},
+		{ID: "t1", Type: "text", Text: "memo"},
+	}}
+
+	areas, err := computeProjectAreas(data, newAssetIndex(nil, globals), TatamiEdoma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas.Rooms) != 2 {
+		t.Fatalf("部屋の件数が不正です: got %d, want 2", len(areas.Rooms))
+	}
+	if got := areas.Rooms[0].Area.M2; got != 9.72 {
+		t.Errorf("洋室の面積が不正です: got %f, want 9.72", got)
+	}
+	if got := areas.Rooms[0].Area.Jo; got != 6.28 {
+		t.Errorf("江戸間の畳数が不正です: got %f, want 6.28", got)
+	}
+	if got := areas.Total.M2; got != 25.92 {
+		t.Errorf("合計面積が不正です: got %f, want 25.92", got)
+	}
+	if got := areas.Total.Tsubo; got != 7.84 {
+		t.Errorf("坪数が不正です: got %f, want 7.84", got)
+	}
+
+	if _, err := computeProjectAreas(data, newAssetIndex(nil, globals), "unknown"); err == nil {
+		t.Error("不明な畳サイズでエラーが返されていません")
+	}
+}
+
+// TestPolygonsOverlapArea は凹多角形の三角形分割と重なり面積を検証します
+func TestPolygonsOverlapArea(t *testing.T) {
+	// L 字 (300×300 から右上 150×150 を欠いたもの)。時計回りでも扱える
+	l := []Vec2{{0, 0}, {0, 300}, {150, 300}, {150, 150}, {300, 150}, {300, 0}}
+	tris := triangulate(l)
+	sum := 0.0
+	for _, tri := range tris {
+		sum += polygonArea(tri[:])
+	}
+	if !approxEqual(sum, 300*300-150*150, 1e-6) {
+		t.Errorf("三角形分割の面積が不正です: got %f", sum)
+	}
+
+	// 欠いた部分にかかる正方形は L 字の部分だけが重なる
+	square := []Vec2{{100, 100}, {200, 100}, {200, 200}, {100, 200}}
+	if got := polygonsOverlapArea(l, square); !approxEqual(got, 100*100-50*50, 1e-6) {
+		t.Errorf("重なり面積が不正です: got %f", got)
+	}
+	// 辺が接するだけなら重ならない
+	touching := []Vec2{{300, 0}, {400, 0}, {400, 100}, {300, 100}}
+	if got := polygonsOverlapArea(l, touching); !approxEqual(got, 0, 1e-6) {
+		t.Errorf("接するだけの多角形が重なっています: got %f", got)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+)
+
+// --- glTF エクスポート ---
+// 3D モデルを glTF 2.0 のバイナリ形式 (.glb) で書き出す。
+// インスタンスごとにノードとメッシュを1つ作り、マテリアル (色) ごとにプリミティブを分ける。
+// 頂点は三角形ごとに持ち (インデックス無し)、位置と法線は float32 で1つのバッファに並べる。
+
+const (
+	glbMagic         = 0x46546C67 // "glTF"
+	glbVersion       = 2
+	glbChunkJSON     = 0x4E4F534A // "JSON"
+	glbChunkBIN      = 0x004E4942 // "BIN\x00"
+	gltfFloat        = 5126
+	gltfArrayBuffer  = 34962
+	gltfModeTriangle = 4
+)
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes,omitempty"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Materials   []gltfMaterial   `json:"materials,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name string `json:"name"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Material   int            `json:"material"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfMaterial struct {
+	Name                 string  `json:"name"`
+	PBRMetallicRoughness gltfPBR `json:"pbrMetallicRoughness"`
+}
+
+type gltfPBR struct {
+	BaseColorFactor [4]float64 `json:"baseColorFactor"`
+	MetallicFactor  float64    `json:"metallicFactor"`
+	RoughnessFactor float64    `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+// srgbToLinear は sRGB の色成分を glTF の baseColorFactor (リニア) にする
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// encodeGLB は 3D モデルを .glb のバイト列にする
+func encodeGLB(m *model) ([]byte, error) {
+	doc := gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "roomGenerator"},
+		Scenes: []gltfScene{{Nodes: []int{}}},
+	}
+	for _, mat := range m.Materials {
+		c := mat.Color
+		doc.Materials = append(doc.Materials, gltfMaterial{
+			Name: mat.Name,
+			PBRMetallicRoughness: gltfPBR{
+				BaseColorFactor: [4]float64{round6(srgbToLinear(c.R)), round6(srgbToLinear(c.G)), round6(srgbToLinear(c.B)), 1},
+				RoughnessFactor: 0.9,
+			},
+		})
+	}
+
+	var bin bytes.Buffer
+	// addVec3 は vec3 の配列をバッファに書き、アクセサの番号を返す
+	addVec3 := func(vs []vec3, bounds bool) int {
+		view := gltfBufferView{Buffer: 0, ByteOffset: bin.Len(), ByteLength: len(vs) * 12, Target: gltfArrayBuffer}
+		acc := gltfAccessor{BufferView: len(doc.BufferViews), ComponentType: gltfFloat, Count: len(vs), Type: "VEC3"}
+		lo := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+		hi := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+		for _, v := range vs {
+			for i, c := range [3]float32{float32(v.X), float32(v.Y), float32(v.Z)} {
+				binary.Write(&bin, binary.LittleEndian, c)
+				lo[i] = math.Min(lo[i], float64(c))
+				hi[i] = math.Max(hi[i], float64(c))
+			}
+		}
+		if bounds {
+			acc.Min, acc.Max = lo, hi
+		}
+		doc.BufferViews = append(doc.BufferViews, view)
+		doc.Accessors = append(doc.Accessors, acc)
+		return len(doc.Accessors) - 1
+	}
+	for _, mesh := range m.Meshes {
+		gm := gltfMesh{Name: mesh.Name}
+		for _, p := range mesh.Primitives {
+			gm.Primitives = append(gm.Primitives, gltfPrimitive{
+				Attributes: map[string]int{"POSITION": addVec3(p.Positions, true), "NORMAL": addVec3(p.Normals, false)},
+				Material:   p.Material,
+				Mode:       gltfModeTriangle,
+			})
+		}
+		doc.Scenes[0].Nodes = append(doc.Scenes[0].Nodes, len(doc.Nodes))
+		doc.Nodes = append(doc.Nodes, gltfNode{Name: mesh.Name, Mesh: len(doc.Meshes)})
+		doc.Meshes = append(doc.Meshes, gm)
+	}
+	if bin.Len() > 0 {
+		doc.Buffers = []gltfBuffer{{ByteLength: bin.Len()}}
+	}
+
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	// チャンクは4バイト境界に揃える (JSON は空白、BIN は 0 で埋める)
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	for bin.Len()%4 != 0 {
+		bin.WriteByte(0)
+	}
+
+	var out bytes.Buffer
+	total := 12 + 8 + len(js)
+	if bin.Len() > 0 {
+		total += 8 + bin.Len()
+	}
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, glbVersion, uint32(total)})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(js)), glbChunkJSON})
+	out.Write(js)
+	if bin.Len() > 0 {
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(bin.Len()), glbChunkBIN})
+		out.Write(bin.Bytes())
+	}
+	return out.Bytes(), nil
+}
+
+func round6(v float64) float64 { return math.Round(v*1e6) / 1e6 }
+
+// ExportProjectGLB returns the project as a binary glTF 2.0 model (.glb) for 3D viewers.
+// Rooms become floor slabs, walls are extruded to their height with the hosted openings cut out, and
+// fixtures and furniture are extruded to Asset.Height in their entity colours. Units are metres with Y up.
+// The result is the file content (base64-encoded on the frontend side).
+func (a *App) ExportProjectGLB(id string, opts ModelExportOptions) ([]byte, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return nil, err
+	}
+	m, err := buildModel(data, assets, opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	glb, err := encodeGLB(m)
+	if err != nil {
+		a.logError("glTF エクスポート失敗 (ID: %s): %v", id, err)
+		return nil, err
+	}
+	a.logInfo("glTF エクスポート: %s", id)
+	return glb, nil
+}
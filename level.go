@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- 階 ---
+// ProjectData.Levels は下の階から順に並べた階の一覧で、インスタンスは LevelID で階に属する。
+// LevelID が空または存在しない階を指すインスタンスは最初の階に属する。階が無いプロジェクトは平屋とみなす。
+// 階段は置いた階 (LevelID) から TopLevelID (省略時は1つ上の階) までの各階に現れる。
+// 重なり・寸法・採光などのチェックと壁の接合は階ごとに行う。
+
+const defaultLevelHeight = 290.0 // 階高 (cm)
+
+// levelIndex は ID の階の添字を返す。無い場合は -1。
+func levelIndex(levels []Level, id string) int {
+	for i, l := range levels {
+		if l.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// instanceLevel はインスタンスが置かれた階の添字
+func instanceLevel(inst Instance, levels []Level) int {
+	if i := levelIndex(levels, inst.LevelID); i >= 0 {
+		return i
+	}
+	return 0
+}
+
+// levelHeight は階高 (cm)。未指定なら既定値。
+func levelHeight(l Level) float64 {
+	if l.Height > 0 {
+		return l.Height
+	}
+	return defaultLevelHeight
+}
+
+// isStair はアセットが階段かどうかを ID・名前から判定する
+func isStair(asset Asset) bool {
+	name := strings.ToLower(asset.Name)
+	return asset.ID == "a_stair" || strings.Contains(name, "階段") || strings.Contains(name, "stair")
+}
+
+// stairSpan は階段がつなぐ階の範囲 (下の階と上の階の添字)
+func stairSpan(inst Instance, levels []Level) (lo, hi int) {
+	lo = instanceLevel(inst, levels)
+	hi = lo + 1
+	if i := levelIndex(levels, inst.TopLevelID); i > lo {
+		hi = i
+	}
+	if hi >= len(levels) {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// onLevel はインスタンスが idx 番目の階に現れるかどうか
+func onLevel(inst Instance, assets assetIndex, levels []Level, idx int) bool {
+	if asset := assets.lookup(inst); asset != nil && isStair(*asset) {
+		lo, hi := stairSpan(inst, levels)
+		return lo <= idx && idx <= hi
+	}
+	return instanceLevel(inst, levels) == idx
+}
+
+// levelData は idx 番目の階に現れるインスタンスだけを持つプロジェクトを返す
+func levelData(data ProjectData, assets assetIndex, idx int) ProjectData {
+	d := data
+	d.Levels = []Level{data.Levels[idx]}
+	d.Instances = []Instance{}
+	for _, inst := range data.Instances {
+		if onLevel(inst, assets, data.Levels, idx) {
+			d.Instances = append(d.Instances, inst)
+		}
+	}
+	return d
+}
+
+// splitLevels はプロジェクトを階ごとに分ける。階が無い場合はそのまま1つ返す。
+func splitLevels(data ProjectData, assets assetIndex) []ProjectData {
+	if len(data.Levels) == 0 {
+		return []ProjectData{data}
+	}
+	levels := make([]ProjectData, len(data.Levels))
+	for i := range data.Levels {
+		levels[i] = levelData(data, assets, i)
+	}
+	return levels
+}
+
+// findLevel は ID または名前から階の添字を返す。ref が空なら最初の階、階が無いプロジェクトでは -1。
+func findLevel(levels []Level, ref string) (int, error) {
+	if ref == "" {
+		if len(levels) == 0 {
+			return -1, nil
+		}
+		return 0, nil
+	}
+	for i, l := range levels {
+		if l.ID == ref {
+			return i, nil
+		}
+	}
+	for i, l := range levels {
+		if l.Name == ref {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level: %s", ref)
+}
+
+// planLevel は図面に描く階のデータと階の名前を返す。階が無いプロジェクトは全体を返す。
+func planLevel(data ProjectData, assets assetIndex, ref string) (ProjectData, string, error) {
+	idx, err := findLevel(data.Levels, ref)
+	if err != nil || idx < 0 {
+		return data, "", err
+	}
+	return levelData(data, assets, idx), data.Levels[idx].Name, nil
+}
+
+// levelTitle はタイトル欄の名前に階の名前を付ける
+func levelTitle(title planTitle, level string) planTitle {
+	if level != "" {
+		title.Name += " " + level
+	}
+	return title
+}
@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+// levelTestProject は 1F・2F に同じ LDK があり、1F から 2F へ階段がかかるプロジェクト
+func levelTestProject() ProjectData {
+	return ProjectData{
+		LocalAssets: []Asset{},
+		Levels: []Level{
+			{ID: "L1", Name: "1F"},
+			{ID: "L2", Name: "2F", Elevation: 290},
+		},
+		Instances: []Instance{
+			{ID: "ldk1", AssetID: "a_ldk10", Type: "room"},
+			{ID: "ldk2", AssetID: "a_ldk10", Type: "room", LevelID: "L2"},
+			{ID: "stair", AssetID: "a_stair", Type: "fixture", X: 10, Y: 10, LevelID: "L1"},
+			{ID: "sofa", AssetID: "a_sofa2", Type: "furniture", X: 50, Y: 50, LevelID: "L2"},
+			{ID: "bed", AssetID: "a_bed_s", Type: "furniture", X: 200, Y: 100},
+		},
+	}
+}
+
+// TestLevels は階ごとの図面・面積・重なり・壁を検証します
+func TestLevels(t *testing.T) {
+	data := levelTestProject()
+	assets := newAssetIndex(data.LocalAssets, getDefaultGlobalAssets())
+
+	ids := func(d ProjectData) []string {
+		var list []string
+		for _, inst := range d.Instances {
+			list = append(list, inst.ID)
+		}
+		return list
+	}
+	for ref, want := range map[string][]string{
+		"":   {"ldk1", "stair", "bed"},
+		"L2": {"ldk2", "stair", "sofa"},
+		"2F": {"ldk2", "stair", "sofa"},
+	} {
+		d, _, err := planLevel(data, assets, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(d); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Errorf("%q の階のインスタンスが不正です: %v", ref, got)
+		}
+	}
+	if _, _, err := planLevel(data, assets, "3F"); err == nil {
+		t.Error("存在しない階でエラーになっていません")
+	}
+
+	// 階段の上の階を指定しない場合は1つ上、最上階に置いた階段はその階だけ
+	if lo, hi := stairSpan(Instance{LevelID: "L2"}, data.Levels); lo != 1 || hi != 1 {
+		t.Errorf("最上階の階段の範囲が不正です: %d-%d", lo, hi)
+	}
+
+	areas, err := computeProjectAreas(data, assets, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas.Levels) != 2 || areas.Levels[0].Total.M2 != 16.2 || areas.Levels[1].Total.M2 != 16.2 || areas.Total.M2 != 32.4 {
+		t.Errorf("階ごとの面積が不正です: %+v", areas)
+	}
+	if areas.Rooms[0].LevelID != "L1" || areas.Rooms[1].LevelID != "L2" {
+		t.Errorf("部屋の階が不正です: %+v", areas.Rooms)
+	}
+
+	// 別の階の部屋は重ならない。2F のソファは階段と重なる
+	report := detectCollisions(data, assets)
+	if len(report.Overlaps) != 1 || report.Overlaps[0].InstanceA != "stair" || report.Overlaps[0].InstanceB != "sofa" {
+		t.Errorf("重なりが不正です: %+v", report.Overlaps)
+	}
+
+	derived, err := deriveWalls(data, assets, WallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	perLevel := map[string]int{}
+	for _, inst := range derived.Instances {
+		if inst.Type == wallType {
+			perLevel[inst.LevelID]++
+		}
+	}
+	if perLevel["L1"] != 4 || perLevel["L2"] != 4 {
+		t.Errorf("階ごとの壁の数が不正です: %v", perLevel)
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+// --- 間取り表記 ---
+// "2LDK+S" や "3DK 洋室6畳×2" のような表記と部屋の一覧を相互に変換する。
+//   n     : 居室 (洋室・和室) の数
+//   LDK 等: LDK / DK / K、居室のみは R
+//   +S 等 : 納戸 (S, N)・ウォークインクローゼット (WIC)・シューズインクローゼット (SIC)
+//   面積  : "LDK15畳" "洋室6帖×2" "WIC3㎡" (単位の無い数値は畳、畳は江戸間)
+
+var (
+	madoriHead  = regexp.MustCompile(`^(\d+)?(S?)(LDK|DK|K|R)((?:\+\d*[A-Z]+)*)$`)
+	madoriExtra = regexp.MustCompile(`\+(\d*)([A-Z]+)`)
+	madoriHint  = regexp.MustCompile(`^(LDK|LD|DK|K|洋室|和室|主寝室|寝室|子供部屋|書斎|S|N|納戸|サービスルーム|WIC|SIC)(\d+(?:\.\d+)?)(畳|J|JO|㎡|M2|M²)?(?:[×X*](\d+))?$`)
+)
+
+// madoriExtras は "+S" などの付加表記と部屋の種類
+var madoriExtras = map[string]string{"S": "storage", "N": "storage", "WIC": "wic", "SIC": "sic"}
+
+// madoriHintKinds は面積表記の見出しと部屋の種類
+var madoriHintKinds = map[string]string{
+	"LDK": "ldk", "LD": "ldk", "DK": "dk", "K": "k",
+	"洋室": "bedroom", "和室": "bedroom", "主寝室": "bedroom", "寝室": "bedroom", "子供部屋": "bedroom", "書斎": "bedroom",
+	"S": "storage", "N": "storage", "納戸": "storage", "サービスルーム": "storage",
+	"WIC": "wic", "SIC": "sic",
+}
+
+// normalizeMadori は全角英数字を半角に、帖を畳にそろえて大文字にする
+func normalizeMadori(s string) string {
+	s = width.Fold.String(s)
+	s = strings.NewReplacer("帖", "畳", "ワンルーム", "1R").Replace(s)
+	s = regexp.MustCompile(`\s*\+\s*`).ReplaceAllString(s, "+")
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// madoriRoom は表記の部屋を作る (ID は生成器の隣接条件と同じ規則)
+func madoriRoom(kind, id, name string) RoomRequirement {
+	return RoomRequirement{ID: id, Kind: kind, Name: name, AssetID: roomKinds[kind].Template}
+}
+
+// parseMadori は間取り表記を部屋の一覧にする
+func parseMadori(notation string) (MadoriProgram, error) {
+	s := normalizeMadori(notation)
+	tokens := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '、' || r == '・' || r == '/' || r == '(' || r == ')'
+	})
+	if len(tokens) == 0 {
+		return MadoriProgram{}, fmt.Errorf("empty madori notation")
+	}
+
+	var m MadoriProgram
+	hasHead := false
+	if h := madoriHead.FindStringSubmatch(tokens[0]); h != nil {
+		hasHead = true
+		tokens = tokens[1:]
+		m.Bedrooms = 1
+		if h[1] != "" {
+			m.Bedrooms, _ = strconv.Atoi(h[1])
+		}
+		m.Main = h[3]
+		if h[2] == "S" {
+			m.Extras = append(m.Extras, "S") // 2SLDK = 2LDK+S
+		}
+		for _, e := range madoriExtra.FindAllStringSubmatch(h[4], -1) {
+			if _, ok := madoriExtras[e[2]]; !ok {
+				return MadoriProgram{}, fmt.Errorf("unknown madori extra: +%s", e[2])
+			}
+			n := 1
+			if e[1] != "" {
+				n, _ = strconv.Atoi(e[1])
+			}
+			for i := 0; i < n; i++ {
+				m.Extras = append(m.Extras, strings.Replace(e[2], "N", "S", 1))
+			}
+		}
+	}
+
+	if m.Main != "" && m.Main != "R" {
+		kind := strings.ToLower(m.Main)
+		m.Rooms = append(m.Rooms, madoriRoom(kind, kind, m.Main))
+	}
+	for i := 1; i <= m.Bedrooms; i++ {
+		m.Rooms = append(m.Rooms, madoriRoom("bedroom", fmt.Sprintf("bedroom%d", i), fmt.Sprintf("洋室%d", i)))
+	}
+	for _, e := range m.Extras {
+		m.Rooms = append(m.Rooms, madoriRoom(madoriExtras[e], "", roomKinds[madoriExtras[e]].Name))
+	}
+
+	// 面積表記を該当する部屋 (まだ面積の無いもの) に割り当てる。見出しが無い場合は部屋を追加する。
+	for _, tok := range tokens {
+		h := madoriHint.FindStringSubmatch(tok)
+		if h == nil {
+			return MadoriProgram{}, fmt.Errorf("invalid madori notation: %q", tok)
+		}
+		kind := madoriHintKinds[h[1]]
+		value, _ := strconv.ParseFloat(h[2], 64)
+		area := jo(value)
+		if h[3] == "㎡" || h[3] == "M2" || h[3] == "M²" {
+			area = value
+		}
+		count := 1
+		if h[4] != "" {
+			count, _ = strconv.Atoi(h[4])
+		}
+		for c := 0; c < count; c++ {
+			idx := -1
+			for i, r := range m.Rooms {
+				if r.Kind == kind && r.AreaM2 == 0 {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				if hasHead && (kind == "bedroom" || kind == "ldk" || kind == "dk" || kind == "k") {
+					return MadoriProgram{}, fmt.Errorf("area hint %s does not match %s", h[1], tokens0(notation))
+				}
+				m.Rooms = append(m.Rooms, madoriRoom(kind, "", roomKinds[kind].Name))
+				idx = len(m.Rooms) - 1
+			}
+			m.Rooms[idx].AreaM2 = round2(area)
+			if kind == "bedroom" && h[1] != "洋室" {
+				m.Rooms[idx].Name = h[1]
+			}
+		}
+	}
+
+	m.summarize()
+	if len(m.Rooms) == 0 {
+		return MadoriProgram{}, fmt.Errorf("madori notation has no rooms: %q", notation)
+	}
+	return m, nil
+}
+
+// tokens0 はエラー表示用に表記の先頭を返す
+func tokens0(notation string) string {
+	if f := strings.Fields(normalizeMadori(notation)); len(f) > 0 {
+		return f[0]
+	}
+	return notation
+}
+
+// summarize は部屋の一覧から居室数・LDK などの種別・付加表記・表記を決め直し、
+// 自動で割り振った部屋 ID と名前を整える
+func (m *MadoriProgram) summarize() {
+	counts := map[string]int{}
+	for _, r := range m.Rooms {
+		counts[r.Kind]++
+	}
+	seen := map[string]int{}
+	m.Bedrooms, m.Main, m.Extras = counts["bedroom"], "", nil
+	for i := range m.Rooms {
+		r := &m.Rooms[i]
+		seen[r.Kind]++
+		if r.ID == "" || r.Kind == "bedroom" {
+			r.ID = r.Kind
+			if counts[r.Kind] > 1 || r.Kind == "bedroom" {
+				r.ID = fmt.Sprintf("%s%d", r.Kind, seen[r.Kind])
+			}
+		}
+		if r.Kind == "bedroom" && strings.HasPrefix(r.Name, "洋室") {
+			r.Name = "洋室"
+			if counts["bedroom"] > 1 {
+				r.Name = fmt.Sprintf("洋室%d", seen["bedroom"])
+			}
+		}
+	}
+	for _, main := range []string{"LDK", "DK", "K"} {
+		if counts[strings.ToLower(main)] > 0 {
+			m.Main = main
+			break
+		}
+	}
+	if m.Main == "" && m.Bedrooms > 0 {
+		m.Main = "R"
+	}
+	for _, e := range []string{"S", "WIC", "SIC"} {
+		for i := 0; i < counts[madoriExtras[e]]; i++ {
+			m.Extras = append(m.Extras, e)
+		}
+	}
+	if m.Extras == nil {
+		m.Extras = []string{}
+	}
+	m.Notation = formatMadori(m.Bedrooms, m.Main, m.Extras)
+}
+
+// formatMadori は居室数・種別・付加表記から "2LDK+S" のような表記を作る
+func formatMadori(bedrooms int, main string, extras []string) string {
+	var b strings.Builder
+	if bedrooms > 0 {
+		b.WriteString(strconv.Itoa(bedrooms))
+	}
+	b.WriteString(main)
+	counts := map[string]int{}
+	for _, e := range extras {
+		counts[e]++
+	}
+	for _, e := range []string{"S", "WIC", "SIC"} {
+		switch n := counts[e]; {
+		case n == 1:
+			b.WriteString("+" + e)
+		case n > 1:
+			fmt.Fprintf(&b, "+%d%s", n, e)
+		}
+	}
+	return b.String()
+}
+
+// madoriKindOf は部屋アセットの名前から間取り表記での種類を推定する。表記に現れない部屋は "" を返す。
+func madoriKindOf(name string) string {
+	n := normalizeMadori(name)
+	has := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(n, w) {
+				return true
+			}
+		}
+		return false
+	}
+	first := strings.FieldsFunc(n, func(r rune) bool { return r == ' ' || r == '(' })
+	word := ""
+	if len(first) > 0 {
+		word = first[0]
+	}
+	switch {
+	case has("WIC", "ウォークイン"):
+		return "wic"
+	case has("SIC", "シューズイン"):
+		return "sic"
+	case has("LDK"):
+		return "ldk"
+	case has("DK"):
+		return "dk"
+	case has("納戸", "サービスルーム") || word == "S" || word == "N":
+		return "storage"
+	case has("洋室", "和室", "寝室", "子供部屋", "書斎"):
+		return "bedroom"
+	case has("リビング") || word == "LD" || word == "L":
+		return "l"
+	case has("ダイニング") || word == "D":
+		return "d"
+	case has("キッチン") || word == "K":
+		return "k"
+	}
+	return ""
+}
+
+// describeMadori はプロジェクトの部屋インスタンスを間取り表記にする。部屋が無い場合は ""。
+func describeMadori(data ProjectData, assets assetIndex) string {
+	counts := map[string]int{}
+	for _, inst := range data.Instances {
+		asset := assets.lookup(inst)
+		if asset == nil || asset.Type != "room" {
+			continue
+		}
+		counts[madoriKindOf(asset.Name)]++
+	}
+
+	main := ""
+	switch {
+	case counts["ldk"] > 0 || (counts["l"] > 0 && counts["k"] > 0):
+		main = "LDK"
+	case counts["dk"] > 0 || (counts["d"] > 0 && counts["k"] > 0):
+		main = "DK"
+	case counts["k"] > 0:
+		main = "K"
+	case counts["bedroom"] > 0:
+		main = "R"
+	}
+	var extras []string
+	for _, e := range []string{"S", "WIC", "SIC"} {
+		for i := 0; i < counts[madoriExtras[e]]; i++ {
+			extras = append(extras, e)
+		}
+	}
+	return formatMadori(counts["bedroom"], main, extras)
+}
+
+// ParseMadori parses a floor plan notation such as "2LDK+S" or "3DK 洋室6畳×2" into rooms.
+func (a *App) ParseMadori(notation string) (MadoriProgram, error) {
+	return parseMadori(notation)
+}
+
+// GetProjectMadori describes the rooms of a project as a floor plan notation such as "2LDK+S".
+func (a *App) GetProjectMadori(id string) (string, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return "", err
+	}
+	return describeMadori(data, assets), nil
+}
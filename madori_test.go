@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestParseMadori は間取り表記が部屋の一覧と正規化された表記になることを検証します
+func TestParseMadori(t *testing.T) {
+	cases := []struct {
+		notation string
+		want     string
+		rooms    []string // 部屋 ID
+	}{
+		{"3LDK", "3LDK", []string{"ldk", "bedroom1", "bedroom2", "bedroom3"}},
+		{"２ＬＤＫ＋Ｓ", "2LDK+S", []string{"ldk", "bedroom1", "bedroom2", "storage"}},
+		{"2SLDK", "2LDK+S", []string{"ldk", "bedroom1", "bedroom2", "storage"}},
+		{"1LDK + WIC + SIC", "1LDK+WIC+SIC", []string{"ldk", "bedroom1", "wic", "sic"}},
+		{"3DK+2S", "3DK+2S", []string{"dk", "bedroom1", "bedroom2", "bedroom3", "storage1", "storage2"}},
+		{"ワンルーム", "1R", []string{"bedroom1"}},
+		{"1K", "1K", []string{"k", "bedroom1"}},
+	}
+	for _, c := range cases {
+		m, err := parseMadori(c.notation)
+		if err != nil {
+			t.Errorf("%s: %v", c.notation, err)
+			continue
+		}
+		if m.Notation != c.want {
+			t.Errorf("%s: 表記が不正です: %s", c.notation, m.Notation)
+		}
+		if len(m.Rooms) != len(c.rooms) {
+			t.Errorf("%s: 部屋の数が不正です: %+v", c.notation, m.Rooms)
+			continue
+		}
+		for i, id := range c.rooms {
+			if m.Rooms[i].ID != id {
+				t.Errorf("%s: 部屋 %d の ID が不正です: %s", c.notation, i, m.Rooms[i].ID)
+			}
+			if m.Rooms[i].AssetID != roomKinds[m.Rooms[i].Kind].Template {
+				t.Errorf("%s: %s のアセットが不正です: %s", c.notation, id, m.Rooms[i].AssetID)
+			}
+		}
+	}
+
+	for _, bad := range []string{"", "3LDK+X", "1LDK 洋室6畳×2", "3LDK 物置4畳"} {
+		if _, err := parseMadori(bad); err == nil {
+			t.Errorf("%q: エラーになっていません", bad)
+		}
+	}
+}
+
+// TestParseMadoriAreaHints は面積表記が該当する部屋に割り当てられることを検証します
+func TestParseMadoriAreaHints(t *testing.T) {
+	m, err := parseMadori("3LDK LDK15畳 洋室6帖×2 主寝室8畳 WIC3㎡")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Notation != "3LDK+WIC" {
+		t.Errorf("表記が不正です: %s", m.Notation)
+	}
+	want := map[string]float64{"ldk": jo(15), "bedroom1": jo(6), "bedroom2": jo(6), "bedroom3": jo(8), "wic": 3}
+	for _, r := range m.Rooms {
+		if math.Abs(r.AreaM2-want[r.ID]) > 0.01 {
+			t.Errorf("%s の面積が不正です: %v (期待値 %v)", r.ID, r.AreaM2, want[r.ID])
+		}
+	}
+	if m.Rooms[3].Name != "主寝室" {
+		t.Errorf("見出しの名前が使われていません: %s", m.Rooms[3].Name)
+	}
+}
+
+// TestDescribeMadori はプロジェクトの部屋インスタンスが間取り表記になることを検証します
+func TestDescribeMadori(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	data := ProjectData{
+		LocalAssets: []Asset{
+			{ID: "l-s", Name: "納戸", Type: "room"},
+			{ID: "l-wic", Name: "ウォークインクローゼット", Type: "room"},
+		},
+		Instances: []Instance{
+			{ID: "1", AssetID: "a_ldk10", Type: "room"},
+			{ID: "2", AssetID: "a_room6", Type: "room"},
+			{ID: "3", AssetID: "a_room6", Type: "room"},
+			{ID: "4", AssetID: "a_ent", Type: "room"},
+			{ID: "5", AssetID: "l-s", Type: "room"},
+			{ID: "6", AssetID: "l-wic", Type: "room"},
+			{ID: "7", AssetID: "a_bed_s", Type: "furniture"},
+			{ID: "8", Type: "text", Text: "洋室"},
+		},
+	}
+	if got := describeMadori(data, newAssetIndex(data.LocalAssets, globals)); got != "2LDK+S+WIC" {
+		t.Errorf("表記が不正です: %s", got)
+	}
+	if got := describeMadori(ProjectData{}, newAssetIndex(nil, globals)); got != "" {
+		t.Errorf("部屋の無いプロジェクトの表記が不正です: %q", got)
+	}
+
+	// 生成した間取りは元の表記に戻る
+	layouts, err := generateFloorPlans(FloorPlanProgram{Preset: "2LDK+S", Seed: 1, Count: 1}, globals, globals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := layouts[0]
+	if got := describeMadori(l.Data, newAssetIndex(l.Data.LocalAssets, globals)); got != "2LDK+S" {
+		t.Errorf("生成した間取りの表記が不正です: %s", got)
+	}
+
+	// 保存時に一覧へ記録し、一覧の取得ではプロジェクトを読み込まない
+	app := newTestApp(t)
+	proj, err := app.CreateProject("madori")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SaveProjectData(proj.ID, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(app.dataDir, projectFileName(ProjectID(proj.ID)))); err != nil {
+		t.Fatal(err)
+	}
+	if projects, _ := app.GetProjects(); len(projects) != 1 || projects[0].Madori != "2LDK+S+WIC" {
+		t.Errorf("一覧に間取りが記録されていません: %+v", projects)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,8 +13,14 @@ import (
 var assets embed.FS
 
 func main() {
+	// サブコマンド指定時は GUI を起動せずに実行する
+	if args := os.Args[1:]; isCLICommand(args) {
+		os.Exit(runCLI(args, os.Stdout, os.Stderr))
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
+	app.dataDirFlag = parseDataDirFlag(os.Args[1:])
 
 	// Create application with options
 	err := wails.Run(&options.App{
@@ -25,6 +32,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// --- スキーマのバージョンとマイグレーション ---
+// 保存するドキュメント (プロジェクトデータ・プロジェクト一覧・グローバルアセット・パレット・設定) はトップレベルに schemaVersion を持つ。
+// 読み込み時は schemaVersion より新しい版のマイグレーションを登録順に適用し、最新の版にしてから解釈する。
+// schemaVersion の無いドキュメントは版 0 とみなす。新しい版のアプリで保存されたドキュメントは読み込まない。
+// マイグレーションは生の JSON に対して行うため、変換しないフィールドはそのまま残る。
+
+// currentSchemaVersion は保存するドキュメントの現在の版
+const currentSchemaVersion = 1
+
+// docKind はマイグレーションの対象となるドキュメントの種類
+type docKind string
+
+const (
+	kindProject  docKind = "project"
+	kindAssets   docKind = "assets"
+	kindPalette  docKind = "palette"
+	kindSettings docKind = "settings"
+	kindIndex    docKind = "index"
+)
+
+// assetsDocument は保存するグローバルアセットの形式
+type assetsDocument struct {
+	Assets []Asset `json:"assets"`
+}
+
+// projectIndexDocument は保存するプロジェクト一覧 (projects_index.json) の形式
+type projectIndexDocument struct {
+	Projects []Project `json:"projects"`
+}
+
+// migration は1つの変換手順。Apply は変換後のドキュメントと、内容が変わったかどうかを返す。
+type migration struct {
+	Kind        docKind
+	Version     int    // この手順を適用した後の版
+	Description string // ログに残す内容
+	Apply       func(doc interface{}) (interface{}, bool)
+}
+
+// migrations は登録順に適用するマイグレーション。版は昇順に並べる。
+var migrations = []migration{
+	{kindProject, 1, "インスタンスの配列をプロジェクトデータに変換", wrapArray("instances", "assets")},
+	{kindProject, 1, "アセットの shapes を entities に変換", renameAssetShapes},
+	{kindAssets, 1, "アセットの配列をドキュメントに変換", wrapArray("assets")},
+	{kindAssets, 1, "アセットの shapes を entities に変換", renameAssetShapes},
+	{kindPalette, 1, "種類の表示名 (labels) を追加", addPaletteLabels},
+	{kindIndex, 1, "プロジェクトの配列をドキュメントに変換", wrapArray("projects")},
+}
+
+// wrapArray はトップレベルが配列のドキュメントを key の値にしたオブジェクトにする。
+// empty に指定したキーは空の配列で補う。
+func wrapArray(key string, empty ...string) func(doc interface{}) (interface{}, bool) {
+	return func(doc interface{}) (interface{}, bool) {
+		list, ok := doc.([]interface{})
+		if !ok {
+			return doc, false
+		}
+		obj := map[string]interface{}{key: list}
+		for _, k := range empty {
+			obj[k] = []interface{}{}
+		}
+		return obj, true
+	}
+}
+
+// renameAssetShapes は旧形式のアセットの shapes キーを entities にし、レイヤーの無いエンティティを default レイヤーにする
+func renameAssetShapes(doc interface{}) (interface{}, bool) {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return doc, false
+	}
+	list, _ := obj["assets"].([]interface{})
+	changed := false
+	for _, v := range list {
+		asset, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		shapes, ok := asset["shapes"]
+		if !ok {
+			continue
+		}
+		delete(asset, "shapes")
+		changed = true
+		if _, ok := asset["entities"]; ok {
+			continue
+		}
+		entities, _ := shapes.([]interface{})
+		for _, e := range entities {
+			if entity, ok := e.(map[string]interface{}); ok {
+				if layer, _ := entity["layer"].(string); layer == "" {
+					entity["layer"] = "default"
+				}
+			}
+		}
+		if entities == nil {
+			entities = []interface{}{}
+		}
+		asset["entities"] = entities
+	}
+	return obj, changed
+}
+
+// addPaletteLabels はパレットに labels が無ければ既定の表示名を追加する
+func addPaletteLabels(doc interface{}) (interface{}, bool) {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return doc, false
+	}
+	if _, ok := obj["labels"]; ok {
+		return obj, false
+	}
+	labels := map[string]interface{}{}
+	for k, v := range defaultTypeLabels {
+		labels[k] = v
+	}
+	obj["labels"] = labels
+	return obj, true
+}
+
+// schemaVersionOf はドキュメントの版を返す。schemaVersion が無ければ 0。
+func schemaVersionOf(doc interface{}) int {
+	if obj, ok := doc.(map[string]interface{}); ok {
+		if v, ok := obj["schemaVersion"].(float64); ok {
+			return int(v)
+		}
+	}
+	return 0
+}
+
+// migrateDocument はドキュメントを現在の版にする。
+// 版が古かった場合は upgraded が true になり、適用して内容が変わった手順の説明を applied に返す。
+func migrateDocument(kind docKind, data []byte) (out []byte, applied []string, upgraded bool, err error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, false, err
+	}
+	version := schemaVersionOf(doc)
+	if version > currentSchemaVersion {
+		return nil, nil, false, fmt.Errorf("%s schema version %d is newer than the supported version %d", kind, version, currentSchemaVersion)
+	}
+	if version == currentSchemaVersion {
+		return data, nil, false, nil
+	}
+	for _, m := range migrations {
+		if m.Kind != kind || m.Version <= version {
+			continue
+		}
+		var changed bool
+		if doc, changed = m.Apply(doc); changed {
+			applied = append(applied, fmt.Sprintf("v%d: %s", m.Version, m.Description))
+		}
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, nil, false, fmt.Errorf("%s document must be a JSON object", kind)
+	}
+	obj["schemaVersion"] = currentSchemaVersion
+	out, err = marshalDocument(obj)
+	return out, applied, true, err
+}
+
+// marshalVersioned は現在の版を schemaVersion に記録したドキュメントの JSON を生成する。v は JSON のオブジェクトになる値。
+func marshalVersioned(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("document must be a JSON object: %v", err)
+	}
+	obj["schemaVersion"] = json.RawMessage(fmt.Sprint(currentSchemaVersion))
+	return marshalDocument(obj)
+}
+
+// upgradeDocument は読み込んだドキュメントを現在の版にする。版が古かった場合は save で書き戻し、適用した手順をログに残す。
+// 書き戻しに失敗しても変換後のドキュメントを返す (次回の読み込みで再び変換する)。
+func (a *App) upgradeDocument(kind docKind, name string, data []byte, save func([]byte) error) ([]byte, error) {
+	out, applied, upgraded, err := migrateDocument(kind, data)
+	if err != nil || !upgraded {
+		return out, err
+	}
+	for _, step := range applied {
+		a.logInfo("%s をマイグレーションしました (%s)", name, step)
+	}
+	if err := save(out); err != nil {
+		a.logError("%s の書き戻しに失敗しました: %v", name, err)
+		return out, nil
+	}
+	a.logInfo("%s をスキーマ v%d で保存しました", name, currentSchemaVersion)
+	return out, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMigrateDocuments は旧形式のドキュメントを読み込み時に1度だけ現在の版へ変換し、書き戻すことを検証します
+func TestMigrateDocuments(t *testing.T) {
+	app := newTestApp(t)
+	store := app.storage()
+
+	// インスタンスの配列だけの非常に古いプロジェクト
+	if err := store.SaveProjectData("old", []byte(`[{"id": "i1", "assetId": "a_room6", "type": "room", "x": 10}]`)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := app.GetProjectData("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Instances) != 1 || data.Instances[0].X != 10 || data.LocalAssets == nil {
+		t.Errorf("配列形式のプロジェクトが変換されていません: %+v", data)
+	}
+	stored, _ := store.LoadProjectData("old")
+	if schemaVersionOf(decodeJSON(t, stored)) != currentSchemaVersion {
+		t.Errorf("変換したプロジェクトが書き戻されていません: %s", stored)
+	}
+
+	// shapes を持つアセットは entities にし、変換しないフィールドは残す。エンティティの無いアセットはそのまま。
+	legacy := `{"assets": [
+		{"id": "l1", "type": "furniture", "height": 70, "unitPrice": 5000, "shapes": [{"type": "rect", "x": 0, "y": 0, "w": 10, "h": 10}]},
+		{"id": "l2", "type": "furniture", "entities": []}
+	], "instances": []}`
+	if err := store.SaveProjectData("shapes", []byte(legacy)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = app.GetProjectData("shapes"); err != nil {
+		t.Fatal(err)
+	}
+	l1, l2 := data.LocalAssets[0], data.LocalAssets[1]
+	if len(l1.Entities) != 1 || l1.Entities[0].Layer != "default" || l1.Height != 70 || l1.UnitPrice != 5000 {
+		t.Errorf("shapes の変換が不正です: %+v", l1)
+	}
+	if len(l2.Entities) != 0 {
+		t.Errorf("エンティティの無いアセットが変わっています: %+v", l2)
+	}
+	stored, _ = store.LoadProjectData("shapes")
+	if strings.Contains(string(stored), `"shapes"`) {
+		t.Errorf("shapes キーが残っています: %s", stored)
+	}
+	// 現在の版のドキュメントは変換しない
+	if out, applied, upgraded, err := migrateDocument(kindProject, stored); err != nil || upgraded || len(applied) != 0 || string(out) != string(stored) {
+		t.Errorf("現在の版のドキュメントが変換されました: %v %v %v", applied, upgraded, err)
+	}
+
+	// 新しい版のドキュメントは読み込まない
+	if err := store.SaveProjectData("new", []byte(`{"schemaVersion": 99, "assets": [], "instances": []}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.GetProjectData("new"); err == nil {
+		t.Error("新しい版のプロジェクトでエラーになっていません")
+	}
+
+	// グローバルアセットの配列はドキュメントにして書き戻す
+	if err := store.SaveGlobalAssets([]byte(`[{"id": "g1", "type": "room", "shapes": []}]`)); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := app.GetAssets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assets := raw.([]Asset); len(assets) != 1 || assets[0].ID != "g1" || assets[0].Entities == nil {
+		t.Errorf("グローバルアセットの変換が不正です: %+v", assets)
+	}
+	stored, _ = store.LoadGlobalAssets()
+	if doc, ok := decodeJSON(t, stored).(map[string]interface{}); !ok || doc["assets"] == nil || schemaVersionOf(doc) != currentSchemaVersion {
+		t.Errorf("グローバルアセットが書き戻されていません: %s", stored)
+	}
+
+	// labels の無いパレットには既定の表示名を追加する。schemaVersion はフロントエンドに渡さない。
+	if err := store.SavePalette([]byte(`{"colors": ["#ffffff"], "defaults": {}}`)); err != nil {
+		t.Fatal(err)
+	}
+	palette, err := app.GetPalette()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := palette.(map[string]interface{})
+	if _, ok := p["labels"]; !ok {
+		t.Errorf("パレットに labels が追加されていません: %v", p)
+	}
+	if _, ok := p["schemaVersion"]; ok {
+		t.Errorf("パレットに schemaVersion が含まれています: %v", p)
+	}
+
+	// 配列だけのプロジェクト一覧はドキュメントにして書き戻す
+	indexPath := filepath.Join(app.dataDir, projectsIndexFile)
+	if err := os.WriteFile(indexPath, []byte(`[{"id": "old", "name": "古い一覧"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := app.GetProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].ID != "old" || projects[0].Name != "古い一覧" {
+		t.Errorf("プロジェクト一覧の変換が不正です: %+v", projects)
+	}
+	stored, _ = os.ReadFile(indexPath)
+	if doc, ok := decodeJSON(t, stored).(map[string]interface{}); !ok || doc["projects"] == nil || schemaVersionOf(doc) != currentSchemaVersion {
+		t.Errorf("プロジェクト一覧が書き戻されていません: %s", stored)
+	}
+}
+
+func decodeJSON(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
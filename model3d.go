@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// --- 3D モデル ---
+// 図面を押し出して簡単な 3D モデルを作り、glTF (.glb)・OBJ として書き出す。
+// 部屋は床スラブ、壁は壁の高さの立体 (開口の上は垂れ壁、窓の下は腰壁)、設備・家具はアセットの輪郭を
+// Asset.Height (省略時は種類ごとの既定値) だけ押し出した立体、階段は段板を積んだ立体にする。
+// 色はエンティティの色 (無ければアセットの色)。ドアは開口だけを空け、窓は開口にガラスの板を入れる。
+// 座標はメートル、Y 軸が上で、図面の (x, y) を (x, -z) に対応させる (右手系)。各階は Level.Elevation の高さに置く。
+
+const (
+	defaultFloorThickness    = 15.0  // 床スラブの厚さ (cm)
+	defaultFixtureHeight     = 85.0  // 設備の高さ (cm)
+	defaultFurnitureHeight   = 70.0  // 家具の高さ (cm)
+	defaultOpeningHead       = 200.0 // ドア・窓の上端の高さ (cm)
+	modelGlassThickness      = 2.0   // 窓ガラスの厚さ (cm)
+	modelStairRise           = 20.0  // 階段の1段の高さの上限 (cm)
+	modelCurveSteps          = 16    // 曲線1本あたりの分割数
+	modelDefaultColor        = "#cccccc"
+	modelDefaultMaterialFile = "model.mtl"
+	modelUnitsPerCentimetre  = 0.01 // 出力の単位 (メートル)
+	modelMinTriangleArea     = 1e-12
+)
+
+// vec3 は 3D モデルの座標 (メートル、Y 軸が上)
+type vec3 struct{ X, Y, Z float64 }
+
+func v3sub(a, b vec3) vec3 { return vec3{a.X - b.X, a.Y - b.Y, a.Z - b.Z} }
+func v3cross(a, b vec3) vec3 {
+	return vec3{a.Y*b.Z - a.Z*b.Y, a.Z*b.X - a.X*b.Z, a.X*b.Y - a.Y*b.X}
+}
+func v3len(a vec3) float64 { return math.Sqrt(a.X*a.X + a.Y*a.Y + a.Z*a.Z) }
+
+// modelPoint は図面の点 (cm) と高さ (cm) をモデルの座標にする
+func modelPoint(v Vec2, z float64) vec3 {
+	return vec3{v.X * modelUnitsPerCentimetre, z * modelUnitsPerCentimetre, -v.Y * modelUnitsPerCentimetre}
+}
+
+// modelMaterial は色ごとのマテリアル
+type modelMaterial struct {
+	Name  string
+	Color rgbColor
+}
+
+// modelPrimitive は1つのマテリアルの三角形の集まり。頂点は3つずつ1枚の三角形で、法線は面ごと。
+type modelPrimitive struct {
+	Material  int
+	Positions []vec3
+	Normals   []vec3
+}
+
+// triangle は三角形 abc (表から見て反時計回り) を加える。面積の無い三角形は捨てる。
+func (p *modelPrimitive) triangle(a, b, c vec3) {
+	n := v3cross(v3sub(b, a), v3sub(c, a))
+	l := v3len(n)
+	if l < modelMinTriangleArea {
+		return
+	}
+	n = vec3{n.X / l, n.Y / l, n.Z / l}
+	p.Positions = append(p.Positions, a, b, c)
+	p.Normals = append(p.Normals, n, n, n)
+}
+
+// modelMesh はインスタンス1つ分の形
+type modelMesh struct {
+	Name       string
+	Primitives []*modelPrimitive
+}
+
+// model は書き出す 3D モデル全体
+type model struct {
+	Materials []modelMaterial
+	Meshes    []*modelMesh
+	materials map[rgbColor]int
+}
+
+func newModel() *model {
+	return &model{materials: map[rgbColor]int{}}
+}
+
+// material は色のマテリアルの番号を返す。解釈できない色は既定の色にする。
+func (m *model) material(color string) int {
+	c, ok := parseColor(color)
+	if !ok {
+		c, _ = parseColor(modelDefaultColor)
+	}
+	if i, ok := m.materials[c]; ok {
+		return i
+	}
+	i := len(m.Materials)
+	m.materials[c] = i
+	m.Materials = append(m.Materials, modelMaterial{
+		Name:  fmt.Sprintf("color_%02x%02x%02x", int(math.Round(c.R*255)), int(math.Round(c.G*255)), int(math.Round(c.B*255))),
+		Color: c,
+	})
+	return i
+}
+
+// mesh は新しいメッシュを加える。三角形が1枚も無いメッシュは書き出さない。
+func (m *model) mesh(name string) *modelMesh {
+	mesh := &modelMesh{Name: name}
+	m.Meshes = append(m.Meshes, mesh)
+	return mesh
+}
+
+// primitive はマテリアルの三角形の集まりを返す
+func (mesh *modelMesh) primitive(mat int) *modelPrimitive {
+	for _, p := range mesh.Primitives {
+		if p.Material == mat {
+			return p
+		}
+	}
+	p := &modelPrimitive{Material: mat}
+	mesh.Primitives = append(mesh.Primitives, p)
+	return p
+}
+
+// prism は多角形 (図面の座標) を高さ z0 から z1 まで押し出した立体を加える
+func (mesh *modelMesh) prism(poly []Vec2, z0, z1 float64, mat int) {
+	if z1-z0 <= 1e-9 {
+		return
+	}
+	tris := triangulate(poly)
+	if len(tris) == 0 {
+		return
+	}
+	p := mesh.primitive(mat)
+	for _, t := range tris {
+		p.triangle(modelPoint(t[0], z1), modelPoint(t[1], z1), modelPoint(t[2], z1))
+		p.triangle(modelPoint(t[0], z0), modelPoint(t[2], z0), modelPoint(t[1], z0))
+	}
+	// 側面は反時計回りの辺ごとに外向きの四角形を置く
+	ccw := poly
+	if polygonArea(poly) < 0 {
+		ccw = make([]Vec2, len(poly))
+		for i, v := range poly {
+			ccw[len(poly)-1-i] = v
+		}
+	}
+	for i, a := range ccw {
+		b := ccw[(i+1)%len(ccw)]
+		if vlen(vsub(b, a)) < 1e-9 {
+			continue
+		}
+		p.triangle(modelPoint(a, z0), modelPoint(b, z0), modelPoint(b, z1))
+		p.triangle(modelPoint(a, z0), modelPoint(b, z1), modelPoint(a, z1))
+	}
+}
+
+// assetModelHeight はアセットを押し出す高さ (cm)。部屋は床スラブの厚さ。
+func assetModelHeight(asset Asset) float64 {
+	if asset.Height > 0 {
+		return asset.Height
+	}
+	switch asset.Type {
+	case "room":
+		return defaultFloorThickness
+	case "furniture":
+		return defaultFurnitureHeight
+	}
+	return defaultFixtureHeight
+}
+
+// levelElevation は idx 番目の階の床の高さ (cm)。階が無い場合は 0。
+func levelElevation(levels []Level, idx int) float64 {
+	if idx < 0 || idx >= len(levels) {
+		return 0
+	}
+	return levels[idx].Elevation
+}
+
+// modelMeshName はメッシュの名前 (アセット名と ID)
+func modelMeshName(name, id string) string {
+	if name = strings.TrimSpace(name); name == "" {
+		return id
+	}
+	return name + " (" + id + ")"
+}
+
+// buildModel はプロジェクトを 3D モデルにする。level を指定するとその階に現れるものだけにする。
+func buildModel(data ProjectData, assets assetIndex, level string) (*model, error) {
+	idx := -1
+	if level != "" {
+		i, err := findLevel(data.Levels, level)
+		if err != nil {
+			return nil, err
+		}
+		idx = i
+	}
+	visible := func(inst Instance) bool {
+		return idx < 0 || onLevel(inst, assets, data.Levels, idx)
+	}
+	elevation := func(inst Instance) float64 {
+		return levelElevation(data.Levels, instanceLevel(inst, data.Levels))
+	}
+
+	m := newModel()
+	walls := map[string]*wallSegment{}
+	for _, w := range placeWalls(data, assets) {
+		walls[w.Inst.ID] = w
+	}
+	meshes := map[string]*modelMesh{}
+	for _, inst := range sortedInstances(data.Instances, assets) {
+		if inst.Type == "text" || !visible(inst) {
+			continue
+		}
+		if inst.Type == wallType {
+			if w := walls[inst.ID]; w != nil {
+				color := inst.Color
+				if color == "" {
+					color = wallColor
+				}
+				mesh := m.mesh(modelMeshName("壁", inst.ID))
+				meshes[inst.ID] = mesh
+				z := elevation(inst)
+				for _, poly := range w.polygons() {
+					mesh.prism(poly, z, z+wallHeight(inst), m.material(color))
+				}
+			}
+			continue
+		}
+		asset := assets.lookup(inst)
+		if asset == nil {
+			continue
+		}
+		mesh := m.mesh(modelMeshName(asset.Name, inst.ID))
+		z := elevation(inst)
+		switch {
+		case openingKind(*asset) != "":
+			// ドア・窓は壁に取り付けたものだけを開口として作る
+			if w := walls[inst.HostID]; inst.HostID != "" && w != nil && meshes[w.Inst.ID] != nil {
+				m.opening(meshes[w.Inst.ID], mesh, inst, *asset, w, z)
+			}
+		case isStair(*asset):
+			lo, hi := stairSpan(inst, data.Levels)
+			rise := levelElevation(data.Levels, hi) - levelElevation(data.Levels, lo)
+			if rise <= 0 {
+				// 最上階の階段や床高を決めていない階では階高だけ上る
+				rise = defaultLevelHeight
+				if lo < len(data.Levels) {
+					rise = levelHeight(data.Levels[lo])
+				}
+			}
+			mesh.stair(inst, *asset, levelElevation(data.Levels, lo), rise, m.material(asset.Color))
+		default:
+			m.extrude(mesh, inst, *asset, z)
+		}
+	}
+
+	var kept []*modelMesh
+	for _, mesh := range m.Meshes {
+		var prims []*modelPrimitive
+		for _, p := range mesh.Primitives {
+			if len(p.Positions) > 0 {
+				prims = append(prims, p)
+			}
+		}
+		if mesh.Primitives = prims; len(prims) > 0 {
+			kept = append(kept, mesh)
+		}
+	}
+	m.Meshes = kept
+	return m, nil
+}
+
+// extrude はアセットの輪郭を押し出す。部屋は床の高さの下に床スラブを作る。
+func (m *model) extrude(mesh *modelMesh, inst Instance, asset Asset, z float64) {
+	z0, z1 := z, z+assetModelHeight(asset)
+	if asset.Type == "room" {
+		z0, z1 = z-assetModelHeight(asset), z
+	}
+	tf := instanceTransform(inst)
+	paths, owners := assetOutlines(asset)
+	for i, path := range paths {
+		color := owners[i].Color
+		if color == "" {
+			color = asset.Color
+		}
+		mesh.prism(path.transform(tf).flatten(modelCurveSteps), z0, z1, m.material(color))
+	}
+}
+
+// opening は壁の開口の上の垂れ壁と窓の下の腰壁を壁のメッシュに、窓ガラスを建具のメッシュに加える
+func (m *model) opening(wallMesh, mesh *modelMesh, inst Instance, asset Asset, w *wallSegment, z float64) {
+	_, width, _ := openingAxis(asset)
+	a := hostOffset(inst, w, width)
+	b := a + width
+	top := wallHeight(w.Inst)
+	head := math.Min(defaultOpeningHead, top)
+	height := asset.OpeningHeight
+	if height <= 0 {
+		height = head
+		if openingKind(asset) == "window" {
+			height = defaultWindowHeight
+		}
+	}
+	sill := math.Max(0, head-height)
+
+	color := w.Inst.Color
+	if color == "" {
+		color = wallColor
+	}
+	part := []Vec2{w.corner(0, a), w.corner(0, b), w.corner(1, b), w.corner(1, a)}
+	wallMesh.prism(part, z+head, z+top, m.material(color))
+	wallMesh.prism(part, z, z+sill, m.material(color))
+	if openingKind(asset) == "window" {
+		half := math.Min(modelGlassThickness, w.Half*2) / 2
+		glass := []Vec2{
+			vadd(w.face(0, a), vscale(w.Normal, w.Half-half)), vadd(w.face(0, b), vscale(w.Normal, w.Half-half)),
+			vadd(w.face(0, b), vscale(w.Normal, w.Half+half)), vadd(w.face(0, a), vscale(w.Normal, w.Half+half)),
+		}
+		mesh.prism(glass, z+sill, z+head, m.material(asset.Color))
+	}
+}
+
+// stair は階段の外接矩形を長辺の向きに段に分け、z から rise だけ上る段板を積む
+func (mesh *modelMesh) stair(inst Instance, asset Asset, z, rise float64, mat int) {
+	lb := assetLocalBounds(asset)
+	if !lb.Valid || rise <= 0 {
+		return
+	}
+	steps := int(math.Ceil(rise/modelStairRise - 1e-9))
+	tf := instanceTransform(inst)
+	for i := 0; i < steps; i++ {
+		t0, t1 := float64(i)/float64(steps), float64(i+1)/float64(steps)
+		var r bbox
+		if lb.width() >= lb.height() {
+			r.add(Vec2{X: lb.Min.X + lb.width()*t0, Y: lb.Min.Y})
+			r.add(Vec2{X: lb.Min.X + lb.width()*t1, Y: lb.Max.Y})
+		} else {
+			r.add(Vec2{X: lb.Min.X, Y: lb.Min.Y + lb.height()*t0})
+			r.add(Vec2{X: lb.Max.X, Y: lb.Min.Y + lb.height()*t1})
+		}
+		poly := []Vec2{tf(r.Min), tf(Vec2{X: r.Max.X, Y: r.Min.Y}), tf(r.Max), tf(Vec2{X: r.Min.X, Y: r.Max.Y})}
+		mesh.prism(poly, z, z+rise*t1, mat)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// meshVolume はメッシュの体積 (m³)。面が外向きに閉じていれば正になる。
+func meshVolume(mesh *modelMesh) float64 {
+	v := 0.0
+	for _, p := range mesh.Primitives {
+		for i := 0; i < len(p.Positions); i += 3 {
+			a, b, c := p.Positions[i], p.Positions[i+1], p.Positions[i+2]
+			n := v3cross(b, c)
+			v += (a.X*n.X + a.Y*n.Y + a.Z*n.Z) / 6
+		}
+	}
+	return v
+}
+
+// TestBuildModel は床・壁の開口・家具・階段の立体と glTF・OBJ の書き出しを検証します
+func TestBuildModel(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	data := ProjectData{
+		LocalAssets: []Asset{},
+		Levels:      []Level{{ID: "L1", Name: "1F"}, {ID: "L2", Name: "2F", Elevation: 290}},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_room6", Type: "room"},
+			{ID: "w", Type: wallType, Length: 400, Thickness: 10},
+			{ID: "door", AssetID: "a_door", Type: "fixture", HostID: "w", HostOffset: 20},
+			{ID: "window", AssetID: "a_window", Type: "fixture", HostID: "w", HostOffset: 150},
+			{ID: "stair", AssetID: "a_stair", Type: "fixture", X: 400},
+			{ID: "sofa", AssetID: "a_sofa2", Type: "furniture", Y: 50, LevelID: "L2"},
+			{ID: "label", Type: "text", Text: "メモ"},
+		},
+	}
+	assets := newAssetIndex(data.LocalAssets, globals)
+
+	m, err := buildModel(data, assets, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meshes := map[string]*modelMesh{}
+	for _, mesh := range m.Meshes {
+		meshes[mesh.Name] = mesh
+	}
+	if len(meshes) != 5 || meshes["ドア(片開) (door)"] != nil {
+		t.Fatalf("メッシュが不正です: %v", meshes)
+	}
+	for name, want := range map[string]float64{
+		"洋室 (6畳) (room)":  3.6 * 2.7 * 0.15,
+		"壁 (w)":           4.0*0.1*2.4 - 0.8*0.1*2.0 - 1.8*0.1*1.8, // ドアと窓の開口を除く
+		"窓(掃出し) (window)": 1.8 * 0.02 * 1.8,
+		"階段(直) (stair)":   0.91 * 3.64 * 2.9 * 16 / 30, // 15段で 1F から 2F まで上る
+		"ソファ(2人) (sofa)":  1.6 * 0.9 * 0.8,
+	} {
+		if mesh := meshes[name]; mesh == nil || !approxEqual(meshVolume(mesh), want, 1e-6) {
+			t.Errorf("%s の体積が不正です: want %v", name, want)
+		}
+	}
+	// 床は床の高さの下、2F の家具は 2F の床の上
+	for _, v := range meshes["洋室 (6畳) (room)"].Primitives[0].Positions {
+		if v.Y > 0 || v.Y < -0.15-1e-9 || v.Z > 0 {
+			t.Fatalf("床の位置が不正です: %+v", v)
+		}
+	}
+	for _, v := range meshes["ソファ(2人) (sofa)"].Primitives[0].Positions {
+		if v.Y < 2.9-1e-9 || v.Y > 3.7+1e-9 {
+			t.Fatalf("2F の家具の高さが不正です: %+v", v)
+		}
+	}
+
+	upper, err := buildModel(data, assets, "2F")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upper.Meshes) != 2 {
+		t.Errorf("2F のメッシュの数が不正です: %d", len(upper.Meshes))
+	}
+	if _, err := buildModel(data, assets, "3F"); err == nil {
+		t.Error("存在しない階でエラーになっていません")
+	}
+
+	// glTF: ヘッダー・チャンクの長さとアクセサがバッファに収まること
+	glb, err := encodeGLB(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]uint32, 5)
+	binary.Read(bytes.NewReader(glb), binary.LittleEndian, header)
+	if header[0] != glbMagic || header[1] != 2 || int(header[2]) != len(glb) || header[4] != glbChunkJSON {
+		t.Fatalf("glb のヘッダーが不正です: %x", header)
+	}
+	var doc gltfDocument
+	if err := json.Unmarshal(glb[20:20+header[3]], &doc); err != nil {
+		t.Fatal(err)
+	}
+	binLen := binary.LittleEndian.Uint32(glb[20+header[3]:])
+	if len(doc.Nodes) != 5 || len(doc.Materials) != len(m.Materials) || doc.Buffers[0].ByteLength > int(binLen) {
+		t.Errorf("glTF の構造が不正です: %+v", doc)
+	}
+	for _, acc := range doc.Accessors {
+		view := doc.BufferViews[acc.BufferView]
+		if acc.Count*12 != view.ByteLength || view.ByteOffset+view.ByteLength > doc.Buffers[0].ByteLength {
+			t.Errorf("アクセサがバッファに収まっていません: %+v %+v", acc, view)
+		}
+	}
+
+	// OBJ: 面の頂点・法線の番号が定義済みの範囲にあること
+	obj, mtl := encodeOBJ(m, "plan.mtl")
+	var vs, vns, fs int
+	for _, line := range strings.Split(obj, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "v":
+			vs++
+		case fields[0] == "vn":
+			vns++
+		case fields[0] == "f":
+			fs++
+			var v, n int
+			for _, f := range fields[1:] {
+				if _, err := fmt.Sscanf(f, "%d//%d", &v, &n); err != nil || v > vs || n > vns {
+					t.Fatalf("面の番号が不正です: %s", line)
+				}
+			}
+		}
+	}
+	if !strings.Contains(obj, "mtllib plan.mtl\n") || vs != fs*3 || vns != fs {
+		t.Errorf("OBJ が不正です: v=%d vn=%d f=%d", vs, vns, fs)
+	}
+	if strings.Count(mtl, "newmtl ") != len(m.Materials) {
+		t.Errorf("MTL が不正です: %s", mtl)
+	}
+}
@@ -5,6 +5,7 @@ type Project struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	UpdatedAt string `json:"updatedAt"`
+	Madori    string `json:"madori,omitempty"` // Floor plan notation of the rooms, e.g. "2LDK+S". Updated when the project data is saved.
 }
 
 // Vec2 represents a 2D vector or point.
@@ -68,6 +69,14 @@ type Asset struct {
 	Snap           bool     `json:"snap,omitempty"`
 	BoundX         *float64 `json:"boundX,omitempty"`
 	BoundY         *float64 `json:"boundY,omitempty"`
+	OpeningHeight  float64  `json:"openingHeight,omitempty"` // Height of a window opening in cm. 0 uses the default of the check.
+	Height         float64  `json:"height,omitempty"`        // Height of the 3D model in cm (floor slab thickness for rooms). 0 uses the default of the asset type.
+
+	// Optional product details for the bill of materials
+	Manufacturer string  `json:"manufacturer,omitempty"`
+	SKU          string  `json:"sku,omitempty"`
+	UnitPrice    float64 `json:"unitPrice,omitempty"`  // Price per piece (tax excluded)
+	LaborPrice   float64 `json:"laborPrice,omitempty"` // Installation labor per piece (tax excluded)
 }
 
 // Instance represents an instance of an Asset placed on the canvas.
@@ -84,6 +93,28 @@ type Instance struct {
 	Text     string   `json:"text,omitempty"`
 	FontSize *float64 `json:"fontSize,omitempty"`
 	Color    string   `json:"color,omitempty"`
+
+	// Wall instance specific. The centreline runs from (X, Y) for Length cm in the Rotation direction.
+	Length    float64 `json:"length,omitempty"`
+	Thickness float64 `json:"thickness,omitempty"` // Default 12
+	Height    float64 `json:"height,omitempty"`    // Default 240
+
+	// Door or window hosted in a wall. Its position and rotation follow the wall.
+	HostID     string  `json:"hostId,omitempty"`     // ID of the wall instance
+	HostOffset float64 `json:"hostOffset,omitempty"` // Distance from the start of the wall centreline to the opening in cm
+	HostFlip   bool    `json:"hostFlip,omitempty"`   // Faces the other side of the wall (e.g. a door swinging to the right side)
+
+	// Level the instance is on. Empty (or an unknown ID) is the first level.
+	LevelID    string `json:"levelId,omitempty"`
+	TopLevelID string `json:"topLevelId,omitempty"` // For stairs: the level the stair leads up to. Default: the level above.
+}
+
+// Level is one storey of a project.
+type Level struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`      // e.g. "1F"
+	Elevation float64 `json:"elevation"` // Floor level above the first level in cm
+	Height    float64 `json:"height"`    // Floor-to-floor height in cm. Default 290.
 }
 
 // ProjectData represents the full data content of a project file.
@@ -91,12 +122,384 @@ type ProjectData struct {
 	LocalAssets   []Asset           `json:"assets"`
 	Instances     []Instance        `json:"instances"`
 	DefaultColors map[string]string `json:"defaultColors,omitempty"`
+	Levels        []Level           `json:"levels,omitempty"`    // Storeys from the bottom up. Empty for single-storey projects.
+	CostRates     *CostRates        `json:"costRates,omitempty"` // Unit prices of the cost estimate. Nil uses the defaults.
+}
+
+// SnapshotInfo represents the metadata of a saved point-in-time copy of a project.
+type SnapshotInfo struct {
+	ID        string `json:"id"`        // UnixNano timestamp, sortable
+	CreatedAt string `json:"createdAt"` // RFC3339
+	Size      int64  `json:"size"`      // Size of the stored JSON in bytes
+}
+
+// SnapshotRetention controls how many project snapshots are kept.
+// Every snapshot younger than KeepAllMinutes is kept, then the newest one per hour
+// up to HourlyHours, then the newest one per day up to DailyDays. Older ones are deleted.
+type SnapshotRetention struct {
+	KeepAllMinutes int `json:"keepAllMinutes"`
+	HourlyHours    int `json:"hourlyHours"`
+	DailyDays      int `json:"dailyDays"`
+}
+
+// FloorArea represents an area in square metres, 畳 and 坪.
+type FloorArea struct {
+	M2    float64 `json:"m2"`
+	Jo    float64 `json:"jo"`
+	Tsubo float64 `json:"tsubo"`
+}
+
+// RoomArea represents the floor area of a single room instance.
+type RoomArea struct {
+	InstanceID string    `json:"instanceId"`
+	LevelID    string    `json:"levelId,omitempty"`
+	AssetID    string    `json:"assetId"`
+	Name       string    `json:"name"`
+	Area       FloorArea `json:"area"`
+	PerimeterM float64   `json:"perimeterM"`
+}
+
+// LevelArea represents the total floor area of one level.
+type LevelArea struct {
+	LevelID string    `json:"levelId"`
+	Name    string    `json:"name"`
+	Total   FloorArea `json:"total"`
+}
+
+// ProjectAreas represents the floor areas of a project.
+type ProjectAreas struct {
+	TatamiSize string      `json:"tatamiSize"` // "edoma", "chukyoma", "kyoma"
+	Rooms      []RoomArea  `json:"rooms"`
+	Levels     []LevelArea `json:"levels,omitempty"` // Per-level totals of multi-level projects
+	Total      FloorArea   `json:"total"`
+}
+
+// PlanExportOptions controls how a project layout is drawn by the plan exporters.
+type PlanExportOptions struct {
+	Scale       float64 `json:"scale"`           // Denominator of the drawing scale (50 = 1:50). Default 100.
+	ShowGrid    bool    `json:"showGrid"`        // Draw a grid behind the plan
+	GridSpacing float64 `json:"gridSpacing"`     // Grid pitch in cm. Default 91 (半間).
+	TitleBlock  bool    `json:"titleBlock"`      // Draw the title block (name, date, scale, total area)
+	RoomLabels  bool    `json:"roomLabels"`      // Draw room names with their area in 畳
+	TatamiSize  string  `json:"tatamiSize"`      // Tatami used for room labels ("edoma", "chukyoma", "kyoma")
+	Title       string  `json:"title,omitempty"` // Overrides the project name in the title block
+	Level       string  `json:"level,omitempty"` // ID or name of the level to draw. Default: the first level.
+}
+
+// PDFExportOptions controls the PDF plan sheet. The plan is drawn at true scale with north up.
+type PDFExportOptions struct {
+	Plan        PlanExportOptions `json:"plan"`        // Plan.Scale 0 picks the largest standard scale that fits the sheet
+	PaperSize   string            `json:"paperSize"`   // "A4" or "A3". Default "A4".
+	Orientation string            `json:"orientation"` // "portrait" or "landscape". Default "landscape".
+	AreaTable   bool              `json:"areaTable"`   // Add a table of room areas next to the plan
+}
+
+// DXFImportOptions controls how a DXF drawing is converted into assets.
+type DXFImportOptions struct {
+	Units     string `json:"units"`     // Overrides $INSUNITS: "mm", "cm", "m", "in" or "ft". Empty uses the file ($INSUNITS, mm when unset).
+	AssetType string `json:"assetType"` // Type of the created assets ("furniture", "fixture" or "room"). Default "furniture".
+}
+
+// DXFImportResult represents assets and instances converted from a DXF drawing (coordinates in cm).
+type DXFImportResult struct {
+	Assets    []Asset    `json:"assets"`
+	Instances []Instance `json:"instances"`
+	Units     string     `json:"units"`    // Unit the drawing was converted from
+	Warnings  []string   `json:"warnings"` // Skipped entities and other conversion notes
+}
+
+// RoomRequirement describes one room of a floor plan program.
+type RoomRequirement struct {
+	ID       string  `json:"id"`       // Key used by adjacency rules (e.g. "ldk", "bed1"). Default: kind + index.
+	Kind     string  `json:"kind"`     // "ldk", "dk", "k", "bedroom", "entrance", "hall", "toilet", "bath", "washroom", "storage", "wic", "sic" or "balcony"
+	Name     string  `json:"name"`     // Display name. Empty uses the kind's name.
+	AreaM2   float64 `json:"areaM2"`   // Target floor area. 0 uses the kind's default area.
+	MinWidth float64 `json:"minWidth"` // Minimum short side in cm. 0 uses the kind's default.
+	AssetID  string  `json:"assetId"`  // Room asset the room is based on (name, color, default area). Empty uses the kind's default asset.
+}
+
+// MadoriProgram is a Japanese floor plan notation such as "2LDK+S" parsed into rooms.
+type MadoriProgram struct {
+	Notation string            `json:"notation"` // Normalized notation, e.g. "2LDK+S"
+	Bedrooms int               `json:"bedrooms"` // The leading n (洋室・和室 count)
+	Main     string            `json:"main"`     // "LDK", "DK", "K" or "R"
+	Extras   []string          `json:"extras"`   // "S" (納戸), "WIC", "SIC"
+	Rooms    []RoomRequirement `json:"rooms"`    // Rooms named by the notation, mapped to room assets. AreaM2 is set from area hints.
+}
+
+// FloorPlanProgram is the input of the floor plan generator.
+type FloorPlanProgram struct {
+	Preset     string            `json:"preset"`     // Madori such as "3LDK" or "2LDK+S LDK15畳". Used when Rooms is empty.
+	Rooms      []RoomRequirement `json:"rooms"`      // Explicit room list
+	Adjacency  [][2]string       `json:"adjacency"`  // Pairs of room IDs (or kinds) that must share a wall, e.g. ["k", "ldk"]
+	SiteWidth  float64           `json:"siteWidth"`  // Buildable rectangle in cm. 0 derives it from the total area.
+	SiteHeight float64           `json:"siteHeight"` // Buildable rectangle in cm. 0 derives it from the total area.
+	Grid       float64           `json:"grid"`       // Wall grid in cm. Default 45.5 (半間).
+	Count      int               `json:"count"`      // Number of layouts to return. Default 3.
+	Seed       int64             `json:"seed"`       // The same program and seed always produce the same layouts
+}
+
+// GeneratedRoom describes where a room of the program was placed (cm, lower-left origin).
+type GeneratedRoom struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	InstanceID string    `json:"instanceId"`
+	X          float64   `json:"x"`
+	Y          float64   `json:"y"`
+	W          float64   `json:"w"`
+	H          float64   `json:"h"`
+	Area       FloorArea `json:"area"` // 畳 uses edoma
+}
+
+// GeneratedLayout is one candidate produced by the floor plan generator.
+type GeneratedLayout struct {
+	Data        ProjectData     `json:"data"`
+	Rooms       []GeneratedRoom `json:"rooms"`
+	Score       float64         `json:"score"`       // Total penalty; lower is better
+	Unsatisfied []string        `json:"unsatisfied"` // Rules the layout does not meet
+}
+
+// FurniturePlacementRequest is the input of the furniture placement solver.
+type FurniturePlacementRequest struct {
+	RoomInstanceID string   `json:"roomInstanceId"` // Room instance to furnish
+	AssetIDs       []string `json:"assetIds"`       // Furniture assets to place, e.g. ["a_bed_s", "a_sofa2"]. Repeat an ID to place it twice.
+	Clearance      float64  `json:"clearance"`      // Free depth in front of windows in cm. Doors keep at least their own width. Default 60.
+	Count          int      `json:"count"`          // Number of candidates to return. Default 3.
+	Seed           int64    `json:"seed"`           // The same request and seed always produce the same candidates
+}
+
+// FurnitureLayout is one candidate produced by the furniture placement solver.
+type FurnitureLayout struct {
+	Instances []Instance `json:"instances"` // New instances to add to the project
+	Score     float64    `json:"score"`     // Total penalty; lower is better
+	Unplaced  []string   `json:"unplaced"`  // Asset IDs that did not fit
+}
+
+// InstanceOverlap represents two instances whose shapes overlap.
+type InstanceOverlap struct {
+	InstanceA string  `json:"instanceA"`
+	InstanceB string  `json:"instanceB"`
+	Area      float64 `json:"area"` // Overlap area in cm²
+}
+
+// OutsideInstance represents a fixture or piece of furniture that sticks out of the rooms.
+type OutsideInstance struct {
+	InstanceID  string  `json:"instanceId"`
+	OutsideArea float64 `json:"outsideArea"` // Area outside every room in cm²
+	Ratio       float64 `json:"ratio"`       // OutsideArea divided by the item's area (0..1)
+}
+
+// CollisionReport lists overlapping instances and items outside the rooms.
+type CollisionReport struct {
+	Overlaps         []InstanceOverlap `json:"overlaps"`
+	Outside          []OutsideInstance `json:"outside"`
+	TotalOverlapArea float64           `json:"totalOverlapArea"` // Sum of the overlap areas in cm²
+}
+
+// ClearanceRules configures the clearance and accessibility checks. Distances are in cm.
+// Fields left at 0 take the value of the preset.
+type ClearanceRules struct {
+	Preset        string  `json:"preset"`        // "standard" or "barrierFree" (wheelchair). Default "standard".
+	FixtureFront  float64 `json:"fixtureFront"`  // Free depth in front of kitchens, washing pans and other fixtures
+	CorridorWidth float64 `json:"corridorWidth"` // Minimum width of corridors (廊下・ホール)
+	DoorWidth     float64 `json:"doorWidth"`     // Minimum width of doors
+	DoorSwing     float64 `json:"doorSwing"`     // Free depth on the room side of doors. Default: the door's own width.
+	PassageWidth  float64 `json:"passageWidth"`  // Minimum width of the passage between two pieces of furniture
+}
+
+// ClearanceFix suggests moving an instance to resolve a violation.
+type ClearanceFix struct {
+	InstanceID string  `json:"instanceId"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+}
+
+// ClearanceViolation is one failed clearance or accessibility check.
+type ClearanceViolation struct {
+	Rule        string        `json:"rule"` // "fixtureFront", "corridorWidth", "doorWidth", "doorSwing" or "passageWidth"
+	Message     string        `json:"message"`
+	InstanceIDs []string      `json:"instanceIds"` // The checked instance first, then the ones in the way
+	Required    float64       `json:"required"`    // cm
+	Actual      float64       `json:"actual"`      // cm
+	Fix         *ClearanceFix `json:"fix,omitempty"`
+}
+
+// ClearanceReport is the result of the clearance checks of a project.
+type ClearanceReport struct {
+	Rules      ClearanceRules       `json:"rules"`   // Rules with the preset values filled in
+	Checked    int                  `json:"checked"` // Number of checks performed
+	Violations []ClearanceViolation `json:"violations"`
+}
+
+// DaylightOptions configures the daylight and ventilation check.
+type DaylightOptions struct {
+	WindowHeights       map[string]float64 `json:"windowHeights,omitempty"` // Window asset ID → opening height in cm. Overrides the asset's openingHeight.
+	DefaultWindowHeight float64            `json:"defaultWindowHeight"`     // Height of windows without one in cm. Default 180.
+}
+
+// RoomWindow is a window on the boundary of a room.
+type RoomWindow struct {
+	InstanceID string  `json:"instanceId"`
+	AssetID    string  `json:"assetId"`
+	Width      float64 `json:"width"`  // Length along the room boundary in cm
+	Height     float64 `json:"height"` // cm
+	AreaM2     float64 `json:"areaM2"`
+}
+
+// RoomDaylight is the daylight and ventilation result of one room.
+type RoomDaylight struct {
+	InstanceID            string       `json:"instanceId"`
+	Name                  string       `json:"name"`
+	Habitable             bool         `json:"habitable"` // 居室. Only habitable rooms must meet the ratios.
+	FloorAreaM2           float64      `json:"floorAreaM2"`
+	Windows               []RoomWindow `json:"windows"`
+	WindowAreaM2          float64      `json:"windowAreaM2"`
+	Ratio                 float64      `json:"ratio"`                 // Window area / floor area
+	DaylightRequiredM2    float64      `json:"daylightRequiredM2"`    // 1/7 of the floor area
+	VentilationRequiredM2 float64      `json:"ventilationRequiredM2"` // 1/20 of the floor area
+	DaylightOK            bool         `json:"daylightOk"`            // Always true for rooms that are not habitable
+	VentilationOK         bool         `json:"ventilationOk"`
+}
+
+// DaylightReport is the result of the daylight and ventilation check of a project.
+type DaylightReport struct {
+	Rooms  []RoomDaylight `json:"rooms"`  // Indoor rooms by level, in instance order
+	Failed int            `json:"failed"` // Number of habitable rooms failing either ratio
+}
+
+// WallOptions configures the derivation of walls from room polygons. Fields left at 0 take the defaults.
+type WallOptions struct {
+	Thickness         float64 `json:"thickness"`         // Walls between two rooms in cm. Default 12.
+	ExteriorThickness float64 `json:"exteriorThickness"` // Walls on the outside of the rooms in cm. Default 15.
+	Height            float64 `json:"height"`            // cm. Default 240.
+}
+
+// WallOutline is the outline of a wall after joining it to the other walls and cutting its openings.
+type WallOutline struct {
+	InstanceID string   `json:"instanceId"`
+	Polygons   [][]Vec2 `json:"polygons"` // One polygon per solid part between the openings, counter-clockwise
+}
+
+// BOMRoomCount is how many pieces of an asset are placed in one room.
+type BOMRoomCount struct {
+	RoomID   string `json:"roomId"` // Room instance ID. Empty for pieces outside every room.
+	RoomName string `json:"roomName"`
+	LevelID  string `json:"levelId,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// BOMItem is one line of the bill of materials: every placed instance of one asset.
+type BOMItem struct {
+	AssetID      string         `json:"assetId"`
+	Name         string         `json:"name"`
+	Type         string         `json:"type"` // "fixture" or "furniture"
+	W            float64        `json:"w"`
+	H            float64        `json:"h"`
+	Color        string         `json:"color"`
+	Manufacturer string         `json:"manufacturer,omitempty"`
+	SKU          string         `json:"sku,omitempty"`
+	UnitPrice    float64        `json:"unitPrice"`
+	Count        int            `json:"count"`
+	Amount       float64        `json:"amount"` // UnitPrice × Count
+	Rooms        []BOMRoomCount `json:"rooms"`
+	InstanceIDs  []string       `json:"instanceIds"`
+}
+
+// BillOfMaterials is the schedule of the fixtures and furniture placed in a project.
+type BillOfMaterials struct {
+	Items       []BOMItem `json:"items"`
+	TotalCount  int       `json:"totalCount"`
+	TotalAmount float64   `json:"totalAmount"`
+}
+
+// PNGExportOptions configures the raster image export. Text and room labels are not drawn.
+type PNGExportOptions struct {
+	Plan   PlanExportOptions `json:"plan"`   // Scale, grid and level of the plan
+	DPI    float64           `json:"dpi"`    // Pixels per inch of the plan printed at Plan.Scale. Default 96.
+	Width  int               `json:"width"`  // When Width or Height is set, the plan is fitted into that many pixels instead (DPI and scale are ignored).
+	Height int               `json:"height"` // 0 keeps the aspect ratio of the plan.
+}
+
+// ModelExportOptions configures the 3D export (glTF, OBJ).
+type ModelExportOptions struct {
+	Level        string `json:"level,omitempty"`        // Level ID or name to export. Empty exports every level.
+	MaterialFile string `json:"materialFile,omitempty"` // OBJ only: file name of the material library referenced by the OBJ. Default "model.mtl".
+}
+
+// OBJExport is a Wavefront OBJ model and the material library it references.
+type OBJExport struct {
+	OBJ string `json:"obj"`
+	MTL string `json:"mtl"`
 }
 
 // AppSettings represents the application-wide settings.
 type AppSettings struct {
-	GridSize         float64 `json:"gridSize"`
-	SnapInterval     float64 `json:"snapInterval"`
-	InitialZoom      float64 `json:"initialZoom"`
-	AutoSaveInterval int     `json:"autoSaveInterval"`
+	GridSize          float64            `json:"gridSize"`
+	SnapInterval      float64            `json:"snapInterval"`
+	InitialZoom       float64            `json:"initialZoom"`
+	AutoSaveInterval  int                `json:"autoSaveInterval"`
+	StorageBackend    string             `json:"storageBackend,omitempty"`    // "json" or "sqlite". Changing it copies the data to the new backend.
+	SnapshotRetention *SnapshotRetention `json:"snapshotRetention,omitempty"` // Nil uses defaultSnapshotRetention
+	ValidationMode    string             `json:"validationMode,omitempty"`    // "warn" (default) logs issues and saves, "block" refuses to save errors
+}
+
+// DataDirInfo describes where the data directory is and how it was chosen.
+type DataDirInfo struct {
+	Path       string `json:"path"`
+	Source     string `json:"source"`     // "flag", "env", "config", "portable", "legacy" or "default"
+	ConfigFile string `json:"configFile"` // Config file in the OS user config directory that MoveDataDir writes
+	Movable    bool   `json:"movable"`    // False when the -data flag or the environment variable fixes the location
+}
+
+// FlooringRate is the flooring price of rooms made from one room asset.
+type FlooringRate struct {
+	AssetID    string  `json:"assetId"`    // Room asset the rate applies to. Empty for the default of the other rooms.
+	Material   string  `json:"material"`   // Name of the flooring, e.g. "フローリング"
+	Unit       string  `json:"unit"`       // "m2" or "jo" (畳 of CostRates.TatamiSize)
+	UnitPrice  float64 `json:"unitPrice"`  // Material price per unit (tax excluded)
+	LaborPrice float64 `json:"laborPrice"` // Installation labor per unit (tax excluded)
+}
+
+// CostRates are the unit prices of the cost estimate of a project.
+// Fixtures and furniture use the unitPrice and laborPrice of their assets.
+type CostRates struct {
+	Flooring   []FlooringRate `json:"flooring"`
+	TaxRate    float64        `json:"taxRate"`    // Consumption tax as a fraction (0.1 = 10%)
+	TatamiSize string         `json:"tatamiSize"` // Tatami used by the "jo" unit ("edoma", "chukyoma", "kyoma")
+}
+
+// EstimateLine is one line item of a cost estimate.
+type EstimateLine struct {
+	Category  string  `json:"category"` // "flooring", "fixture", "furniture" or "labor"
+	Name      string  `json:"name"`
+	RoomID    string  `json:"roomId,omitempty"`  // Room instance of flooring lines and their labor
+	AssetID   string  `json:"assetId,omitempty"` // Asset of the line
+	Quantity  float64 `json:"quantity"`
+	Unit      string  `json:"unit"` // "m²", "畳" or "個"
+	UnitPrice float64 `json:"unitPrice"`
+	Amount    float64 `json:"amount"` // UnitPrice × Quantity
+}
+
+// CostEstimate is the line-item estimate of a project with the consumption tax.
+type CostEstimate struct {
+	Lines    []EstimateLine `json:"lines"`
+	Subtotal float64        `json:"subtotal"` // Tax excluded
+	TaxRate  float64        `json:"taxRate"`
+	Tax      float64        `json:"tax"`   // Rounded down to whole currency units
+	Total    float64        `json:"total"` // Tax included
+}
+
+// ValidationIssue is one problem found in project data.
+type ValidationIssue struct {
+	Path     string `json:"path"`     // JSON path of the offending value, e.g. "instances[12].assetId"
+	Severity string `json:"severity"` // "error" or "warning"
+	Message  string `json:"message"`
+}
+
+// ValidationReport lists the problems found in project data in document order.
+type ValidationReport struct {
+	Issues   []ValidationIssue `json:"issues"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
 }
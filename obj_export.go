@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// --- OBJ エクスポート ---
+// 3D モデルを Wavefront OBJ と、色を定義するマテリアルライブラリ (MTL) で書き出す。
+// インスタンスごとにオブジェクト (o) を作り、マテリアルごとに usemtl で切り替える。法線は面ごとに1つ持つ。
+
+// objNum は座標を OBJ 用に整形する (小数点以下6桁まで)
+func objNum(v float64) string {
+	s := strconv.FormatFloat(round6(v), 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// objName は OBJ・MTL の名前に使えるように空白を置き換える
+func objName(s string) string {
+	return strings.Join(strings.Fields(s), "_")
+}
+
+// encodeOBJ は 3D モデルを OBJ と MTL の文字列にする。mtlFile は OBJ から参照する MTL のファイル名。
+func encodeOBJ(m *model, mtlFile string) (string, string) {
+	var obj strings.Builder
+	fmt.Fprintf(&obj, "# roomGenerator\n# units: m, +Y up\nmtllib %s\n", mtlFile)
+	next := 1 // 次の頂点・法線の番号
+	for _, mesh := range m.Meshes {
+		fmt.Fprintf(&obj, "o %s\n", objName(mesh.Name))
+		for _, p := range mesh.Primitives {
+			fmt.Fprintf(&obj, "usemtl %s\n", m.Materials[p.Material].Name)
+			for _, v := range p.Positions {
+				fmt.Fprintf(&obj, "v %s %s %s\n", objNum(v.X), objNum(v.Y), objNum(v.Z))
+			}
+			for i := 0; i < len(p.Normals); i += 3 {
+				n := p.Normals[i]
+				fmt.Fprintf(&obj, "vn %s %s %s\n", objNum(n.X), objNum(n.Y), objNum(n.Z))
+			}
+			for i := 0; i < len(p.Positions); i += 3 {
+				v, n := next+i, (next-1)/3+i/3+1
+				fmt.Fprintf(&obj, "f %d//%d %d//%d %d//%d\n", v, n, v+1, n, v+2, n)
+			}
+			next += len(p.Positions)
+		}
+	}
+
+	var mtl strings.Builder
+	mtl.WriteString("# roomGenerator\n")
+	for _, mat := range m.Materials {
+		c := mat.Color
+		fmt.Fprintf(&mtl, "newmtl %s\nKa 0 0 0\nKd %s %s %s\nKs 0 0 0\nd 1\nillum 1\n", mat.Name, objNum(c.R), objNum(c.G), objNum(c.B))
+	}
+	return obj.String(), mtl.String()
+}
+
+// ExportProjectOBJ returns the project as a Wavefront OBJ model and its MTL material library.
+// The geometry is the same as ExportProjectGLB (metres, Y up). The OBJ references the material library
+// as opts.MaterialFile (default "model.mtl"), so save the MTL under that name next to the OBJ.
+func (a *App) ExportProjectOBJ(id string, opts ModelExportOptions) (OBJExport, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return OBJExport{}, err
+	}
+	m, err := buildModel(data, assets, opts.Level)
+	if err != nil {
+		return OBJExport{}, err
+	}
+	mtlFile := strings.TrimSpace(opts.MaterialFile)
+	if mtlFile == "" {
+		mtlFile = modelDefaultMaterialFile
+	}
+	obj, mtl := encodeOBJ(m, mtlFile)
+	a.logInfo("OBJ エクスポート: %s", id)
+	return OBJExport{OBJ: obj, MTL: mtl}, nil
+}
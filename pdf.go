@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// --- 最小限の PDF ライター ---
+// 図面出力に必要な機能 (パス・塗り・テキスト) だけを持つ。
+// 和文は埋め込み不要の標準 CID フォント (HeiseiKakuGo-W5, UniJIS-UCS2-H) を使う。
+
+// mmToPt は mm を PDF のポイント (1/72 インチ) に変換する係数
+const mmToPt = 72 / 25.4
+
+// pdfDocument は PDF のオブジェクト列を組み立てる
+type pdfDocument struct {
+	objects [][]byte // objects[i] はオブジェクト番号 i+1 の本体
+}
+
+// reserve はオブジェクト番号を先に確保する (相互参照のため)
+func (d *pdfDocument) reserve() int {
+	d.objects = append(d.objects, nil)
+	return len(d.objects)
+}
+
+func (d *pdfDocument) set(n int, body string) {
+	d.objects[n-1] = []byte(body)
+}
+
+func (d *pdfDocument) add(body string) int {
+	n := d.reserve()
+	d.set(n, body)
+	return n
+}
+
+// addStream は Flate 圧縮したストリームオブジェクトを追加する
+func (d *pdfDocument) addStream(data []byte) int {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	n := d.reserve()
+	d.objects[n-1] = append([]byte(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n", buf.Len())),
+		append(buf.Bytes(), []byte("\nendstream")...)...)
+	return n
+}
+
+// bytes はクロスリファレンス表を含む PDF ファイル全体を返す
+func (d *pdfDocument) bytes(root, info int) []byte {
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(body)
+		out.WriteString("\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(d.objects)+1, root, info, xref)
+	return out.Bytes()
+}
+
+// pdfTextString は文字列を UTF-16BE (BOM 付き) の16進文字列にする (文書情報用)
+func pdfTextString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// pdfCIDString は UniJIS-UCS2-H 用に文字列を UCS-2 の16進文字列にする。
+// BMP 外の文字は表示できないため〓に置き換える。
+func pdfCIDString(s string) string {
+	var b strings.Builder
+	b.WriteString("<")
+	for _, r := range s {
+		if r > 0xFFFF {
+			r = '〓'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// pdfNum は数値を PDF 用に短く整形する
+func pdfNum(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "0"
+	}
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// textWidthEm は文字列のおおよその幅 (em)。半角は 0.5em、それ以外は 1em とする。
+// フォントの /W 指定と一致させている。
+func textWidthEm(s string) float64 {
+	w := 0.0
+	for _, r := range s {
+		if r < 0x80 || (r >= 0xFF61 && r <= 0xFF9F) {
+			w += 0.5
+		} else {
+			w++
+		}
+	}
+	return w
+}
+
+// pdfCanvas はページの描画命令を組み立てる。座標は mm (原点は左下, Y 上向き)。
+type pdfCanvas struct {
+	b bytes.Buffer
+}
+
+func newPDFCanvas() *pdfCanvas {
+	c := &pdfCanvas{}
+	// 以降の座標を mm で指定できるようにする
+	fmt.Fprintf(&c.b, "%s 0 0 %s 0 0 cm\n1 J 1 j\n", pdfNum(mmToPt), pdfNum(mmToPt))
+	return c
+}
+
+func (c *pdfCanvas) op(format string, args ...interface{}) {
+	fmt.Fprintf(&c.b, format, args...)
+	c.b.WriteByte('\n')
+}
+
+func (c *pdfCanvas) save()    { c.op("q") }
+func (c *pdfCanvas) restore() { c.op("Q") }
+
+func (c *pdfCanvas) strokeColor(col rgbColor) {
+	c.op("%s %s %s RG", pdfNum(col.R), pdfNum(col.G), pdfNum(col.B))
+}
+
+func (c *pdfCanvas) fillColor(col rgbColor) {
+	c.op("%s %s %s rg", pdfNum(col.R), pdfNum(col.G), pdfNum(col.B))
+}
+
+func (c *pdfCanvas) lineWidth(mm float64) { c.op("%s w", pdfNum(mm)) }
+
+func (c *pdfCanvas) moveTo(p Vec2) { c.op("%s %s m", pdfNum(p.X), pdfNum(p.Y)) }
+func (c *pdfCanvas) lineTo(p Vec2) { c.op("%s %s l", pdfNum(p.X), pdfNum(p.Y)) }
+
+func (c *pdfCanvas) line(a, b Vec2) {
+	c.moveTo(a)
+	c.lineTo(b)
+	c.op("S")
+}
+
+// rect は矩形のパスを追加する。paint は "S" (線)・"f" (塗り)・"B" (塗りと線)。
+func (c *pdfCanvas) rect(x, y, w, h float64, paint string) {
+	c.op("%s %s %s %s re %s", pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h), paint)
+}
+
+// path は輪郭 (座標変換済み) を描く
+func (c *pdfCanvas) path(p shapePath, f func(Vec2) Vec2, paint string) {
+	if len(p) == 0 {
+		return
+	}
+	c.moveTo(f(p[0].P0))
+	for _, s := range p {
+		if s.Line {
+			c.lineTo(f(s.P3))
+			continue
+		}
+		p1, p2, p3 := f(s.P1), f(s.P2), f(s.P3)
+		c.op("%s %s %s %s %s %s c", pdfNum(p1.X), pdfNum(p1.Y), pdfNum(p2.X), pdfNum(p2.Y), pdfNum(p3.X), pdfNum(p3.Y))
+	}
+	c.op("h %s", paint)
+}
+
+// clip は以降の描画を矩形内に制限する (restore で解除)
+func (c *pdfCanvas) clip(x, y, w, h float64) {
+	c.op("%s %s %s %s re W n", pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h))
+}
+
+// text は文字列を描く。align は 0: 左揃え, 0.5: 中央, 1: 右揃え。rotation は度 (反時計回り)。
+func (c *pdfCanvas) text(pos Vec2, size float64, s string, align, rotation float64) {
+	if s == "" {
+		return
+	}
+	rad := rotation * math.Pi / 180
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	dx := -textWidthEm(s) * size * align
+	x := pos.X + dx*cos
+	y := pos.Y + dx*sin
+	c.op("BT /F1 %s Tf %s %s %s %s %s %s Tm %s Tj ET",
+		pdfNum(size), pdfNum(cos), pdfNum(sin), pdfNum(-sin), pdfNum(cos), pdfNum(x), pdfNum(y), pdfCIDString(s))
+}
+
+// buildPDF は canvases を1つずつのページにした PDF を生成する。pageW/pageH は mm で全ページ共通。
+func buildPDF(pageW, pageH float64, canvases []*pdfCanvas, title string, now time.Time) []byte {
+	var d pdfDocument
+	catalog := d.reserve()
+	pages := d.reserve()
+
+	descFont := d.add("<< /Type /FontDescriptor /FontName /HeiseiKakuGo-W5 /Flags 4 " +
+		"/FontBBox [-92 -250 1010 922] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 737 /StemV 114 >>")
+	// CID 1-95 (ASCII) と 231-632 (半角形) は半角幅
+	cidFont := d.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /HeiseiKakuGo-W5 "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 2 >> "+
+		"/FontDescriptor %d 0 R /DW 1000 /W [1 95 500 231 632 500] >>", descFont))
+	font := d.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /HeiseiKakuGo-W5-UniJIS-UCS2-H "+
+		"/Encoding /UniJIS-UCS2-H /DescendantFonts [%d 0 R] >>", cidFont))
+
+	var kids []string
+	for _, canvas := range canvases {
+		content := d.addStream(canvas.b.Bytes())
+		page := d.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pages, pdfNum(pageW*mmToPt), pdfNum(pageH*mmToPt), font, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	d.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	d.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+
+	info := d.add(fmt.Sprintf("<< /Title %s /Producer (roomGenerator) /CreationDate (D:%s) >>",
+		pdfTextString(title), now.UTC().Format("20060102150405Z")))
+	return d.bytes(catalog, info)
+}
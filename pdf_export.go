@@ -0,0 +1,383 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// --- PDF 図面シート ---
+// 用紙の左下を原点とした mm 座標でレイアウトし、図面は指定縮尺の実寸で描く。
+
+// paperSizesMM は縦向きの用紙寸法 (幅, 高さ)
+var paperSizesMM = map[string][2]float64{
+	"A4": {210, 297},
+	"A3": {297, 420},
+}
+
+// standardScales は縮尺自動選択の候補 (分母)
+var standardScales = []float64{10, 20, 30, 50, 100, 150, 200, 300, 500, 1000}
+
+const (
+	sheetMarginMM  = 10.0
+	sheetGapMM     = 4.0
+	titleStripHMM  = 28.0
+	areaTableWMM   = 70.0
+	areaRowHMM     = 5.0
+	areaTextMM     = 2.6
+	scaleBarMaxMM  = 50.0
+	scaleBarHMM    = 2.0
+	northArrowHMM  = 14.0
+	sheetBorderMM  = 0.5
+	sheetRuleMM    = 0.25
+	pdfRoomLineMM  = 0.35
+	pdfItemLineMM  = 0.18
+	pdfGridLineMM  = 0.08
+	pdfLabelMM     = 3.0
+	pdfLabelAreaMM = 2.2
+)
+
+var (
+	pdfBlack     = rgbColor{0, 0, 0}
+	pdfWhite     = rgbColor{1, 1, 1}
+	pdfDarkGray  = rgbColor{0.2, 0.2, 0.2}
+	pdfMidGray   = rgbColor{0.4, 0.4, 0.4}
+	pdfGridColor = rgbColor{0.85, 0.85, 0.85}
+)
+
+// mmRect は用紙上の矩形 (左下原点)
+type mmRect struct{ X, Y, W, H float64 }
+
+func (r mmRect) center() Vec2 { return Vec2{X: r.X + r.W/2, Y: r.Y + r.H/2} }
+
+// sheetLayout は用紙上の各領域
+type sheetLayout struct {
+	PageW, PageH float64
+	Drawing      mmRect
+	Title        mmRect
+	Table        mmRect // AreaTable が false の場合は空
+}
+
+// normalizePDFOptions は用紙サイズと向きの既定値を補い、不正な値を検出する
+func normalizePDFOptions(opts PDFExportOptions) (PDFExportOptions, error) {
+	opts.PaperSize = strings.ToUpper(strings.TrimSpace(opts.PaperSize))
+	if opts.PaperSize == "" {
+		opts.PaperSize = "A4"
+	}
+	if _, ok := paperSizesMM[opts.PaperSize]; !ok {
+		return opts, fmt.Errorf("unsupported paper size: %s", opts.PaperSize)
+	}
+	switch opts.Orientation {
+	case "":
+		opts.Orientation = "landscape"
+	case "portrait", "landscape":
+	default:
+		return opts, fmt.Errorf("unsupported orientation: %s", opts.Orientation)
+	}
+	return opts, nil
+}
+
+// layoutSheet は用紙を図面・タイトル欄・面積表の領域に分割する
+func layoutSheet(opts PDFExportOptions) sheetLayout {
+	size := paperSizesMM[opts.PaperSize]
+	w, h := size[0], size[1]
+	if opts.Orientation == "landscape" {
+		w, h = h, w
+	}
+	l := sheetLayout{PageW: w, PageH: h}
+	inner := mmRect{X: sheetMarginMM, Y: sheetMarginMM, W: w - 2*sheetMarginMM, H: h - 2*sheetMarginMM}
+	l.Title = mmRect{X: inner.X, Y: inner.Y, W: inner.W, H: titleStripHMM}
+
+	top := inner.Y + titleStripHMM
+	l.Drawing = mmRect{X: inner.X, Y: top, W: inner.W, H: inner.Y + inner.H - top}
+	if opts.AreaTable {
+		l.Table = mmRect{X: inner.X + inner.W - areaTableWMM, Y: top, W: areaTableWMM, H: l.Drawing.H}
+		l.Drawing.W -= areaTableWMM
+	}
+	return l
+}
+
+// fitsScale は図面が縮尺 1:scale で領域に収まるかどうか (領域内の余白を含む)
+func fitsScale(bounds bbox, area mmRect, scale float64) bool {
+	k := 10 / scale
+	return bounds.width()*k <= area.W-2*sheetGapMM && bounds.height()*k <= area.H-2*sheetGapMM
+}
+
+// chooseScale は領域に収まる最も大きな標準縮尺 (最小の分母) を返す
+func chooseScale(bounds bbox, area mmRect) (float64, bool) {
+	for _, s := range standardScales {
+		if fitsScale(bounds, area, s) {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// scaleBarLength はスケールバーの長さ (cm) を用紙上 scaleBarMaxMM 以下で選ぶ
+func scaleBarLength(scale float64) float64 {
+	best := 50.0
+	for _, cm := range []float64{50, 100, 200, 500, 1000, 2000, 5000, 10000} {
+		if cm*10/scale <= scaleBarMaxMM {
+			best = cm
+		}
+	}
+	return best
+}
+
+// renderPlanPDF は図面シートの PDF を生成する。opts.Plan.Scale が 0 の場合は縮尺を自動で選ぶ。
+func renderPlanPDF(p plan, areas ProjectAreas, opts PDFExportOptions, title planTitle, now time.Time) ([]byte, error) {
+	opts, err := normalizePDFOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	layout := layoutSheet(opts)
+	bounds := planBounds(p)
+	sheetName := opts.PaperSize + " " + opts.Orientation
+
+	if opts.Plan.Scale == 0 {
+		scale, ok := chooseScale(bounds, layout.Drawing)
+		if !ok {
+			return nil, fmt.Errorf("plan does not fit on %s at 1:%s", sheetName, pdfNum(standardScales[len(standardScales)-1]))
+		}
+		opts.Plan.Scale = scale
+	}
+	if opts.Plan, err = normalizePlanOptions(opts.Plan); err != nil {
+		return nil, err
+	}
+	if !fitsScale(bounds, layout.Drawing, opts.Plan.Scale) {
+		if s, ok := chooseScale(bounds, layout.Drawing); ok {
+			return nil, fmt.Errorf("plan does not fit on %s at 1:%s (use 1:%s or a larger sheet)",
+				sheetName, pdfNum(opts.Plan.Scale), pdfNum(s))
+		}
+		return nil, fmt.Errorf("plan does not fit on %s at 1:%s", sheetName, pdfNum(opts.Plan.Scale))
+	}
+
+	c := newPDFCanvas()
+	c.strokeColor(pdfBlack)
+	c.lineWidth(sheetBorderMM)
+	c.rect(sheetMarginMM, sheetMarginMM, layout.PageW-2*sheetMarginMM, layout.PageH-2*sheetMarginMM, "S")
+
+	drawPDFPlan(c, p, opts.Plan, bounds, layout.Drawing)
+	if opts.AreaTable {
+		drawPDFAreaTable(c, areas, layout.Table)
+	}
+	name := title.Name
+	if opts.Plan.Title != "" {
+		name = opts.Plan.Title
+	}
+	drawPDFTitleBlock(c, name, formatPlanDate(title.UpdatedAt), opts, layout.Title)
+
+	return buildPDF(layout.PageW, layout.PageH, []*pdfCanvas{c}, name, now), nil
+}
+
+// drawPDFPlan は図面を領域の中央に実寸で描く
+func drawPDFPlan(c *pdfCanvas, p plan, opts PlanExportOptions, bounds bbox, area mmRect) {
+	k := 10 / opts.Scale
+	center := bounds.center()
+	toPaper := func(v Vec2) Vec2 {
+		return vadd(area.center(), vscale(vsub(v, center), k))
+	}
+
+	c.save()
+	c.clip(area.X, area.Y, area.W, area.H)
+
+	if opts.ShowGrid {
+		c.strokeColor(pdfGridColor)
+		c.lineWidth(pdfGridLineMM)
+		// 図面の外側まで用紙の描画領域全体にグリッドを引く
+		minW := vadd(center, vscale(vsub(Vec2{X: area.X, Y: area.Y}, area.center()), 1/k))
+		maxW := vadd(center, vscale(vsub(Vec2{X: area.X + area.W, Y: area.Y + area.H}, area.center()), 1/k))
+		s := opts.GridSpacing
+		for x := math.Ceil(minW.X/s) * s; x <= maxW.X; x += s {
+			c.line(toPaper(Vec2{X: x, Y: minW.Y}), toPaper(Vec2{X: x, Y: maxW.Y}))
+		}
+		for y := math.Ceil(minW.Y/s) * s; y <= maxW.Y; y += s {
+			c.line(toPaper(Vec2{X: minW.X, Y: y}), toPaper(Vec2{X: maxW.X, Y: y}))
+		}
+	}
+
+	for _, s := range p.Shapes {
+		c.strokeColor(pdfMidGray)
+		c.lineWidth(pdfItemLineMM)
+		if s.AssetType == "room" || s.AssetType == wallType {
+			c.strokeColor(pdfDarkGray)
+			c.lineWidth(pdfRoomLineMM)
+		}
+		paint := "S"
+		if fill, ok := parseColor(s.Fill); ok {
+			c.fillColor(fill)
+			paint = "B"
+		}
+		c.path(s.Path, toPaper, paint)
+	}
+
+	if opts.RoomLabels {
+		c.fillColor(pdfDarkGray)
+		for _, l := range p.Labels {
+			pos := toPaper(l.Pos)
+			c.text(pos, pdfLabelMM, l.Name, 0.5, 0)
+			if l.AreaM2 > 0 {
+				c.text(Vec2{X: pos.X, Y: pos.Y - pdfLabelMM - 0.5}, pdfLabelAreaMM, formatJo(l.AreaM2, opts.TatamiSize), 0.5, 0)
+			}
+		}
+	}
+
+	for _, t := range p.Texts {
+		col, ok := parseColor(t.Color)
+		if !ok {
+			col = pdfBlack
+		}
+		c.fillColor(col)
+		align := 0.0
+		if t.Middle {
+			align = 0.5
+		}
+		c.text(toPaper(t.Pos), t.Size*k, t.Text, align, t.Rotation)
+	}
+	c.restore()
+}
+
+// drawPDFTitleBlock はプロジェクト名・日付・縮尺・スケールバー・方位記号を描く
+func drawPDFTitleBlock(c *pdfCanvas, name, date string, opts PDFExportOptions, r mmRect) {
+	c.strokeColor(pdfBlack)
+	c.lineWidth(sheetRuleMM)
+	c.rect(r.X, r.Y, r.W, r.H, "S")
+
+	// 右から方位記号 (24mm)・スケールバー (70mm)・名称欄の順に区切る
+	northW, barW := 24.0, 70.0
+	barX := r.X + r.W - northW - barW
+	northX := r.X + r.W - northW
+	c.line(Vec2{X: barX, Y: r.Y}, Vec2{X: barX, Y: r.Y + r.H})
+	c.line(Vec2{X: northX, Y: r.Y}, Vec2{X: northX, Y: r.Y + r.H})
+
+	c.fillColor(pdfBlack)
+	c.text(Vec2{X: r.X + 4, Y: r.Y + r.H - 10}, 6, name, 0, 0)
+	details := "S=1:" + pdfNum(opts.Plan.Scale) + " (" + opts.PaperSize + ")"
+	if date != "" {
+		details = date + "    " + details
+	}
+	c.text(Vec2{X: r.X + 4, Y: r.Y + 6}, 3.2, details, 0, 0)
+
+	// スケールバー: 4分割の白黒交互
+	lengthCM := scaleBarLength(opts.Plan.Scale)
+	barLen := lengthCM * 10 / opts.Plan.Scale
+	bx := barX + (barW-barLen)/2
+	by := r.Y + r.H/2 - scaleBarHMM/2
+	c.lineWidth(sheetRuleMM)
+	for i := 0; i < 4; i++ {
+		col := pdfWhite
+		if i%2 == 0 {
+			col = pdfBlack
+		}
+		c.fillColor(col)
+		c.rect(bx+barLen*float64(i)/4, by, barLen/4, scaleBarHMM, "B")
+	}
+	c.fillColor(pdfBlack)
+	c.text(Vec2{X: bx, Y: by + scaleBarHMM + 1.5}, 2.5, "0", 0.5, 0)
+	c.text(Vec2{X: bx + barLen, Y: by + scaleBarHMM + 1.5}, 2.5, pdfNum(lengthCM/100)+"m", 0.5, 0)
+
+	// 方位記号: 図面の上方向を北とする
+	tip := Vec2{X: northX + northW/2, Y: r.Y + r.H/2 + northArrowHMM/2 - 3}
+	base := tip.Y - northArrowHMM + 2
+	c.moveTo(tip)
+	c.lineTo(Vec2{X: tip.X + 3.5, Y: base})
+	c.lineTo(Vec2{X: tip.X, Y: base + 3})
+	c.lineTo(Vec2{X: tip.X - 3.5, Y: base})
+	c.op("h B")
+	c.text(Vec2{X: tip.X, Y: tip.Y + 1.5}, 3.5, "N", 0.5, 0)
+}
+
+// drawPDFAreaTable は部屋ごとの面積と合計の表を描く。収まらない部屋はまとめて件数のみ表示する。
+func drawPDFAreaTable(c *pdfCanvas, areas ProjectAreas, r mmRect) {
+	r = mmRect{X: r.X + sheetGapMM, Y: r.Y + sheetGapMM, W: r.W - 2*sheetGapMM, H: r.H - 2*sheetGapMM}
+	// 室名は左揃え、数値列は右揃え
+	cols := []struct {
+		title string
+		x     float64
+	}{
+		{"室名", r.X + 2},
+		{"m²", r.X + 40},
+		{"畳", r.X + 52},
+		{"坪", r.X + r.W - 2},
+	}
+	row := func(y float64, name string, a FloorArea) {
+		c.text(Vec2{X: cols[0].x, Y: y}, areaTextMM, name, 0, 0)
+		c.text(Vec2{X: cols[1].x, Y: y}, areaTextMM, fmt.Sprintf("%.2f", a.M2), 1, 0)
+		c.text(Vec2{X: cols[2].x, Y: y}, areaTextMM, fmt.Sprintf("%.1f", a.Jo), 1, 0)
+		c.text(Vec2{X: cols[3].x, Y: y}, areaTextMM, fmt.Sprintf("%.2f", a.Tsubo), 1, 0)
+	}
+
+	c.strokeColor(pdfBlack)
+	c.lineWidth(sheetRuleMM)
+	c.fillColor(pdfBlack)
+
+	top := r.Y + r.H
+	y := top - areaRowHMM + 1.5
+	c.text(Vec2{X: cols[0].x, Y: y}, areaTextMM, "面積表 ("+tatamiLabel(areas.TatamiSize)+")", 0, 0)
+	y -= areaRowHMM
+	for i := 0; i < 4; i++ {
+		align := 1.0
+		if i == 0 {
+			align = 0
+		}
+		c.text(Vec2{X: cols[i].x, Y: y}, areaTextMM, cols[i].title, align, 0)
+	}
+	c.line(Vec2{X: r.X, Y: y - 1.5}, Vec2{X: r.X + r.W, Y: y - 1.5})
+
+	maxRows := int((y - r.Y - 2*areaRowHMM) / areaRowHMM)
+	rooms := areas.Rooms
+	hidden := 0
+	if len(rooms) > maxRows {
+		hidden = len(rooms) - maxRows + 1
+		rooms = rooms[:maxRows-1]
+	}
+	for _, room := range rooms {
+		y -= areaRowHMM
+		row(y, room.Name, room.Area)
+	}
+	if hidden > 0 {
+		y -= areaRowHMM
+		c.text(Vec2{X: cols[0].x, Y: y}, areaTextMM, fmt.Sprintf("他 %d 室", hidden), 0, 0)
+	}
+	c.line(Vec2{X: r.X, Y: y - 1.5}, Vec2{X: r.X + r.W, Y: y - 1.5})
+	y -= areaRowHMM
+	row(y, "合計", areas.Total)
+	c.rect(r.X, y-1.5, r.W, top-(y-1.5), "S")
+}
+
+// tatamiLabel は畳サイズの表示名
+func tatamiLabel(size string) string {
+	switch size {
+	case TatamiChukyoma:
+		return "中京間"
+	case TatamiKyoma:
+		return "京間"
+	}
+	return "江戸間"
+}
+
+// ExportProjectPDF renders the project as a print-ready PDF plan sheet at true scale.
+// The result is the PDF file content (base64-encoded on the frontend side).
+func (a *App) ExportProjectPDF(id string, options PDFExportOptions) ([]byte, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return nil, err
+	}
+	// 複数階のプロジェクトは指定した階 (省略時は最初の階) だけを描く
+	data, level, err := planLevel(data, assets, options.Plan.Level)
+	if err != nil {
+		return nil, err
+	}
+	areas, err := computeProjectAreas(data, assets, options.Plan.TatamiSize)
+	if err != nil {
+		return nil, err
+	}
+	pdf, err := renderPlanPDF(buildPlan(data, assets), areas, options, levelTitle(a.planTitleFor(id), level), time.Now())
+	if err != nil {
+		a.logError("PDF エクスポート失敗 (ID: %s): %v", id, err)
+		return nil, err
+	}
+	a.logInfo("PDF エクスポート: %s", id)
+	return pdf, nil
+}
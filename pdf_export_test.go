@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRenderPlanPDF は PDF の構造・縮尺の自動選択・収まらない場合のエラーを検証します
+func TestRenderPlanPDF(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	data := ProjectData{Instances: []Instance{{ID: "r1", AssetID: "a_room6", Type: "room"}}}
+	assets := newAssetIndex(nil, globals)
+	areas, err := computeProjectAreas(data, assets, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := buildPlan(data, assets)
+
+	opts := PDFExportOptions{Plan: PlanExportOptions{RoomLabels: true}, AreaTable: true}
+	pdf, err := renderPlanPDF(p, areas, opts, planTitle{Name: "テスト邸"}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("PDF のヘッダー・トレーラーが不正です")
+	}
+
+	// クロスリファレンス表の各オフセットがオブジェクトの先頭を指していること
+	startxref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(pdf)
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		off, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if !bytes.HasPrefix(pdf[off:], []byte(fmt.Sprintf("%d 0 obj", i))) {
+			t.Errorf("オブジェクト %d のオフセットが不正です", i)
+		}
+	}
+
+	// 360×270cm の部屋は A4 横 (面積表あり) に 1:20 で収まる
+	content := pdfContentStream(t, pdf)
+	if !strings.Contains(content, pdfCIDString("S=1:20 (A4)")) {
+		t.Error("縮尺が自動選択されていません")
+	}
+	for _, want := range []string{"テスト邸", "洋室 (6畳)", "合計", "N"} {
+		if !strings.Contains(content, pdfCIDString(want)) {
+			t.Errorf("%q が描画されていません", want)
+		}
+	}
+
+	opts.Plan.Scale = 10
+	if _, err := renderPlanPDF(p, areas, opts, planTitle{}, time.Now()); err == nil || !strings.Contains(err.Error(), "1:20") {
+		t.Errorf("用紙に収まらない縮尺でエラーになっていません: %v", err)
+	}
+	opts.PaperSize = "B5"
+	if _, err := renderPlanPDF(p, areas, opts, planTitle{}, time.Now()); err == nil {
+		t.Error("未対応の用紙サイズでエラーになっていません")
+	}
+}
+
+// pdfContentStream はページの描画命令 (Flate 圧縮) を展開して返す
+func pdfContentStream(t *testing.T, pdf []byte) string {
+	t.Helper()
+	start := bytes.Index(pdf, []byte("stream\n"))
+	end := bytes.Index(pdf, []byte("\nendstream"))
+	if start < 0 || end < 0 {
+		t.Fatal("ストリームが見つかりません")
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(pdf[start+len("stream\n") : end]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"math"
+)
+
+// --- PNG エクスポート ---
+// 図面を画像にする。DPI を指定すると指定の縮尺で印刷した大きさ、幅・高さを指定するとその大きさに収めて描く。
+// 図形の塗りと輪郭・グリッドだけを描き、文字 (部屋名・テキスト) は描かない。
+
+const (
+	defaultRasterDPI = 96.0
+	maxRasterPixels  = 50000000 // 画像の画素数の上限
+	fitMarginPx      = 8.0      // 大きさを指定した場合の余白 (px)
+	fitRoomStrokePx  = 1.0
+	fitItemStrokePx  = 0.6
+)
+
+// rasterLayout は図面を画像に描く大きさと線の太さ (px)
+type rasterLayout struct {
+	Width, Height int
+	Mapper        paperMapper
+	RoomStroke    float64
+	ItemStroke    float64
+	GridStroke    float64
+}
+
+// newRasterLayout は PNG の大きさと図面の配置を決める。opts.Plan は normalizePlanOptions 済みであること。
+func newRasterLayout(bounds bbox, opts PNGExportOptions) (rasterLayout, error) {
+	var l rasterLayout
+	if opts.Width < 0 || opts.Height < 0 {
+		return l, fmt.Errorf("invalid image size: %dx%d", opts.Width, opts.Height)
+	}
+	bw, bh := math.Max(bounds.width(), 1), math.Max(bounds.height(), 1)
+	if opts.Width > 0 || opts.Height > 0 {
+		// 大きさに収める。片方だけの指定は図面の縦横比に合わせる
+		k := math.Inf(1)
+		if opts.Width > 0 {
+			k = math.Min(k, (float64(opts.Width)-2*fitMarginPx)/bw)
+		}
+		if opts.Height > 0 {
+			k = math.Min(k, (float64(opts.Height)-2*fitMarginPx)/bh)
+		}
+		if k <= 0 {
+			return l, fmt.Errorf("image too small: %dx%d", opts.Width, opts.Height)
+		}
+		l.Width, l.Height = opts.Width, opts.Height
+		if l.Width == 0 {
+			l.Width = int(math.Ceil(bw*k + 2*fitMarginPx))
+		}
+		if l.Height == 0 {
+			l.Height = int(math.Ceil(bh*k + 2*fitMarginPx))
+		}
+		offset := Vec2{X: (float64(l.Width) - bw*k) / 2, Y: (float64(l.Height) - bh*k) / 2}
+		l.Mapper = paperMapper{origin: Vec2{X: bounds.Min.X, Y: bounds.Max.Y}, k: k, offset: offset}
+		l.RoomStroke, l.ItemStroke, l.GridStroke = fitRoomStrokePx, fitItemStrokePx, fitItemStrokePx
+	} else {
+		dpi := opts.DPI
+		if dpi == 0 {
+			dpi = defaultRasterDPI
+		}
+		if dpi < 0 || math.IsNaN(dpi) || math.IsInf(dpi, 0) {
+			return l, fmt.Errorf("invalid dpi: %v", opts.DPI)
+		}
+		pxPerMM := dpi / 25.4
+		margin := svgMarginMM * pxPerMM
+		k := 10 / opts.Plan.Scale * pxPerMM
+		l.Width = int(math.Ceil(bw*k + 2*margin))
+		l.Height = int(math.Ceil(bh*k + 2*margin))
+		l.Mapper = paperMapper{origin: Vec2{X: bounds.Min.X, Y: bounds.Max.Y}, k: k, offset: Vec2{X: margin, Y: margin}}
+		l.RoomStroke = math.Max(roomStrokeMM*pxPerMM, 1)
+		l.ItemStroke = math.Max(itemStrokeMM*pxPerMM, 0.6)
+		l.GridStroke = math.Max(gridStrokeMM*pxPerMM, 0.6)
+	}
+	if l.Width <= 0 || l.Height <= 0 || float64(l.Width)*float64(l.Height) > maxRasterPixels {
+		return l, fmt.Errorf("image too large: %dx%d px", l.Width, l.Height)
+	}
+	return l, nil
+}
+
+// renderPlanPNG は図面を PNG にする。opts.Plan は normalizePlanOptions 済みであること。
+func renderPlanPNG(p plan, opts PNGExportOptions) ([]byte, error) {
+	bounds := planBounds(p)
+	l, err := newRasterLayout(bounds, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := newCanvas(l.Width, l.Height, rgbColor{1, 1, 1})
+	m := l.Mapper
+
+	if opts.Plan.ShowGrid {
+		grid, _ := parseColor("#ddd")
+		spacing := opts.Plan.GridSpacing
+		var lines [][]Vec2
+		for x := math.Ceil(bounds.Min.X/spacing) * spacing; x <= bounds.Max.X+1e-9; x += spacing {
+			lines = append(lines, strokePolys([]Vec2{m.point(Vec2{X: x, Y: bounds.Min.Y}), m.point(Vec2{X: x, Y: bounds.Max.Y})}, l.GridStroke, false)...)
+		}
+		for y := math.Ceil(bounds.Min.Y/spacing) * spacing; y <= bounds.Max.Y+1e-9; y += spacing {
+			lines = append(lines, strokePolys([]Vec2{m.point(Vec2{X: bounds.Min.X, Y: y}), m.point(Vec2{X: bounds.Max.X, Y: y})}, l.GridStroke, false)...)
+		}
+		c.fill(lines, grid)
+	}
+
+	roomStroke, _ := parseColor("#333")
+	itemStroke, _ := parseColor("#666")
+	for _, s := range p.Shapes {
+		poly := s.Path.transform(m.point).flatten(collisionCurveSteps)
+		if fill, ok := parseColor(s.Fill); ok {
+			c.fill([][]Vec2{poly}, fill)
+		}
+		if s.AssetType == "room" || s.AssetType == wallType {
+			c.stroke(poly, l.RoomStroke, true, roomStroke)
+		} else {
+			c.stroke(poly, l.ItemStroke, true, itemStroke)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportProjectPNG renders the project plan as a PNG image, either at opts.DPI for the plan printed
+// at opts.Plan.Scale or fitted into opts.Width×opts.Height pixels. Text and room labels are not drawn.
+// The result is the file content (base64-encoded on the frontend side).
+func (a *App) ExportProjectPNG(id string, opts PNGExportOptions) ([]byte, error) {
+	planOpts, err := normalizePlanOptions(opts.Plan)
+	if err != nil {
+		return nil, err
+	}
+	opts.Plan = planOpts
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err = planLevel(data, assets, opts.Plan.Level)
+	if err != nil {
+		return nil, err
+	}
+	img, err := renderPlanPNG(buildPlan(data, assets), opts)
+	if err != nil {
+		a.logError("PNG エクスポート失敗 (ID: %s): %v", id, err)
+		return nil, err
+	}
+	a.logInfo("PNG エクスポート: %s", id)
+	return img, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"testing"
+)
+
+// TestRenderPlanPNG は画像の大きさ・塗りと輪郭・取得時のサムネイル作成と保存時の破棄を検証します
+func TestRenderPlanPNG(t *testing.T) {
+	data := ProjectData{LocalAssets: []Asset{}, Instances: []Instance{{ID: "r1", AssetID: "a_room6", Type: "room"}}}
+	assets := newAssetIndex(nil, getDefaultGlobalAssets())
+	p := buildPlan(data, assets)
+	plan, _ := normalizePlanOptions(PlanExportOptions{})
+
+	decode := func(b []byte, err error) image.Image {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	rgb := func(c color.Color) [3]uint32 {
+		r, g, b, _ := c.RGBA()
+		return [3]uint32{r >> 8, g >> 8, b >> 8}
+	}
+
+	// 96dpi・1:100 で 360cm は 136px、余白は両側 10mm
+	img := decode(renderPlanPNG(p, PNGExportOptions{Plan: plan}))
+	if size := img.Bounds().Size(); size.X != 212 || size.Y != 178 {
+		t.Errorf("画像の大きさが不正です: %v", size)
+	}
+	if c := rgb(img.At(106, 89)); c != [3]uint32{0xfd, 0xfc, 0xdc} {
+		t.Errorf("部屋の塗りが不正です: %v", c)
+	}
+	if c := rgb(img.At(2, 2)); c != [3]uint32{255, 255, 255} {
+		t.Errorf("背景が白ではありません: %v", c)
+	}
+	if c := rgb(img.At(38, 89)); c[0] > 0xc0 {
+		t.Errorf("部屋の輪郭が描かれていません: %v", c)
+	}
+
+	// 幅だけを指定すると図面の縦横比で高さを決める
+	img = decode(renderPlanPNG(p, PNGExportOptions{Width: 320}))
+	if size := img.Bounds().Size(); size.X != 320 || size.Y != 244 {
+		t.Errorf("幅を指定した画像の大きさが不正です: %v", size)
+	}
+	if _, err := renderPlanPNG(p, PNGExportOptions{Plan: plan, DPI: 1e6}); err == nil {
+		t.Error("大きすぎる画像でエラーになっていません")
+	}
+
+	app := newTestApp(t)
+	proj, err := app.CreateProject("thumb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SaveProjectData(proj.ID, data); err != nil {
+		t.Fatal(err)
+	}
+	// サムネイルは保存時には描かず、取得時に作ってキャッシュする
+	if _, err := app.storage().LoadThumbnail(proj.ID); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("保存時にサムネイルが作られています: %v", err)
+	}
+	img = decode(app.GetProjectThumbnail(proj.ID))
+	if size := img.Bounds().Size(); size.X != thumbnailWidth || size.Y != thumbnailHeight {
+		t.Errorf("サムネイルの大きさが不正です: %v", size)
+	}
+	if _, err := app.storage().LoadThumbnail(proj.ID); err != nil {
+		t.Errorf("サムネイルがキャッシュされていません: %v", err)
+	}
+	// 保存すると古いサムネイルは捨てる
+	if err := app.SaveProjectData(proj.ID, ProjectData{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.storage().LoadThumbnail(proj.ID); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("保存前のサムネイルが残っています: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// --- プロジェクト共通ヘルパー ---
+
+// ProjectID はプロジェクトの ID。data/ 以下のファイル名 (project_<id>.json) やディレクトリ名 (snapshots/<id>/) になるため、
+// 外部から受け取った ID は parseProjectID で検証してから使う。
+type ProjectID string
+
+// maxProjectIDLength は ID の最大長 (UnixNano の10進表記は19桁)
+const maxProjectIDLength = 64
+
+// newProjectID は作成時刻から新しいプロジェクトの ID を生成する
+func newProjectID(now time.Time) ProjectID {
+	return ProjectID(strconv.FormatInt(now.UnixNano(), 10))
+}
+
+// parseProjectID は英数字・ハイフン・アンダースコアだけからなる ID を受け付ける。
+// パスの区切りや "." を含む ID は data/ の外を指しうるため拒否する。
+func parseProjectID(id string) (ProjectID, error) {
+	if id == "" {
+		return "", fmt.Errorf("project ID is empty")
+	}
+	if len(id) > maxProjectIDLength {
+		return "", fmt.Errorf("project ID is longer than %d characters", maxProjectIDLength)
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return "", fmt.Errorf("invalid project ID %q: only letters, digits, '-' and '_' are allowed", id)
+		}
+	}
+	return ProjectID(id), nil
+}
+
+// assetIndex はアセット ID からアセットを引く索引。
+// フロントエンドと同様に、同じ ID があればプロジェクトのローカルアセットを優先する。
+type assetIndex map[string]*Asset
+
+func newAssetIndex(local []Asset, global []Asset) assetIndex {
+	idx := assetIndex{}
+	for i := range global {
+		idx[global[i].ID] = &global[i]
+	}
+	for i := range local {
+		idx[local[i].ID] = &local[i]
+	}
+	return idx
+}
+
+// lookup はインスタンスが参照するアセットを返す。テキストや参照切れの場合は nil。
+func (idx assetIndex) lookup(inst Instance) *Asset {
+	if inst.Type == "text" || inst.AssetID == "" {
+		return nil
+	}
+	return idx[inst.AssetID]
+}
+
+// globalAssets はグローバルアセットを []Asset として返す
+func (a *App) globalAssets() []Asset {
+	raw, err := a.GetAssets()
+	if err != nil {
+		a.logError("グローバルアセットの読み込みに失敗しました: %v", err)
+		return getDefaultGlobalAssets()
+	}
+	if assets, ok := raw.([]Asset); ok {
+		return assets
+	}
+	return getDefaultGlobalAssets()
+}
+
+// loadProject はプロジェクトデータと、それを解決するためのアセット索引を返す
+func (a *App) loadProject(id string) (ProjectData, assetIndex, error) {
+	data, err := a.GetProjectData(id)
+	if err != nil {
+		return ProjectData{}, nil, err
+	}
+	return data, newAssetIndex(data.LocalAssets, a.globalAssets()), nil
+}
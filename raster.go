@@ -0,0 +1,152 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// --- ラスタライザ ---
+// 多角形を非ゼロ規則で塗る簡単なスキャンライン方式のラスタライザ。
+// 1ピクセルの行を rasterSubsamples 本の走査線で調べ、横方向は区間の長さから被覆率を求めてアンチエイリアスする。
+// 線は線分ごとの長方形 (端を線幅の半分だけ伸ばす) を塗って描く。
+
+const rasterSubsamples = 4
+
+// canvas は RGBA 画像への描画先
+type canvas struct {
+	img *image.RGBA
+}
+
+func newCanvas(w, h int, bg rgbColor) *canvas {
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, w, h))}
+	fill := color.RGBA{R: to8(bg.R), G: to8(bg.G), B: to8(bg.B), A: 255}
+	for i := 0; i < len(c.img.Pix); i += 4 {
+		c.img.Pix[i], c.img.Pix[i+1], c.img.Pix[i+2], c.img.Pix[i+3] = fill.R, fill.G, fill.B, fill.A
+	}
+	return c
+}
+
+func to8(v float64) uint8 { return uint8(math.Round(clamp01(v) * 255)) }
+
+// rasterCrossing は走査線と辺の交点
+type rasterCrossing struct {
+	X   float64
+	Dir int
+}
+
+// fill は多角形 (ピクセル座標) をまとめて非ゼロ規則で塗る
+func (c *canvas) fill(polys [][]Vec2, col rgbColor) {
+	var b bbox
+	for _, poly := range polys {
+		for _, v := range poly {
+			b.add(v)
+		}
+	}
+	size := c.img.Bounds().Size()
+	if !b.Valid || b.Max.X <= 0 || b.Max.Y <= 0 || b.Min.X >= float64(size.X) || b.Min.Y >= float64(size.Y) {
+		return
+	}
+	x0 := int(math.Max(0, math.Floor(b.Min.X)))
+	x1 := int(math.Min(float64(size.X), math.Ceil(b.Max.X)))
+	y0 := int(math.Max(0, math.Floor(b.Min.Y)))
+	y1 := int(math.Min(float64(size.Y), math.Ceil(b.Max.Y)))
+	cover := make([]float64, x1-x0)
+	var xs []rasterCrossing
+	for y := y0; y < y1; y++ {
+		for i := range cover {
+			cover[i] = 0
+		}
+		touched := false
+		for s := 0; s < rasterSubsamples; s++ {
+			sy := float64(y) + (float64(s)+0.5)/rasterSubsamples
+			xs = xs[:0]
+			for _, poly := range polys {
+				for i, a := range poly {
+					e := poly[(i+1)%len(poly)]
+					if a.Y == e.Y || sy < math.Min(a.Y, e.Y) || sy >= math.Max(a.Y, e.Y) {
+						continue
+					}
+					dir := 1
+					if e.Y < a.Y {
+						dir = -1
+					}
+					xs = append(xs, rasterCrossing{X: a.X + (sy-a.Y)*(e.X-a.X)/(e.Y-a.Y), Dir: dir})
+				}
+			}
+			sort.Slice(xs, func(i, j int) bool { return xs[i].X < xs[j].X })
+			winding := 0
+			for i, cr := range xs {
+				winding += cr.Dir
+				if winding != 0 && i+1 < len(xs) {
+					addCoverage(cover, cr.X-float64(x0), xs[i+1].X-float64(x0), 1.0/rasterSubsamples)
+					touched = true
+				}
+			}
+		}
+		if !touched {
+			continue
+		}
+		for x, a := range cover {
+			if a <= 0 {
+				continue
+			}
+			a = math.Min(a, 1)
+			i := c.img.PixOffset(x0+x, y)
+			px := c.img.Pix[i : i+3 : i+3]
+			for k, v := range [3]float64{col.R, col.G, col.B} {
+				px[k] = uint8(math.Round(float64(px[k])*(1-a) + v*255*a))
+			}
+		}
+	}
+}
+
+// addCoverage は走査線上の区間 [l, r) の被覆率 w をピクセルに加える
+func addCoverage(cover []float64, l, r, w float64) {
+	l = math.Max(l, 0)
+	r = math.Min(r, float64(len(cover)))
+	if r <= l {
+		return
+	}
+	il, ir := int(l), int(r)
+	if il == ir {
+		cover[il] += (r - l) * w
+		return
+	}
+	cover[il] += (float64(il+1) - l) * w
+	for i := il + 1; i < ir; i++ {
+		cover[i] += w
+	}
+	if ir < len(cover) {
+		cover[ir] += (r - float64(ir)) * w
+	}
+}
+
+// strokePolys は折れ線 (closed なら閉じた線) を幅 width の長方形の集まりにする
+func strokePolys(line []Vec2, width float64, closed bool) [][]Vec2 {
+	var polys [][]Vec2
+	n := len(line) - 1
+	if closed {
+		n = len(line)
+	}
+	for i := 0; i < n; i++ {
+		a, b := line[i], line[(i+1)%len(line)]
+		l := vlen(vsub(b, a))
+		if l < 1e-9 {
+			continue
+		}
+		d := vscale(vsub(b, a), width/2/l)
+		nrm := Vec2{X: -d.Y, Y: d.X}
+		a, b = vsub(a, d), vadd(b, d)
+		polys = append(polys, []Vec2{vadd(a, nrm), vsub(a, nrm), vsub(b, nrm), vadd(b, nrm)})
+	}
+	return polys
+}
+
+// stroke は折れ線を幅 width (ピクセル) で描く
+func (c *canvas) stroke(line []Vec2, width float64, closed bool, col rgbColor) {
+	if polys := strokePolys(line, width, closed); len(polys) > 0 {
+		c.fill(polys, col)
+	}
+}
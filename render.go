@@ -0,0 +1,261 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --- 図面の組み立て ---
+// ProjectData をワールド座標 (cm) の図形とテキストの一覧に変換する。
+// SVG・PDF などの各エクスポーターはこの plan を描画するだけにする。
+
+// baseScale はフロントエンドの BASE_SCALE (1cm あたりの SVG 単位)。
+// テキストインスタンスの fontSize はこの単位で指定されている。
+const baseScale = 2.0
+
+// 描画順 (フロントエンドの LAYERS と同じ)
+var layerOrder = map[string]int{"room": 0, "wall": 1, "fixture": 2, "furniture": 3, "text": 4}
+
+// planShape はワールド座標の閉じた輪郭
+type planShape struct {
+	Path       shapePath
+	Fill       string
+	Layer      string // エンティティのレイヤー名
+	AssetType  string // "room", "wall", "fixture", "furniture"
+	InstanceID string
+}
+
+// planText はワールド座標のテキスト。Pos はベースライン左端 (Anchor が middle の場合は中央)。
+type planText struct {
+	Pos      Vec2
+	Text     string
+	Size     float64 // 文字高さ (cm)
+	Color    string
+	Rotation float64 // 度 (反時計回り)
+	Middle   bool    // true: Pos を中心に配置する
+	Bold     bool
+}
+
+// planLabel は部屋名ラベル
+type planLabel struct {
+	Pos    Vec2
+	Name   string
+	AreaM2 float64
+}
+
+// plan は描画可能な図面全体
+type plan struct {
+	Shapes []planShape
+	Texts  []planText
+	Labels []planLabel
+	Bounds bbox
+}
+
+// instanceTransform はアセット座標をワールド座標に変換する関数を返す (回転してから平行移動)
+func instanceTransform(inst Instance) func(Vec2) Vec2 {
+	origin := Vec2{X: inst.X, Y: inst.Y}
+	return func(v Vec2) Vec2 {
+		return vadd(origin, rotateVec(v, inst.Rotation))
+	}
+}
+
+// assetOutlines はアセットの輪郭と塗り色を返す。エンティティが無い場合は w×h の矩形とする。
+func assetOutlines(asset Asset) ([]shapePath, []Entity) {
+	entities := asset.Entities
+	if len(entities) == 0 {
+		x, y, w, h := 0.0, 0.0, asset.W, asset.H
+		entities = []Entity{{Type: "rect", X: &x, Y: &y, W: &w, H: &h, Color: asset.Color}}
+	}
+	var paths []shapePath
+	var owners []Entity
+	for _, e := range entities {
+		if path, ok := entityOutline(e); ok {
+			paths = append(paths, path)
+			owners = append(owners, e)
+		}
+	}
+	return paths, owners
+}
+
+// sortedInstances はインスタンスを描画順 (部屋 → 壁 → 建具 → 家具 → テキスト) に並べ替えたコピーを返す
+func sortedInstances(instances []Instance, assets assetIndex) []Instance {
+	sorted := append([]Instance(nil), instances...)
+	order := func(inst Instance) int {
+		if inst.Type == "text" {
+			return layerOrder["text"]
+		}
+		if asset := assets.lookup(inst); asset != nil {
+			return layerOrder[asset.Type]
+		}
+		return layerOrder[inst.Type]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return order(sorted[i]) < order(sorted[j]) })
+	return sorted
+}
+
+// buildPlan はプロジェクトをワールド座標の図面に変換する。参照切れのインスタンスは無視する。
+func buildPlan(data ProjectData, assets assetIndex) plan {
+	var p plan
+	walls := map[string]*wallSegment{}
+	for _, w := range placeWalls(data, assets) {
+		walls[w.Inst.ID] = w
+	}
+	for _, inst := range sortedInstances(data.Instances, assets) {
+		tf := instanceTransform(inst)
+
+		if w := walls[inst.ID]; inst.Type == wallType {
+			if w == nil {
+				continue
+			}
+			fill := inst.Color
+			if fill == "" {
+				fill = wallColor
+			}
+			for _, poly := range w.polygons() {
+				p.Shapes = append(p.Shapes, planShape{
+					Path:       polylinePath(poly),
+					Fill:       fill,
+					Layer:      wallType,
+					AssetType:  wallType,
+					InstanceID: inst.ID,
+				})
+				for _, v := range poly {
+					p.Bounds.add(v)
+				}
+			}
+			continue
+		}
+
+		if inst.Type == "text" {
+			if strings.TrimSpace(inst.Text) == "" {
+				continue
+			}
+			size := derefOr(inst.FontSize, 24) / baseScale
+			p.Texts = append(p.Texts, planText{
+				Pos:      tf(Vec2{}),
+				Text:     inst.Text,
+				Size:     size,
+				Color:    inst.Color,
+				Rotation: inst.Rotation,
+				Bold:     true,
+			})
+			p.Bounds.add(tf(Vec2{}))
+			p.Bounds.add(tf(Vec2{X: size * float64(len([]rune(inst.Text))), Y: size}))
+			continue
+		}
+
+		asset := assets.lookup(inst)
+		if asset == nil {
+			continue
+		}
+		paths, owners := assetOutlines(*asset)
+		for i, path := range paths {
+			world := path.transform(tf)
+			fill := owners[i].Color
+			if fill == "" {
+				fill = asset.Color
+			}
+			p.Shapes = append(p.Shapes, planShape{
+				Path:       world,
+				Fill:       fill,
+				Layer:      owners[i].Layer,
+				AssetType:  asset.Type,
+				InstanceID: inst.ID,
+			})
+			wb := world.bounds()
+			p.Bounds.add(wb.Min)
+			p.Bounds.add(wb.Max)
+		}
+
+		// アセット内のテキストエンティティ
+		for _, e := range asset.Entities {
+			if e.Type != "text" || e.Text == "" {
+				continue
+			}
+			pos := tf(Vec2{X: deref(e.X), Y: deref(e.Y)})
+			color := e.Color
+			if color == "" {
+				color = "#333"
+			}
+			p.Texts = append(p.Texts, planText{
+				Pos:      pos,
+				Text:     e.Text,
+				Size:     derefOr(e.FontSize, 24) / baseScale,
+				Color:    color,
+				Rotation: inst.Rotation + deref(e.Rotation),
+			})
+		}
+
+		// 部屋名はフロントエンドと同じくアセット外接矩形の中心に置く
+		if asset.Type == "room" {
+			center := Vec2{X: deref(asset.BoundX) + asset.W/2, Y: deref(asset.BoundY) + asset.H/2}
+			p.Labels = append(p.Labels, planLabel{
+				Pos:    tf(center),
+				Name:   asset.Name,
+				AreaM2: cm2ToM2(assetArea(*asset)),
+			})
+		}
+	}
+	return p
+}
+
+// rgbColor は 0..1 の RGB 色
+type rgbColor struct{ R, G, B float64 }
+
+// namedColors は parseColor が解釈する色名 (CSS の基本色のみ)
+var namedColors = map[string]rgbColor{
+	"black": {0, 0, 0}, "white": {1, 1, 1}, "gray": {0.5, 0.5, 0.5}, "grey": {0.5, 0.5, 0.5},
+	"red": {1, 0, 0}, "green": {0, 0.5, 0}, "blue": {0, 0, 1}, "yellow": {1, 1, 0},
+	"orange": {1, 0.647, 0}, "brown": {0.647, 0.165, 0.165}, "pink": {1, 0.753, 0.796},
+}
+
+// parseColor は "#rgb" / "#rrggbb" (アルファ付きも可)・"rgb(r, g, b)"・基本色名を解釈する。
+// 透明や解釈できない色は false を返す。
+func parseColor(c string) (rgbColor, bool) {
+	c = strings.ToLower(strings.TrimSpace(c))
+	if named, ok := namedColors[c]; ok {
+		return named, true
+	}
+	if strings.HasPrefix(c, "#") {
+		hex := c[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 && len(hex) != 8 {
+			return rgbColor{}, false
+		}
+		v, err := strconv.ParseUint(hex[:6], 16, 32)
+		if err != nil {
+			return rgbColor{}, false
+		}
+		return rgbColor{float64(v>>16&0xff) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255}, true
+	}
+	if (strings.HasPrefix(c, "rgb(") || strings.HasPrefix(c, "rgba(")) && strings.HasSuffix(c, ")") {
+		inner := c[strings.Index(c, "(")+1 : len(c)-1]
+		parts := strings.Split(inner, ",")
+		if len(parts) < 3 {
+			return rgbColor{}, false
+		}
+		var rgb [3]float64
+		for i := 0; i < 3; i++ {
+			v, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+			if err != nil {
+				return rgbColor{}, false
+			}
+			rgb[i] = clamp01(v / 255)
+		}
+		return rgbColor{rgb[0], rgb[1], rgb[2]}, true
+	}
+	return rgbColor{}, false
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// --- JSON Schema ---
+// data/ のファイルとインポートする JSON の形式を Go の型から JSON Schema (draft 2020-12) として生成する。
+// スキーマはデータ形式の版 (currentSchemaVersion) ごとに schema/v<版>/ に置き、インポート時の検査にも使う。
+// 検査は生成するスキーマが使うキーワード ($ref・type・properties・required・items・additionalProperties・
+// minItems・minimum・maximum・anyOf・allOf・if/then) だけを解釈する。
+
+// jsonSchemaDraft は生成するスキーマの仕様
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// paletteDocument は palette.json の形式
+type paletteDocument struct {
+	Colors   []string          `json:"colors"`
+	Defaults map[string]string `json:"defaults"` // Default colour of each asset type
+	Labels   map[string]string `json:"labels"`   // Display name of each asset type
+}
+
+// schemaDocument は公開するスキーマの1つ。Versioned はトップレベルに schemaVersion を持つ保存ファイル。
+type schemaDocument struct {
+	Name      string
+	Title     string
+	Type      reflect.Type
+	Versioned bool
+}
+
+// schemaDocuments は公開するスキーマ
+var schemaDocuments = []schemaDocument{
+	{"project", "roomGenerator project data (data/project_<id>.json, ImportProject)", reflect.TypeOf(ProjectData{}), true},
+	{"assets", "roomGenerator global assets (data/global_assets.json, ImportGlobalAssets)", reflect.TypeOf(assetsDocument{}), true},
+	{"asset", "roomGenerator asset", reflect.TypeOf(Asset{}), false},
+	{"entity", "roomGenerator asset entity", reflect.TypeOf(Entity{}), false},
+	{"instance", "roomGenerator instance", reflect.TypeOf(Instance{}), false},
+	{"palette", "roomGenerator colour palette (data/palette.json)", reflect.TypeOf(paletteDocument{}), true},
+	{"settings", "roomGenerator settings (data/settings.json)", reflect.TypeOf(AppSettings{}), true},
+	{"projects", "roomGenerator project index (data/projects_index.json)", reflect.TypeOf(projectIndexDocument{}), true},
+}
+
+// schemaRequired は型ごとの必須プロパティ。読み込み時に補えるものは必須にしない。
+var schemaRequired = map[string][]string{
+	"ProjectData":          {"assets", "instances"},
+	"assetsDocument":       {"assets"},
+	"projectIndexDocument": {"projects"},
+	"Project":              {"id", "name"},
+	"Asset":                {"id", "type"},
+	"Entity":               {"type"},
+	"Instance":             {"id", "type"},
+	"Level":                {"id"},
+	"Point":                {"x", "y"},
+	"Vec2":                 {"x", "y"},
+}
+
+// requireAny は props のいずれかが必要であることを表すスキーマ
+func requireAny(description string, props ...string) map[string]interface{} {
+	var anyOf []interface{}
+	for _, p := range props {
+		anyOf = append(anyOf, map[string]interface{}{"required": []string{p}})
+	}
+	return map[string]interface{}{"description": description, "anyOf": anyOf}
+}
+
+// entityTypeRule は Entity の type ごとの規則 (if/then)
+func entityTypeRule(types []string, then map[string]interface{}) map[string]interface{} {
+	cond := map[string]interface{}{"required": []string{"type"}, "properties": map[string]interface{}{"type": map[string]interface{}{"enum": types}}}
+	return map[string]interface{}{"if": cond, "then": then}
+}
+
+// schemaRules は型ごとに追加する規則。Entity は種類によって必要な値が違う (validateEntity と同じ規則)。
+var schemaRules = map[string][]interface{}{
+	"Entity": {
+		entityTypeRule([]string{"polygon"}, map[string]interface{}{
+			"required":   []string{"points"},
+			"properties": map[string]interface{}{"points": map[string]interface{}{"type": "array", "minItems": 3}},
+		}),
+		entityTypeRule([]string{"rect"}, map[string]interface{}{"required": []string{"x", "y", "w", "h"}}),
+		entityTypeRule([]string{"circle", "ellipse", "arc"}, requireAny("circle, ellipse and arc need the radius rx or the width w", "rx", "w")),
+		entityTypeRule([]string{"ellipse", "arc"}, requireAny("ellipse and arc need the radius ry or the height h", "ry", "h")),
+	},
+}
+
+// schemaBuilder は Go の型からスキーマを組み立てる。構造体は $defs に1度だけ定義する。
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+// nullable は Go が null を書き出す値 (nil のポインタ・スライス・マップ) の型
+func nullable(t string) []string { return []string{t, "null"} }
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := b.typeSchema(t.Elem())
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = nullable(typ)
+			return s
+		}
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "null"}, s}}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float64, reflect.Float32:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": nullable("array"), "items": b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": nullable("object"), "additionalProperties": b.typeSchema(t.Elem())}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		if _, ok := b.defs[t.Name()]; !ok {
+			b.defs[t.Name()] = nil // 再帰する型のために先に登録する
+			b.defs[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	}
+	panic("unsupported type in schema: " + t.String())
+}
+
+// structSchema は json タグに従って構造体のプロパティを並べる
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = b.typeSchema(f.Type)
+	}
+	s := map[string]interface{}{"type": "object", "properties": props}
+	if req := schemaRequired[t.Name()]; len(req) > 0 {
+		s["required"] = req
+	}
+	if rules := schemaRules[t.Name()]; len(rules) > 0 {
+		s["allOf"] = rules
+	}
+	return s
+}
+
+// buildJSONSchema は公開するスキーマの1つを生成する
+func buildJSONSchema(doc schemaDocument) map[string]interface{} {
+	b := &schemaBuilder{defs: map[string]interface{}{}}
+	root := b.structSchema(doc.Type)
+	delete(b.defs, doc.Type.Name())
+	if doc.Versioned {
+		root["properties"].(map[string]interface{})["schemaVersion"] = map[string]interface{}{
+			"type": "integer", "minimum": 0, "maximum": currentSchemaVersion,
+			"description": "Version of the data format. Older files are migrated when they are loaded.",
+		}
+	}
+	root["$schema"] = jsonSchemaDraft
+	root["$id"] = fmt.Sprintf("urn:roomGenerator:schema:v%d:%s", currentSchemaVersion, doc.Name)
+	root["title"] = doc.Title
+	if len(b.defs) > 0 {
+		root["$defs"] = b.defs
+	}
+	return root
+}
+
+// findSchemaDocument は名前から公開するスキーマを探す
+func findSchemaDocument(name string) (schemaDocument, error) {
+	for _, doc := range schemaDocuments {
+		if doc.Name == name {
+			return doc, nil
+		}
+	}
+	return schemaDocument{}, fmt.Errorf("unknown schema: %s", name)
+}
+
+// marshalJSONSchema はスキーマをファイルに書く形式にする
+func marshalJSONSchema(name string) ([]byte, error) {
+	doc, err := findSchemaDocument(name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(buildJSONSchema(doc), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// schemaFileName はスキーマを置くパス (出力先からの相対パス)
+func schemaFileName(name string) string {
+	return filepath.Join(fmt.Sprintf("v%d", currentSchemaVersion), name+".schema.json")
+}
+
+// writeJSONSchemas はすべてのスキーマを dir/v<版>/ に書き出す
+func writeJSONSchemas(dir string) ([]string, error) {
+	var written []string
+	for _, doc := range schemaDocuments {
+		out, err := marshalJSONSchema(doc.Name)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, schemaFileName(doc.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// --- スキーマによる検査 ---
+
+// schemaValidator は1つのスキーマ (JSON として読み直したもの) で文書を検査する
+type schemaValidator struct {
+	root   map[string]interface{}
+	errors []string
+}
+
+// maxSchemaErrors は報告するエラーの最大数
+const maxSchemaErrors = 20
+
+// validateAgainstSchema は文書を公開スキーマ name で検査し、パス付きのエラーを返す
+func validateAgainstSchema(name string, data []byte) ([]string, error) {
+	raw, err := marshalJSONSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	v := &schemaValidator{root: schema}
+	v.validate(schema, doc, "")
+	return v.errors, nil
+}
+
+// schemaError は検査エラーを import のエラーにする
+func schemaError(what string, errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	shown := errs
+	if len(shown) > 10 {
+		shown = shown[:10]
+	}
+	msg := fmt.Sprintf("%s does not match the schema:\n  %s", what, strings.Join(shown, "\n  "))
+	if len(errs) > len(shown) {
+		msg += fmt.Sprintf("\n  (and %d more)", len(errs)-len(shown))
+	}
+	return errors.New(msg)
+}
+
+// checkImport はインポートする文書を現在の版にしてからスキーマ name で検査する
+func checkImport(kind docKind, name string, data []byte) error {
+	data, _, _, err := migrateDocument(kind, data)
+	if err != nil {
+		return fmt.Errorf("invalid %s data: %v", kind, err)
+	}
+	errs, err := validateAgainstSchema(name, data)
+	if err != nil {
+		return fmt.Errorf("invalid %s data: %v", kind, err)
+	}
+	return schemaError("imported "+string(kind)+" data", errs)
+}
+
+func (v *schemaValidator) fail(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "(document)"
+	}
+	v.errors = append(v.errors, path+": "+fmt.Sprintf(format, args...))
+}
+
+// check はエラーを記録せずに適合するかだけを調べる (anyOf・if 用)
+func (v *schemaValidator) check(schema map[string]interface{}, doc interface{}) bool {
+	sub := &schemaValidator{root: v.root}
+	sub.validate(schema, doc, "")
+	return len(sub.errors) == 0
+}
+
+// jsonTypeName は JSON の値の型名
+func jsonTypeName(doc interface{}) string {
+	switch d := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if d == math.Trunc(d) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// nonNullForms は anyOf のうち null だけを許す形を除いたもの
+func nonNullForms(anyOf []interface{}) []map[string]interface{} {
+	var forms []map[string]interface{}
+	for _, s := range anyOf {
+		if sub, ok := s.(map[string]interface{}); ok && sub["type"] != "null" {
+			forms = append(forms, sub)
+		}
+	}
+	return forms
+}
+
+func typeMatches(want, got string) bool {
+	return want == got || (want == "number" && got == "integer")
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func (v *schemaValidator) validate(schema map[string]interface{}, doc interface{}, path string) {
+	if len(v.errors) >= maxSchemaErrors {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		defs, _ := v.root["$defs"].(map[string]interface{})
+		target, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if target == nil {
+			v.fail(path, "unresolved schema reference %s", ref)
+			return
+		}
+		v.validate(target, doc, path)
+		return
+	}
+
+	if t, ok := schema["type"]; ok {
+		var types []string
+		switch t := t.(type) {
+		case string:
+			types = []string{t}
+		case []interface{}:
+			for _, s := range t {
+				types = append(types, fmt.Sprint(s))
+			}
+		}
+		got := jsonTypeName(doc)
+		matched := false
+		for _, want := range types {
+			matched = matched || typeMatches(want, got)
+		}
+		if !matched {
+			v.fail(path, "expected %s, got %s", strings.Join(types, " or "), got)
+			return
+		}
+	}
+
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		if req, ok := schema["required"].([]interface{}); ok {
+			for _, r := range req {
+				if _, ok := d[r.(string)]; !ok {
+					v.fail(path, "missing required property %q", r)
+				}
+			}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k].(map[string]interface{}); ok {
+				v.validate(ps, d[k], joinPath(path, k))
+			} else if as, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+				v.validate(as, d[k], joinPath(path, k))
+			}
+		}
+	case []interface{}:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(d)) < min {
+			v.fail(path, "expected at least %d items, got %d", int(min), len(d))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range d {
+				v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && d < min {
+			v.fail(path, "must be at least %s", fmtNum(min))
+		}
+		if max, ok := schema["maximum"].(float64); ok && d > max {
+			v.fail(path, "must be at most %s", fmtNum(max))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == doc
+		}
+		if !found {
+			v.fail(path, "must be one of %v", enum)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, s := range anyOf {
+			if sub, ok := s.(map[string]interface{}); ok && v.check(sub, doc) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if desc, ok := schema["description"].(string); ok {
+				v.fail(path, "%s", desc)
+			} else if forms := nonNullForms(anyOf); doc != nil && len(forms) == 1 {
+				// null も許す値 (ポインタ) は null 以外の形の問題をそのまま報告する
+				v.validate(forms[0], doc, path)
+			} else {
+				v.fail(path, "does not match any of the allowed forms")
+			}
+		}
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, s := range allOf {
+			if sub, ok := s.(map[string]interface{}); ok {
+				v.validate(sub, doc, path)
+			}
+		}
+	}
+	if cond, ok := schema["if"].(map[string]interface{}); ok && v.check(cond, doc) {
+		if then, ok := schema["then"].(map[string]interface{}); ok {
+			v.validate(then, doc, path)
+		}
+	}
+}
+
+// GetJSONSchema returns the published JSON Schema of a data file: "project", "assets", "asset", "entity",
+// "instance", "palette" or "settings". The schemas describe the current data format version.
+func (a *App) GetJSONSchema(name string) (string, error) {
+	out, err := marshalJSONSchema(name)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
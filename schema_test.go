@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestJSONSchema は公開しているスキーマが Go の型から生成したものと一致し、インポートをパス付きのエラーで拒否することを検証します
+func TestJSONSchema(t *testing.T) {
+	// schema/ のファイルは `roomGenerator schema` で再生成する
+	for _, doc := range schemaDocuments {
+		want, err := marshalJSONSchema(doc.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join("schema", schemaFileName(doc.Name)))
+		if err != nil || string(got) != string(want) {
+			t.Errorf("schema/%s が古くなっています (roomGenerator schema で再生成してください): %v", schemaFileName(doc.Name), err)
+		}
+	}
+	if _, err := (&App{}).GetJSONSchema("unknown"); err == nil {
+		t.Error("不明なスキーマでエラーになっていません")
+	}
+
+	// 既定のアセットと書き出したプロジェクトはスキーマに適合する
+	app := newTestApp(t)
+	assets, err := app.ExportGlobalAssets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkImport(kindAssets, "assets", []byte(assets)); err != nil {
+		t.Errorf("既定のアセットがスキーマに適合しません: %v", err)
+	}
+	proj, err := app.CreateProject("schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := 1.0
+	data := ProjectData{
+		LocalAssets: []Asset{{ID: "l1", Type: "furniture", Entities: []Entity{{Type: "circle", CX: &one, CY: &one, RX: &one}}}},
+		Instances:   []Instance{{ID: "i1", AssetID: "l1", Type: "furniture"}},
+		CostRates:   &CostRates{TaxRate: 0.1},
+	}
+	if err := app.SaveProjectData(proj.ID, data); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := app.ExportProject(proj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.ImportProject("copy", exported); err != nil {
+		t.Errorf("書き出したプロジェクトをインポートできません: %v", err)
+	}
+
+	// 種類ごとに必要な値の不足や型の誤りはパス付きで報告する
+	invalid := `{"assets": [{"id": "l1", "type": "furniture", "entities": [
+		{"type": "polygon", "points": [{"x": 0, "y": 0}]},
+		{"type": "rect", "x": 0, "y": 0, "w": 10},
+		{"type": "ellipse", "rx": 5}
+	]}], "instances": [{"id": "i1", "type": "room", "x": "10"}, {"type": "text"}], "costRates": {"taxRate": "10%"}}`
+	errs, err := validateAgainstSchema("project", []byte(invalid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"assets[0].entities[0].points: expected at least 3 items, got 1",
+		`assets[0].entities[1]: missing required property "h"`,
+		"assets[0].entities[2]: ellipse and arc need the radius ry or the height h",
+		"instances[0].x: expected number, got string",
+		`instances[1]: missing required property "id"`,
+		"costRates.taxRate: expected number, got string",
+	} {
+		found := false
+		for _, e := range errs {
+			found = found || e == want
+		}
+		if !found {
+			t.Errorf("%q が報告されていません: %v", want, errs)
+		}
+	}
+	if len(errs) != 6 {
+		t.Errorf("エラーの件数が不正です: %v", errs)
+	}
+
+	before, _ := app.GetProjects()
+	if _, err := app.ImportProject("invalid", invalid); err == nil || !strings.Contains(err.Error(), "instances[0].x") {
+		t.Errorf("スキーマに適合しないプロジェクトのエラーが不正です: %v", err)
+	}
+	if after, _ := app.GetProjects(); len(after) != len(before) {
+		t.Errorf("インポートに失敗したプロジェクトが作られています: %+v", after)
+	}
+	if err := app.ImportGlobalAssets(`{"assets": [{"id": "g1"}]}`, true); err == nil || !strings.Contains(err.Error(), `assets[0]: missing required property "type"`) {
+		t.Errorf("スキーマに適合しないアセットのエラーが不正です: %v", err)
+	}
+	// 旧形式 (shapes) は変換してから検査する
+	if _, err := app.ImportProject("legacy", `{"assets": [{"id": "l1", "type": "furniture", "shapes": [{"type": "rect", "x": 0, "y": 0, "w": 1, "h": 1}]}], "instances": []}`); err != nil {
+		t.Errorf("旧形式のプロジェクトをインポートできません: %v", err)
+	}
+}
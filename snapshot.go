@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// defaultSnapshotRetention は「1時間以内は全て・1日以内は1時間ごと・1ヶ月以内は1日ごと」
+var defaultSnapshotRetention = SnapshotRetention{
+	KeepAllMinutes: 60,
+	HourlyHours:    24,
+	DailyDays:      30,
+}
+
+// newSnapshotInfo は now を ID とするスナップショットのメタデータを生成する
+func newSnapshotInfo(now time.Time) SnapshotInfo {
+	return SnapshotInfo{
+		ID:        strconv.FormatInt(now.UnixNano(), 10),
+		CreatedAt: now.Format(time.RFC3339),
+	}
+}
+
+// snapshotTime はスナップショット ID から作成時刻を復元する
+func snapshotTime(id string) (time.Time, error) {
+	ns, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid snapshot id: %s", id)
+	}
+	return time.Unix(0, ns), nil
+}
+
+// checkSnapshotRef は外部から受け取ったプロジェクト ID とスナップショット ID を検証する
+func checkSnapshotRef(id, snapshotID string) error {
+	if _, err := parseProjectID(id); err != nil {
+		return err
+	}
+	_, err := snapshotTime(snapshotID)
+	return err
+}
+
+// sortSnapshots はスナップショットを新しい順に並べ替える
+func sortSnapshots(snaps []SnapshotInfo) {
+	sort.Slice(snaps, func(i, j int) bool {
+		ti, _ := snapshotTime(snaps[i].ID)
+		tj, _ := snapshotTime(snaps[j].ID)
+		return ti.After(tj)
+	})
+}
+
+// snapshotsToPrune は保持ルールから外れたスナップショットの ID を返す。
+// snaps は新しい順に並んでいる必要がある。最新のスナップショットは常に残す。
+func snapshotsToPrune(snaps []SnapshotInfo, now time.Time, r SnapshotRetention) []string {
+	keepAll := time.Duration(r.KeepAllMinutes) * time.Minute
+	hourly := time.Duration(r.HourlyHours) * time.Hour
+	daily := time.Duration(r.DailyDays) * 24 * time.Hour
+
+	var prune []string
+	seenHours := map[time.Time]bool{}
+	seenDays := map[string]bool{}
+	for i, snap := range snaps {
+		created, err := snapshotTime(snap.ID)
+		if err != nil {
+			continue
+		}
+		age := now.Sub(created)
+		switch {
+		case i == 0 || age < keepAll:
+			continue
+		case age < hourly:
+			// 新しい順に見ているので、各時間帯で最初に現れたものが最新
+			hour := created.Truncate(time.Hour)
+			if !seenHours[hour] {
+				seenHours[hour] = true
+				continue
+			}
+		case age < daily:
+			day := created.Format("2006-01-02")
+			if !seenDays[day] {
+				seenDays[day] = true
+				continue
+			}
+		}
+		prune = append(prune, snap.ID)
+	}
+	return prune
+}
+
+// snapshotRetention は設定の保持ルール。未指定なら既定のルール。
+func snapshotRetention(settings AppSettings) SnapshotRetention {
+	if settings.SnapshotRetention == nil {
+		return defaultSnapshotRetention
+	}
+	return *settings.SnapshotRetention
+}
+
+// takeSnapshot は保存されたプロジェクトデータのスナップショットを作成し、古いものを整理する。
+// 直前のスナップショットと内容が同じ場合は作成しない。
+// スナップショットの失敗で保存自体を失敗させないよう、エラーはログに残すのみとする。
+func (a *App) takeSnapshot(id string, data []byte, retention SnapshotRetention) {
+	store := a.storage()
+	snaps, err := store.ListSnapshots(id)
+	if err != nil {
+		a.logError("スナップショット一覧の取得に失敗しました (ID: %s): %v", id, err)
+		return
+	}
+	if len(snaps) > 0 {
+		if latest, err := store.LoadSnapshot(id, snaps[0].ID); err == nil && bytes.Equal(latest, data) {
+			return
+		}
+	}
+
+	now := time.Now()
+	snap := newSnapshotInfo(now)
+	// 時計の分解能が粗い環境で ID が重複しないようにする
+	if len(snaps) > 0 {
+		if latest, err := snapshotTime(snaps[0].ID); err == nil && !now.After(latest) {
+			now = latest.Add(time.Nanosecond)
+			snap = newSnapshotInfo(now)
+		}
+	}
+	snap.Size = int64(len(data))
+	if err := store.SaveSnapshot(id, snap, data); err != nil {
+		a.logError("スナップショット作成失敗 (ID: %s): %v", id, err)
+		return
+	}
+
+	snaps = append([]SnapshotInfo{snap}, snaps...)
+	for _, snapID := range snapshotsToPrune(snaps, now, retention) {
+		if err := store.DeleteSnapshot(id, snapID); err != nil {
+			a.logError("スナップショット削除失敗 (ID: %s/%s): %v", id, snapID, err)
+		}
+	}
+}
+
+// ListSnapshots returns the snapshots of a project, newest first
+func (a *App) ListSnapshots(id string) ([]SnapshotInfo, error) {
+	if _, err := parseProjectID(id); err != nil {
+		a.logError("スナップショット一覧の取得に失敗しました: %v", err)
+		return nil, err
+	}
+	snaps, err := a.storage().ListSnapshots(id)
+	if err != nil {
+		a.logError("スナップショット一覧の取得に失敗しました (ID: %s): %v", id, err)
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// GetSnapshotData returns the project data stored in a snapshot for preview
+func (a *App) GetSnapshotData(id string, snapshotID string) (ProjectData, error) {
+	if err := checkSnapshotRef(id, snapshotID); err != nil {
+		a.logError("スナップショット読み込み失敗: %v", err)
+		return ProjectData{}, err
+	}
+	data, err := a.storage().LoadSnapshot(id, snapshotID)
+	if err != nil {
+		a.logError("スナップショット読み込み失敗 (ID: %s/%s): %v", id, snapshotID, err)
+		return ProjectData{}, err
+	}
+	return parseProjectData(data)
+}
+
+// RestoreSnapshot replaces the current project data with a snapshot.
+// The restored state is recorded as a new snapshot, so the restore itself can be undone.
+func (a *App) RestoreSnapshot(id string, snapshotID string) error {
+	projData, err := a.GetSnapshotData(id, snapshotID)
+	if err != nil {
+		return err
+	}
+	if err := a.SaveProjectData(id, projData); err != nil {
+		return err
+	}
+	a.logInfo("スナップショットから復元しました: %s (%s)", id, snapshotID)
+	return nil
+}
+
+// RestoreSnapshotAsProject creates a new project from a snapshot
+func (a *App) RestoreSnapshotAsProject(id string, snapshotID string, name string) (*Project, error) {
+	if err := checkSnapshotRef(id, snapshotID); err != nil {
+		a.logError("スナップショット読み込み失敗: %v", err)
+		return nil, err
+	}
+	data, err := a.storage().LoadSnapshot(id, snapshotID)
+	if err != nil {
+		a.logError("スナップショット読み込み失敗 (ID: %s/%s): %v", id, snapshotID, err)
+		return nil, err
+	}
+	if name == "" {
+		if project, err := a.storage().GetProject(id); err == nil {
+			name = project.Name + " (復元)"
+		} else {
+			name = "復元したプロジェクト"
+		}
+	}
+	return a.ImportProject(name, string(data))
+}
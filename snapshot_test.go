@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSnapshotsToPrune は保持ルールに従って間引かれることを検証します
+func TestSnapshotsToPrune(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+	at := func(d time.Duration) SnapshotInfo { return newSnapshotInfo(now.Add(-d)) }
+
+	snaps := []SnapshotInfo{
+		at(1 * time.Minute),              // 1時間以内: 保持
+		at(30 * time.Minute),             // 1時間以内: 保持
+		at(2*time.Hour + 10*time.Minute), // 09:50 台の最新: 保持
+		at(2*time.Hour + 20*time.Minute), // 同じ時間帯の古いもの: 削除
+		at(3 * 24 * time.Hour),           // 5/7 の最新: 保持
+		at(3*24*time.Hour + time.Hour),   // 同じ日の古いもの: 削除
+		at(40 * 24 * time.Hour),          // 保持期間外: 削除
+	}
+	sortSnapshots(snaps)
+
+	got := map[string]bool{}
+	for _, id := range snapshotsToPrune(snaps, now, defaultSnapshotRetention) {
+		got[id] = true
+	}
+	want := map[string]bool{snaps[3].ID: true, snaps[5].ID: true, snaps[6].ID: true}
+	if len(got) != len(want) {
+		t.Fatalf("削除対象の件数が不正です: got %v, want %v", got, want)
+	}
+	for id := range want {
+		if !got[id] {
+			t.Errorf("スナップショット %s が削除対象になっていません", id)
+		}
+	}
+
+	// 最新のスナップショットは古くても残す
+	old := []SnapshotInfo{at(100 * 24 * time.Hour)}
+	if ids := snapshotsToPrune(old, now, defaultSnapshotRetention); len(ids) != 0 {
+		t.Errorf("最新のスナップショットが削除対象になっています: %v", ids)
+	}
+}
+
+// TestSnapshotRestore は保存ごとのスナップショット作成と復元を検証します
+func TestSnapshotRestore(t *testing.T) {
+	app := newTestApp(t)
+	proj, err := app.CreateProject("history")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := ProjectData{LocalAssets: []Asset{}, Instances: []Instance{{ID: "i1", Type: "room"}}}
+	second := ProjectData{LocalAssets: []Asset{}, Instances: []Instance{{ID: "i2", Type: "room"}}}
+	app.SaveProjectData(proj.ID, first)
+	app.SaveProjectData(proj.ID, first) // 内容が同じ場合は作成しない
+	app.SaveProjectData(proj.ID, second)
+
+	snaps, err := app.ListSnapshots(proj.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("スナップショット数が不正です: got %d, want 2", len(snaps))
+	}
+
+	preview, err := app.GetSnapshotData(proj.ID, snaps[1].ID)
+	if err != nil || len(preview.Instances) != 1 || preview.Instances[0].ID != "i1" {
+		t.Fatalf("プレビューの内容が不正です: %+v, %v", preview, err)
+	}
+
+	if err := app.RestoreSnapshot(proj.ID, snaps[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	current, _ := app.GetProjectData(proj.ID)
+	if current.Instances[0].ID != "i1" {
+		t.Errorf("復元されていません: %+v", current)
+	}
+
+	restored, err := app.RestoreSnapshotAsProject(proj.ID, snaps[0].ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Name != "history (復元)" {
+		t.Errorf("復元プロジェクト名が不正です: %s", restored.Name)
+	}
+	data, _ := app.GetProjectData(restored.ID)
+	if len(data.Instances) != 1 || data.Instances[0].ID != "i2" {
+		t.Errorf("新規プロジェクトとして復元されていません: %+v", data)
+	}
+
+	// SQLite ストアはパスを使わないため、ID は App の入口で検証する
+	dir := t.TempDir()
+	sqlite, err := newSQLiteStore(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+	sqliteApp := &App{dataDir: dir, store: sqlite}
+	if _, err := sqliteApp.ListSnapshots("../x"); err == nil {
+		t.Error("不正なプロジェクト ID でスナップショット一覧を取得できました")
+	}
+	if _, err := sqliteApp.GetSnapshotData("../x", snaps[0].ID); err == nil {
+		t.Error("不正なプロジェクト ID でスナップショットを読み込めました")
+	}
+	if _, err := sqliteApp.RestoreSnapshotAsProject(proj.ID, "../"+snaps[0].ID, ""); err == nil {
+		t.Error("不正なスナップショット ID で復元できました")
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ストレージバックエンドの識別子 (AppSettings.StorageBackend)
+const (
+	StorageBackendJSON   = "json"
+	StorageBackendSQLite = "sqlite"
+)
+
+// Store は App が利用する永続化バックエンドです。
+// ドキュメント (プロジェクトデータ・アセット・パレット・設定) は JSON のバイト列として受け渡し、
+// レガシー形式の解釈は App 側で行います。
+// Load 系メソッドはデータが存在しない場合 fs.ErrNotExist をラップしたエラーを返します。
+type Store interface {
+	// ListProjects は作成順にプロジェクト一覧を返す
+	ListProjects() ([]Project, error)
+	// GetProject は指定 ID のプロジェクトのメタデータを返す
+	GetProject(id string) (Project, error)
+	// PutProject はプロジェクトのメタデータを追加または更新する
+	PutProject(p Project) error
+	// DeleteProject はメタデータ・プロジェクトデータ・スナップショットを削除する
+	DeleteProject(id string) error
+
+	LoadProjectData(id string) ([]byte, error)
+	SaveProjectData(id string, data []byte) error
+
+	// LoadThumbnail・SaveThumbnail はプロジェクト一覧に表示するサムネイル (PNG) のキャッシュ
+	LoadThumbnail(id string) ([]byte, error)
+	SaveThumbnail(id string, png []byte) error
+	// DeleteThumbnail はキャッシュを捨てる。無い場合はエラーにしない。
+	DeleteThumbnail(id string) error
+
+	// ListSnapshots は新しい順にプロジェクトのスナップショット一覧を返す
+	ListSnapshots(projectID string) ([]SnapshotInfo, error)
+	LoadSnapshot(projectID, snapshotID string) ([]byte, error)
+	SaveSnapshot(projectID string, snap SnapshotInfo, data []byte) error
+	DeleteSnapshot(projectID, snapshotID string) error
+
+	LoadGlobalAssets() ([]byte, error)
+	SaveGlobalAssets(data []byte) error
+
+	LoadPalette() ([]byte, error)
+	SavePalette(data []byte) error
+
+	LoadSettings() ([]byte, error)
+	SaveSettings(data []byte) error
+
+	Close() error
+}
+
+// openStore は backend に対応するストアを dataDir に開く。空文字は JSON ストアとして扱う。
+func openStore(backend string, dataDir string, logError func(format string, v ...interface{})) (Store, error) {
+	switch backend {
+	case "", StorageBackendJSON:
+		return newJSONStore(dataDir, logError), nil
+	case StorageBackendSQLite:
+		return newSQLiteStore(dataDir, logError)
+	}
+	return nil, fmt.Errorf("unknown storage backend: %s", backend)
+}
+
+// copyStore は src の全データを dst に書き込む。バックエンド切り替え時に使用する。
+// 設定は両バックエンドで settings.json を共有するためコピーしない。
+func copyStore(src, dst Store) error {
+	projects, err := src.ListProjects()
+	if err != nil {
+		return err
+	}
+	for _, p := range projects {
+		if err := dst.PutProject(p); err != nil {
+			return err
+		}
+		data, err := src.LoadProjectData(p.ID)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("project %s: %v", p.ID, err)
+		}
+		if err := dst.SaveProjectData(p.ID, data); err != nil {
+			return err
+		}
+		// サムネイルはキャッシュのため、無ければ表示時に作り直される
+		if png, err := src.LoadThumbnail(p.ID); err == nil {
+			if err := dst.SaveThumbnail(p.ID, png); err != nil {
+				return err
+			}
+		}
+
+		snaps, err := src.ListSnapshots(p.ID)
+		if err != nil {
+			return err
+		}
+		for _, snap := range snaps {
+			data, err := src.LoadSnapshot(p.ID, snap.ID)
+			if err != nil {
+				return fmt.Errorf("snapshot %s/%s: %v", p.ID, snap.ID, err)
+			}
+			if err := dst.SaveSnapshot(p.ID, snap, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if data, err := src.LoadGlobalAssets(); err == nil {
+		if err := dst.SaveGlobalAssets(data); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if data, err := src.LoadPalette(); err == nil {
+		if err := dst.SavePalette(data); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// marshalDocument はストアに保存する JSON を生成する
+func marshalDocument(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// notFound は fs.ErrNotExist をラップした「存在しない」エラーを返す
+func notFound(what string) error {
+	return fmt.Errorf("%s: %w", what, fs.ErrNotExist)
+}
+
+// guardedStore は App の現在のストアへの操作を、データディレクトリの移動とバックエンドの切り替えが終わるまで待たせる。
+// 待っていた操作は切り替え後のストアに対して行う。
+type guardedStore struct {
+	app *App
+}
+
+// with は移動・切り替えを止めた状態で現在のストアを渡す
+func (g guardedStore) with(fn func(Store) error) error {
+	g.app.moveMu.RLock()
+	defer g.app.moveMu.RUnlock()
+	return fn(g.app.currentStore())
+}
+
+func (g guardedStore) ListProjects() (projects []Project, err error) {
+	err = g.with(func(s Store) error { projects, err = s.ListProjects(); return err })
+	return projects, err
+}
+
+func (g guardedStore) GetProject(id string) (p Project, err error) {
+	err = g.with(func(s Store) error { p, err = s.GetProject(id); return err })
+	return p, err
+}
+
+func (g guardedStore) PutProject(p Project) error {
+	return g.with(func(s Store) error { return s.PutProject(p) })
+}
+
+func (g guardedStore) DeleteProject(id string) error {
+	return g.with(func(s Store) error { return s.DeleteProject(id) })
+}
+
+func (g guardedStore) LoadProjectData(id string) (data []byte, err error) {
+	err = g.with(func(s Store) error { data, err = s.LoadProjectData(id); return err })
+	return data, err
+}
+
+func (g guardedStore) SaveProjectData(id string, data []byte) error {
+	return g.with(func(s Store) error { return s.SaveProjectData(id, data) })
+}
+
+func (g guardedStore) LoadThumbnail(id string) (png []byte, err error) {
+	err = g.with(func(s Store) error { png, err = s.LoadThumbnail(id); return err })
+	return png, err
+}
+
+func (g guardedStore) SaveThumbnail(id string, png []byte) error {
+	return g.with(func(s Store) error { return s.SaveThumbnail(id, png) })
+}
+
+func (g guardedStore) DeleteThumbnail(id string) error {
+	return g.with(func(s Store) error { return s.DeleteThumbnail(id) })
+}
+
+func (g guardedStore) ListSnapshots(projectID string) (snaps []SnapshotInfo, err error) {
+	err = g.with(func(s Store) error { snaps, err = s.ListSnapshots(projectID); return err })
+	return snaps, err
+}
+
+func (g guardedStore) LoadSnapshot(projectID, snapshotID string) (data []byte, err error) {
+	err = g.with(func(s Store) error { data, err = s.LoadSnapshot(projectID, snapshotID); return err })
+	return data, err
+}
+
+func (g guardedStore) SaveSnapshot(projectID string, snap SnapshotInfo, data []byte) error {
+	return g.with(func(s Store) error { return s.SaveSnapshot(projectID, snap, data) })
+}
+
+func (g guardedStore) DeleteSnapshot(projectID, snapshotID string) error {
+	return g.with(func(s Store) error { return s.DeleteSnapshot(projectID, snapshotID) })
+}
+
+func (g guardedStore) LoadGlobalAssets() (data []byte, err error) {
+	err = g.with(func(s Store) error { data, err = s.LoadGlobalAssets(); return err })
+	return data, err
+}
+
+func (g guardedStore) SaveGlobalAssets(data []byte) error {
+	return g.with(func(s Store) error { return s.SaveGlobalAssets(data) })
+}
+
+func (g guardedStore) LoadPalette() (data []byte, err error) {
+	err = g.with(func(s Store) error { data, err = s.LoadPalette(); return err })
+	return data, err
+}
+
+func (g guardedStore) SavePalette(data []byte) error {
+	return g.with(func(s Store) error { return s.SavePalette(data) })
+}
+
+func (g guardedStore) LoadSettings() (data []byte, err error) {
+	err = g.with(func(s Store) error { data, err = s.LoadSettings(); return err })
+	return data, err
+}
+
+func (g guardedStore) SaveSettings(data []byte) error {
+	return g.with(func(s Store) error { return s.SaveSettings(data) })
+}
+
+func (g guardedStore) Close() error {
+	return g.with(func(s Store) error { return s.Close() })
+}
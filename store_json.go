@@ -0,0 +1,458 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSON ストアが data/ 以下に作成するファイル名
+const (
+	projectsIndexFile = "projects_index.json"
+	globalAssetsFile  = "global_assets.json"
+	paletteFile       = "palette.json"
+	settingsFile      = "settings.json"
+	snapshotsDir      = "snapshots"
+)
+
+// jsonStore は data/ ディレクトリ内の JSON ファイルにデータを保存するデフォルトのストア
+type jsonStore struct {
+	dir      string
+	mu       sync.Mutex
+	logError func(format string, v ...interface{})
+}
+
+func newJSONStore(dir string, logError func(format string, v ...interface{})) *jsonStore {
+	return &jsonStore{dir: dir, logError: logError}
+}
+
+func projectFileName(id ProjectID) string {
+	return fmt.Sprintf("project_%s.json", id)
+}
+
+// thumbnailFileName はプロジェクトファイルの隣に置くサムネイルのファイル名
+func thumbnailFileName(id ProjectID) string {
+	return fmt.Sprintf("project_%s.png", id)
+}
+
+// path は data/ 以下のパスを返す。data/ の外を指すパスはエラーにする。
+func (s *jsonStore) path(elem ...string) (string, error) {
+	path := filepath.Join(append([]string{s.dir}, elem...)...)
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("path %q is outside the data directory", filepath.Join(elem...))
+	}
+	return path, nil
+}
+
+// projectPath はプロジェクトのファイル (name は ID からファイル名を作る関数) のパスを返す
+func (s *jsonStore) projectPath(id string, name func(ProjectID) string) (string, error) {
+	pid, err := parseProjectID(id)
+	if err != nil {
+		return "", err
+	}
+	return s.path(name(pid))
+}
+
+// ヘルパー：ファイル保存
+// 一時ファイルへの書き込み → fsync → rename の順で置き換えるため、
+// 書き込み途中でクラッシュしても既存ファイルが壊れることはない。
+func (s *jsonStore) writeFile(name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(path, data)
+}
+
+// ヘルパー：ファイル読み込み
+func (s *jsonStore) readFile(name string) ([]byte, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readFileLocked(path)
+}
+
+// 本体が存在しない、または JSON として解析できない場合は .bak にフォールバックする。
+func (s *jsonStore) readFileLocked(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil && json.Valid(data) {
+		return data, nil
+	}
+
+	backup, bakErr := os.ReadFile(backupPath(path))
+	if bakErr == nil && json.Valid(backup) {
+		if s.logError != nil {
+			s.logError("%s を読み込めないため、バックアップから復元します", filepath.Base(path))
+		}
+		return backup, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s is corrupted and no valid backup exists", filepath.Base(path))
+}
+
+// readIndexLocked は projects_index.json を読み込む。ファイルがない場合は空リストを返す。
+// 以前の版の一覧 (プロジェクトの配列) は現在の版に変換して書き戻す。
+func (s *jsonStore) readIndexLocked() ([]Project, error) {
+	path := filepath.Join(s.dir, projectsIndexFile)
+	data, err := s.readFileLocked(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Project{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, _, upgraded, err := migrateDocument(kindIndex, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", projectsIndexFile, err)
+	}
+	if upgraded {
+		if err := writeFileAtomic(path, data); err != nil && s.logError != nil {
+			s.logError("%s の書き戻しに失敗しました: %v", projectsIndexFile, err)
+		}
+	}
+	var doc projectIndexDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Projects == nil {
+		doc.Projects = []Project{}
+	}
+	return doc.Projects, nil
+}
+
+func (s *jsonStore) writeIndexLocked(projects []Project) error {
+	data, err := marshalVersioned(projectIndexDocument{Projects: projects})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.dir, projectsIndexFile), data)
+}
+
+func (s *jsonStore) ListProjects() ([]Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readIndexLocked()
+}
+
+func (s *jsonStore) GetProject(id string) (Project, error) {
+	projects, err := s.ListProjects()
+	if err != nil {
+		return Project{}, err
+	}
+	for _, p := range projects {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return Project{}, notFound("project " + id)
+}
+
+func (s *jsonStore) PutProject(p Project) error {
+	if _, err := parseProjectID(p.ID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects, err := s.readIndexLocked()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range projects {
+		if projects[i].ID == p.ID {
+			projects[i] = p
+			found = true
+		}
+	}
+	if !found {
+		projects = append(projects, p)
+	}
+	return s.writeIndexLocked(projects)
+}
+
+func (s *jsonStore) DeleteProject(id string) error {
+	projPath, err := s.projectPath(id, projectFileName)
+	if err != nil {
+		return err
+	}
+	thumbPath, err := s.projectPath(id, thumbnailFileName)
+	if err != nil {
+		return err
+	}
+	snapDir, err := s.snapshotDir(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects, err := s.readIndexLocked()
+	if err != nil {
+		return err
+	}
+	newProjects := []Project{}
+	for _, p := range projects {
+		if p.ID != id {
+			newProjects = append(newProjects, p)
+		}
+	}
+	if err := s.writeIndexLocked(newProjects); err != nil {
+		return err
+	}
+
+	if err := os.Remove(projPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(backupPath(projPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(thumbPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.RemoveAll(snapDir)
+}
+
+func (s *jsonStore) LoadProjectData(id string) ([]byte, error) {
+	pid, err := parseProjectID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.readFile(projectFileName(pid))
+}
+
+func (s *jsonStore) SaveProjectData(id string, data []byte) error {
+	pid, err := parseProjectID(id)
+	if err != nil {
+		return err
+	}
+	return s.writeFile(projectFileName(pid), data)
+}
+
+// LoadThumbnail はサムネイルを読む。PNG のため .bak へのフォールバックは行わない。
+func (s *jsonStore) LoadThumbnail(id string) ([]byte, error) {
+	path, err := s.projectPath(id, thumbnailFileName)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, notFound("thumbnail " + id)
+	}
+	return data, err
+}
+
+func (s *jsonStore) SaveThumbnail(id string, png []byte) error {
+	pid, err := parseProjectID(id)
+	if err != nil {
+		return err
+	}
+	return s.writeFile(thumbnailFileName(pid), png)
+}
+
+func (s *jsonStore) DeleteThumbnail(id string) error {
+	path, err := s.projectPath(id, thumbnailFileName)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range []string{path, backupPath(path)} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotDir はプロジェクトのスナップショットを保存するディレクトリ (data/snapshots/<id>/)
+func (s *jsonStore) snapshotDir(projectID string) (string, error) {
+	pid, err := parseProjectID(projectID)
+	if err != nil {
+		return "", err
+	}
+	return s.path(snapshotsDir, string(pid))
+}
+
+// snapshotPath はスナップショットのファイルのパスを返す。ID は作成時刻 (UnixNano) の数字のみ。
+func (s *jsonStore) snapshotPath(projectID, snapshotID string) (string, error) {
+	if _, err := snapshotTime(snapshotID); err != nil {
+		return "", err
+	}
+	dir, err := s.snapshotDir(projectID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, snapshotID+".json"), nil
+}
+
+func (s *jsonStore) ListSnapshots(projectID string) ([]SnapshotInfo, error) {
+	dir, err := s.snapshotDir(projectID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []SnapshotInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := []SnapshotInfo{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		createdAt, err := snapshotTime(id)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, SnapshotInfo{
+			ID:        id,
+			CreatedAt: createdAt.Format(time.RFC3339),
+			Size:      info.Size(),
+		})
+	}
+	sortSnapshots(snaps)
+	return snaps, nil
+}
+
+func (s *jsonStore) LoadSnapshot(projectID, snapshotID string) ([]byte, error) {
+	path, err := s.snapshotPath(projectID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.ReadFile(path)
+}
+
+func (s *jsonStore) SaveSnapshot(projectID string, snap SnapshotInfo, data []byte) error {
+	path, err := s.snapshotPath(projectID, snap.ID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+func (s *jsonStore) DeleteSnapshot(projectID, snapshotID string) error {
+	path, err := s.snapshotPath(projectID, snapshotID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *jsonStore) LoadGlobalAssets() ([]byte, error) { return s.readFile(globalAssetsFile) }
+
+func (s *jsonStore) SaveGlobalAssets(data []byte) error { return s.writeFile(globalAssetsFile, data) }
+
+func (s *jsonStore) LoadPalette() ([]byte, error) { return s.readFile(paletteFile) }
+
+func (s *jsonStore) SavePalette(data []byte) error { return s.writeFile(paletteFile, data) }
+
+func (s *jsonStore) LoadSettings() ([]byte, error) { return s.readFile(settingsFile) }
+
+func (s *jsonStore) SaveSettings(data []byte) error { return s.writeFile(settingsFile, data) }
+
+func (s *jsonStore) Close() error { return nil }
+
+// backupPath は直前の世代を保持するバックアップファイルのパスを返す
+func backupPath(path string) string {
+	return path + ".bak"
+}
+
+// writeFileAtomic は同じディレクトリの一時ファイルに書き込んで fsync した後、
+// rename で本体を置き換える。置き換え前の本体が正常な JSON であれば .bak として残す。
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// 壊れた本体でバックアップを上書きしないよう、正常な場合のみ退避する
+	if current, err := os.ReadFile(path); err == nil && json.Valid(current) {
+		if err := os.Rename(path, backupPath(path)); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir は rename をディスクに反映させるためにディレクトリを fsync する。
+// Windows などディレクトリを開けない環境ではエラーを無視する。
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLite ストアのデータベースファイル名
+const sqliteDBFile = "roomgenerator.db"
+
+// ドキュメントテーブルのキー
+const (
+	docGlobalAssets = "global_assets"
+	docPalette      = "palette"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	id         TEXT NOT NULL UNIQUE,
+	name       TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	madori     TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS project_data (
+	id   TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS snapshots (
+	project_id TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	data       BLOB NOT NULL,
+	PRIMARY KEY (project_id, id)
+);
+CREATE TABLE IF NOT EXISTS thumbnails (
+	id   TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS documents (
+	name TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+`
+
+// sqliteStore は組み込み SQLite (純 Go ドライバ) にデータを保存するストア。
+// プロジェクト一覧は行単位で更新されるため、プロジェクト数が増えても作成・名前変更のコストは一定。
+// 設定はバックエンドの選択自体を含むため、JSON ストアと同じ settings.json に保存する。
+type sqliteStore struct {
+	db       *sql.DB
+	settings *jsonStore
+}
+
+func newSQLiteStore(dir string, logError func(format string, v ...interface{})) (*sqliteStore, error) {
+	dsn := "file:" + filepath.ToSlash(filepath.Join(dir, sqliteDBFile)) +
+		"?_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite は単一ライターのため接続を 1 本に制限する
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// 間取り表記の列が無い以前のデータベースには列を追加する
+	if err := addColumnIfMissing(db, "projects", "madori", `TEXT NOT NULL DEFAULT ''`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db, settings: newJSONStore(dir, logError)}, nil
+}
+
+func (s *sqliteStore) ListProjects() ([]Project, error) {
+	rows, err := s.db.Query(`SELECT id, name, updated_at, madori FROM projects ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		var p Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.UpdatedAt, &p.Madori); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+func (s *sqliteStore) GetProject(id string) (Project, error) {
+	var p Project
+	err := s.db.QueryRow(`SELECT id, name, updated_at, madori FROM projects WHERE id = ?`, id).
+		Scan(&p.ID, &p.Name, &p.UpdatedAt, &p.Madori)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Project{}, notFound("project " + id)
+	}
+	return p, err
+}
+
+func (s *sqliteStore) PutProject(p Project) error {
+	if _, err := parseProjectID(p.ID); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`INSERT INTO projects (id, name, updated_at, madori) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, updated_at = excluded.updated_at, madori = excluded.madori`,
+		p.ID, p.Name, p.UpdatedAt, p.Madori)
+	return err
+}
+
+// addColumnIfMissing はテーブルに列が無ければ追加する
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+func (s *sqliteStore) DeleteProject(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM project_data WHERE id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM snapshots WHERE project_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM thumbnails WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) LoadProjectData(id string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM project_data WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("project data " + id)
+	}
+	return data, err
+}
+
+func (s *sqliteStore) SaveProjectData(id string, data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO project_data (id, data) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data`, id, data)
+	return err
+}
+
+func (s *sqliteStore) LoadThumbnail(id string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM thumbnails WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("thumbnail " + id)
+	}
+	return data, err
+}
+
+func (s *sqliteStore) SaveThumbnail(id string, png []byte) error {
+	_, err := s.db.Exec(`INSERT INTO thumbnails (id, data) VALUES (?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data`, id, png)
+	return err
+}
+
+func (s *sqliteStore) DeleteThumbnail(id string) error {
+	_, err := s.db.Exec(`DELETE FROM thumbnails WHERE id = ?`, id)
+	return err
+}
+
+func (s *sqliteStore) ListSnapshots(projectID string) ([]SnapshotInfo, error) {
+	rows, err := s.db.Query(`SELECT id, created_at, length(data) FROM snapshots WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snaps := []SnapshotInfo{}
+	for rows.Next() {
+		var snap SnapshotInfo
+		if err := rows.Scan(&snap.ID, &snap.CreatedAt, &snap.Size); err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortSnapshots(snaps)
+	return snaps, nil
+}
+
+func (s *sqliteStore) LoadSnapshot(projectID, snapshotID string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM snapshots WHERE project_id = ? AND id = ?`, projectID, snapshotID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("snapshot " + snapshotID)
+	}
+	return data, err
+}
+
+func (s *sqliteStore) SaveSnapshot(projectID string, snap SnapshotInfo, data []byte) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO snapshots (project_id, id, created_at, data) VALUES (?, ?, ?, ?)`,
+		projectID, snap.ID, snap.CreatedAt, data)
+	return err
+}
+
+func (s *sqliteStore) DeleteSnapshot(projectID, snapshotID string) error {
+	_, err := s.db.Exec(`DELETE FROM snapshots WHERE project_id = ? AND id = ?`, projectID, snapshotID)
+	return err
+}
+
+func (s *sqliteStore) loadDocument(name string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM documents WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound(name)
+	}
+	return data, err
+}
+
+func (s *sqliteStore) saveDocument(name string, data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO documents (name, data) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET data = excluded.data`, name, data)
+	return err
+}
+
+func (s *sqliteStore) LoadGlobalAssets() ([]byte, error) { return s.loadDocument(docGlobalAssets) }
+
+func (s *sqliteStore) SaveGlobalAssets(data []byte) error {
+	return s.saveDocument(docGlobalAssets, data)
+}
+
+func (s *sqliteStore) LoadPalette() ([]byte, error) { return s.loadDocument(docPalette) }
+
+func (s *sqliteStore) SavePalette(data []byte) error { return s.saveDocument(docPalette, data) }
+
+func (s *sqliteStore) LoadSettings() ([]byte, error) { return s.settings.LoadSettings() }
+
+func (s *sqliteStore) SaveSettings(data []byte) error { return s.settings.SaveSettings(data) }
+
+func (s *sqliteStore) Close() error { return s.db.Close() }
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStoreContract は全バックエンド共通の振る舞いを検証します
+func testStoreContract(t *testing.T, s Store) {
+	t.Helper()
+
+	if projects, err := s.ListProjects(); err != nil || len(projects) != 0 {
+		t.Fatalf("空のストアのプロジェクト一覧が不正です: %v, %v", projects, err)
+	}
+	if _, err := s.LoadProjectData("p1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("存在しないプロジェクトで fs.ErrNotExist が返されていません: %v", err)
+	}
+	if _, err := s.LoadGlobalAssets(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("存在しないアセットで fs.ErrNotExist が返されていません: %v", err)
+	}
+
+	for _, p := range []Project{{ID: "p1", Name: "one"}, {ID: "p2", Name: "two"}} {
+		if err := s.PutProject(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.PutProject(Project{ID: "p1", Name: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := s.ListProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].ID != "p1" || projects[0].Name != "renamed" || projects[1].ID != "p2" {
+		t.Errorf("作成順・更新内容が保持されていません: %+v", projects)
+	}
+	if err := s.PutProject(Project{ID: "p2", Name: "two", Madori: "2LDK"}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.GetProject("p2"); err != nil || p.Name != "two" || p.Madori != "2LDK" {
+		t.Errorf("GetProject の結果が不正です: %+v, %v", p, err)
+	}
+	if _, err := s.GetProject("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("存在しないプロジェクトで fs.ErrNotExist が返されていません: %v", err)
+	}
+
+	if err := s.SaveProjectData("p1", []byte(`{"assets":[],"instances":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.LoadProjectData("p1"); err != nil || !json.Valid(data) {
+		t.Errorf("プロジェクトデータを読み込めません: %s, %v", data, err)
+	}
+
+	snap := newSnapshotInfo(time.Now())
+	if err := s.SaveSnapshot("p1", snap, []byte(`{"assets":[],"instances":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if snaps, err := s.ListSnapshots("p1"); err != nil || len(snaps) != 1 || snaps[0].ID != snap.ID {
+		t.Errorf("スナップショット一覧が不正です: %+v, %v", snaps, err)
+	}
+	if data, err := s.LoadSnapshot("p1", snap.ID); err != nil || !json.Valid(data) {
+		t.Errorf("スナップショットを読み込めません: %s, %v", data, err)
+	}
+
+	if _, err := s.LoadThumbnail("p1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("存在しないサムネイルで fs.ErrNotExist が返されていません: %v", err)
+	}
+	if err := s.SaveThumbnail("p1", []byte("\x89PNG")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.LoadThumbnail("p1"); err != nil || string(data) != "\x89PNG" {
+		t.Errorf("サムネイルを読み込めません: %q, %v", data, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.DeleteThumbnail("p1"); err != nil {
+			t.Errorf("サムネイルを削除できません: %v", err)
+		}
+	}
+	if _, err := s.LoadThumbnail("p1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("削除したサムネイルが残っています: %v", err)
+	}
+	if err := s.SaveThumbnail("p1", []byte("\x89PNG")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteProject("p1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadProjectData("p1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("削除したプロジェクトのデータが残っています: %v", err)
+	}
+	if snaps, _ := s.ListSnapshots("p1"); len(snaps) != 0 {
+		t.Errorf("削除したプロジェクトのスナップショットが残っています: %+v", snaps)
+	}
+	if _, err := s.LoadThumbnail("p1"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("削除したプロジェクトのサムネイルが残っています: %v", err)
+	}
+	if projects, _ := s.ListProjects(); len(projects) != 1 {
+		t.Errorf("削除後のプロジェクト一覧が不正です: %+v", projects)
+	}
+
+	if err := s.SavePalette([]byte(`{"colors":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.LoadPalette(); err != nil || string(data) != `{"colors":[]}` {
+		t.Errorf("パレットを読み込めません: %s, %v", data, err)
+	}
+	if err := s.SaveSettings([]byte(`{"gridSize":20}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := s.LoadSettings(); err != nil || string(data) != `{"gridSize":20}` {
+		t.Errorf("設定を読み込めません: %s, %v", data, err)
+	}
+}
+
+func TestJSONStore(t *testing.T) {
+	s := newJSONStore(t.TempDir(), nil)
+	defer s.Close()
+	testStoreContract(t, s)
+}
+
+func TestSQLiteStore(t *testing.T) {
+	s, err := newSQLiteStore(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("SQLite ストアを開けません: %v", err)
+	}
+	defer s.Close()
+	testStoreContract(t, s)
+}
+
+// TestCopyStore はバックエンド切り替え時に全データがコピーされることを検証します
+// TestSQLiteStoreAddsMadoriColumn は間取りの列が無い以前のデータベースに列を追加することを検証します
+func TestSQLiteStoreAddsMadoriColumn(t *testing.T) {
+	dir := t.TempDir()
+	old, err := sql.Open("sqlite", filepath.Join(dir, sqliteDBFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Exec(`CREATE TABLE projects (seq INTEGER PRIMARY KEY AUTOINCREMENT, id TEXT NOT NULL UNIQUE, name TEXT NOT NULL, updated_at TEXT NOT NULL);
+		INSERT INTO projects (id, name, updated_at) VALUES ('p1', 'old', '')`); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	s, err := newSQLiteStore(dir, nil)
+	if err != nil {
+		t.Fatalf("以前のデータベースを開けません: %v", err)
+	}
+	defer s.Close()
+	if err := s.PutProject(Project{ID: "p1", Name: "old", Madori: "1K"}); err != nil {
+		t.Fatal(err)
+	}
+	if projects, err := s.ListProjects(); err != nil || len(projects) != 1 || projects[0].Madori != "1K" {
+		t.Errorf("間取りが保存されていません: %+v, %v", projects, err)
+	}
+}
+
+func TestCopyStore(t *testing.T) {
+	dir := t.TempDir()
+	src := newJSONStore(dir, nil)
+	src.PutProject(Project{ID: "p1", Name: "one"})
+	src.SaveProjectData("p1", []byte(`{"assets":[],"instances":[]}`))
+	src.SaveGlobalAssets([]byte(`[]`))
+
+	dst, err := newSQLiteStore(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if err := copyStore(src, dst); err != nil {
+		t.Fatalf("コピーに失敗しました: %v", err)
+	}
+	if p, err := dst.GetProject("p1"); err != nil || p.Name != "one" {
+		t.Errorf("プロジェクトがコピーされていません: %+v, %v", p, err)
+	}
+	if _, err := dst.LoadProjectData("p1"); err != nil {
+		t.Errorf("プロジェクトデータがコピーされていません: %v", err)
+	}
+	if _, err := dst.LoadGlobalAssets(); err != nil {
+		t.Errorf("グローバルアセットがコピーされていません: %v", err)
+	}
+}
+
+// TestWriteFileAtomicKeepsBackup は保存時に直前の世代が .bak として残ることを検証します
+func TestWriteFileAtomicKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, projectsIndexFile)
+
+	if err := writeFileAtomic(path, []byte(`["first"]`)); err != nil {
+		t.Fatalf("1回目の保存に失敗しました: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte(`["second"]`)); err != nil {
+		t.Fatalf("2回目の保存に失敗しました: %v", err)
+	}
+	if data, err := os.ReadFile(backupPath(path)); err != nil || string(data) != `["first"]` {
+		t.Errorf(".bak の内容が直前の世代ではありません: %s, %v", data, err)
+	}
+
+	// 破損した本体で正常なバックアップを上書きしないこと
+	os.WriteFile(path, []byte(`["sec`), 0644)
+	if err := writeFileAtomic(path, []byte(`["third"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(backupPath(path)); string(data) != `["first"]` {
+		t.Errorf("破損した本体が .bak に退避されています: %s", data)
+	}
+
+	// 一時ファイルが残っていないこと
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("一時ファイルが残っています: %s", e.Name())
+		}
+	}
+}
+
+// TestProjectIDConfinement は data/ の外を指す ID を拒否し、一覧に無いプロジェクトのファイルを作らないことを検証します
+func TestProjectIDConfinement(t *testing.T) {
+	for _, id := range []string{"1792260711940444898", "p1", "my-project_2"} {
+		if _, err := parseProjectID(id); err != nil {
+			t.Errorf("%q が拒否されました: %v", id, err)
+		}
+	}
+	bad := []string{"", "../secret", "..", "a/b", `a\b`, "/etc/passwd", "p1.json", "p 1", strings.Repeat("a", maxProjectIDLength+1)}
+	for _, id := range bad {
+		if _, err := parseProjectID(id); err == nil {
+			t.Errorf("%q が受け付けられました", id)
+		}
+	}
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "data")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(root, "project_x.json")
+	if err := os.WriteFile(outside, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app := &App{dataDir: dir, store: newJSONStore(dir, nil)}
+	s := app.storage()
+	for _, id := range []string{"../x", "../project_x", "x/../../x"} {
+		if _, err := s.LoadProjectData(id); err == nil {
+			t.Errorf("LoadProjectData(%q) がエラーになっていません", id)
+		}
+		if err := s.SaveProjectData(id, []byte(`{}`)); err == nil {
+			t.Errorf("SaveProjectData(%q) がエラーになっていません", id)
+		}
+		if err := s.DeleteProject(id); err == nil {
+			t.Errorf("DeleteProject(%q) がエラーになっていません", id)
+		}
+		if err := s.PutProject(Project{ID: id}); err == nil {
+			t.Errorf("PutProject(%q) がエラーになっていません", id)
+		}
+		if _, err := s.ListSnapshots(id); err == nil {
+			t.Errorf("ListSnapshots(%q) がエラーになっていません", id)
+		}
+		if _, err := app.GetProjectData(id); err == nil {
+			t.Errorf("GetProjectData(%q) がエラーになっていません", id)
+		}
+		if err := app.DeleteProject(id); err == nil {
+			t.Errorf("DeleteProject(%q) がエラーになっていません", id)
+		}
+		if err := app.UpdateProjectName(id, "x"); err == nil {
+			t.Errorf("UpdateProjectName(%q) がエラーになっていません", id)
+		}
+	}
+	if _, err := s.LoadSnapshot("p1", "../../project_x"); err == nil {
+		t.Error("data/ の外のスナップショットを読み込めました")
+	}
+	if data, err := os.ReadFile(outside); err != nil || string(data) != `{}` {
+		t.Errorf("data/ の外のファイルが変更されました: %s, %v", data, err)
+	}
+
+	// 一覧に無い ID では保存しない
+	if err := app.SaveProjectData("unknown", ProjectData{}); err == nil {
+		t.Error("一覧に無いプロジェクトが保存されました")
+	}
+	if _, err := os.Stat(filepath.Join(dir, projectFileName("unknown"))); !os.IsNotExist(err) {
+		t.Errorf("一覧に無いプロジェクトのファイルが作られています: %v", err)
+	}
+}
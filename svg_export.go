@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- SVG エクスポート ---
+// 図面は用紙上の mm 単位で出力し、width/height に mm を指定することで
+// 印刷時に指定した縮尺 (1:50 など) になるようにする。
+
+const (
+	defaultPlanScale   = 100.0
+	defaultGridSpacing = 91.0 // 半間 (cm)
+
+	svgMarginMM      = 10.0
+	titleBlockWMM    = 90.0
+	titleBlockHMM    = 20.0
+	roomLabelSizeMM  = 3.5
+	roomAreaSizeMM   = 2.5
+	roomStrokeMM     = 0.35
+	itemStrokeMM     = 0.18
+	gridStrokeMM     = 0.1
+	titleTextSizeMM  = 5.0
+	detailTextSizeMM = 2.8
+)
+
+// planTitle はタイトル欄に表示するプロジェクト情報
+type planTitle struct {
+	Name      string
+	UpdatedAt string
+}
+
+// normalizePlanOptions は既定値を補い、不正な値を検出する
+func normalizePlanOptions(opts PlanExportOptions) (PlanExportOptions, error) {
+	if opts.Scale == 0 {
+		opts.Scale = defaultPlanScale
+	}
+	if opts.Scale < 0 || math.IsNaN(opts.Scale) || math.IsInf(opts.Scale, 0) {
+		return opts, fmt.Errorf("invalid scale: %v", opts.Scale)
+	}
+	if opts.GridSpacing <= 0 {
+		opts.GridSpacing = defaultGridSpacing
+	}
+	if opts.TatamiSize == "" {
+		opts.TatamiSize = TatamiEdoma
+	}
+	if _, ok := tatamiAreasM2[opts.TatamiSize]; !ok {
+		return opts, fmt.Errorf("unknown tatami size: %s", opts.TatamiSize)
+	}
+	return opts, nil
+}
+
+// paperMapper はワールド座標 (cm, Y 上向き) を用紙座標 (mm, Y 下向き) に変換する
+type paperMapper struct {
+	origin Vec2    // 用紙上の原点 (mm) に対応するワールド座標の左上
+	k      float64 // 1cm あたりの mm
+	offset Vec2    // 用紙上の描画領域の左上 (mm)
+}
+
+func newPaperMapper(bounds bbox, scale float64, offset Vec2) paperMapper {
+	return paperMapper{
+		origin: Vec2{X: bounds.Min.X, Y: bounds.Max.Y},
+		k:      10 / scale,
+		offset: offset,
+	}
+}
+
+func (m paperMapper) point(v Vec2) Vec2 {
+	return Vec2{
+		X: m.offset.X + (v.X-m.origin.X)*m.k,
+		Y: m.offset.Y + (m.origin.Y-v.Y)*m.k,
+	}
+}
+
+// length はワールドの長さ (cm) を用紙上の長さ (mm) に変換する
+func (m paperMapper) length(cm float64) float64 { return cm * m.k }
+
+// planBounds は描画範囲を返す。空の図面の場合は 1m 四方とする。
+func planBounds(p plan) bbox {
+	if p.Bounds.Valid {
+		return p.Bounds
+	}
+	return bbox{Min: Vec2{}, Max: Vec2{X: 100, Y: 100}, Valid: true}
+}
+
+// fmtNum は座標値を SVG 用に短く整形する
+func fmtNum(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// safeColor は色指定として安全な文字列のみを通す (属性値への埋め込み対策)
+var safeColorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|(rgb|rgba|hsl|hsla)\([0-9.,%\s]+\))$`)
+
+func safeColor(c string, def string) string {
+	c = strings.TrimSpace(c)
+	if c == "" || !safeColorPattern.MatchString(c) {
+		return def
+	}
+	return c
+}
+
+// svgEscape は XML のテキスト・属性値をエスケープする
+func svgEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '"':
+			b.WriteString("&quot;")
+		case '\'':
+			b.WriteString("&apos;")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// svgPathData は輪郭を SVG の d 属性に変換する
+func svgPathData(path shapePath, m paperMapper) string {
+	if len(path) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	p0 := m.point(path[0].P0)
+	fmt.Fprintf(&b, "M%s %s", fmtNum(p0.X), fmtNum(p0.Y))
+	for _, s := range path {
+		p3 := m.point(s.P3)
+		if s.Line {
+			fmt.Fprintf(&b, "L%s %s", fmtNum(p3.X), fmtNum(p3.Y))
+			continue
+		}
+		p1, p2 := m.point(s.P1), m.point(s.P2)
+		fmt.Fprintf(&b, "C%s %s %s %s %s %s",
+			fmtNum(p1.X), fmtNum(p1.Y), fmtNum(p2.X), fmtNum(p2.Y), fmtNum(p3.X), fmtNum(p3.Y))
+	}
+	b.WriteString("Z")
+	return b.String()
+}
+
+// formatJo は畳数を表示用に整形する (例: 6.1畳)
+func formatJo(m2 float64, tatamiSize string) string {
+	return strconv.FormatFloat(m2/tatamiAreasM2[tatamiSize], 'f', 1, 64) + "畳"
+}
+
+// renderPlanSVG は図面を SVG 文書に変換する。opts は normalizePlanOptions 済みであること。
+func renderPlanSVG(p plan, opts PlanExportOptions, title planTitle) string {
+	bounds := planBounds(p)
+	k := 10 / opts.Scale
+	drawW := bounds.width() * k
+	drawH := bounds.height() * k
+
+	pageW := drawW + 2*svgMarginMM
+	pageH := drawH + 2*svgMarginMM
+	if opts.TitleBlock {
+		pageW = math.Max(pageW, titleBlockWMM+2*svgMarginMM)
+		pageH += titleBlockHMM + svgMarginMM/2
+	}
+	m := newPaperMapper(bounds, opts.Scale, Vec2{X: svgMarginMM, Y: svgMarginMM})
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %s %s" font-family="sans-serif">`+"\n",
+		fmtNum(pageW), fmtNum(pageH), fmtNum(pageW), fmtNum(pageH))
+	fmt.Fprintf(&b, `<rect x="0" y="0" width="%s" height="%s" fill="#fff"/>`+"\n", fmtNum(pageW), fmtNum(pageH))
+
+	if opts.ShowGrid {
+		writeSVGGrid(&b, bounds, m, opts.GridSpacing)
+	}
+
+	b.WriteString(`<g id="shapes" stroke-linejoin="round">` + "\n")
+	for _, s := range p.Shapes {
+		stroke, width := "#666", itemStrokeMM
+		if s.AssetType == "room" || s.AssetType == wallType {
+			stroke, width = "#333", roomStrokeMM
+		}
+		fmt.Fprintf(&b, `<path d="%s" fill="%s" stroke="%s" stroke-width="%s"/>`+"\n",
+			svgPathData(s.Path, m), safeColor(s.Fill, "none"), stroke, fmtNum(width))
+	}
+	b.WriteString("</g>\n")
+
+	if opts.RoomLabels && len(p.Labels) > 0 {
+		b.WriteString(`<g id="labels" text-anchor="middle" fill="#333">` + "\n")
+		for _, l := range p.Labels {
+			pos := m.point(l.Pos)
+			fmt.Fprintf(&b, `<text x="%s" y="%s" font-size="%s" font-weight="bold">%s</text>`+"\n",
+				fmtNum(pos.X), fmtNum(pos.Y), fmtNum(roomLabelSizeMM), svgEscape(l.Name))
+			if l.AreaM2 > 0 {
+				fmt.Fprintf(&b, `<text x="%s" y="%s" font-size="%s">%s</text>`+"\n",
+					fmtNum(pos.X), fmtNum(pos.Y+roomLabelSizeMM+0.5), fmtNum(roomAreaSizeMM),
+					svgEscape(formatJo(l.AreaM2, opts.TatamiSize)))
+			}
+		}
+		b.WriteString("</g>\n")
+	}
+
+	if len(p.Texts) > 0 {
+		b.WriteString(`<g id="texts">` + "\n")
+		for _, t := range p.Texts {
+			pos := m.point(t.Pos)
+			attrs := fmt.Sprintf(`x="%s" y="%s" font-size="%s" fill="%s"`,
+				fmtNum(pos.X), fmtNum(pos.Y), fmtNum(m.length(t.Size)), safeColor(t.Color, "#000"))
+			if t.Rotation != 0 {
+				// 用紙座標は Y 下向きのため回転方向が反転する
+				attrs += fmt.Sprintf(` transform="rotate(%s %s %s)"`, fmtNum(-t.Rotation), fmtNum(pos.X), fmtNum(pos.Y))
+			}
+			if t.Middle {
+				attrs += ` text-anchor="middle"`
+			}
+			if t.Bold {
+				attrs += ` font-weight="bold"`
+			}
+			fmt.Fprintf(&b, "<text %s>%s</text>\n", attrs, svgEscape(t.Text))
+		}
+		b.WriteString("</g>\n")
+	}
+
+	if opts.TitleBlock {
+		writeSVGTitleBlock(&b, p, opts, title, Vec2{X: pageW - svgMarginMM - titleBlockWMM, Y: pageH - svgMarginMM - titleBlockHMM})
+	}
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// writeSVGGrid は描画範囲にグリッド線を描く
+func writeSVGGrid(b *strings.Builder, bounds bbox, m paperMapper, spacing float64) {
+	b.WriteString(`<g id="grid" stroke="#ddd" stroke-width="` + fmtNum(gridStrokeMM) + `">` + "\n")
+	top, bottom := m.point(Vec2{Y: bounds.Max.Y}).Y, m.point(Vec2{Y: bounds.Min.Y}).Y
+	left, right := m.point(Vec2{X: bounds.Min.X}).X, m.point(Vec2{X: bounds.Max.X}).X
+	for x := math.Ceil(bounds.Min.X/spacing) * spacing; x <= bounds.Max.X+1e-9; x += spacing {
+		px := m.point(Vec2{X: x}).X
+		fmt.Fprintf(b, `<line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n", fmtNum(px), fmtNum(top), fmtNum(px), fmtNum(bottom))
+	}
+	for y := math.Ceil(bounds.Min.Y/spacing) * spacing; y <= bounds.Max.Y+1e-9; y += spacing {
+		py := m.point(Vec2{Y: y}).Y
+		fmt.Fprintf(b, `<line x1="%s" y1="%s" x2="%s" y2="%s"/>`+"\n", fmtNum(left), fmtNum(py), fmtNum(right), fmtNum(py))
+	}
+	b.WriteString("</g>\n")
+}
+
+// writeSVGTitleBlock は右下にタイトル欄 (名前・日付・縮尺・延床面積) を描く
+func writeSVGTitleBlock(b *strings.Builder, p plan, opts PlanExportOptions, title planTitle, at Vec2) {
+	name := title.Name
+	if opts.Title != "" {
+		name = opts.Title
+	}
+	total := 0.0
+	for _, l := range p.Labels {
+		total += l.AreaM2
+	}
+	details := []string{"S=1:" + fmtNum(opts.Scale)}
+	if date := formatPlanDate(title.UpdatedAt); date != "" {
+		details = append(details, date)
+	}
+	if total > 0 {
+		details = append(details, fmt.Sprintf("延床 %.2fm² (%.2f坪)", total, total/tsuboM2))
+	}
+
+	fmt.Fprintf(b, `<g id="title-block" fill="#333"><rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="#333" stroke-width="%s"/>`+"\n",
+		fmtNum(at.X), fmtNum(at.Y), fmtNum(titleBlockWMM), fmtNum(titleBlockHMM), fmtNum(roomStrokeMM))
+	fmt.Fprintf(b, `<text x="%s" y="%s" font-size="%s" font-weight="bold">%s</text>`+"\n",
+		fmtNum(at.X+3), fmtNum(at.Y+8), fmtNum(titleTextSizeMM), svgEscape(name))
+	fmt.Fprintf(b, `<text x="%s" y="%s" font-size="%s">%s</text></g>`+"\n",
+		fmtNum(at.X+3), fmtNum(at.Y+15), fmtNum(detailTextSizeMM), svgEscape(strings.Join(details, "  ")))
+}
+
+// formatPlanDate は UpdatedAt (RFC3339) を日付表示にする。解析できない場合はそのまま返す。
+func formatPlanDate(s string) string {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Local().Format("2006/01/02")
+	}
+	return s
+}
+
+// planTitleFor はタイトル欄用のプロジェクト情報を取得する
+func (a *App) planTitleFor(id string) planTitle {
+	project, err := a.storage().GetProject(id)
+	if err != nil {
+		return planTitle{Name: id}
+	}
+	return planTitle{Name: project.Name, UpdatedAt: project.UpdatedAt}
+}
+
+// ExportProjectSVG renders the project layout as a standalone SVG document at the given scale
+func (a *App) ExportProjectSVG(id string, opts PlanExportOptions) (string, error) {
+	opts, err := normalizePlanOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return "", err
+	}
+	data, level, err := planLevel(data, assets, opts.Level)
+	if err != nil {
+		return "", err
+	}
+	svg := renderPlanSVG(buildPlan(data, assets), opts, levelTitle(a.planTitleFor(id), level))
+	a.logInfo("SVG エクスポート: %s (1:%s)", id, fmtNum(opts.Scale))
+	return svg, nil
+}
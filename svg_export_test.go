@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuildPlan はインスタンスの回転・平行移動と描画順を検証します
+func TestBuildPlan(t *testing.T) {
+	data := ProjectData{Instances: []Instance{
+		{ID: "f1", AssetID: "a_bed_s", Type: "furniture"},
+		{ID: "r1", AssetID: "a_room6", Type: "room", X: 100, Y: 50, Rotation: 90},
+		{ID: "t1", Type: "text", Text: "北", X: 0, Y: 0},
+		{ID: "x1", AssetID: "missing", Type: "furniture"},
+	}}
+	p := buildPlan(data, newAssetIndex(nil, getDefaultGlobalAssets()))
+
+	if len(p.Shapes) == 0 || p.Shapes[0].InstanceID != "r1" {
+		t.Fatalf("部屋が最初に描画されていません: %+v", p.Shapes)
+	}
+	// 360×270 の部屋を 90 度回転して (100, 50) に置くと x: -170..100, y: 50..410
+	b := p.Shapes[0].Path.bounds()
+	if !approxEqual(b.Min.X, -170, 1e-6) || !approxEqual(b.Max.X, 100, 1e-6) ||
+		!approxEqual(b.Min.Y, 50, 1e-6) || !approxEqual(b.Max.Y, 410, 1e-6) {
+		t.Errorf("部屋の位置が不正です: %+v", b)
+	}
+	if len(p.Labels) != 1 || !approxEqual(p.Labels[0].AreaM2, 9.72, 1e-9) {
+		t.Errorf("部屋ラベルが不正です: %+v", p.Labels)
+	}
+	if len(p.Texts) != 1 || p.Texts[0].Text != "北" {
+		t.Errorf("テキストインスタンスが含まれていません: %+v", p.Texts)
+	}
+}
+
+// TestRenderPlanSVG は縮尺・ラベル・タイトル欄・エスケープを検証します
+func TestRenderPlanSVG(t *testing.T) {
+	data := ProjectData{Instances: []Instance{
+		{ID: "r1", AssetID: "a_room6", Type: "room"},
+		{ID: "t1", Type: "text", Text: "<A&B>", Color: `red" onload="x`},
+	}}
+	opts, err := normalizePlanOptions(PlanExportOptions{Scale: 50, ShowGrid: true, TitleBlock: true, RoomLabels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := renderPlanSVG(buildPlan(data, newAssetIndex(nil, getDefaultGlobalAssets())), opts, planTitle{Name: "テスト邸"})
+
+	// 360×270cm の部屋は 1:50 で 72×54mm (余白 10mm の位置から描く)
+	if !strings.Contains(svg, `d="M10 64L82 64L82 10L10 10L10 64Z"`) {
+		t.Errorf("縮尺が反映されていません:\n%s", svg)
+	}
+	for _, want := range []string{`id="grid"`, "洋室", "畳", "テスト邸", "S=1:50", "&lt;A&amp;B&gt;"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("%q が含まれていません", want)
+		}
+	}
+	if strings.Contains(svg, "onload") {
+		t.Error("不正な色指定がそのまま出力されています")
+	}
+
+	if _, err := normalizePlanOptions(PlanExportOptions{Scale: -1}); err == nil {
+		t.Error("負の縮尺でエラーが返されていません")
+	}
+}
+
+// TestExportCommand はコマンドラインからの SVG 書き出しを検証します
+func TestExportCommand(t *testing.T) {
+	app := newTestApp(t)
+	proj, err := app.CreateProject("CLI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := ProjectData{LocalAssets: []Asset{}, Instances: []Instance{{ID: "r1", AssetID: "a_room6", Type: "room"}}}
+	if err := app.SaveProjectData(proj.ID, data); err != nil {
+		t.Fatal(err)
+	}
+	app.storage().Close()
+
+	out := filepath.Join(t.TempDir(), "plan.svg")
+	var stderr strings.Builder
+	code := runCLI([]string{"export", "-data", app.dataDir, "-project", "CLI", "-o", out, "-scale", "50"}, io.Discard, &stderr)
+	if code != 0 {
+		t.Fatalf("終了コードが不正です: %d (%s)", code, stderr.String())
+	}
+	svg, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(svg), "<?xml") || !strings.Contains(string(svg), "<path") {
+		t.Errorf("SVG が出力されていません:\n%s", svg)
+	}
+
+	if code := runCLI([]string{"export", "-data", app.dataDir, "-project", "none", "-o", out}, io.Discard, &stderr); code == 0 {
+		t.Error("存在しないプロジェクトでエラーになっていません")
+	}
+
+	// 標準出力にはコマンドの結果だけを書き、ログは標準エラー出力に書く
+	var stdout strings.Builder
+	stderr.Reset()
+	runCLI([]string{"check", "-data", app.dataDir, "-project", "CLI"}, &stdout, &stderr)
+	if strings.Contains(stdout.String(), "[INFO]") || !strings.Contains(stderr.String(), "[INFO]") {
+		t.Errorf("ログが標準出力に混ざっています:\n%s", stdout.String())
+	}
+
+	// 存在しないデータディレクトリは作らずにエラーにする
+	missing := filepath.Join(t.TempDir(), "missing")
+	if code := runCLI([]string{"export", "-data", missing, "-project", "CLI", "-o", out}, io.Discard, &stderr); code == 0 {
+		t.Error("存在しないデータディレクトリでエラーになっていません")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("存在しないデータディレクトリが作られています: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// --- サムネイル ---
+// プロジェクト一覧に表示する図面の縮小画像 (PNG)。最初の階を thumbnailWidth×thumbnailHeight に収めて描く。
+// 自動保存のたびに描かないよう、SaveProjectData はキャッシュを捨てるだけにして、一覧が求めたときに描いて保存する。
+
+const (
+	thumbnailWidth  = 320
+	thumbnailHeight = 180
+)
+
+// renderThumbnail はプロジェクトのサムネイルを描く
+func renderThumbnail(data ProjectData, assets assetIndex) ([]byte, error) {
+	data, _, err := planLevel(data, assets, "")
+	if err != nil {
+		return nil, err
+	}
+	return renderPlanPNG(buildPlan(data, assets), PNGExportOptions{Width: thumbnailWidth, Height: thumbnailHeight})
+}
+
+// updateThumbnail はサムネイルを作り直して保存する。失敗してもプロジェクトの保存は妨げない。
+func (a *App) updateThumbnail(id string, data ProjectData, assets assetIndex) []byte {
+	img, err := renderThumbnail(data, assets)
+	if err == nil {
+		err = a.storage().SaveThumbnail(id, img)
+	}
+	if err != nil {
+		a.logError("サムネイル作成失敗 (ID: %s): %v", id, err)
+	}
+	return img
+}
+
+// GetProjectThumbnail returns a PNG preview of the project plan for the project list
+// (base64-encoded on the frontend side). Thumbnails are rendered on the first request after a save and cached.
+func (a *App) GetProjectThumbnail(id string) ([]byte, error) {
+	img, err := a.storage().LoadThumbnail(id)
+	if err == nil {
+		return img, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return nil, err
+	}
+	if img := a.updateThumbnail(id, data, assets); img != nil {
+		return img, nil
+	}
+	return nil, errors.New("failed to render thumbnail")
+}
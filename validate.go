@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// --- プロジェクトデータの検証 ---
+// ProjectData を走査し、描画・計算できない値や参照切れを JSON のパス付きで列挙する。
+// error は図面や計算が壊れるもの、warning は読み替えて扱えるが意図と違う可能性が高いもの。
+// 保存・インポート時は設定 (validationMode) に従い、ログに残して保存するか、error があれば保存しない。
+
+// 検証結果の重大度 (ValidationIssue.Severity)
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// 保存時の検証の扱い (AppSettings.ValidationMode)
+const (
+	ValidationModeWarn  = "warn"
+	ValidationModeBlock = "block"
+)
+
+// knownAssetTypes はアセットとインスタンスの種類
+var knownAssetTypes = map[string]bool{"room": true, "furniture": true, "fixture": true}
+
+// knownEntityTypes は描画できるエンティティの種類
+var knownEntityTypes = map[string]bool{"polygon": true, "rect": true, "circle": true, "ellipse": true, "arc": true, "text": true}
+
+// validator は検証結果を集める
+type validator struct {
+	report ValidationReport
+}
+
+func (v *validator) add(severity, path, format string, args ...interface{}) {
+	v.report.Issues = append(v.report.Issues, ValidationIssue{Path: path, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	if severity == SeverityError {
+		v.report.Errors++
+	} else {
+		v.report.Warnings++
+	}
+}
+
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	v.add(SeverityError, path, format, args...)
+}
+
+func (v *validator) warnf(path, format string, args ...interface{}) {
+	v.add(SeverityWarning, path, format, args...)
+}
+
+// number は数値が有限であることを確かめる。nonNegative の場合は負の値もエラーにする。
+func (v *validator) number(path string, val float64, nonNegative bool) bool {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		v.errorf(path, "value is not a finite number")
+		return false
+	}
+	if nonNegative && val < 0 {
+		v.errorf(path, "value must not be negative: %s", fmtNum(val))
+		return false
+	}
+	return true
+}
+
+// optional は省略可能な数値を確かめる
+func (v *validator) optional(path string, val *float64, nonNegative bool) {
+	if val != nil {
+		v.number(path, *val, nonNegative)
+	}
+}
+
+// validateProjectData はプロジェクトデータを検証する。assets はローカル・グローバルのアセットの索引。
+func validateProjectData(data ProjectData, assets assetIndex) ValidationReport {
+	v := &validator{report: ValidationReport{Issues: []ValidationIssue{}}}
+
+	localIDs := map[string]bool{}
+	for i, a := range data.LocalAssets {
+		path := fmt.Sprintf("assets[%d]", i)
+		if a.ID == "" {
+			v.errorf(path+".id", "asset ID is empty")
+		} else if localIDs[a.ID] {
+			v.errorf(path+".id", "duplicate asset ID %q", a.ID)
+		}
+		localIDs[a.ID] = true
+		v.validateAsset(path, a)
+	}
+
+	levelIDs := map[string]bool{}
+	for i, l := range data.Levels {
+		path := fmt.Sprintf("levels[%d]", i)
+		if l.ID == "" {
+			v.errorf(path+".id", "level ID is empty")
+		} else if levelIDs[l.ID] {
+			v.errorf(path+".id", "duplicate level ID %q", l.ID)
+		}
+		levelIDs[l.ID] = true
+		v.number(path+".elevation", l.Elevation, false)
+		v.number(path+".height", l.Height, true)
+	}
+
+	instanceTypes := map[string]string{}
+	for _, inst := range data.Instances {
+		if _, ok := instanceTypes[inst.ID]; !ok {
+			instanceTypes[inst.ID] = inst.Type
+		}
+	}
+	seen := map[string]bool{}
+	for i, inst := range data.Instances {
+		path := fmt.Sprintf("instances[%d]", i)
+		if inst.ID == "" {
+			v.errorf(path+".id", "instance ID is empty")
+		} else if seen[inst.ID] {
+			v.errorf(path+".id", "duplicate instance ID %q", inst.ID)
+		}
+		seen[inst.ID] = true
+		v.validateInstance(path, inst, assets, instanceTypes)
+
+		if inst.LevelID != "" && len(data.Levels) > 0 && !levelIDs[inst.LevelID] {
+			v.warnf(path+".levelId", "unknown level %q (placed on the first level)", inst.LevelID)
+		}
+		if inst.TopLevelID != "" && !levelIDs[inst.TopLevelID] {
+			v.warnf(path+".topLevelId", "unknown level %q", inst.TopLevelID)
+		}
+	}
+
+	for key, c := range data.DefaultColors {
+		if _, ok := parseColor(c); !ok {
+			v.warnf("defaultColors."+key, "invalid color %q", c)
+		}
+	}
+	if data.CostRates != nil {
+		v.validateCostRates("costRates", *data.CostRates, assets)
+	}
+	return v.report
+}
+
+// validateAsset はアセットの寸法とエンティティを検証する
+func (v *validator) validateAsset(path string, a Asset) {
+	if !knownAssetTypes[a.Type] {
+		v.warnf(path+".type", "unknown asset type %q", a.Type)
+	}
+	v.number(path+".w", a.W, true)
+	v.number(path+".h", a.H, true)
+	v.optional(path+".boundX", a.BoundX, false)
+	v.optional(path+".boundY", a.BoundY, false)
+	v.number(path+".openingHeight", a.OpeningHeight, true)
+	v.number(path+".height", a.Height, true)
+	v.number(path+".unitPrice", a.UnitPrice, true)
+	v.number(path+".laborPrice", a.LaborPrice, true)
+	if a.Color != "" {
+		if _, ok := parseColor(a.Color); !ok {
+			v.warnf(path+".color", "invalid color %q", a.Color)
+		}
+	}
+	for j, e := range a.Entities {
+		v.validateEntity(fmt.Sprintf("%s.entities[%d]", path, j), e)
+	}
+}
+
+// validateEntity は形状ごとに必要な値がそろっているかを検証する
+func (v *validator) validateEntity(path string, e Entity) {
+	if !knownEntityTypes[e.Type] {
+		v.warnf(path+".type", "unknown entity type %q", e.Type)
+	}
+	for k, p := range e.Points {
+		pp := fmt.Sprintf("%s.points[%d]", path, k)
+		v.number(pp+".x", p.X, false)
+		v.number(pp+".y", p.Y, false)
+		if p.IsCurve {
+			v.number(pp+".h1.x", p.H1.X, false)
+			v.number(pp+".h1.y", p.H1.Y, false)
+			v.number(pp+".h2.x", p.H2.X, false)
+			v.number(pp+".h2.y", p.H2.Y, false)
+		}
+	}
+	v.optional(path+".cx", e.CX, false)
+	v.optional(path+".cy", e.CY, false)
+	v.optional(path+".rx", e.RX, true)
+	v.optional(path+".ry", e.RY, true)
+	v.optional(path+".startAngle", e.StartAngle, false)
+	v.optional(path+".endAngle", e.EndAngle, false)
+	v.optional(path+".rotation", e.Rotation, false)
+	v.optional(path+".x", e.X, false)
+	v.optional(path+".y", e.Y, false)
+	v.optional(path+".w", e.W, true)
+	v.optional(path+".h", e.H, true)
+	v.optional(path+".fontSize", e.FontSize, true)
+
+	switch e.Type {
+	case "polygon":
+		if len(e.Points) < 3 {
+			v.errorf(path+".points", "polygon needs at least 3 points, got %d", len(e.Points))
+		}
+	case "rect":
+		for _, f := range []struct {
+			name string
+			val  *float64
+		}{{"x", e.X}, {"y", e.Y}, {"w", e.W}, {"h", e.H}} {
+			if f.val == nil {
+				v.errorf(path+"."+f.name, "rect is missing %s", f.name)
+			}
+		}
+	case "circle", "ellipse", "arc":
+		// 半径は rx・ry、または外接矩形の w・h で指定する
+		if e.RX == nil && e.W == nil {
+			v.errorf(path+".rx", "%s is missing its radius (rx)", e.Type)
+		}
+		if e.Type != "circle" && e.RY == nil && e.H == nil {
+			v.errorf(path+".ry", "%s is missing its radius (ry)", e.Type)
+		}
+	}
+}
+
+// validateInstance はインスタンスの座標と参照先を検証する
+func (v *validator) validateInstance(path string, inst Instance, assets assetIndex, instanceTypes map[string]string) {
+	v.number(path+".x", inst.X, false)
+	v.number(path+".y", inst.Y, false)
+	v.number(path+".rotation", inst.Rotation, false)
+	v.optional(path+".fontSize", inst.FontSize, true)
+	v.number(path+".thickness", inst.Thickness, true)
+	v.number(path+".height", inst.Height, true)
+	v.number(path+".hostOffset", inst.HostOffset, false)
+
+	switch inst.Type {
+	case "":
+		v.errorf(path+".type", "instance type is empty")
+	case "text":
+		if strings.TrimSpace(inst.Text) == "" {
+			v.warnf(path+".text", "text is empty")
+		}
+	case wallType:
+		if v.number(path+".length", inst.Length, false) && inst.Length <= 0 {
+			v.errorf(path+".length", "wall length must be positive")
+		}
+	default:
+		if inst.AssetID == "" {
+			v.errorf(path+".assetId", "asset ID is empty")
+		} else if a := assets[inst.AssetID]; a == nil {
+			v.errorf(path+".assetId", "unknown asset %q", inst.AssetID)
+		} else if a.Type != inst.Type {
+			v.warnf(path+".type", "instance type %q differs from the asset type %q", inst.Type, a.Type)
+		}
+	}
+
+	if inst.HostID != "" {
+		switch host, ok := instanceTypes[inst.HostID]; {
+		case !ok:
+			v.errorf(path+".hostId", "unknown host wall %q", inst.HostID)
+		case host != wallType:
+			v.errorf(path+".hostId", "host %q is not a wall", inst.HostID)
+		}
+	}
+}
+
+// validateCostRates は見積の単価を検証する
+func (v *validator) validateCostRates(path string, rates CostRates, assets assetIndex) {
+	if v.number(path+".taxRate", rates.TaxRate, true) && rates.TaxRate > 1 {
+		v.errorf(path+".taxRate", "tax rate must be a fraction between 0 and 1")
+	}
+	if _, ok := tatamiAreasM2[rates.TatamiSize]; rates.TatamiSize != "" && !ok {
+		v.errorf(path+".tatamiSize", "unknown tatami size %q", rates.TatamiSize)
+	}
+	for i, f := range rates.Flooring {
+		fp := fmt.Sprintf("%s.flooring[%d]", path, i)
+		if f.Unit != "" && f.Unit != FlooringUnitM2 && f.Unit != FlooringUnitJo {
+			v.errorf(fp+".unit", "unknown flooring unit %q", f.Unit)
+		}
+		v.number(fp+".unitPrice", f.UnitPrice, true)
+		v.number(fp+".laborPrice", f.LaborPrice, true)
+		if f.AssetID != "" {
+			if a := assets[f.AssetID]; a == nil || a.Type != "room" {
+				v.warnf(fp+".assetId", "unknown room asset %q", f.AssetID)
+			}
+		}
+	}
+}
+
+// validationError は保存を止める検証エラー。最初のエラーと残りの件数を示す。
+func validationError(report ValidationReport) error {
+	for _, issue := range report.Issues {
+		if issue.Severity != SeverityError {
+			continue
+		}
+		msg := fmt.Sprintf("invalid project data: %s: %s", issue.Path, issue.Message)
+		if report.Errors > 1 {
+			msg += fmt.Sprintf(" (and %d more errors)", report.Errors-1)
+		}
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// checkProjectData は保存前の検証を行う。block の場合はエラーがあれば保存を止める。
+// 自動保存のたびに同じ結果を残さないよう、検証結果は前回の保存から変わったときだけログに残す。
+func (a *App) checkProjectData(id string, data ProjectData, assets assetIndex, mode string) error {
+	report := validateProjectData(data, assets)
+	lines := make([]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		lines[i] = fmt.Sprintf("%s: %s: %s", issue.Severity, issue.Path, issue.Message)
+	}
+	if a.swapValidationLog(id, strings.Join(lines, "\n")) {
+		for _, line := range lines {
+			a.logInfo("検証 (ID: %s) %s", id, line)
+		}
+	}
+	if report.Errors > 0 && mode == ValidationModeBlock {
+		return validationError(report)
+	}
+	return nil
+}
+
+// swapValidationLog はプロジェクトの検証結果を記録し、前回の記録から変わったかを返す
+func (a *App) swapValidationLog(id, issues string) bool {
+	a.validationMu.Lock()
+	defer a.validationMu.Unlock()
+	if a.validationLog == nil {
+		a.validationLog = map[string]string{}
+	}
+	prev, ok := a.validationLog[id]
+	a.validationLog[id] = issues
+	return ok && prev != issues || !ok && issues != ""
+}
+
+// ValidateProject checks the stored project data and returns every problem with the JSON path of the value:
+// errors for references to missing assets, walls or levels, duplicate IDs, shapes that cannot be drawn and
+// non-finite numbers, and warnings for values that are accepted but probably unintended.
+func (a *App) ValidateProject(id string) (ValidationReport, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+	return validateProjectData(data, assets), nil
+}
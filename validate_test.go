@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestValidateProjectData は参照切れ・形状の不足・重複 ID・非数をパス付きで検出し、block の設定で保存を止めることを検証します
+func TestValidateProjectData(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	assets := newAssetIndex(nil, globals)
+
+	// 既定のアセットをすべて置いたプロジェクトには問題が無い
+	var clean ProjectData
+	for _, a := range globals {
+		clean.Instances = append(clean.Instances, Instance{ID: a.ID + "_1", AssetID: a.ID, Type: a.Type})
+	}
+	if report := validateProjectData(clean, assets); len(report.Issues) != 0 {
+		t.Fatalf("既定のアセットで問題が報告されました: %+v", report.Issues)
+	}
+
+	one := 1.0
+	data := ProjectData{
+		LocalAssets: []Asset{{
+			ID: "l1", Type: "furniture", W: 10, H: 10,
+			Entities: []Entity{
+				{Type: "polygon", Points: []Point{{X: 0, Y: 0}, {X: math.NaN(), Y: 1}}},
+				{Type: "circle", CX: &one, CY: &one},
+				{Type: "ellipse", RX: &one},
+			},
+		}, {ID: "l1", Type: "room"}},
+		Instances: []Instance{
+			{ID: "i1", AssetID: "missing", Type: "furniture"},
+			{ID: "i1", AssetID: "a_room6", Type: "room", X: math.Inf(1)},
+			{ID: "i2", AssetID: "a_door", Type: "fixture", HostID: "i1"},
+			{ID: "i3", Type: "text"},
+		},
+	}
+	report := validateProjectData(data, newAssetIndex(data.LocalAssets, globals))
+	want := map[string]string{
+		"assets[0].entities[0].points":      SeverityError,
+		"assets[0].entities[0].points[1].x": SeverityError,
+		"assets[0].entities[1].rx":          SeverityError,
+		"assets[0].entities[2].ry":          SeverityError,
+		"assets[1].id":                      SeverityError,
+		"instances[0].assetId":              SeverityError,
+		"instances[1].id":                   SeverityError,
+		"instances[1].x":                    SeverityError,
+		"instances[2].hostId":               SeverityError,
+		"instances[3].text":                 SeverityWarning,
+	}
+	got := map[string]string{}
+	for _, issue := range report.Issues {
+		got[issue.Path] = issue.Severity
+	}
+	for path, severity := range want {
+		if got[path] != severity {
+			t.Errorf("%s の %s が報告されていません: %+v", path, severity, report.Issues)
+		}
+	}
+	if len(report.Issues) != len(want) || report.Errors != 9 || report.Warnings != 1 {
+		t.Errorf("報告の件数が不正です: %+v", report)
+	}
+
+	// 既定 (warn) では保存し、block ではエラーがあれば保存しない
+	app := newTestApp(t)
+	var logs bytes.Buffer
+	app.logOutput = &logs
+	proj, err := app.CreateProject("validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := ProjectData{Instances: []Instance{{ID: "i1", AssetID: "missing", Type: "furniture"}}}
+	if err := app.SaveProjectData(proj.ID, invalid); err != nil {
+		t.Fatalf("warn の設定で保存できません: %v", err)
+	}
+	if report, err := app.ValidateProject(proj.ID); err != nil || report.Errors != 1 || report.Issues[0].Path != "instances[0].assetId" {
+		t.Errorf("ValidateProject の結果が不正です: %+v, %v", report, err)
+	}
+
+	// 検証結果は変わったときだけログに残す
+	app.SaveProjectData(proj.ID, invalid)
+	if n := strings.Count(logs.String(), "検証 (ID"); n != 1 {
+		t.Errorf("同じ検証結果が %d 回ログに残りました: %s", n, logs.String())
+	}
+	app.SaveProjectData(proj.ID, ProjectData{})
+	app.SaveProjectData(proj.ID, invalid)
+	if n := strings.Count(logs.String(), "検証 (ID"); n != 2 {
+		t.Errorf("変わった検証結果がログに残っていません: %s", logs.String())
+	}
+
+	settings, _ := app.GetSettings()
+	settings.ValidationMode = ValidationModeBlock
+	if err := app.SaveSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SaveProjectData(proj.ID, invalid); err == nil {
+		t.Error("block の設定でエラーのあるデータが保存されました")
+	}
+	if _, err := app.ImportProject("invalid", `{"assets": [], "instances": [{"id": "i1", "type": "room", "assetId": "missing"}]}`); err == nil {
+		t.Error("block の設定でエラーのあるデータがインポートされました")
+	}
+	if projects, _ := app.GetProjects(); len(projects) != 1 {
+		t.Errorf("インポートに失敗したプロジェクトが残っています: %+v", projects)
+	}
+	settings.ValidationMode = "strict"
+	if err := app.SaveSettings(settings); err == nil {
+		t.Error("不明な検証の設定が保存されました")
+	}
+}
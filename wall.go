@@ -0,0 +1,562 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// --- 壁 ---
+// 壁は Type "wall" のインスタンスで、(X, Y) から Rotation の向きに Length だけ伸びる中心線と、
+// 厚さ Thickness・高さ Height を持つ。アセットは持たない。
+// 端どうしが接する所 (L字・十字など) は隣り合う面の線を交わらせて留め、
+// 端が他の壁の途中に接する所 (T字) はその壁の面まで伸ばす。
+// ドア・窓は HostID で壁に取り付けると、壁の中心線上の HostOffset の位置に壁の向きで置かれ、
+// 壁にはその幅の開口が空く。壁を動かすと保存時に取り付けた建具も付いて動く。
+
+const (
+	wallType                     = "wall"
+	defaultWallThickness         = 12.0  // 間仕切り壁の厚さ (cm)
+	defaultExteriorWallThickness = 15.0  // 外壁の厚さ (cm)
+	defaultWallHeight            = 240.0 // 天井高 (cm)
+	wallColor                    = "#444444"
+	wallJoinTolerance            = 1.0 // 端どうし・端と他の壁が接しているとみなす距離 (cm)
+	wallMiterLimit               = 4.0 // 留めの角が接合点から厚さの半分の何倍まで離れてよいか
+	minWallLength                = 1.0 // 部屋から作る壁の最小の長さ (cm)
+)
+
+// wallSegment は壁インスタンスの中心線と、接合を考えた端の角
+type wallSegment struct {
+	Inst     Instance
+	A, B     Vec2 // 中心線の始点・終点
+	Dir      Vec2 // 始点から終点への単位ベクトル
+	Normal   Vec2 // 左向きの単位法線
+	Length   float64
+	Half     float64      // 厚さの半分
+	Corners  [2][2]Vec2   // [端 (0: 始点, 1: 終点)][面 (0: 右, 1: 左)]
+	Openings [][2]float64 // 開口の区間 (中心線の始点からの距離)
+}
+
+// wallThickness は壁の厚さ (cm)。未指定なら既定値。
+func wallThickness(inst Instance) float64 {
+	if inst.Thickness > 0 {
+		return inst.Thickness
+	}
+	return defaultWallThickness
+}
+
+// wallHeight は壁の高さ (cm)。未指定なら既定値。
+func wallHeight(inst Instance) float64 {
+	if inst.Height > 0 {
+		return inst.Height
+	}
+	return defaultWallHeight
+}
+
+// newWallSegment は壁インスタンスの中心線を求める。壁でない・長さが無い場合は false。
+func newWallSegment(inst Instance) (wallSegment, bool) {
+	if inst.Type != wallType || inst.Length <= 0 {
+		return wallSegment{}, false
+	}
+	dir := rotateVec(Vec2{X: 1}, inst.Rotation)
+	w := wallSegment{
+		Inst:   inst,
+		A:      Vec2{X: inst.X, Y: inst.Y},
+		Dir:    dir,
+		Normal: Vec2{X: -dir.Y, Y: dir.X},
+		Length: inst.Length,
+		Half:   wallThickness(inst) / 2,
+	}
+	w.B = vadd(w.A, vscale(dir, inst.Length))
+	for end := 0; end < 2; end++ {
+		s := 0.0
+		if end == 1 {
+			s = w.Length
+		}
+		w.Corners[end] = [2]Vec2{w.face(0, s), w.face(1, s)}
+	}
+	return w, true
+}
+
+// end は中心線の端 (0: 始点, 1: 終点)
+func (w *wallSegment) end(i int) Vec2 {
+	if i == 0 {
+		return w.A
+	}
+	return w.B
+}
+
+// face は面 side (0: 右, 1: 左) 上で、中心線の始点から s の位置の点
+func (w *wallSegment) face(side int, s float64) Vec2 {
+	off := -w.Half
+	if side == 1 {
+		off = w.Half
+	}
+	return vadd(vadd(w.A, vscale(w.Dir, s)), vscale(w.Normal, off))
+}
+
+// corner は面 side 上の位置 s の点。壁の端では接合を考えた角を返す。
+func (w *wallSegment) corner(side int, s float64) Vec2 {
+	switch {
+	case s <= 0:
+		return w.Corners[0][side]
+	case s >= w.Length:
+		return w.Corners[1][side]
+	}
+	return w.face(side, s)
+}
+
+// polygons は開口で区切った壁の輪郭 (反時計回り) を返す
+func (w *wallSegment) polygons() [][]Vec2 {
+	openings := append([][2]float64(nil), w.Openings...)
+	sort.Slice(openings, func(i, j int) bool { return openings[i][0] < openings[j][0] })
+	var polys [][]Vec2
+	add := func(a, b float64) {
+		if b-a > 1e-6 {
+			polys = append(polys, []Vec2{w.corner(0, a), w.corner(0, b), w.corner(1, b), w.corner(1, a)})
+		}
+	}
+	from := 0.0
+	for _, o := range openings {
+		add(from, o[0])
+		from = math.Max(from, o[1])
+	}
+	add(from, w.Length)
+	return polys
+}
+
+// wallEnd は接合点に集まる壁の端
+type wallEnd struct {
+	Wall *wallSegment
+	End  int // 0: 始点, 1: 終点
+}
+
+// out は接合点から壁の本体へ向かう単位ベクトル
+func (e wallEnd) out() Vec2 {
+	if e.End == 0 {
+		return e.Wall.Dir
+	}
+	return vscale(e.Wall.Dir, -1)
+}
+
+// leftSide は out の左側にある面 (0: 右, 1: 左)
+func (e wallEnd) leftSide() int {
+	if e.End == 0 {
+		return 1
+	}
+	return 0
+}
+
+// joinWalls は壁の端の角を接合に合わせて動かす
+func joinWalls(walls []*wallSegment) {
+	type node struct {
+		P    Vec2
+		Ends []wallEnd
+	}
+	var nodes []*node
+	for _, w := range walls {
+		for end := 0; end < 2; end++ {
+			p := w.end(end)
+			var n *node
+			for _, c := range nodes {
+				if vlen(vsub(c.P, p)) <= wallJoinTolerance {
+					n = c
+					break
+				}
+			}
+			if n == nil {
+				n = &node{P: p}
+				nodes = append(nodes, n)
+			}
+			n.Ends = append(n.Ends, wallEnd{Wall: w, End: end})
+		}
+	}
+
+	for _, n := range nodes {
+		if len(n.Ends) == 1 {
+			joinTee(n.Ends[0], walls)
+			continue
+		}
+		// 接合点のまわりに反時計回りに並べ、隣り合う壁の向かい合う面を交わらせる
+		sort.Slice(n.Ends, func(i, j int) bool {
+			a, b := n.Ends[i].out(), n.Ends[j].out()
+			return math.Atan2(a.Y, a.X) < math.Atan2(b.Y, b.X)
+		})
+		for i, a := range n.Ends {
+			b := n.Ends[(i+1)%len(n.Ends)]
+			sa, sb := a.leftSide(), 1-b.leftSide()
+			p, ok := lineIntersection(a.Wall.Corners[a.End][sa], a.Wall.Dir, b.Wall.Corners[b.End][sb], b.Wall.Dir)
+			if !ok || vlen(vsub(p, n.P)) > wallMiterLimit*math.Max(a.Wall.Half, b.Wall.Half) {
+				continue // 一直線につながる壁や鋭すぎる角は端を直角のままにする
+			}
+			a.Wall.Corners[a.End][sa] = p
+			b.Wall.Corners[b.End][sb] = p
+		}
+	}
+}
+
+// joinTee は他の壁の途中に接する端を、その壁の手前の面まで伸ばす (縮める)
+func joinTee(e wallEnd, walls []*wallSegment) {
+	p := e.Wall.end(e.End)
+	for _, host := range walls {
+		if host == e.Wall {
+			continue
+		}
+		s := vdot(vsub(p, host.A), host.Dir)
+		if s <= 0 || s >= host.Length || math.Abs(vcross(host.Dir, vsub(p, host.A))) > host.Half+wallJoinTolerance {
+			continue
+		}
+		side := 0
+		if vcross(host.Dir, vsub(e.Wall.end(1-e.End), host.A)) > 0 {
+			side = 1
+		}
+		q := host.face(side, 0)
+		for k := 0; k < 2; k++ {
+			if c, ok := lineIntersection(e.Wall.Corners[e.End][k], e.Wall.Dir, q, host.Dir); ok {
+				e.Wall.Corners[e.End][k] = c
+			}
+		}
+		return
+	}
+}
+
+// openingAxis は建具の長辺の向きを返す。start は長辺方向の中心線の始点 (アセット座標)、width は長辺の長さ、
+// rot は長辺を壁の向きに合わせるための追加の回転 (度)。
+func openingAxis(asset Asset) (start Vec2, width, rot float64) {
+	lb := assetLocalBounds(asset)
+	c := lb.center()
+	if lb.width() >= lb.height() {
+		return Vec2{X: lb.Min.X, Y: c.Y}, lb.width(), 0
+	}
+	return Vec2{X: c.X, Y: lb.Min.Y}, lb.height(), -90
+}
+
+// hostOffset は開口が壁からはみ出さないように HostOffset を詰めた値
+func hostOffset(inst Instance, w *wallSegment, width float64) float64 {
+	return math.Max(0, math.Min(inst.HostOffset, w.Length-width))
+}
+
+// hostedPlacement は壁に取り付けた建具の位置と回転を壁に合わせる
+func hostedPlacement(inst Instance, asset Asset, w *wallSegment) Instance {
+	start, width, rot := openingAxis(asset)
+	off := hostOffset(inst, w, width)
+	p := vadd(w.A, vscale(w.Dir, off))
+	inst.HostOffset = roundCoord(off)
+	inst.Rotation = normalizeDeg(roundCoord(w.Inst.Rotation + rot))
+	if inst.HostFlip {
+		// 反対向きに置き、長辺の始点を開口の終わりに合わせる
+		inst.Rotation = normalizeDeg(inst.Rotation + 180)
+		p = vadd(p, vscale(w.Dir, width))
+	}
+	o := vsub(p, rotateVec(start, inst.Rotation))
+	inst.X, inst.Y = roundCoord(o.X), roundCoord(o.Y)
+	return inst
+}
+
+// placeWalls は壁インスタンスをつなぎ、取り付けられたドア・窓の開口を付けて返す
+func placeWalls(data ProjectData, assets assetIndex) []*wallSegment {
+	var walls []*wallSegment
+	byID := map[string]*wallSegment{}
+	byLevel := map[int][]*wallSegment{}
+	for _, inst := range data.Instances {
+		if w, ok := newWallSegment(inst); ok {
+			walls = append(walls, &w)
+			byID[inst.ID] = &w
+			level := instanceLevel(inst, data.Levels)
+			byLevel[level] = append(byLevel[level], &w)
+		}
+	}
+	// 壁は同じ階の壁とだけつなぐ
+	for _, level := range byLevel {
+		joinWalls(level)
+	}
+	for _, inst := range data.Instances {
+		w, asset := byID[inst.HostID], assets.lookup(inst)
+		if inst.HostID == "" || w == nil || asset == nil {
+			continue
+		}
+		_, width, _ := openingAxis(*asset)
+		off := hostOffset(inst, w, width)
+		w.Openings = append(w.Openings, [2]float64{off, off + width})
+	}
+	return walls
+}
+
+// syncHostedOpenings は壁に取り付けた建具を壁の位置に合わせる。壁が無くなった建具は今の位置のまま取り外す。
+func syncHostedOpenings(data ProjectData, assets assetIndex) ProjectData {
+	walls := map[string]*wallSegment{}
+	for _, inst := range data.Instances {
+		if w, ok := newWallSegment(inst); ok {
+			walls[inst.ID] = &w
+		}
+	}
+	instances := make([]Instance, len(data.Instances))
+	for i, inst := range data.Instances {
+		if inst.HostID != "" {
+			if w := walls[inst.HostID]; w == nil {
+				inst.HostID, inst.HostOffset, inst.HostFlip = "", 0, false
+			} else if asset := assets.lookup(inst); asset != nil {
+				inst = hostedPlacement(inst, *asset, w)
+			}
+		}
+		instances[i] = inst
+	}
+	data.Instances = instances
+	return data
+}
+
+// hostOpenings は壁の上にあるドア・窓を、向きを保ったまま同じ階の壁に取り付ける
+func hostOpenings(instances []Instance, assets assetIndex, levels []Level) {
+	var walls []*wallSegment
+	for _, inst := range instances {
+		if w, ok := newWallSegment(inst); ok {
+			walls = append(walls, &w)
+		}
+	}
+	for i := range instances {
+		inst := &instances[i]
+		asset := assets.lookup(*inst)
+		if asset == nil || openingKind(*asset) == "" {
+			continue
+		}
+		a, b, thickness := windowSpan(*inst, *asset)
+		_, width, _ := openingAxis(*asset)
+		for _, w := range walls {
+			if instanceLevel(w.Inst, levels) != instanceLevel(*inst, levels) {
+				continue
+			}
+			lo, hi, ok := collinearOverlap(a, b, w.A, w.B, w.Half+thickness/2+wallJoinTolerance)
+			if !ok || hi-lo < width/2 {
+				continue
+			}
+			sa, sb := vdot(vsub(a, w.A), w.Dir), vdot(vsub(b, w.A), w.Dir)
+			inst.HostID = w.Inst.ID
+			inst.HostFlip = sb < sa
+			inst.HostOffset = math.Min(sa, sb)
+			*inst = hostedPlacement(*inst, *asset, w)
+			break
+		}
+	}
+}
+
+// wallPiece は部屋の辺から作る壁の中心線
+type wallPiece struct {
+	A, B      Vec2
+	Thickness float64
+}
+
+// mergeWallPieces は同じ厚さで一直線につながる2枚の壁を1枚にする
+func mergeWallPieces(a, b wallPiece) (wallPiece, bool) {
+	if a.Thickness != b.Thickness {
+		return wallPiece{}, false
+	}
+	touch := false
+	for _, p := range []Vec2{a.A, a.B} {
+		for _, q := range []Vec2{b.A, b.B} {
+			touch = touch || vlen(vsub(p, q)) <= wallJoinTolerance
+		}
+	}
+	if !touch {
+		return wallPiece{}, false
+	}
+	d := vsub(a.B, a.A)
+	l := vlen(d)
+	if math.Abs(vcross(d, vsub(b.B, b.A)))/(l*vlen(vsub(b.B, b.A))) > 0.02 ||
+		math.Abs(vcross(d, vsub(b.A, a.A)))/l > wallJoinTolerance || math.Abs(vcross(d, vsub(b.B, a.A)))/l > wallJoinTolerance {
+		return wallPiece{}, false
+	}
+	u := vscale(d, 1/l)
+	lo, hi := 0.0, l
+	for _, q := range []Vec2{b.A, b.B} {
+		s := vdot(vsub(q, a.A), u)
+		lo, hi = math.Min(lo, s), math.Max(hi, s)
+	}
+	return wallPiece{A: vadd(a.A, vscale(u, lo)), B: vadd(a.A, vscale(u, hi)), Thickness: a.Thickness}, true
+}
+
+// roomWallPieces は部屋の辺に沿って壁を作る。2つの部屋が接している区間には間仕切り壁を1枚、
+// それ以外 (屋外のベランダ等に接する区間を含む) には外壁を作る。中心線は部屋の辺 (芯々) とする。
+func roomWallPieces(rooms []placedShape, interior, exterior float64) []wallPiece {
+	type sharedSpan struct {
+		Lo, Hi float64
+		Room   int
+	}
+	var pieces []wallPiece
+	for i, r := range rooms {
+		if isOutdoor(*r.Asset) {
+			continue
+		}
+		for _, poly := range r.Polygons {
+			for k := range poly {
+				p, q := poly[k], poly[(k+1)%len(poly)]
+				l := vlen(vsub(q, p))
+				if l < minWallLength {
+					continue
+				}
+				var spans []sharedSpan
+				cuts := []float64{0, l}
+				for j, o := range rooms {
+					if j == i || isOutdoor(*o.Asset) {
+						continue
+					}
+					for _, op := range o.Polygons {
+						for m := range op {
+							if lo, hi, ok := collinearOverlap(op[m], op[(m+1)%len(op)], p, q, wallJoinTolerance); ok && hi-lo >= minWallLength {
+								spans = append(spans, sharedSpan{Lo: lo, Hi: hi, Room: j})
+								cuts = append(cuts, lo, hi)
+							}
+						}
+					}
+				}
+				sort.Float64s(cuts)
+				for c := 0; c+1 < len(cuts); c++ {
+					lo, hi := cuts[c], cuts[c+1]
+					if hi-lo < 1e-6 {
+						continue
+					}
+					thickness := exterior
+					for _, s := range spans {
+						if s.Lo <= (lo+hi)/2 && (lo+hi)/2 <= s.Hi {
+							thickness = interior
+							if s.Room < i {
+								thickness = 0 // 接している部屋の側で作る
+							}
+							break
+						}
+					}
+					if thickness > 0 {
+						pieces = append(pieces, wallPiece{A: vlerp(p, q, lo/l), B: vlerp(p, q, hi/l), Thickness: thickness})
+					}
+				}
+			}
+		}
+	}
+
+	for merged := true; merged; {
+		merged = false
+		for a := 0; a < len(pieces) && !merged; a++ {
+			for b := a + 1; b < len(pieces); b++ {
+				if m, ok := mergeWallPieces(pieces[a], pieces[b]); ok {
+					pieces[a] = m
+					pieces = append(pieces[:b], pieces[b+1:]...)
+					merged = true
+					break
+				}
+			}
+		}
+	}
+	return pieces
+}
+
+// deriveWalls は部屋の輪郭から壁を作ったプロジェクトを返す。既存の壁は置き換え、
+// 壁の上にあるドア・窓は壁に取り付ける。
+func deriveWalls(data ProjectData, assets assetIndex, opts WallOptions) (ProjectData, error) {
+	if opts.Thickness < 0 || opts.ExteriorThickness < 0 {
+		return ProjectData{}, fmt.Errorf("invalid wall thickness: %v, %v", opts.Thickness, opts.ExteriorThickness)
+	}
+	if opts.Height < 0 {
+		return ProjectData{}, fmt.Errorf("invalid wall height: %v", opts.Height)
+	}
+	interior, exterior, height := opts.Thickness, opts.ExteriorThickness, opts.Height
+	if interior == 0 {
+		interior = defaultWallThickness
+	}
+	if exterior == 0 {
+		exterior = defaultExteriorWallThickness
+	}
+	if height == 0 {
+		height = defaultWallHeight
+	}
+
+	var instances []Instance
+	used := map[string]bool{}
+	for _, inst := range data.Instances {
+		if inst.Type == wallType {
+			continue
+		}
+		inst.HostID, inst.HostOffset, inst.HostFlip = "", 0, false
+		instances = append(instances, inst)
+		used[inst.ID] = true
+	}
+	// 壁以外の配置が使っている ID は飛ばす
+	n := 0
+	nextID := func() string {
+		for {
+			n++
+			if id := fmt.Sprintf("i-wall-%d", n); !used[id] {
+				return id
+			}
+		}
+	}
+	for i, level := range splitLevels(data, assets) {
+		var rooms []placedShape
+		for _, s := range placeShapes(level, assets) {
+			if s.Asset.Type == "room" {
+				rooms = append(rooms, s)
+			}
+		}
+		levelID := ""
+		if len(data.Levels) > 0 {
+			levelID = data.Levels[i].ID
+		}
+		for _, pc := range roomWallPieces(rooms, interior, exterior) {
+			d := vsub(pc.B, pc.A)
+			instances = append(instances, Instance{
+				ID:        nextID(),
+				Type:      wallType,
+				X:         roundCoord(pc.A.X),
+				Y:         roundCoord(pc.A.Y),
+				Rotation:  normalizeDeg(roundCoord(math.Atan2(d.Y, d.X) * 180 / math.Pi)),
+				Length:    roundCoord(vlen(d)),
+				Thickness: pc.Thickness,
+				Height:    height,
+				LevelID:   levelID,
+			})
+		}
+	}
+	hostOpenings(instances, assets, data.Levels)
+	data.Instances = instances
+	return data, nil
+}
+
+// wallOutlines は壁ごとの輪郭を返す
+func wallOutlines(data ProjectData, assets assetIndex) []WallOutline {
+	outlines := []WallOutline{}
+	for _, w := range placeWalls(data, assets) {
+		o := WallOutline{InstanceID: w.Inst.ID, Polygons: [][]Vec2{}}
+		for _, poly := range w.polygons() {
+			for i, v := range poly {
+				poly[i] = Vec2{X: roundCoord(v.X), Y: roundCoord(v.Y)}
+			}
+			o.Polygons = append(o.Polygons, poly)
+		}
+		outlines = append(outlines, o)
+	}
+	return outlines
+}
+
+// DeriveWalls returns the project with walls generated along the room boundaries: one interior wall where
+// two rooms touch and exterior walls elsewhere, centred on the boundary. Existing walls are replaced and
+// doors and windows lying on a wall are hosted in it. The project is not saved.
+func (a *App) DeriveWalls(id string, opts WallOptions) (ProjectData, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return ProjectData{}, err
+	}
+	derived, err := deriveWalls(data, assets, opts)
+	if err != nil {
+		a.logError("壁の生成失敗 (ID: %s): %v", id, err)
+		return ProjectData{}, err
+	}
+	a.logInfo("壁の生成: %s", id)
+	return derived, nil
+}
+
+// GetWallOutlines returns the outline of each wall of a project with its joins and openings applied.
+func (a *App) GetWallOutlines(id string) ([]WallOutline, error) {
+	data, assets, err := a.loadProject(id)
+	if err != nil {
+		return nil, err
+	}
+	return wallOutlines(data, assets), nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import "testing"
+
+// TestDeriveWalls は部屋の輪郭から外壁と間仕切り壁を作り、ドア・窓を壁に取り付けることを検証します
+func TestDeriveWalls(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	// 洋室 (360×270) の上に LDK (360×450)、下にベランダ
+	data := ProjectData{
+		LocalAssets: []Asset{},
+		Instances: []Instance{
+			{ID: "room", AssetID: "a_room6", Type: "room"},
+			{ID: "ldk", AssetID: "a_ldk10", Type: "room", Y: 270},
+			{ID: "balcony", AssetID: "a_balcony", Type: "room", Y: -90},
+			{ID: "door", AssetID: "a_door", Type: "fixture", X: 40, Y: 267.5},
+			{ID: "window", AssetID: "a_window", Type: "fixture", X: 90, Y: -2.5},
+			{ID: "old", Type: wallType, Length: 100},
+		},
+	}
+	assets := newAssetIndex(data.LocalAssets, globals)
+
+	derived, err := deriveWalls(data, assets, WallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var walls []Instance
+	insts := map[string]Instance{}
+	for _, inst := range derived.Instances {
+		insts[inst.ID] = inst
+		if inst.Type == wallType {
+			walls = append(walls, inst)
+		}
+	}
+	if _, ok := insts["old"]; ok || len(walls) != 5 {
+		t.Fatalf("壁の数が不正です: %+v", walls)
+	}
+	exterior := 0.0
+	for _, w := range walls {
+		switch w.Thickness {
+		case defaultExteriorWallThickness:
+			exterior += w.Length
+		case defaultWallThickness:
+			if w.Length != 360 || w.Y != 270 {
+				t.Errorf("間仕切り壁が不正です: %+v", w)
+			}
+		}
+		if w.Height != defaultWallHeight {
+			t.Errorf("壁の高さが不正です: %+v", w)
+		}
+	}
+	if exterior != 2160 {
+		t.Errorf("外壁の長さの合計が不正です: %v", exterior)
+	}
+
+	door, window := insts["door"], insts["window"]
+	if door.HostID == "" || insts[door.HostID].Thickness != defaultWallThickness || door.X != 40 || door.Y != 267.5 || door.Rotation != 0 {
+		t.Errorf("ドアが間仕切り壁に取り付けられていません: %+v", door)
+	}
+	if window.HostID == "" || insts[window.HostID].Thickness != defaultExteriorWallThickness || window.X != 90 || window.Y != -2.5 {
+		t.Errorf("窓が外壁に取り付けられていません: %+v", window)
+	}
+
+	// 外壁は留め、間仕切り壁は外壁の面まで。開口の分だけ壁が抜ける
+	area, parts := 0.0, 0
+	for _, o := range wallOutlines(derived, assets) {
+		for _, poly := range o.Polygons {
+			if polygonArea(poly) <= 0 {
+				t.Errorf("%s の輪郭が反時計回りではありません: %v", o.InstanceID, poly)
+			}
+			area += polygonArea(poly)
+			parts++
+		}
+	}
+	want := (375*735 - 345*705) - 180*15 + (345-80)*12.0
+	if !approxEqual(area, want, 0.01) || parts != 7 {
+		t.Errorf("壁の面積が不正です: %v (%d 個), want %v", area, parts, want)
+	}
+
+	// 既に使われている ID と重ならない。作り直しても重ならない
+	data.Instances = append(data.Instances, Instance{ID: "i-wall-1", Type: "text", Text: "i-wall-1"})
+	for i := 0; i < 2; i++ {
+		if data, err = deriveWalls(data, assets, WallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		ids := map[string]bool{}
+		for _, inst := range data.Instances {
+			if ids[inst.ID] {
+				t.Errorf("ID %s が重複しています", inst.ID)
+			}
+			ids[inst.ID] = true
+		}
+	}
+
+	if _, err := deriveWalls(data, assets, WallOptions{Thickness: -1}); err == nil {
+		t.Error("負の厚さでエラーになっていません")
+	}
+}
+
+// TestHostedOpeningsFollowWall は壁を動かすと取り付けた建具が付いて動くことを検証します
+func TestHostedOpeningsFollowWall(t *testing.T) {
+	globals := getDefaultGlobalAssets()
+	data := ProjectData{
+		LocalAssets: []Asset{},
+		Instances: []Instance{
+			{ID: "w1", Type: wallType, Length: 400, Thickness: 10},
+			{ID: "w2", Type: wallType, Rotation: 90, Length: 200, Thickness: 10},
+			{ID: "door", AssetID: "a_door", Type: "fixture", HostID: "w1", HostOffset: 100},
+			{ID: "window", AssetID: "a_window", Type: "fixture", HostID: "w1", HostOffset: 260},
+			{ID: "orphan", AssetID: "a_door", Type: "fixture", X: 10, Y: 20, HostID: "missing", HostOffset: 5},
+		},
+	}
+	assets := newAssetIndex(data.LocalAssets, globals)
+
+	// L字の角は外側 (-5, -5) と内側 (5, 5) で留める
+	walls := placeWalls(data, assets)
+	if c := walls[0].Corners[0]; c[0] != (Vec2{X: -5, Y: -5}) || !approxEqual(c[1].X, 5, 1e-9) || !approxEqual(c[1].Y, 5, 1e-9) {
+		t.Errorf("L字の角が不正です: %v", c)
+	}
+	if len(walls[0].polygons()) != 2 {
+		t.Errorf("開口で壁が分かれていません: %v", walls[0].polygons())
+	}
+
+	data.Instances[0].X, data.Instances[0].Y, data.Instances[0].Rotation = 50, 100, 90
+	synced := syncHostedOpenings(data, assets)
+	door, window, orphan := synced.Instances[2], synced.Instances[3], synced.Instances[4]
+	if door.X != 52.5 || door.Y != 200 || door.Rotation != 90 {
+		t.Errorf("ドアが壁に付いて動いていません: %+v", door)
+	}
+	if window.HostOffset != 220 || window.Y != 320 {
+		t.Errorf("壁からはみ出す窓が詰められていません: %+v", window)
+	}
+	if orphan.HostID != "" || orphan.X != 10 || orphan.Y != 20 {
+		t.Errorf("壁の無い建具が取り外されていません: %+v", orphan)
+	}
+
+	var shapes int
+	for _, s := range buildPlan(synced, assets).Shapes {
+		if s.AssetType == wallType {
+			shapes++
+		}
+	}
+	if shapes != 3 {
+		t.Errorf("図面の壁の数が不正です: %d", shapes)
+	}
+}